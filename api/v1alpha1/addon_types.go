@@ -17,9 +17,13 @@ package v1alpha1
 import (
 	"fmt"
 	"hash/adler32"
+	"hash/fnv"
 	"strconv"
 
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/json"
 )
 
@@ -46,6 +50,21 @@ type AddonParams struct {
 	// Data values that will be parameters injected into workflows
 	// +optional
 	Data map[string]FlexString `json:"data,omitempty"`
+	// Features toggle optional features of the addon, templates reference them with {{addon.features.<key>}}
+	// +optional
+	Features map[string]bool `json:"features,omitempty"`
+}
+
+// AddonFeature declares a feature toggle of the addon
+type AddonFeature struct {
+	// Name is the key of the feature in spec.params.features
+	Name string `json:"name"`
+	// Default is the value of the feature when it is not set in spec.params.features
+	// +optional
+	Default bool `json:"default,omitempty"`
+	// Description of the feature
+	// +optional
+	Description string `json:"description,omitempty"`
 }
 
 // FlexString is a ptr to string type that is used to provide additional configs
@@ -133,7 +152,8 @@ const (
 	random
 )
 
-// ApplicationAssemblyPhase tracks the Addon CRD phases: pending, succeeded, failed, deleting, deleteFailed
+// ApplicationAssemblyPhase tracks the Addon CRD phases: pending, succeeded, failed, deleting, deleteFailed, rolledBack,
+// quarantined, skipped, conflict
 type ApplicationAssemblyPhase string
 
 // Constants
@@ -151,6 +171,45 @@ const (
 	Deleting ApplicationAssemblyPhase = "Deleting"
 	// DeleteFailed Used to indicate that delete failed.
 	DeleteFailed ApplicationAssemblyPhase = "Delete Failed"
+	// RolledBack Used to indicate that install failed and the last successfully applied spec was installed again.
+	RolledBack ApplicationAssemblyPhase = "Rolled Back"
+	// ValidationPassed Used to indicate that an addon in Validate mode is valid, it is not installed.
+	ValidationPassed ApplicationAssemblyPhase = "Validation Passed"
+	// Quarantined Used to indicate that the addon failed too many times in a row and is not retried until its spec
+	// changes or it is force reinstalled.
+	Quarantined ApplicationAssemblyPhase = "Quarantined"
+	// Skipped Used to indicate that the cluster does not meet the requirements of the addon, it is not installed.
+	Skipped ApplicationAssemblyPhase = "Skipped"
+	// Conflict Used to indicate that the package version of the addon is installed by another addon, it is not
+	// installed.
+	Conflict ApplicationAssemblyPhase = "Conflict"
+)
+
+// Completed returns true if the install has finished, successfully or not
+func (p ApplicationAssemblyPhase) Completed() bool {
+	switch p {
+	case Succeeded, Failed, ValidationFailed, ValidationPassed, RolledBack, Quarantined, Skipped, Conflict:
+		return true
+	}
+	return false
+}
+
+// FailureCategory is the cause of the last failure of an addon
+type FailureCategory string
+
+const (
+	// FailureValidation addons have an invalid spec
+	FailureValidation FailureCategory = "Validation"
+	// FailureDependency addons have dependencies, CRDs or a namespace which are not available
+	FailureDependency FailureCategory = "Dependency"
+	// FailureWorkflow addons failed to run a lifecycle workflow
+	FailureWorkflow FailureCategory = "Workflow"
+	// FailureSecret addons are missing required secrets or image pull secrets
+	FailureSecret FailureCategory = "Secret"
+	// FailureObservation addons failed to observe their resources
+	FailureObservation FailureCategory = "Observation"
+	// FailureTimeout addons did not complete within their ttl or lifecycle step timeout
+	FailureTimeout FailureCategory = "Timeout"
 )
 
 // DeploymentPhase represents the status of observed resources
@@ -163,8 +222,21 @@ const (
 	Ready DeploymentPhase = "Ready"
 	// Unknown deployment phase for resources in addon
 	Unknown DeploymentPhase = "Unknown"
+	// Missing deployment phase for resources of an installed addon which no longer exist
+	Missing DeploymentPhase = "Missing"
+	// Degraded deployment phase for cron jobs which have not succeeded within their schedule interval
+	Degraded DeploymentPhase = "Degraded"
 )
 
+// DegradedCondition is true while resources of the installed addon are missing or its cron jobs are not succeeding
+const DegradedCondition = "Degraded"
+
+// ResourcesTruncatedCondition is true while status lists fewer resources than were observed
+const ResourcesTruncatedCondition = "ResourcesTruncated"
+
+// RolloutPausedCondition is true while the install of the addon is held because addons of a previous rollout stage failed
+const RolloutPausedCondition = "RolloutPaused"
+
 // LifecycleStep is a string representation of the lifecycle steps available in Addon spec: prereqs, install, delete, validate
 type LifecycleStep string
 
@@ -177,8 +249,26 @@ const (
 	Delete LifecycleStep = "delete"
 	// Validate constant
 	Validate LifecycleStep = "validate"
+	// Combined constant, the workflow running prereqs and install together
+	Combined LifecycleStep = "combined"
+	// PostInstall constant, the workflow validating the addon after install
+	PostInstall LifecycleStep = "postinstall"
 )
 
+// AddonRequirements are the capabilities a cluster needs for an addon to be installed on it
+type AddonRequirements struct {
+	// MinKubernetesVersion is the lowest Kubernetes version the addon is installed on, e.g. 1.19
+	// +optional
+	MinKubernetesVersion string `json:"minKubernetesVersion,omitempty"`
+	// MaxKubernetesVersion is the highest Kubernetes version the addon is installed on, a major.minor version
+	// includes its patch releases
+	// +optional
+	MaxKubernetesVersion string `json:"maxKubernetesVersion,omitempty"`
+	// APIGroups are the API groups the cluster must serve, e.g. networking.istio.io
+	// +optional
+	APIGroups []string `json:"apiGroups,omitempty"`
+}
+
 // AddonOverridesSpec represents a template of the resources that can be deployed or patched alongside the main deployment
 type AddonOverridesSpec struct {
 	// Kustomize specs
@@ -187,6 +277,73 @@ type AddonOverridesSpec struct {
 	// Template specs
 	// +optional
 	Template map[string]string `json:"template,omitempty" protobuf:"bytes,2,rep,name=template"`
+	// Patches are applied to the resources rendered from the addon source before they are applied
+	// +optional
+	Patches []OverridePatch `json:"patches,omitempty"`
+}
+
+// AddonMode is what the controller does with a valid addon
+type AddonMode string
+
+const (
+	// InstallMode installs valid addons, the default
+	InstallMode AddonMode = "Install"
+	// ValidateMode only validates addons, workflows are never run and no resources are created or deleted
+	ValidateMode AddonMode = "Validate"
+)
+
+// OverridePatchType is the type of an override patch: strategic or json
+type OverridePatchType string
+
+const (
+	// StrategicMergePatch merges the patch into the resource, lists are merged by their merge keys
+	StrategicMergePatch OverridePatchType = "strategic"
+	// JSONPatch applies the RFC 6902 operations of the patch to the resource
+	JSONPatch OverridePatchType = "json"
+)
+
+// OverridePatch is a patch of a single resource rendered from the addon source
+type OverridePatch struct {
+	// Target is the resource that is patched
+	Target OverrideTarget `json:"target"`
+	// Type of the patch, defaults to strategic
+	// +kubebuilder:validation:Enum=strategic;json
+	// +optional
+	Type OverridePatchType `json:"type,omitempty"`
+	// Patch is the strategic merge patch or the list of JSON patch operations, as YAML or JSON
+	Patch string `json:"patch"`
+}
+
+// OverrideTarget identifies a resource by group, version, kind and name
+type OverrideTarget struct {
+	// +optional
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+}
+
+// String returns the target as kind.group/name
+func (t OverrideTarget) String() string {
+	if t.Group == "" {
+		return fmt.Sprintf("%s/%s", t.Kind, t.Name)
+	}
+	return fmt.Sprintf("%s.%s/%s", t.Kind, t.Group, t.Name)
+}
+
+// ReplicaOverride sets the replica count of a workload installed by the addon
+type ReplicaOverride struct {
+	// Kind of the workload
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	Kind string `json:"kind"`
+	// Name of the workload
+	Name string `json:"name"`
+	// Namespace of the workload, defaults to spec.params.namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Replicas is the replica count the workload is scaled to
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
 }
 
 // SecretCmdSpec is a secret list and/or generator for secrets using the available commands: random, cert.
@@ -194,6 +351,9 @@ type SecretCmdSpec struct {
 	Name string   `json:"name"`
 	Cmd  CmdType  `json:"cmd,omitempty"`
 	Args []string `json:"args,omitempty" protobuf:"bytes,4,rep,name=args"`
+	// RequiredKeys must be present and non-empty in the data of the secret before the install workflow runs
+	// +optional
+	RequiredKeys []string `json:"requiredKeys,omitempty"`
 }
 
 // WorkflowType allows user to specify workflow templates with optional namePrefix, workflowRole or role.
@@ -208,16 +368,167 @@ type WorkflowType struct {
 	// WorkflowRole used to denote the role annotation that should be used by the workflow
 	// +optional
 	WorkflowRole string `json:"workflowRole,omitempty"`
-	// Template is used to provide the workflow spec
+	// Template is used to provide the workflow spec, inline or as an oci://registry/repository:tag reference
 	Template string `json:"template"`
+	// Suspend skips the step, it succeeds as if no template was provided
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// ActiveDeadlineSeconds is set on the workflow so that Argo fails it when it runs longer, it replaces the deadline
+	// of the template and the default of 300s
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds int64 `json:"activeDeadlineSeconds,omitempty"`
+	// Outputs are global output parameters of the prereqs workflow passed to the install workflow as parameters
+	// +optional
+	Outputs []WorkflowOutput `json:"outputs,omitempty"`
+}
+
+// WorkflowOutput is a global output parameter of the prereqs workflow that is passed to the install workflow
+type WorkflowOutput struct {
+	// Name of the output parameter, the install workflow parameter has the same name
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Optional outputs are not passed when the prereqs workflow does not set them, otherwise install fails
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+	// Sensitive output values are redacted from events and logs
+	// +optional
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// CombinedWorkflowType is a single workflow running prereqs and install. Prereqs succeed once the workflow template
+// running them completed, install succeeds with the workflow.
+type CombinedWorkflowType struct {
+	WorkflowType `json:",inline"`
+	// PrereqsTemplate is the name of the workflow template running prereqs, defaults to prereqs
+	// +optional
+	PrereqsTemplate string `json:"prereqsTemplate,omitempty"`
+}
+
+// DeleteWorkflowType is the delete workflow template with an optional timeout after which the addon is marked DeleteFailed.
+type DeleteWorkflowType struct {
+	WorkflowType `json:",inline"`
+	// TimeoutSeconds is how long the delete workflow may run before the addon is marked DeleteFailed, zero waits indefinitely
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+	// ForceRemoveOnTimeout removes the finalizer when the delete workflow times out, otherwise it keeps waiting
+	// +optional
+	ForceRemoveOnTimeout bool `json:"forceRemoveOnTimeout,omitempty"`
+	// DeletePVCs removes persistent volume claims labeled for the addon once it is deleted, otherwise they are reported as orphaned
+	// +optional
+	DeletePVCs bool `json:"deletePVCs,omitempty"`
 }
 
 // LifecycleWorkflowSpec is where all of the lifecycle workflow templates will be specified under
 type LifecycleWorkflowSpec struct {
-	Prereqs  WorkflowType `json:"prereqs,omitempty"`
-	Install  WorkflowType `json:"install,omitempty"`
-	Delete   WorkflowType `json:"delete,omitempty"`
-	Validate WorkflowType `json:"validate,omitempty"`
+	Prereqs  WorkflowType       `json:"prereqs,omitempty"`
+	Install  WorkflowType       `json:"install,omitempty"`
+	Delete   DeleteWorkflowType `json:"delete,omitempty"`
+	Validate WorkflowType       `json:"validate,omitempty"`
+	// CombinedTemplate runs prereqs and install as a single workflow instead of the prereqs and install workflows
+	// +optional
+	CombinedTemplate CombinedWorkflowType `json:"combinedTemplate,omitempty"`
+	// PostInstall runs after install completed, e.g. a smoke test, the addon is only installed once it succeeded
+	// +optional
+	PostInstall WorkflowType `json:"postInstall,omitempty"`
+	// ServiceAccount that all lifecycle workflows run as, it must exist in the workflow namespace
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// ImagePullSecrets are the names of secrets all pods of lifecycle workflows pull images with, they must exist in
+	// the workflow namespace
+	// +optional
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// Permissions are granted to the service account in spec.params.namespace with a Role and RoleBinding generated
+	// for the addon, so that its workflows do not need broader permissions
+	// +optional
+	Permissions []rbacv1.PolicyRule `json:"permissions,omitempty"`
+	// RollbackOnFailure runs the install workflow of the last successfully applied spec when install fails
+	// +optional
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
+	// SelfHeal reinstalls the addon when resources of the installed addon are missing
+	// +optional
+	SelfHeal bool `json:"selfHeal,omitempty"`
+	// WorkflowInstanceID is the instance id of the Argo workflow-controller that runs the lifecycle workflows,
+	// defaults to addon-manager-workflow-controller
+	// +optional
+	WorkflowInstanceID string `json:"workflowInstanceID,omitempty"`
+	// PodTemplate is the scheduling of the pods of all lifecycle workflows
+	// +optional
+	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
+	// DeleteProtection keeps the addon and its finalizer when it is deleted, the delete workflow only runs once
+	// protection is removed from the spec
+	// +optional
+	DeleteProtection bool `json:"deleteProtection,omitempty"`
+	// PrereqsTimeoutSeconds is how long prereqs may run before they are marked Failed, zero only applies the addon ttl
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PrereqsTimeoutSeconds int64 `json:"prereqsTimeoutSeconds,omitempty"`
+	// InstallTimeoutSeconds is how long install may run before it is marked Failed, zero only applies the addon ttl
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InstallTimeoutSeconds int64 `json:"installTimeoutSeconds,omitempty"`
+}
+
+// PodTemplate is the scheduling of workflow pods, it is merged with the scheduling of the workflow template
+type PodTemplate struct {
+	// NodeSelector labels are added to the node selector of the workflow, they take precedence over equal keys
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations are added to the tolerations of the workflow
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity terms are added to the affinity of the workflow, required node selector terms must all match
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+}
+
+// KustomizeSource is a kustomization that is built and applied by a generated install workflow
+type KustomizeSource struct {
+	// Path of the kustomization as accepted by kustomize, e.g. github.com/org/repo//overlays/prod
+	Path string `json:"path,omitempty"`
+	// Ref is the git reference of a remote path, pin a tag or commit so that overlay changes change the addon checksum
+	// +optional
+	Ref string `json:"ref,omitempty"`
+	// Image used to build and apply the kustomization, it must provide kubectl 1.21+
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// GitSource is a path of a git repository holding the addon manifests or a kustomization
+type GitSource struct {
+	// Repo is the http(s) URL of the repository
+	Repo string `json:"repo,omitempty"`
+	// Ref is the branch, tag or commit, the default branch when empty. The resolved commit is part of the checksum.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+	// Path of the manifests or kustomization in the repository, the repository root when empty
+	// +optional
+	Path string `json:"path,omitempty"`
+	// SecretName is the Secret in the workflow namespace with the username and password used to access the repository
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// Image used to clone the repository and apply the resources, it must provide git and kubectl 1.21+
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// AddonSource is where addon resources are rendered from instead of the install workflow template
+type AddonSource struct {
+	// Kustomize builds and applies a kustomization
+	// +optional
+	Kustomize KustomizeSource `json:"kustomize,omitempty"`
+
+	// Git clones a ref of a repository and applies the manifests or kustomization at its path
+	// +optional
+	Git GitSource `json:"git,omitempty"`
+
+	// Manifests are applied by the controller with server-side apply, no install workflow is run
+	// +optional
+	Manifests []runtime.RawExtension `json:"manifests,omitempty"`
 }
 
 // PackageSpec is the package level details needed by addon
@@ -237,24 +548,107 @@ type AddonSpec struct {
 	// Parameters that will be injected into the workflows for addon
 	// +optional
 	Params AddonParams `json:"params,omitempty"`
+	// FeatureSchema declares the features of the addon, only declared features can be set in spec.params.features
+	// when it is set
+	// +optional
+	FeatureSchema []AddonFeature `json:"featureSchema,omitempty"`
 	// Selector that is used to filter the resource watching
 	// +optional
 	Selector metav1.LabelSelector `json:"selector,omitempty"`
-	// Overrides are kustomize patches that can be applied to templates
+	// ObservationLabels replaces the keys of the app.kubernetes.io/managed-by and app.kubernetes.io/name labels that are
+	// added to the selector of observed resources, an empty key drops the label
+	// +optional
+	ObservationLabels map[string]string `json:"observationLabels,omitempty"`
+	// ObservedNamespaces are the namespaces resources of the addon are observed in, defaults to spec.params.namespace
+	// +optional
+	ObservedNamespaces []string `json:"observedNamespaces,omitempty"`
+	// CommonLabels are added to all resources installed by the addon and to its workflows, the
+	// app.kubernetes.io/managed-by and app.kubernetes.io/name labels cannot be set
+	// +optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	// CommonAnnotations are added to all resources installed by the addon and to its workflows
+	// +optional
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+	// Overrides are kustomize patches that can be applied to templates, patches apply to resources of the source
 	// +optional
 	Overrides AddonOverridesSpec `json:"overrides,omitempty"`
+	// Replicas are the replica counts of workloads installed by the addon, they are applied after install and whenever
+	// the workloads are scaled out-of-band
+	// +optional
+	Replicas []ReplicaOverride `json:"replicas,omitempty"`
 	// Secrets is a list of secret names expected to exist in the target namespace
 	// +optional
 	Secrets []SecretCmdSpec `json:"secrets,omitempty"`
+	// RequiredCRDs are the names of CRDs that must be established before the install workflow runs
+	// +optional
+	RequiredCRDs []string `json:"requiredCRDs,omitempty"`
+	// Requirements are the cluster version and APIs the addon needs, the addon is skipped on clusters which do not
+	// meet them
+	// +optional
+	Requirements AddonRequirements `json:"requirements,omitempty"`
 
 	// +optional
 	Lifecycle LifecycleWorkflowSpec `json:"lifecycle,omitempty"`
+
+	// WorkflowNamespace is the namespace where lifecycle workflows are created, defaults to the addon namespace
+	// +optional
+	WorkflowNamespace string `json:"workflowNamespace,omitempty"`
+
+	// Source renders addon resources with a generated install workflow or applies them directly, it cannot be used
+	// with an install template
+	// +optional
+	Source AddonSource `json:"source,omitempty"`
+
+	// Mode is Install to install the addon once it is valid or Validate to only validate it continuously
+	// +kubebuilder:validation:Enum=Install;Validate
+	// +optional
+	Mode AddonMode `json:"mode,omitempty"`
+
+	// SuspendWorkflows stops new lifecycle workflows from being submitted while resources are still observed
+	// +optional
+	SuspendWorkflows bool `json:"suspendWorkflows,omitempty"`
+
+	// Wave orders installs within the namespace, workflows only run once addons in lower waves have completed
+	// +optional
+	Wave int `json:"wave,omitempty"`
+
+	// Group is the bundle the addon belongs to, the install status of the addons of a group is aggregated
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$`
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Priority orders reconciles when the controller is backlogged, addons with a higher priority are reconciled first
+	// +optional
+	Priority int `json:"priority,omitempty"`
+
+	// Rollout stages the install of the addons of a rollout by the namespace they install into
+	// +optional
+	Rollout RolloutSpec `json:"rollout,omitempty"`
+}
+
+// RolloutSpec stages the install of addons across namespaces, e.g. into a canary namespace first
+type RolloutSpec struct {
+	// Name of the rollout, addons with the same rollout name are rolled out together
+	Name string `json:"name,omitempty"`
+	// Stages are ordered groups of namespaces, addons installing into a stage wait for the addons of all previous
+	// stages to succeed
+	// +optional
+	Stages []RolloutStage `json:"stages,omitempty"`
+}
+
+// RolloutStage is a group of namespaces that is rolled out together
+type RolloutStage struct {
+	// Namespaces of the stage, matched against spec.params.namespace of the addons of the rollout
+	Namespaces []string `json:"namespaces"`
 }
 
 // AddonStatusLifecycle defines the lifecycle status for steps.
 type AddonStatusLifecycle struct {
 	Prereqs   ApplicationAssemblyPhase `json:"prereqs,omitempty"`
 	Installed ApplicationAssemblyPhase `json:"installed,omitempty"`
+	// PostInstall is the phase of the post-install workflow
+	PostInstall ApplicationAssemblyPhase `json:"postInstall,omitempty"`
 }
 
 // ObjectStatus is a generic status holder for objects
@@ -264,12 +658,38 @@ type ObjectStatus struct {
 	Link string `json:"link,omitempty"`
 	// Name of object
 	Name string `json:"name,omitempty"`
+	// Namespace of object, empty for cluster-scoped objects
+	Namespace string `json:"namespace,omitempty"`
 	// Kind of object
 	Kind string `json:"kind,omitempty"`
 	// Object group
 	Group string `json:"group,omitempty"`
-	// Status. Values: InProgress, Ready, Unknown
+	// Owner is the kind/name of the controller owning the object, e.g. the deployment of a replica set
+	// +optional
+	Owner string `json:"owner,omitempty"`
+	// Status. Values: InProgress, Ready, Unknown, Missing, Degraded
 	Status string `json:"status,omitempty"`
+	// CurrentReplicas of a scaled object
+	CurrentReplicas int32 `json:"currentReplicas,omitempty"`
+	// DesiredReplicas of a scaled object
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+	// LastScheduleTime of a cron job
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulTime is when the latest successful job of a cron job completed
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// ActiveJobs is the number of running jobs of a cron job
+	ActiveJobs int32 `json:"activeJobs,omitempty"`
+	// CurrentHealthy is the number of healthy pods of a pod disruption budget
+	CurrentHealthy int32 `json:"currentHealthy,omitempty"`
+	// DesiredHealthy is the minimum number of healthy pods required by a pod disruption budget
+	DesiredHealthy int32 `json:"desiredHealthy,omitempty"`
+	// DisruptionsAllowed is the number of pod disruptions currently allowed by a pod disruption budget
+	DisruptionsAllowed int32 `json:"disruptionsAllowed,omitempty"`
+	// Reason the object is Degraded, e.g. the failing condition of a deployment or job
+	// +optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // AddonStatus defines the observed state of Addon
@@ -279,6 +699,141 @@ type AddonStatus struct {
 	Resources []ObjectStatus       `json:"resources"`
 	Reason    string               `json:"reason"`
 	StartTime int64                `json:"starttime"`
+
+	// CompletionTime is when the install of the current spec succeeded, in milliseconds like StartTime
+	// +optional
+	CompletionTime int64 `json:"completionTime,omitempty"`
+
+	// PrereqsStartTime is when prereqs of the current spec started to run, in milliseconds like StartTime
+	// +optional
+	PrereqsStartTime int64 `json:"prereqsStartTime,omitempty"`
+
+	// InstallStartTime is when install of the current spec started to run, in milliseconds like StartTime
+	// +optional
+	InstallStartTime int64 `json:"installStartTime,omitempty"`
+
+	// DeleteRetryTime is when the delete workflow was last retried with the retry delete annotation, in milliseconds
+	// like StartTime, the delete timeout restarts from it
+	// +optional
+	DeleteRetryTime int64 `json:"deleteRetryTime,omitempty"`
+
+	// FailedAttempts is the number of consecutive reconciles which failed the addon, each is retried with backoff
+	// +optional
+	FailedAttempts int `json:"failedAttempts,omitempty"`
+
+	// RollbackAttempts is the number of rollbacks of the failed spec to the last applied spec, reset when the spec changes
+	// +optional
+	RollbackAttempts int `json:"rollbackAttempts,omitempty"`
+
+	// FailureCategory is the cause of the failure of the current spec, empty unless the addon failed
+	// +kubebuilder:validation:Enum=Validation;Dependency;Workflow;Secret;Observation;Timeout
+	// +optional
+	FailureCategory FailureCategory `json:"failureCategory,omitempty"`
+
+	// InstallDuration is the time the install of the current spec took from StartTime to CompletionTime
+	// +optional
+	InstallDuration string `json:"installDuration,omitempty"`
+
+	// LastAppliedChecksum is the checksum of the spec that was last installed successfully
+	// +optional
+	LastAppliedChecksum string `json:"lastAppliedChecksum,omitempty"`
+
+	// ObservedGeneration is the generation of the addon spec the status was last reconciled for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ObservedReconcileToken is the last reconcile token annotation the addon status was refreshed for
+	// +optional
+	ObservedReconcileToken string `json:"observedReconcileToken,omitempty"`
+
+	// ActiveWorkflow is the lifecycle workflow that is running, it is cleared once the workflow completes
+	// +optional
+	ActiveWorkflow *ActiveWorkflow `json:"activeWorkflow,omitempty"`
+
+	// AppliedOverrides are the targets of the override patches applied by the install of the current spec
+	// +optional
+	AppliedOverrides []string `json:"appliedOverrides,omitempty"`
+
+	// ManifestTiers is the apply progress of each tier of the manifests source, namespaces and CRDs are applied and
+	// ready before the other manifests are applied
+	// +optional
+	ManifestTiers []ManifestTierStatus `json:"manifestTiers,omitempty"`
+
+	// ResolvedCommit is the commit the ref of the git source resolved to, it is part of the checksum
+	// +optional
+	ResolvedCommit string `json:"resolvedCommit,omitempty"`
+
+	// ResourceCount is the number of observed resources, Resources may list fewer when the list is truncated
+	// +optional
+	ResourceCount int `json:"resourceCount,omitempty"`
+
+	// PkgName is the package the addon was created for, spec.pkgName is immutable once it is recorded
+	// +optional
+	PkgName string `json:"pkgName,omitempty"`
+
+	// PkgVersion is the package version the addon was last reconciled with
+	// +optional
+	PkgVersion string `json:"pkgVersion,omitempty"`
+
+	// Dependencies is the resolution state of each dependency in spec.pkgDeps
+	// +optional
+	Dependencies []DependencyStatus `json:"dependencies,omitempty"`
+
+	// WorkflowPermissions is the namespace/name of the Role and RoleBinding granting spec.lifecycle.permissions
+	// +optional
+	WorkflowPermissions string `json:"workflowPermissions,omitempty"`
+
+	// Conditions of the addon, Degraded is true while resources of the installed addon are missing or its cron jobs
+	// are not succeeding, RolloutPaused is true while addons of a previous rollout stage have failed
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// DependencyState is the resolution state of a dependency: Installed, Pending, Missing or VersionMismatch
+type DependencyState string
+
+const (
+	// DependencyInstalled dependencies have a successfully installed version
+	DependencyInstalled DependencyState = "Installed"
+	// DependencyPending dependencies are being installed
+	DependencyPending DependencyState = "Pending"
+	// DependencyMissing dependencies have no installed version
+	DependencyMissing DependencyState = "Missing"
+	// DependencyVersionMismatch dependencies are installed with other versions than the required one
+	DependencyVersionMismatch DependencyState = "VersionMismatch"
+)
+
+// DependencyStatus is the resolution state of a dependency of the addon
+type DependencyStatus struct {
+	// PkgName of the dependency
+	PkgName string `json:"pkgName"`
+	// PkgVersion required of the dependency, * for any version
+	PkgVersion string `json:"pkgVersion"`
+	// State of the dependency
+	State DependencyState `json:"state"`
+}
+
+// ManifestTierStatus is the apply progress of a tier of the manifests source
+type ManifestTierStatus struct {
+	// Name of the tier, Definitions for namespaces and CRDs, Resources for all other manifests
+	Name string `json:"name"`
+	// Manifests is the number of manifests in the tier
+	Manifests int `json:"manifests"`
+	// Phase of the tier, Pending until its manifests are applied and ready, empty while a previous tier is pending
+	// +optional
+	Phase ApplicationAssemblyPhase `json:"phase,omitempty"`
+}
+
+// ActiveWorkflow identifies a running lifecycle workflow of the addon
+type ActiveWorkflow struct {
+	// Name of the workflow
+	Name string `json:"name"`
+	// LifecycleStep the workflow runs
+	LifecycleStep LifecycleStep `json:"lifecycleStep"`
+	// Phase of the lifecycle step
+	Phase ApplicationAssemblyPhase `json:"phase"`
 }
 
 // +kubebuilder:object:root=true
@@ -291,7 +846,12 @@ type AddonStatus struct {
 // +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".spec.pkgVersion"
 // +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.lifecycle.installed"
 // +kubebuilder:printcolumn:name="REASON",type="string",JSONPath=".status.reason"
+// +kubebuilder:printcolumn:name="WORKFLOW",type="string",JSONPath=".status.activeWorkflow.name"
+// +kubebuilder:printcolumn:name="DURATION",type="string",JSONPath=".status.installDuration"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="GROUP",type="string",JSONPath=".spec.group",priority=1
+// +kubebuilder:printcolumn:name="CHECKSUM",type="string",JSONPath=".status.checksum",priority=1
+// +kubebuilder:printcolumn:name="APPLIED",type="string",JSONPath=".status.lastAppliedChecksum",priority=1
 type Addon struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -349,9 +909,13 @@ func (a *Addon) GetWorkflowType(step LifecycleStep) (*WorkflowType, error) {
 	case Prereqs:
 		wt = &a.Spec.Lifecycle.Prereqs
 	case Delete:
-		wt = &a.Spec.Lifecycle.Delete
+		wt = &a.Spec.Lifecycle.Delete.WorkflowType
 	case Validate:
 		wt = &a.Spec.Lifecycle.Validate
+	case Combined:
+		wt = &a.Spec.Lifecycle.CombinedTemplate.WorkflowType
+	case PostInstall:
+		wt = &a.Spec.Lifecycle.PostInstall
 	default:
 		return nil, fmt.Errorf("no WorkflowType of type %s exists", step)
 	}
@@ -359,7 +923,9 @@ func (a *Addon) GetWorkflowType(step LifecycleStep) (*WorkflowType, error) {
 	return wt, nil
 }
 
-// GetFormattedWorkflowName used the addon name, workflow prefix, addon checksum, and lifecycle step to compose the workflow name
+// GetFormattedWorkflowName used the addon name, workflow prefix, lifecycle step and a hash of the addon namespace and spec
+// to compose the workflow name. The hash is used instead of the addon checksum, as the adler32 checksum of
+// configurations which only differ in a few characters, e.g. in params, can be the same.
 func (a *Addon) GetFormattedWorkflowName(lifecycleStep LifecycleStep) string {
 	wt, err := a.GetWorkflowType(lifecycleStep)
 	if err != nil {
@@ -370,13 +936,82 @@ func (a *Addon) GetFormattedWorkflowName(lifecycleStep LifecycleStep) string {
 	if wt.NamePrefix != "" {
 		prefix = fmt.Sprintf("%s-%s", prefix, wt.NamePrefix)
 	}
-	wfIdentifierName := fmt.Sprintf("%s-%s-%s-wf", prefix, lifecycleStep, a.CalculateChecksum())
+	wfIdentifierName := fmt.Sprintf("%s-%s-%s-wf", prefix, lifecycleStep, a.CalculateConfigHash())
 	return wfIdentifierName
 }
 
 // CalculateChecksum converts the AddonSpec into a hash string (using Alder32 algo)
 func (a *Addon) CalculateChecksum() string {
-	return fmt.Sprintf("%x", adler32.Checksum([]byte(fmt.Sprintf("%+v", a.Spec))))
+	return fmt.Sprintf("%x", adler32.Checksum([]byte(a.checksumData())))
+}
+
+// CalculateConfigHash returns the FNV-1a hash of the namespace and the checksummed spec of the addon, which names its
+// workflows
+func (a *Addon) CalculateConfigHash() string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(a.GetNamespace() + "/" + a.checksumData()))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// checksumData returns the spec fields which change what is installed by the addon
+func (a *Addon) checksumData() string {
+	// Suspending workflows does not change what is installed, toggling it must not trigger new workflows
+	spec := a.Spec
+	spec.SuspendWorkflows = false
+	// Waves only order installs, moving an addon to another wave must not reinstall it
+	spec.Wave = 0
+	// Delete protection only guards deletion, toggling it must not reinstall the addon
+	spec.Lifecycle.DeleteProtection = false
+	// Skipping the delete workflow only affects deletion
+	spec.Lifecycle.Delete.Suspend = false
+	// Groups only aggregate status, moving an addon to another group must not reinstall it
+	spec.Group = ""
+	// Priorities only order reconciles
+	spec.Priority = 0
+	// Rollouts only order installs
+	spec.Rollout = RolloutSpec{}
+	// Observed namespaces only change where resources are observed
+	spec.ObservedNamespaces = nil
+	// Replica counts are applied to the installed workloads without running workflows
+	spec.Replicas = nil
+	// The pod template holds pointers, which would be printed as addresses, it is hashed as JSON instead
+	var podTemplate []byte
+	if spec.Lifecycle.PodTemplate != nil {
+		podTemplate, _ = json.Marshal(spec.Lifecycle.PodTemplate)
+		spec.Lifecycle.PodTemplate = nil
+	}
+	data := fmt.Sprintf("%+v%s", spec, podTemplate)
+	// A moved git ref changes what is installed like a changed spec
+	if spec.Source.Git.Repo != "" {
+		data += a.Status.ResolvedCommit
+	}
+	return data
+}
+
+// RunsCombinedWorkflow returns true if prereqs and install of the addon run as a single workflow
+func (a *Addon) RunsCombinedWorkflow() bool {
+	return a.Spec.Lifecycle.CombinedTemplate.Template != ""
+}
+
+// CombinedPrereqsTemplate returns the name of the workflow template running prereqs in the combined workflow
+func (a *Addon) CombinedPrereqsTemplate() string {
+	if name := a.Spec.Lifecycle.CombinedTemplate.PrereqsTemplate; name != "" {
+		return name
+	}
+	return string(Prereqs)
+}
+
+// ValidateOnly returns true if the addon is only validated and never installed
+func (a *Addon) ValidateOnly() bool {
+	return a.Spec.Mode == ValidateMode
+}
+
+// GetWorkflowNamespace returns the namespace where lifecycle workflows are run for addon
+func (a *Addon) GetWorkflowNamespace() string {
+	if a.Spec.WorkflowNamespace != "" {
+		return a.Spec.WorkflowNamespace
+	}
+	return a.GetNamespace()
 }
 
 // GetInstallStatus returns the install phase for addon
@@ -130,9 +130,9 @@ var _ = Describe("Addon", func() {
 						Install: WorkflowType{
 							Template: wfSpecTemplate,
 						},
-						Delete: WorkflowType{
+						Delete: DeleteWorkflowType{WorkflowType: WorkflowType{
 							Template: wfSpecTemplate,
-						},
+						}},
 					},
 				},
 			}
@@ -169,7 +169,7 @@ var _ = Describe("Addon", func() {
 			fetched.Status.Checksum = checksum
 
 			wfName := fetched.GetFormattedWorkflowName(Install)
-			Expect(wfName).To(Equal(fmt.Sprintf("foo-install-%s-wf", checksum)))
+			Expect(wfName).To(Equal(fmt.Sprintf("foo-install-%s-wf", fetched.CalculateConfigHash())))
 
 			By("updating labels")
 			updated := fetched.DeepCopy()
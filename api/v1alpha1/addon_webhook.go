@@ -0,0 +1,60 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager registers the validating webhook of addons with the manager
+func (a *Addon) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(a).Complete()
+}
+
+// +kubebuilder:webhook:verbs=update,path=/validate-addonmgr-keikoproj-io-v1alpha1-addon,mutating=false,failurePolicy=fail,groups=addonmgr.keikoproj.io,resources=addons,versions=v1alpha1,name=vaddon.addonmgr.keikoproj.io
+
+var _ webhook.Validator = &Addon{}
+
+// ValidateCreate implements webhook.Validator, addons are validated by the controller
+func (a *Addon) ValidateCreate() error {
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator, it rejects changes of the package name
+func (a *Addon) ValidateUpdate(old runtime.Object) error {
+	prev, ok := old.(*Addon)
+	if !ok {
+		return nil
+	}
+	return ValidatePkgNameChange(prev.Spec.PkgName, a.Spec.PkgName)
+}
+
+// ValidateDelete implements webhook.Validator
+func (a *Addon) ValidateDelete() error {
+	return nil
+}
+
+// ValidatePkgNameChange returns an error if the package name of an addon changes. Dependencies reference addons by
+// their package, an addon for another package must be a new addon.
+func ValidatePkgNameChange(prev, name string) error {
+	if prev == "" || prev == name {
+		return nil
+	}
+	return fmt.Errorf("spec.pkgName is immutable, it cannot be changed from %q to %q. Create a new addon for package %q instead", prev, name, name)
+}
@@ -0,0 +1,34 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestAddon_ValidateUpdate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	prev := &Addon{Spec: AddonSpec{PackageSpec: PackageSpec{PkgName: "my-addon", PkgVersion: "1.0.0"}}}
+
+	a := prev.DeepCopy()
+	a.Spec.PkgVersion = "1.1.0"
+	g.Expect(a.ValidateUpdate(prev)).To(gomega.Succeed())
+
+	a.Spec.PkgName = "other-addon"
+	g.Expect(a.ValidateUpdate(prev)).To(gomega.MatchError(`spec.pkgName is immutable, it cannot be changed from "my-addon" to "other-addon". Create a new addon for package "other-addon" instead`))
+}
@@ -0,0 +1,56 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGetFormattedWorkflowName_Params(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	newAddon := func(version string) *Addon {
+		a := &Addon{Spec: AddonSpec{PackageSpec: PackageSpec{PkgName: "my-addon", PkgVersion: "1.0.0"}}}
+		a.Name, a.Namespace = "my-addon", "default"
+		a.Spec.Lifecycle.Install.Template = "kind: Workflow"
+		a.Spec.Params.Data = map[string]FlexString{"version": FlexString(version)}
+		return a
+	}
+
+	// The params only differ in characters which keep the adler32 checksum
+	a, b := newAddon("1.3.1"), newAddon("2.1.2")
+	g.Expect(a.CalculateChecksum()).To(gomega.Equal(b.CalculateChecksum()))
+	g.Expect(a.GetFormattedWorkflowName(Install)).NotTo(gomega.Equal(b.GetFormattedWorkflowName(Install)))
+	g.Expect(a.GetFormattedWorkflowName(Install)).To(gomega.MatchRegexp(`^my-addon-install-[0-9a-f]{8}-wf$`))
+
+	// Addons of the same name and spec in different namespaces do not share workflows either
+	b = newAddon("1.3.1")
+	b.Namespace = "other"
+	g.Expect(a.GetFormattedWorkflowName(Install)).NotTo(gomega.Equal(b.GetFormattedWorkflowName(Install)))
+}
+
+func TestGetFormattedWorkflowName_Length(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// The longest addon name allowed with the longest lifecycle step still fits the 63 characters of a label value,
+	// the workflow name is copied to the workflows.argoproj.io/workflow label of its pods
+	a := &Addon{Spec: AddonSpec{PackageSpec: PackageSpec{PkgName: "my-addon", PkgVersion: "1.0.0"}}}
+	a.Name, a.Namespace = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "default"
+	a.Spec.Lifecycle.PostInstall.Template = "kind: Workflow"
+	g.Expect(a.Name).To(gomega.HaveLen(31))
+	g.Expect(len(a.GetFormattedWorkflowName(PostInstall))).To(gomega.BeNumerically("<=", 63))
+}
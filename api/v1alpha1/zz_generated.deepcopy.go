@@ -19,9 +19,27 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveWorkflow) DeepCopyInto(out *ActiveWorkflow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveWorkflow.
+func (in *ActiveWorkflow) DeepCopy() *ActiveWorkflow {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveWorkflow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Addon) DeepCopyInto(out *Addon) {
 	*out = *in
@@ -49,6 +67,21 @@ func (in *Addon) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonFeature) DeepCopyInto(out *AddonFeature) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonFeature.
+func (in *AddonFeature) DeepCopy() *AddonFeature {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonFeature)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonList) DeepCopyInto(out *AddonList) {
 	*out = *in
@@ -92,6 +125,11 @@ func (in *AddonOverridesSpec) DeepCopyInto(out *AddonOverridesSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]OverridePatch, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonOverridesSpec.
@@ -115,6 +153,13 @@ func (in *AddonParams) DeepCopyInto(out *AddonParams) {
 			(*out)[key] = val
 		}
 	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonParams.
@@ -127,13 +172,69 @@ func (in *AddonParams) DeepCopy() *AddonParams {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonRequirements) DeepCopyInto(out *AddonRequirements) {
+	*out = *in
+	if in.APIGroups != nil {
+		in, out := &in.APIGroups, &out.APIGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonRequirements.
+func (in *AddonRequirements) DeepCopy() *AddonRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonSpec) DeepCopyInto(out *AddonSpec) {
 	*out = *in
 	in.PackageSpec.DeepCopyInto(&out.PackageSpec)
 	in.Params.DeepCopyInto(&out.Params)
+	if in.FeatureSchema != nil {
+		in, out := &in.FeatureSchema, &out.FeatureSchema
+		*out = make([]AddonFeature, len(*in))
+		copy(*out, *in)
+	}
 	in.Selector.DeepCopyInto(&out.Selector)
+	if in.ObservationLabels != nil {
+		in, out := &in.ObservationLabels, &out.ObservationLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ObservedNamespaces != nil {
+		in, out := &in.ObservedNamespaces, &out.ObservedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	in.Overrides.DeepCopyInto(&out.Overrides)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]ReplicaOverride, len(*in))
+		copy(*out, *in)
+	}
 	if in.Secrets != nil {
 		in, out := &in.Secrets, &out.Secrets
 		*out = make([]SecretCmdSpec, len(*in))
@@ -141,7 +242,15 @@ func (in *AddonSpec) DeepCopyInto(out *AddonSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Lifecycle = in.Lifecycle
+	if in.RequiredCRDs != nil {
+		in, out := &in.RequiredCRDs, &out.RequiredCRDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Requirements.DeepCopyInto(&out.Requirements)
+	in.Lifecycle.DeepCopyInto(&out.Lifecycle)
+	in.Source.DeepCopyInto(&out.Source)
+	in.Rollout.DeepCopyInto(&out.Rollout)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonSpec.
@@ -154,6 +263,30 @@ func (in *AddonSpec) DeepCopy() *AddonSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonSource) DeepCopyInto(out *AddonSource) {
+	*out = *in
+	out.Kustomize = in.Kustomize
+	out.Git = in.Git
+	if in.Manifests != nil {
+		in, out := &in.Manifests, &out.Manifests
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonSource.
+func (in *AddonSource) DeepCopy() *AddonSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonStatus) DeepCopyInto(out *AddonStatus) {
 	*out = *in
@@ -161,8 +294,37 @@ func (in *AddonStatus) DeepCopyInto(out *AddonStatus) {
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = make([]ObjectStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ActiveWorkflow != nil {
+		in, out := &in.ActiveWorkflow, &out.ActiveWorkflow
+		*out = new(ActiveWorkflow)
+		**out = **in
+	}
+	if in.AppliedOverrides != nil {
+		in, out := &in.AppliedOverrides, &out.AppliedOverrides
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ManifestTiers != nil {
+		in, out := &in.ManifestTiers, &out.ManifestTiers
+		*out = make([]ManifestTierStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]DependencyStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonStatus.
@@ -212,6 +374,83 @@ func (in *ClusterContext) DeepCopy() *ClusterContext {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CombinedWorkflowType) DeepCopyInto(out *CombinedWorkflowType) {
+	*out = *in
+	in.WorkflowType.DeepCopyInto(&out.WorkflowType)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CombinedWorkflowType.
+func (in *CombinedWorkflowType) DeepCopy() *CombinedWorkflowType {
+	if in == nil {
+		return nil
+	}
+	out := new(CombinedWorkflowType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeleteWorkflowType) DeepCopyInto(out *DeleteWorkflowType) {
+	*out = *in
+	in.WorkflowType.DeepCopyInto(&out.WorkflowType)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeleteWorkflowType.
+func (in *DeleteWorkflowType) DeepCopy() *DeleteWorkflowType {
+	if in == nil {
+		return nil
+	}
+	out := new(DeleteWorkflowType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependencyStatus) DeepCopyInto(out *DependencyStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyStatus.
+func (in *DependencyStatus) DeepCopy() *DependencyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitSource) DeepCopyInto(out *GitSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSource.
+func (in *GitSource) DeepCopy() *GitSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GitSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KustomizeSource) DeepCopyInto(out *KustomizeSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KustomizeSource.
+func (in *KustomizeSource) DeepCopy() *KustomizeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(KustomizeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KustomizeSpec) DeepCopyInto(out *KustomizeSpec) {
 	*out = *in
@@ -272,10 +511,29 @@ func (in *KustomizeTemplate) DeepCopy() *KustomizeTemplate {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LifecycleWorkflowSpec) DeepCopyInto(out *LifecycleWorkflowSpec) {
 	*out = *in
-	out.Prereqs = in.Prereqs
-	out.Install = in.Install
-	out.Delete = in.Delete
-	out.Validate = in.Validate
+	in.Prereqs.DeepCopyInto(&out.Prereqs)
+	in.Install.DeepCopyInto(&out.Install)
+	in.Delete.DeepCopyInto(&out.Delete)
+	in.Validate.DeepCopyInto(&out.Validate)
+	in.CombinedTemplate.DeepCopyInto(&out.CombinedTemplate)
+	in.PostInstall.DeepCopyInto(&out.PostInstall)
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]rbacv1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleWorkflowSpec.
@@ -288,9 +546,32 @@ func (in *LifecycleWorkflowSpec) DeepCopy() *LifecycleWorkflowSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestTierStatus) DeepCopyInto(out *ManifestTierStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestTierStatus.
+func (in *ManifestTierStatus) DeepCopy() *ManifestTierStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestTierStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectStatus) DeepCopyInto(out *ObjectStatus) {
 	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStatus.
@@ -303,6 +584,37 @@ func (in *ObjectStatus) DeepCopy() *ObjectStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverridePatch) DeepCopyInto(out *OverridePatch) {
+	*out = *in
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverridePatch.
+func (in *OverridePatch) DeepCopy() *OverridePatch {
+	if in == nil {
+		return nil
+	}
+	out := new(OverridePatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverrideTarget) DeepCopyInto(out *OverrideTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverrideTarget.
+func (in *OverrideTarget) DeepCopy() *OverrideTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(OverrideTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageSpec) DeepCopyInto(out *PackageSpec) {
 	*out = *in
@@ -325,6 +637,97 @@ func (in *PackageSpec) DeepCopy() *PackageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodTemplate) DeepCopyInto(out *PodTemplate) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodTemplate.
+func (in *PodTemplate) DeepCopy() *PodTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PodTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaOverride) DeepCopyInto(out *ReplicaOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaOverride.
+func (in *ReplicaOverride) DeepCopy() *ReplicaOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
+	*out = *in
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]RolloutStage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStage) DeepCopyInto(out *RolloutStage) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStage.
+func (in *RolloutStage) DeepCopy() *RolloutStage {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretCmdSpec) DeepCopyInto(out *SecretCmdSpec) {
 	*out = *in
@@ -333,6 +736,11 @@ func (in *SecretCmdSpec) DeepCopyInto(out *SecretCmdSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RequiredKeys != nil {
+		in, out := &in.RequiredKeys, &out.RequiredKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretCmdSpec.
@@ -345,9 +753,29 @@ func (in *SecretCmdSpec) DeepCopy() *SecretCmdSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowOutput) DeepCopyInto(out *WorkflowOutput) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowOutput.
+func (in *WorkflowOutput) DeepCopy() *WorkflowOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowType) DeepCopyInto(out *WorkflowType) {
 	*out = *in
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]WorkflowOutput, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowType.
@@ -0,0 +1,62 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/discovery"
+
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// workflowsDetector reports whether the Argo Workflow CRD is served. Once it is served it is not checked again,
+// otherwise discovery is checked at most once per period so that pending addons do not flood the API server.
+type workflowsDetector struct {
+	sync.Mutex
+	discovery discovery.DiscoveryInterface
+	period    time.Duration
+	served    bool
+	checked   time.Time
+}
+
+func newWorkflowsDetector(d discovery.DiscoveryInterface, period time.Duration) *workflowsDetector {
+	return &workflowsDetector{
+		discovery: d,
+		period:    period,
+	}
+}
+
+// Served returns true if workflows can be run, a nil detector assumes they can
+func (w *workflowsDetector) Served() bool {
+	if w == nil {
+		return true
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.served || time.Since(w.checked) < w.period {
+		return w.served
+	}
+	w.checked = time.Now()
+
+	gvr := common.WorkflowGVR()
+	list, err := w.discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	w.served = err == nil && containsResource(list, gvr.Resource)
+
+	return w.served
+}
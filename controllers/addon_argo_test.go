@@ -0,0 +1,50 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWorkflowsDetector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	d := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	w := newWorkflowsDetector(d, time.Hour)
+	g.Expect(w.Served()).To(BeFalse())
+
+	d.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "argoproj.io/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "workflows", Kind: "Workflow", Namespaced: true}},
+	}}
+	// Discovery is not checked again within the period
+	g.Expect(w.Served()).To(BeFalse())
+
+	w.checked = time.Time{}
+	g.Expect(w.Served()).To(BeTrue())
+
+	// Once served it is not checked again
+	d.Resources = nil
+	g.Expect(w.Served()).To(BeTrue())
+
+	var none *workflowsDetector
+	g.Expect(none.Served()).To(BeTrue())
+}
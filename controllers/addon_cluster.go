@@ -0,0 +1,41 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/oci"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+// validateClusterParams returns an error if a workflow template references cluster values which are not set for the
+// controller, the placeholders would otherwise be left in the workflow. Registry hosted templates are only checked
+// when they are rendered.
+func (r *AddonReconciler) validateClusterParams(instance *addonmgrv1alpha1.Addon) error {
+	values := r.Cluster.Params()
+	for _, step := range []addonmgrv1alpha1.LifecycleStep{addonmgrv1alpha1.Prereqs, addonmgrv1alpha1.Install, addonmgrv1alpha1.Delete, addonmgrv1alpha1.Validate, addonmgrv1alpha1.Combined, addonmgrv1alpha1.PostInstall} {
+		wt, err := instance.GetWorkflowType(step)
+		if err != nil || wt.Template == "" || oci.IsReference(wt.Template) {
+			continue
+		}
+		if unresolved := workflows.UnresolvedClusterParams(wt.Template, values); len(unresolved) > 0 {
+			return fmt.Errorf("invalid workflow template %q, cluster params %s are not set for the controller, see --cluster-name and --environment", step, strings.Join(unresolved, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+func TestValidateClusterParams(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := &AddonReconciler{}
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Spec.Lifecycle.Install.Template = "args: [\"{{cluster.name}}\"]"
+	instance.Spec.Lifecycle.Delete.Template = "args: [\"{{cluster.environment}}\"]"
+	instance.Spec.Lifecycle.Prereqs.Template = "oci://registry.example.com/addons/prereqs:{{cluster.environment}}"
+
+	g.Expect(r.validateClusterParams(instance)).To(MatchError(ContainSubstring(`invalid workflow template "install", cluster params {{cluster.name}} are not set`)))
+
+	r.Cluster = workflows.ClusterValues{Name: "prod-usw2"}
+	g.Expect(r.validateClusterParams(instance)).To(MatchError(ContainSubstring(`invalid workflow template "delete", cluster params {{cluster.environment}}`)))
+
+	r.Cluster.Environment = "prod"
+	g.Expect(r.validateClusterParams(instance)).To(Succeed())
+}
@@ -0,0 +1,108 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/oci"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+// executeCombined runs prereqs and install of the addon as a single workflow. The checks run between the prereqs and
+// install workflows are left to the combined workflow.
+func (r *AddonReconciler) executeCombined(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) error {
+	prereqsPhase, installPhase, err := r.runCombinedWorkflow(ctx, instance, wfl)
+	if oci.IsPullError(err) {
+		r.templatePullFailed(log, instance, addonmgrv1alpha1.Combined, err)
+		return err
+	}
+	if err != nil {
+		reason := fmt.Sprintf("Addon %s/%s combined workflow failed. %v", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		workflowLogger(log, instance, addonmgrv1alpha1.Combined).Error(err, "Addon combined workflow failed.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
+		instance.Status.Reason = reason
+
+		return err
+	}
+	instance.Status.Lifecycle.Prereqs = prereqsPhase
+	if prereqsPhase == addonmgrv1alpha1.Pending && stepTimedOut(instance, addonmgrv1alpha1.Prereqs) {
+		return r.failTimedOutStep(log, instance, addonmgrv1alpha1.Prereqs)
+	}
+
+	if prereqsPhase == addonmgrv1alpha1.Failed {
+		reason := r.workflowFailureReason(ctx, log, instance, wfl, addonmgrv1alpha1.Combined)
+		workflowLogger(log, instance, addonmgrv1alpha1.Combined).Info("Addon combined workflow failed in prereqs.", "reason", reason)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
+		instance.Status.Reason = reason
+
+		return fmt.Errorf(reason)
+	}
+
+	if prereqsPhase == addonmgrv1alpha1.Succeeded {
+		if installPhase == addonmgrv1alpha1.Pending && stepTimedOut(instance, addonmgrv1alpha1.Install) {
+			return r.failTimedOutStep(log, instance, addonmgrv1alpha1.Install)
+		}
+		return r.installCompleted(ctx, log, instance, wfl, addonmgrv1alpha1.Combined, installPhase, nil, nil)
+	}
+
+	return nil
+}
+
+// runCombinedWorkflow runs the combined workflow of the addon and returns the phases of prereqs and install
+func (r *AddonReconciler) runCombinedWorkflow(ctx context.Context, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) (addonmgrv1alpha1.ApplicationAssemblyPhase, addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	phase, err := r.runWorkflow(addonmgrv1alpha1.Combined, instance, wfl, nil)
+	if err != nil {
+		return phase, phase, err
+	}
+	if instance.Spec.SuspendWorkflows {
+		return instance.Status.Lifecycle.Prereqs, phase, nil
+	}
+
+	templates, err := wfl.TemplatePhases(ctx, instance.GetFormattedWorkflowName(addonmgrv1alpha1.Combined))
+	if err != nil {
+		return addonmgrv1alpha1.Failed, addonmgrv1alpha1.Failed, err
+	}
+
+	prereqsPhase, installPhase := combinedPhases(phase, templates[instance.CombinedPrereqsTemplate()])
+	return prereqsPhase, installPhase, nil
+}
+
+// combinedPhases maps the phase of the combined workflow and of its prereqs template to the prereqs and install phases.
+// Prereqs succeed once their template completed, a failed workflow fails install and prereqs unless they succeeded.
+func combinedPhases(phase, prereqsTemplate addonmgrv1alpha1.ApplicationAssemblyPhase) (addonmgrv1alpha1.ApplicationAssemblyPhase, addonmgrv1alpha1.ApplicationAssemblyPhase) {
+	switch phase {
+	case addonmgrv1alpha1.Succeeded:
+		return addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.Succeeded
+	case addonmgrv1alpha1.Failed:
+		if prereqsTemplate == addonmgrv1alpha1.Succeeded {
+			return addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.Failed
+		}
+		return addonmgrv1alpha1.Failed, addonmgrv1alpha1.Failed
+	}
+
+	if prereqsTemplate == addonmgrv1alpha1.Succeeded {
+		return addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.Pending
+	}
+	return addonmgrv1alpha1.Pending, addonmgrv1alpha1.Pending
+}
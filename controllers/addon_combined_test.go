@@ -0,0 +1,103 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// combinedLifecycle reports the phase of the combined workflow and of its templates
+type combinedLifecycle struct {
+	fakeLifecycle
+	phase     addonmgrv1alpha1.ApplicationAssemblyPhase
+	templates map[string]addonmgrv1alpha1.ApplicationAssemblyPhase
+	submitted []string
+}
+
+func (c *combinedLifecycle) Install(_ context.Context, _ *addonmgrv1alpha1.WorkflowType, name string, _ map[string]string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	c.submitted = append(c.submitted, name)
+	return c.phase, nil
+}
+
+func (c *combinedLifecycle) TemplatePhases(context.Context, string) (map[string]addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	return c.templates, nil
+}
+
+func TestCombinedPhases(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		phase, prereqsTemplate, wantPrereqs, wantInstall addonmgrv1alpha1.ApplicationAssemblyPhase
+	}{
+		{addonmgrv1alpha1.Pending, "", addonmgrv1alpha1.Pending, addonmgrv1alpha1.Pending},
+		{addonmgrv1alpha1.Pending, addonmgrv1alpha1.Failed, addonmgrv1alpha1.Pending, addonmgrv1alpha1.Pending},
+		{addonmgrv1alpha1.Pending, addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.Pending},
+		{addonmgrv1alpha1.Failed, addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.Failed},
+		{addonmgrv1alpha1.Failed, addonmgrv1alpha1.Failed, addonmgrv1alpha1.Failed, addonmgrv1alpha1.Failed},
+		{addonmgrv1alpha1.Failed, "", addonmgrv1alpha1.Failed, addonmgrv1alpha1.Failed},
+		{addonmgrv1alpha1.Succeeded, "", addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.Succeeded},
+	}
+	for _, tt := range tests {
+		prereqs, install := combinedPhases(tt.phase, tt.prereqsTemplate)
+		g.Expect(prereqs).To(Equal(tt.wantPrereqs), "phase %s, prereqs template %s", tt.phase, tt.prereqsTemplate)
+		g.Expect(install).To(Equal(tt.wantInstall), "phase %s, prereqs template %s", tt.phase, tt.prereqsTemplate)
+	}
+}
+
+func TestExecutePrereqAndInstall_Combined(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{
+		Log:      log,
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Params.Namespace = "logging"
+	instance.Spec.Lifecycle.CombinedTemplate.Template = "kind: Workflow"
+
+	// Prereqs completed while install still runs
+	wfl := &combinedLifecycle{
+		phase:     addonmgrv1alpha1.Pending,
+		templates: map[string]addonmgrv1alpha1.ApplicationAssemblyPhase{"prereqs": addonmgrv1alpha1.Succeeded},
+	}
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, wfl)).To(Succeed())
+	g.Expect(wfl.submitted).To(Equal([]string{instance.GetFormattedWorkflowName(addonmgrv1alpha1.Combined)}))
+	g.Expect(instance.Status.Lifecycle.Prereqs).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.ActiveWorkflow.LifecycleStep).To(Equal(addonmgrv1alpha1.Combined))
+
+	wfl.phase = addonmgrv1alpha1.Succeeded
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, wfl)).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(instance.Status.LastAppliedChecksum).To(Equal(instance.Status.Checksum))
+
+	// Failed prereqs fail the install as well
+	instance.Status = addonmgrv1alpha1.AddonStatus{}
+	wfl = &combinedLifecycle{phase: addonmgrv1alpha1.Failed}
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, wfl)).To(HaveOccurred())
+	g.Expect(instance.Status.Lifecycle.Prereqs).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("combined workflow"))
+}
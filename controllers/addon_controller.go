@@ -16,7 +16,10 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -24,14 +27,23 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/jinzhu/inflection"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
@@ -39,8 +51,11 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -49,12 +64,31 @@ import (
 	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
 	"github.com/keikoproj/addon-manager/pkg/addon"
 	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/git"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
+	"github.com/keikoproj/addon-manager/pkg/notify"
+	"github.com/keikoproj/addon-manager/pkg/oci"
 	"github.com/keikoproj/addon-manager/pkg/workflows"
 )
 
 // addon ttl time
 const TTL = time.Duration(1) * time.Hour // 1 hour
 
+// minimum interval between reconcile timing events of an addon
+const timingEventInterval = 10 * time.Minute
+
+// Workflow watch defaults
+const (
+	DefaultWorkflowResyncPeriod  = 30 * time.Minute
+	DefaultWorkflowRecheckPeriod = 5 * time.Minute
+)
+
+// DefaultWorkflowHistoryLimit is the default number of completed workflows of previous specs kept per lifecycle step
+const DefaultWorkflowHistoryLimit = 3
+
+// DefaultFinalizerName is the finalizer set on addons when none is configured
+const DefaultFinalizerName = "delete.addonmgr.keikoproj.io"
+
 // Watched resources
 var (
 	resources = [...]runtime.Object{
@@ -64,66 +98,195 @@ var (
 		&appsv1.DaemonSet{TypeMeta: metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"}},
 		&appsv1.ReplicaSet{TypeMeta: metav1.TypeMeta{Kind: "ReplicaSet", APIVersion: "apps/v1"}},
 		&appsv1.StatefulSet{TypeMeta: metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"}},
+		&v1.PersistentVolumeClaim{TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"}},
+	}
+	// Watched resources which may not be served by the cluster, only the first served version of each kind is watched
+	optionalResources = [...]runtime.Object{
+		&autoscalingv2beta2.HorizontalPodAutoscaler{TypeMeta: metav1.TypeMeta{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v2beta2"}},
+		&autoscalingv1.HorizontalPodAutoscaler{TypeMeta: metav1.TypeMeta{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v1"}},
+		&networkingv1.NetworkPolicy{TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "networking.k8s.io/v1"}},
+		&policyv1beta1.PodDisruptionBudget{TypeMeta: metav1.TypeMeta{Kind: "PodDisruptionBudget", APIVersion: "policy/v1beta1"}},
+	}
+	// Watched cluster-scoped resources, matched by the addon labels only
+	clusterResources = [...]runtime.Object{
+		&rbacv1.ClusterRole{TypeMeta: metav1.TypeMeta{Kind: "ClusterRole", APIVersion: "rbac.authorization.k8s.io/v1"}},
+		&rbacv1.ClusterRoleBinding{TypeMeta: metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"}},
 	}
-	finalizerName      = "delete.addonmgr.keikoproj.io"
 	generatedInformers informers.SharedInformerFactory
 )
 
 // AddonReconciler reconciles a Addon object
 type AddonReconciler struct {
 	client.Client
-	Log             logr.Logger
-	Scheme          *runtime.Scheme
-	versionCache    addon.VersionCacheClient
-	dynClient       dynamic.Interface
-	generatedClient *kubernetes.Clientset
-	recorder        record.EventRecorder
-	statusWGMap     map[string]*sync.WaitGroup
+	Log              logr.Logger
+	Scheme           *runtime.Scheme
+	apiReader        client.Reader
+	versionCache     addon.VersionCacheClient
+	dynClient        dynamic.Interface
+	restMapper       meta.RESTMapper
+	generatedClient  kubernetes.Interface
+	discovery        discovery.DiscoveryInterface
+	recorder         record.EventRecorder
+	eventThrottle    *eventThrottle
+	statusWGMap      map[string]*sync.WaitGroup
+	templates        oci.Fetcher
+	gitResolver      git.Resolver
+	gitPolls         sync.Map
+	deletedWorkflows sync.Map
+	lastObserved     sync.Map
+	inventories      sync.Map
+	generations      sync.Map
+	statusWrites     sync.Map
+	priorities       sync.Map
+	priorityQueue    *priorityFront
+	timingEvents     map[string]time.Time
+	timingEventsMu   sync.Mutex
+	requeueEvents    chan event.GenericEvent
+	rateLimiter      *addonRateLimiter
+	requeueJitter    *requeueJitter
+	workflows        *workflowsDetector
+	watched          []runtime.Object
+	nameLabelKeys    sync.Map
+	drain            reconcileDrain
+
+	// WorkflowResyncPeriod is the resync period of the workflow informers
+	WorkflowResyncPeriod time.Duration
+	// WorkflowRecheckPeriod is how often the workflow phase of a pending addon is checked when no workflow event is seen
+	WorkflowRecheckPeriod time.Duration
+	// DriftCheckPeriod is how often the resources of an addon are observed when no event is seen, zero disables it
+	DriftCheckPeriod time.Duration
+	// GitPollInterval is how often the refs of git sources are resolved, zero resolves them on every reconcile
+	GitPollInterval time.Duration
+	// QuarantineAfter is the number of consecutive failed reconciles after which an addon is quarantined, zero disables it
+	QuarantineAfter int
+	// Intervals are the requeue intervals of addons which wait on something
+	Intervals ReconcileIntervals
+	// ReconcilesPerMinute caps the reconciles of a single addon, zero disables the limit
+	ReconcilesPerMinute int
+	// WorkflowHistoryLimit is the number of completed workflows of previous specs kept per lifecycle step, negative keeps all
+	WorkflowHistoryLimit int
+	// WorkflowTTL is how long Argo keeps completed workflows, zero keeps them until their spec changes
+	WorkflowTTL time.Duration
+	// MaxObservedResources caps the resources listed in addon status, zero lists all resources
+	MaxObservedResources int
+	// RequireWorkflowInstanceID fails validation of addons running workflows without a workflow instance id
+	RequireWorkflowInstanceID bool
+	// Cluster are the values of the cluster workflow templates can reference, placeholders of unset values fail validation
+	Cluster workflows.ClusterValues
+	// DefaultAddonNamespace is set as spec.params.namespace of addons without one, empty fails their validation
+	DefaultAddonNamespace string
+	// FinalizerName is the finalizer the controller sets on addons, distinct builds sharing a cluster need distinct names
+	FinalizerName string
+	// PreviousFinalizerNames are finalizers set by earlier configurations, they are replaced with FinalizerName
+	PreviousFinalizerNames []string
+	// WatchNamespaces are the namespaces addons and their workflows are watched in, empty watches all namespaces
+	WatchNamespaces []string
+	// StatusDebounce is how long status changes of an addon are coalesced after a status write, phase transitions are
+	// written immediately, zero writes every change
+	StatusDebounce time.Duration
+	// Notifications receives install phase transitions of addons, nil disables notifications
+	Notifications *notify.Dispatcher
+	// StatusStream receives every persisted status change of addons, nil disables streaming
+	StatusStream *notify.Stream
+	// MetricsLabelKeys are the common label keys of addons added as labels to the addon lifecycle phase metric
+	MetricsLabelKeys []string
 }
 
 // NewAddonReconciler returns an instance of AddonReconciler
 func NewAddonReconciler(mgr manager.Manager, log logr.Logger) *AddonReconciler {
+	events := newEventThrottle(mgr.GetEventRecorderFor("addons"), eventThrottleWindow)
+	generatedClient := kubernetes.NewForConfigOrDie(mgr.GetConfig())
 	return &AddonReconciler{
 		Client:          mgr.GetClient(),
 		Log:             log,
 		Scheme:          mgr.GetScheme(),
+		apiReader:       mgr.GetAPIReader(),
 		versionCache:    addon.NewAddonVersionCacheClient(),
 		dynClient:       dynamic.NewForConfigOrDie(mgr.GetConfig()),
-		generatedClient: kubernetes.NewForConfigOrDie(mgr.GetConfig()),
-		recorder:        mgr.GetEventRecorderFor("addons"),
+		restMapper:      mgr.GetRESTMapper(),
+		generatedClient: generatedClient,
+		discovery:       generatedClient.Discovery(),
+		recorder:        events,
+		eventThrottle:   events,
 		statusWGMap:     map[string]*sync.WaitGroup{},
+		templates:       oci.NewFetcher(&http.Client{Timeout: 30 * time.Second}),
+		gitResolver:     git.NewResolver(&http.Client{Timeout: 30 * time.Second}),
+		timingEvents:    map[string]time.Time{},
+		requeueEvents:   make(chan event.GenericEvent, 100),
+		requeueJitter:   newRequeueJitter(rand.NewSource(time.Now().UnixNano()), requeueJitterFactor),
+
+		WorkflowResyncPeriod:  DefaultWorkflowResyncPeriod,
+		WorkflowRecheckPeriod: DefaultWorkflowRecheckPeriod,
+		DriftCheckPeriod:      DefaultDriftCheckPeriod,
+		GitPollInterval:       DefaultGitPollInterval,
+		ReconcilesPerMinute:   DefaultReconcilesPerMinute,
+		QuarantineAfter:       DefaultQuarantineAfter,
+		Intervals:             DefaultReconcileIntervals(),
+		WorkflowHistoryLimit:  DefaultWorkflowHistoryLimit,
+		WorkflowTTL:           workflows.DefaultWorkflowTTL,
+		MaxObservedResources:  DefaultMaxObservedResources,
+		FinalizerName:         DefaultFinalizerName,
+		StatusDebounce:        DefaultStatusDebounce,
 	}
 }
 
+// VersionCache returns the cache of addon versions dependencies are resolved with, it is populated by reconciles and
+// so only by the leader
+func (r *AddonReconciler) VersionCache() addon.VersionCacheClient {
+	return r.versionCache
+}
+
 // +kubebuilder:rbac:groups=addonmgr.keikoproj.io,resources=addons,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=addonmgr.keikoproj.io,resources=addons/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=argoproj.io,resources=workflows,namespace=system,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=list
-// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;patch;create
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+// +kubebuilder:rbac:groups=argoproj.io,resources=workflows,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;create;update;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;patch;create;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;create;update;delete;bind;escalate
 // +kubebuilder:rbac:groups="",resources=namespaces;clusterroles;configmaps;events;pods;serviceaccounts;services,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;replicasets;statefulsets,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups=extensions,resources=deployments;daemonsets;replicasets;ingresses,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
 
 // Reconcile method for all addon requests
 func (r *AddonReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
-	log := r.Log.WithValues("addon", req.NamespacedName)
+	log := r.Log.WithValues(logKeyAddon, req.Name, logKeyNamespace, req.Namespace)
+
+	// Reconciles started during shutdown could not persist the status, leave the addon to the next leader
+	if !r.drain.begin() {
+		log.Info("Shutting down, addon is not reconciled.")
+		return reconcile.Result{}, nil
+	}
+	defer r.drain.end()
 
 	log.Info("Starting addon-manager reconcile...")
 	var instance = &addonmgrv1alpha1.Addon{}
 	if err := r.Get(context.TODO(), req.NamespacedName, instance); err != nil {
 		log.Info("Addon not found.")
 
-		// Remove version from cache
-		if ok, v := r.versionCache.HasVersionName(req.Name); ok {
-			r.versionCache.RemoveVersion(v.PkgName, v.PkgVersion)
+		if apierrors.IsNotFound(err) {
+			r.removeFromCache(ctx, log, req.NamespacedName)
 		}
 
 		return reconcile.Result{}, ignoreNotFound(err)
 	}
 
-	return r.execAddon(ctx, req, log, instance)
+	// Cap reconciles of a single addon so an addon in a hot loop does not starve others
+	if ok, delay := r.rateLimiter.Allow(req.NamespacedName); !ok {
+		if r.rateLimiter.ShouldRecordEvent(req.NamespacedName) {
+			r.recorder.Event(instance, "Warning", "RateLimited", fmt.Sprintf("Addon %s/%s is reconciled too often and is being rate limited.", instance.Namespace, instance.Name))
+		}
+		log.Info("Addon reconcile is rate limited", "requeueAfter", delay)
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+
+	return r.execAddon(ctx, req, addonLogger(r.Log, instance), instance)
 }
 
 func (r *AddonReconciler) execAddon(ctx context.Context, req reconcile.Request, log logr.Logger, instance *addonmgrv1alpha1.Addon) (reconcile.Result, error) {
@@ -133,19 +296,46 @@ func (r *AddonReconciler) execAddon(ctx context.Context, req reconcile.Request,
 		}
 	}()
 
-	var wfl = workflows.NewWorkflowLifecycle(r.Client, r.dynClient, instance, r.recorder, r.Scheme)
+	var wfl = workflows.NewWorkflowLifecycle(r.Client, r.dynClient, instance, r.recorder, r.Scheme, r.Cluster, r.WorkflowTTL)
+	var prevPhase = instance.Status.Lifecycle.Installed
 
 	// Resource is being deleted, run finalizers and exit.
 	if !instance.ObjectMeta.DeletionTimestamp.IsZero() {
+		// Addons which failed to delete run the delete workflow again once when asked to, before it is finalized
+		retried, err := r.retryDelete(ctx, log, instance, wfl)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if retried {
+			err := r.updateAddonStatus(ctx, log, instance, prevPhase)
+			return reconcile.Result{Requeue: true}, err
+		}
+
 		// For a better user experience we want to update the status and requeue
-		if instance.Status.Lifecycle.Installed != addonmgrv1alpha1.Deleting {
+		if instance.Status.Lifecycle.Installed != addonmgrv1alpha1.Deleting && instance.Status.Lifecycle.Installed != addonmgrv1alpha1.DeleteFailed {
 			instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Deleting
 			log.Info("Requeue to set deleting status")
-			err := r.updateAddonStatus(ctx, log, instance)
-			return reconcile.Result{}, err
+			err := r.updateAddonStatus(ctx, log, instance, prevPhase)
+			return reconcile.Result{Requeue: true}, err
 		}
 
-		err := r.Finalize(ctx, instance, wfl, finalizerName)
+		var prevReason = instance.Status.Reason
+		err = r.Finalize(ctx, instance, wfl, r.FinalizerName)
+		if common.IsRetryable(err) {
+			log.Info("Addon could not be finalized, retrying.", "error", err.Error())
+			return reconcile.Result{Requeue: true}, nil
+		}
+		if isDependentsInstalled(err) {
+			if prevReason != instance.Status.Reason {
+				if err := r.updateAddonStatus(ctx, log, instance, prevPhase); err != nil {
+					return reconcile.Result{}, err
+				}
+			}
+			return reconcile.Result{
+				Requeue:      true,
+				RequeueAfter: r.requeueJitter.Apply(r.Intervals.Pending),
+			}, nil
+		}
 		if err != nil {
 			reason := fmt.Sprintf("Addon %s/%s could not be finalized. %v", instance.Namespace, instance.Name, err)
 			r.recorder.Event(instance, "Warning", "Failed", reason)
@@ -154,20 +344,69 @@ func (r *AddonReconciler) execAddon(ctx context.Context, req reconcile.Request,
 			log.Error(err, "Failed to finalize addon.")
 			return reconcile.Result{}, err
 		}
+
+		// Delete workflow timed out or the addon is protected and the finalizer was kept, record it on the addon.
+		var changed = prevPhase != instance.Status.Lifecycle.Installed || prevReason != instance.Status.Reason
+		if changed && len(r.finalizersOf(instance, r.FinalizerName)) > 0 {
+			if err := r.updateAddonStatus(ctx, log, instance, prevPhase); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		// Protected addons are finalized once protection is removed, which is observed as a spec change
+		if instance.Spec.Lifecycle.DeleteProtection {
+			return reconcile.Result{}, nil
+		}
+
 		// Requeue to remove from caches
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	// Addons read from a lagging cache would submit the workflows of a previous spec, their resources and status are
+	// still refreshed but workflows are left to the request queued once the cache is updated
+	stale := r.staleGeneration(instance)
+	if stale {
+		log.Info("Addon generation is older than the reconciled generation, not submitting workflows of stale request.", "generation", instance.Generation)
+	}
+
+	// Unchanged installed addons only have their resources re-checked until the next full reconcile is due
+	if ret, ok := r.fastPath(log, instance); ok {
+		return ret, nil
+	}
+
 	// Process addon instance
-	ret, procErr := r.processAddon(ctx, log, instance, wfl)
+	timings := metrics.NewPhaseTimings()
+	ret, procErr := r.processAddon(ctx, log, instance, wfl, timings)
+	r.recordTimings(instance, timings)
+	if stale {
+		// Stale requests do not lower the reconciled generation and are retried to pick up the latest spec
+		ret.Requeue = true
+	} else if procErr == nil {
+		// Only successful reconciles record the generation, failed ones are retried with it
+		r.reconciled(instance)
+	}
+
+	// Quarantined addons are not retried with backoff
+	if r.trackFailure(log, instance, procErr) {
+		ret, procErr = reconcile.Result{}, nil
+	}
 
 	// Always update cache, status
 	r.addAddonToCache(log, instance)
 
-	err := r.updateAddonStatus(ctx, log, instance)
+	// Rapid status changes are written once the debounce period of the previous write passed
+	if delay := r.coalesceStatus(instance, prevPhase, procErr); delay > 0 {
+		metrics.StatusWritesCoalesced.Inc()
+		if ret.RequeueAfter == 0 || ret.RequeueAfter > delay {
+			ret.RequeueAfter = delay
+		}
+		return ret, nil
+	}
+
+	err := r.updateAddonStatus(ctx, log, instance, prevPhase)
 	if err != nil {
 		// Force retry when status fails to update
-		return reconcile.Result{RequeueAfter: 1 * time.Second}, err
+		return reconcile.Result{RequeueAfter: r.Intervals.StatusRetry}, err
 	}
 
 	return ret, procErr
@@ -176,25 +415,89 @@ func (r *AddonReconciler) execAddon(ctx context.Context, req reconcile.Request,
 // SetupWithManager is called to setup manager and watchers
 func (r *AddonReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	log := r.Log
-	managedNS := "addon-manager-system"
 
-	nsInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(r.dynClient, time.Minute*30, managedNS, nil)
-	wfInf := nsInformers.ForResource(common.WorkflowGVR())
+	r.rateLimiter = newAddonRateLimiter(r.ReconcilesPerMinute)
+
+	if err := r.registerGroupMetrics(); err != nil {
+		return err
+	}
+	if err := r.registerPhaseMetrics(); err != nil {
+		return err
+	}
+
+	r.workflows = newWorkflowsDetector(r.generatedClient.Discovery(), r.Intervals.WorkflowsNotServed)
+	if !r.workflows.Served() {
+		err := fmt.Errorf("%s.%s/%s is not served", common.WorkflowGVR().Resource, common.WorkflowGVR().Group, common.WorkflowGVR().Version)
+		log.Error(err, "Argo Workflows is not installed, addons are kept Pending until it is.")
+	}
+
+	// Addons with a higher priority are handed to the controller first when the queue is backlogged, all watches queue
+	// their requests by priority
+	r.priorityQueue = newPriorityFront("addon-priority", maxConcurrentReconciles, r.addonPriority)
+	if err := mgr.Add(r.priorityQueue); err != nil {
+		return err
+	}
+
 	bldr := ctrl.NewControllerManagedBy(mgr).
-		For(&addonmgrv1alpha1.Addon{}).
-		// Watch workflows created by addon only in addon-manager-system namespace
-		Watches(&source.Informer{Informer: wfInf.Informer().(cache.Informer)}, &handler.EnqueueRequestForOwner{
-			IsController: true,
-			OwnerType:    &addonmgrv1alpha1.Addon{},
+		For(&addonmgrv1alpha1.Addon{}, builder.WithPredicates(queuedByPriority)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
+		Watches(&source.Kind{Type: &addonmgrv1alpha1.Addon{}}, r.prioritized(&handler.EnqueueRequestForObject{})).
+		// Reconcile addons requested by other addons, e.g. all addons in a dependency cycle
+		Watches(&source.Channel{Source: r.requeueEvents}, r.prioritized(&handler.EnqueueRequestForObject{})).
+		// Reconcile addons pending on a dependency as soon as its install completes
+		Watches(&source.Kind{Type: &addonmgrv1alpha1.Addon{}}, r.prioritized(&handler.Funcs{
+			UpdateFunc: r.dependencyCompleted,
+		}))
+
+	namespaces := r.WatchNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var wfInformers []dynamicinformer.DynamicSharedInformerFactory
+	for _, ns := range namespaces {
+		nsInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(r.dynClient, r.WorkflowResyncPeriod, ns, nil)
+		wfInf := nsInformers.ForResource(common.WorkflowGVR())
+
+		// Workflows running outside of the addon namespace cannot have owner references and are labeled instead
+		labeledInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(r.dynClient, r.WorkflowResyncPeriod, ns, func(options *metav1.ListOptions) {
+			options.LabelSelector = fmt.Sprintf("app.kubernetes.io/managed-by=%s", common.AddonGVR().Group)
 		})
+		labeledWfInf := labeledInformers.ForResource(common.WorkflowGVR())
+
+		bldr = bldr.
+			// Watch workflows created by addon in the watched namespaces
+			Watches(&source.Informer{Informer: wfInf.Informer().(cache.Informer)}, r.prioritized(&handler.EnqueueRequestForOwner{
+				IsController: true,
+				OwnerType:    &addonmgrv1alpha1.Addon{},
+			})).
+			// Watch running workflows which are deleted externally
+			Watches(&source.Informer{Informer: wfInf.Informer().(cache.Informer)}, r.prioritized(&handler.Funcs{
+				DeleteFunc: r.workflowDeleted,
+			})).
+			// Watch workflows created by addon in a workflow namespace override
+			Watches(&source.Informer{Informer: labeledWfInf.Informer().(cache.Informer)}, r.prioritized(&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+					if metav1.GetControllerOf(a.Meta) != nil {
+						// Owned workflows are handled by the owner watch
+						return nil
+					}
+					return r.getAddonRequestsFromLabels(a)
+				}),
+			}))
+
+		wfInformers = append(wfInformers, nsInformers, labeledInformers)
+	}
 
 	generatedInformers = informers.NewSharedInformerFactory(r.generatedClient, time.Minute*30)
 
 	err := mgr.Add(manager.RunnableFunc(func(s <-chan struct{}) error {
 		generatedInformers.Start(s)
 		generatedInformers.WaitForCacheSync(s)
-		nsInformers.Start(s)
-		nsInformers.WaitForCacheSync(s)
+		for _, inf := range wfInformers {
+			inf.Start(s)
+			inf.WaitForCacheSync(s)
+		}
 		<-s
 		return nil
 	}))
@@ -204,7 +507,8 @@ func (r *AddonReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	// Watch for changes to kubernetes Resources matching addon labels.
-	for _, resc := range resources {
+	r.watched = append(resources[:], r.servedResources(optionalResources[:])...)
+	for _, resc := range append(clusterResources[:], r.watched...) {
 		gvk := resc.GetObjectKind().GroupVersionKind()
 		_, kind := gvk.ToAPIVersionAndKind()
 
@@ -219,44 +523,179 @@ func (r *AddonReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return err
 		}
 
-		bldr = bldr.Watches(&source.Informer{Informer: inf.Informer().(cache.Informer)}, &handler.EnqueueRequestsFromMapFunc{
-			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
-				var reqs = make([]reconcile.Request, 0)
-				var labels = a.Meta.GetLabels()
-				if name, ok := labels["app.kubernetes.io/name"]; ok && strings.TrimSpace(name) != "" {
-					// Let's lookup addon related to this object.
-					if ok, v := r.versionCache.HasVersionName(name); ok {
-						reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
-							Name:      v.Name,
-							Namespace: v.Namespace,
-						}})
-					}
-				}
-				return reqs
-			}),
-		})
+		bldr = bldr.Watches(&source.Informer{Informer: inf.Informer().(cache.Informer)}, r.prioritized(&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.getAddonRequestsFromLabels),
+		}))
+	}
+
+	return bldr.Complete(&priorityReconciler{Reconciler: r, queue: r.priorityQueue})
+}
+
+// servedResources returns the first version of each kind that is served by the cluster
+func (r *AddonReconciler) servedResources(candidates []runtime.Object) []runtime.Object {
+	var served []runtime.Object
+	var found = map[string]bool{}
+
+	for _, resc := range candidates {
+		gvk := resc.GetObjectKind().GroupVersionKind()
+		if found[gvk.GroupKind().String()] {
+			continue
+		}
+
+		gvr := schema.GroupVersionResource{
+			Group:    gvk.Group,
+			Version:  gvk.Version,
+			Resource: inflection.Plural(strings.ToLower(gvk.Kind)),
+		}
+
+		list, err := r.generatedClient.Discovery().ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+		if err != nil || !containsResource(list, gvr.Resource) {
+			r.Log.Info("Resource is not served, skipping watch", "groupVersion", gvk.GroupVersion().String(), "kind", gvk.Kind)
+			continue
+		}
+
+		found[gvk.GroupKind().String()] = true
+		served = append(served, resc)
 	}
 
-	return bldr.Complete(r)
+	return served
 }
 
-func (r *AddonReconciler) processAddon(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) (reconcile.Result, error) {
+func containsResource(list *metav1.APIResourceList, resource string) bool {
+	for _, res := range list.APIResources {
+		if res.Name == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// getAddonRequestsFromLabels returns the addons the object is labeled for, or its owners when it has no name label
+func (r *AddonReconciler) getAddonRequestsFromLabels(a handler.MapObject) []reconcile.Request {
+	var reqs = make([]reconcile.Request, 0)
+	var labels = a.Meta.GetLabels()
+	var names = map[string]bool{}
+	if name, ok := labels[common.NameLabel]; ok && strings.TrimSpace(name) != "" {
+		names[name] = true
+	}
+	// Addons may observe their resources with a custom name label
+	r.nameLabelKeys.Range(func(key, _ interface{}) bool {
+		if name, ok := labels[key.(string)]; ok && strings.TrimSpace(name) != "" {
+			names[name] = true
+		}
+		return true
+	})
+	// Resources whose labels were removed are still mapped by their owner references
+	if len(names) == 0 {
+		return getAddonRequestsFromOwners(a.Meta)
+	}
+	for name := range names {
+		// Let's lookup addons related to this object, addons in different namespaces may share the name.
+		for _, v := range r.versionCache.GetVersionsWithName(name) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      v.Name,
+				Namespace: v.Namespace,
+			}})
+		}
+	}
+	return reqs
+}
+
+func (r *AddonReconciler) processAddon(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle, timings *metrics.PhaseTimings) (reconcile.Result, error) {
+
+	// The package of an addon is immutable, addons depending on it reference the installed package
+	if err := r.guardPackage(log, instance); err != nil {
+		reason := fmt.Sprintf("Addon %s/%s is not valid. %v", instance.Namespace, instance.Name, err)
+		if instance.Status.Reason != reason {
+			r.recorder.Event(instance, "Warning", "Failed", reason)
+		}
+		log.Error(err, "Addon package name was changed.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.ValidationFailed
+		instance.Status.Reason = reason
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureValidation
+
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.defaultNamespace(ctx, log, instance); err != nil {
+		reason := fmt.Sprintf("Addon %s/%s could not be set to the default namespace %s. %v", instance.Namespace, instance.Name, r.DefaultAddonNamespace, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		log.Error(err, "Addon could not be set to the default namespace.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureValidation
+		instance.Status.Reason = reason
+
+		return reconcile.Result{}, err
+	}
+
+	// Git sources are installed from the commit their ref resolves to, which is part of the checksum
+	if err := r.resolveGitSource(ctx, log, instance); err != nil {
+		reason := fmt.Sprintf("Addon %s/%s is waiting on git ref %q to be resolved. %v", instance.Namespace, instance.Name, instance.Spec.Source.Git.Ref, err)
+		if instance.Status.Reason != reason {
+			r.recorder.Event(instance, "Warning", "Pending", reason)
+		}
+		log.Error(err, "Addon git ref could not be resolved.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+		instance.Status.Reason = reason
+
+		return reconcile.Result{
+			Requeue:      true,
+			RequeueAfter: r.requeueJitter.Apply(r.Intervals.Pending),
+		}, nil
+	}
 
 	// Calculate Checksum, returns true if checksum is not changed
 	var changedStatus bool
 	changedStatus, instance.Status.Checksum = r.validateChecksum(instance)
+	log = addonLogger(r.Log, instance)
 
-	// Resources list
+	// Resources list, the previously observed resources are expected for drift detection
+	expected := instance.Status.Resources
 	instance.Status.Resources = make([]addonmgrv1alpha1.ObjectStatus, 0)
 
-	if changedStatus {
+	forced, err := r.forceReinstall(ctx, log, instance, wfl)
+	if err != nil {
+		reason := fmt.Sprintf("Addon %s/%s could not be reinstalled. %v", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		log.Error(err, "Addon could not be reinstalled.")
+		return reconcile.Result{}, err
+	}
+
+	if changedStatus || forced {
 		// Set ttl starttime if checksum has changed
 		instance.Status.StartTime = common.GetCurretTimestamp()
 
 		// Clear out status and reason
 		instance.Status.Lifecycle.Prereqs = ""
 		instance.Status.Lifecycle.Installed = ""
+		instance.Status.Lifecycle.PostInstall = ""
 		instance.Status.Reason = ""
+		instance.Status.FailureCategory = ""
+		instance.Status.CompletionTime = 0
+		instance.Status.PrereqsStartTime = 0
+		instance.Status.InstallStartTime = 0
+		instance.Status.InstallDuration = ""
+		instance.Status.ActiveWorkflow = nil
+		instance.Status.AppliedOverrides = nil
+		instance.Status.ManifestTiers = nil
+		instance.Status.FailedAttempts = 0
+		instance.Status.RollbackAttempts = 0
+		removeCondition(&instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition)
+	}
+
+	// Quarantined and skipped addons are left as they are until their spec changes or they are force reinstalled
+	if instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Quarantined || instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Skipped {
+		instance.Status.Resources = expected
+		return reconcile.Result{}, nil
+	}
+
+	if changedStatus {
+		// Workflows of previous specs are history, only keep the most recent ones
+		for _, step := range []addonmgrv1alpha1.LifecycleStep{addonmgrv1alpha1.Prereqs, addonmgrv1alpha1.Install, addonmgrv1alpha1.Combined, addonmgrv1alpha1.PostInstall} {
+			if err := wfl.Prune(ctx, step, r.WorkflowHistoryLimit); err != nil {
+				workflowLogger(log, instance, step).Error(err, "Addon workflow history could not be pruned.")
+			}
+		}
 	}
 
 	// Update status that we have started reconciling this addon.
@@ -266,6 +705,26 @@ func (r *AddonReconciler) processAddon(ctx context.Context, log logr.Logger, ins
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	// Workflows cannot run until Argo Workflows is installed, start the ttl once it is.
+	if instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Pending && usesWorkflows(instance) && !r.workflows.Served() {
+		reason := fmt.Sprintf("Addon %s/%s is waiting on Argo Workflows to be installed, the Workflow CRD is not served.", instance.Namespace, instance.Name)
+		if instance.Status.Reason != reason {
+			r.recorder.Event(instance, "Warning", "Pending", reason)
+		}
+		instance.Status.StartTime = common.GetCurretTimestamp()
+		instance.Status.Reason = reason
+
+		return reconcile.Result{
+			Requeue:      true,
+			RequeueAfter: r.requeueJitter.Apply(r.Intervals.WorkflowsNotServed),
+		}, nil
+	}
+
+	// Suspended addons wait for workflows to resume, start the ttl once they do.
+	if instance.Spec.SuspendWorkflows && instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Pending {
+		instance.Status.StartTime = common.GetCurretTimestamp()
+	}
+
 	// Check if addon installation expired.
 	if instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Pending && common.IsExpired(instance.Status.StartTime, TTL.Milliseconds()) {
 		reason := fmt.Sprintf("Addon %s/%s ttl expired, starttime exceeded %s", instance.Namespace, instance.Name, TTL.String())
@@ -274,13 +733,26 @@ func (r *AddonReconciler) processAddon(ctx context.Context, log logr.Logger, ins
 		log.Error(err, reason)
 
 		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureTimeout
 		instance.Status.Reason = reason
 
 		return reconcile.Result{}, err
 	}
 
 	// Validate Addon
-	if ok, err := addon.NewAddonValidator(instance, r.versionCache, r.dynClient).Validate(); !ok {
+	validationStart := time.Now()
+	instance.Status.Dependencies = addon.DependencyStatuses(r.versionCache, instance)
+	ok, err := addon.NewAddonValidator(instance, r.versionCache, r.dynClient, r.discovery).Validate()
+	if ok && r.RequireWorkflowInstanceID && usesWorkflows(instance) && instance.Spec.Lifecycle.WorkflowInstanceID == "" {
+		ok, err = false, fmt.Errorf("spec.lifecycle.workflowInstanceID is required, workflow controllers of the cluster are sharded by instance id")
+	}
+	if ok {
+		if err = r.validateClusterParams(instance); err != nil {
+			ok = false
+		}
+	}
+	timings.Observe(metrics.PhaseValidation, validationStart)
+	if !ok {
 		// if an addons dependency is in a Pending state then make the parent addon Pending
 		if strings.HasPrefix(err.Error(), addon.ErrDepPending) {
 			reason := fmt.Sprintf("Addon %s/%s is waiting on dependencies to be out of Pending state.", instance.Namespace, instance.Name)
@@ -291,18 +763,56 @@ func (r *AddonReconciler) processAddon(ctx context.Context, log logr.Logger, ins
 
 			log.Info("Addon %s/%s is waiting on dependencies to be out of Pending state.", instance.Namespace, instance.Name)
 
-			// requeue after 10 seconds
 			return reconcile.Result{
 				Requeue:      true,
-				RequeueAfter: 10 * time.Second,
+				RequeueAfter: r.requeueJitter.Apply(r.Intervals.Pending),
 			}, nil
 		}
 
+		// Addons are not installed on clusters which do not meet their requirements, which is not a failure
+		var reqErr *addon.RequirementsError
+		if errors.As(err, &reqErr) {
+			reason := fmt.Sprintf("Addon %s/%s is skipped. %v", instance.Namespace, instance.Name, err)
+			r.recorder.Event(instance, "Normal", "Skipped", reason)
+			instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Skipped
+			instance.Status.Reason = reason
+
+			log.Info("Addon is skipped, the cluster does not meet its requirements.", "unmet", reqErr.Unmet)
+
+			return reconcile.Result{}, nil
+		}
+
+		// Addons installing the package version of another addon would fight over its resources, they are not
+		// installed and validated again until the other addon is deleted
+		var conflictErr *addon.ConflictError
+		if errors.As(err, &conflictErr) {
+			reason := fmt.Sprintf("Addon %s/%s conflicts with addon %s. %v", instance.Namespace, instance.Name, conflictErr.Owner, err)
+			if instance.Status.Reason != reason {
+				r.recorder.Event(instance, "Warning", "Conflict", reason)
+			}
+			instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Conflict
+			instance.Status.Reason = reason
+
+			log.Info("Addon conflicts with the addon installing its package version.", "owner", conflictErr.Owner)
+
+			return reconcile.Result{RequeueAfter: r.requeueJitter.Apply(r.Intervals.Validation)}, nil
+		}
+
 		reason := fmt.Sprintf("Addon %s/%s is not valid. %v", instance.Namespace, instance.Name, err)
 		// Record an event if addon is not valid
 		r.recorder.Event(instance, "Warning", "Failed", reason)
 		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.ValidationFailed
 		instance.Status.Reason = reason
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureValidation
+		if addon.IsDependencyError(err) {
+			instance.Status.FailureCategory = addonmgrv1alpha1.FailureDependency
+		}
+
+		// Addons in a dependency cycle would otherwise wait on each other, fail all of them
+		var cycleErr *addon.CycleError
+		if errors.As(err, &cycleErr) {
+			r.requeueAddons(log, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, cycleErr.Addons())
+		}
 
 		log.Error(err, "Failed to validate addon.")
 
@@ -312,8 +822,15 @@ func (r *AddonReconciler) processAddon(ctx context.Context, log logr.Logger, ins
 	// Record successful validation
 	r.recorder.Event(instance, "Normal", "Completed", fmt.Sprintf("Addon %s/%s is valid.", instance.Namespace, instance.Name))
 
+	// Validate-only addons are never installed, validate them again so that their status follows their dependencies
+	if instance.ValidateOnly() {
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.ValidationPassed
+		instance.Status.Reason = ""
+		return reconcile.Result{RequeueAfter: r.requeueJitter.Apply(r.Intervals.Validation)}, nil
+	}
+
 	// Set finalizer only after addon is valid
-	if err := r.SetFinalizer(ctx, instance, finalizerName); err != nil {
+	if err := r.SetFinalizer(ctx, instance, r.FinalizerName); err != nil {
 		reason := fmt.Sprintf("Addon %s/%s could not add finalizer. %v", instance.Namespace, instance.Name, err)
 		r.recorder.Event(instance, "Warning", "Failed", reason)
 		log.Error(err, "Failed to add finalizer for addon.")
@@ -323,37 +840,173 @@ func (r *AddonReconciler) processAddon(ctx context.Context, log logr.Logger, ins
 	}
 
 	// Execute PreReq and Install workflow, if spec body has changed.
-	// In the case when validation failed and continued here we should execute.
+	// In the case when validation failed or the addon conflicted and continued here we should execute.
 	// Also if workflow is in Pending state, execute it to update status to terminal state.
-	if changedStatus || instance.Status.Lifecycle.Installed == addonmgrv1alpha1.ValidationFailed ||
-		instance.Status.Lifecycle.Prereqs == addonmgrv1alpha1.Pending || instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Pending {
+	// Stale generations are not executed, they would submit the workflows of a previous spec.
+	var executed bool
+	if !r.staleGeneration(instance) && (changedStatus || instance.Status.Lifecycle.Installed == addonmgrv1alpha1.ValidationFailed ||
+		instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Conflict ||
+		instance.Status.Lifecycle.Prereqs == addonmgrv1alpha1.Pending || instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Pending) {
+		// Workflows that have not started yet wait for addons in lower waves of the namespace and in previous stages
+		// of their rollout
+		if instance.Status.Lifecycle.Prereqs == "" {
+			if wave, names := addon.BlockingWave(r.versionCache, instance); len(names) > 0 {
+				reason := fmt.Sprintf("Addon %s/%s is waiting on wave %d addons %s to complete.", instance.Namespace, instance.Name, wave, strings.Join(names, ", "))
+				if instance.Status.Reason != reason {
+					r.recorder.Event(instance, "Normal", "Pending", reason)
+				}
+				instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+				instance.Status.Reason = reason
+
+				return reconcile.Result{
+					Requeue:      true,
+					RequeueAfter: r.requeueJitter.Apply(r.Intervals.Pending),
+				}, nil
+			}
+			if r.rolloutBlocked(instance) {
+				return reconcile.Result{
+					Requeue:      true,
+					RequeueAfter: r.requeueJitter.Apply(r.Intervals.Pending),
+				}, nil
+			}
+		}
+
 		log.Info("Addon spec is updated, workflows will be generated")
 
+		workflowStart := time.Now()
+		executed = true
 		err := r.executePrereqAndInstall(ctx, log, instance, wfl)
+		timings.Observe(metrics.PhaseWorkflow, workflowStart)
+		if oci.IsPullError(err) || isCRDsPending(err) {
+			return reconcile.Result{
+				Requeue:      true,
+				RequeueAfter: r.requeueJitter.Apply(r.Intervals.Pending),
+			}, nil
+		}
+		if isNamespacePending(err) {
+			return reconcile.Result{
+				Requeue:      true,
+				RequeueAfter: r.requeueJitter.Apply(namespaceRetryDelay(instance.Status.StartTime)),
+			}, nil
+		}
+		if isTransient(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
 		if err != nil {
 			return reconcile.Result{}, err
 		}
 	}
 
 	// Observe resources matching selector labels.
+	observeStart := time.Now()
 	observed, err := r.observeResources(ctx, instance)
+	timings.Observe(metrics.PhaseObserve, observeStart)
+	if common.IsRetryable(err) {
+		log.Info("Addon could not find deployed resources, retrying.", "error", err.Error())
+		return reconcile.Result{Requeue: true}, nil
+	}
 	if err != nil {
 		reason := fmt.Sprintf("Addon %s/%s failed to find deployed resources. %v", instance.Namespace, instance.Name, err)
 		r.recorder.Event(instance, "Warning", "Failed", reason)
 		log.Error(err, "Addon failed to find deployed resources.")
 		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureObservation
 		instance.Status.Reason = reason
 
 		return reconcile.Result{}, err
 	}
 
-	if len(observed) > 0 {
-		instance.Status.Resources = observed
+	// Resources observed since the install succeeded which disappeared out-of-band are drift
+	var selfHeal bool
+	if !executed && instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Succeeded {
+		var missing []string
+		observed, missing = detectDrift(expected, observed)
+		if selfHeal, err = r.checkDrift(ctx, log, instance, missing, degradedResources(observed)); err != nil {
+			log.Error(err, "Addon could not be reinstalled to restore missing resources.")
+		}
+		// Workloads scaled out-of-band are scaled back, reinstalled addons are scaled once the install completes
+		if !selfHeal {
+			r.applyReplicas(ctx, log, instance)
+		}
+	}
+
+	r.setResources(instance, observed)
+	r.observedFully(instance)
+
+	// The inventory is best effort, it is written again on the next reconcile
+	if err := r.saveInventory(ctx, instance, observed); err != nil {
+		log.Error(err, "Addon inventory could not be saved.")
+	}
+
+	// A changed reconcile token only asks for the status to be refreshed, which has happened by now
+	if token, ok := instance.GetAnnotations()[common.ReconcileTokenAnnotation]; ok && token != instance.Status.ObservedReconcileToken {
+		r.recorder.Event(instance, "Normal", "Refreshed", fmt.Sprintf("Addon %s/%s status was refreshed for reconcile token %q.", instance.Namespace, instance.Name, token))
+		instance.Status.ObservedReconcileToken = token
+	}
+
+	if selfHeal {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Manifests waiting on namespaces and CRDs to be ready raise no workflow event, they are applied again shortly.
+	if len(instance.Spec.Source.Manifests) > 0 && instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Pending {
+		return ctrl.Result{RequeueAfter: r.requeueJitter.Apply(r.Intervals.Pending)}, nil
+	}
+
+	// Workflow events can be missed, re-check the workflow phase of pending addons directly.
+	if r.WorkflowRecheckPeriod > 0 && (instance.Status.Lifecycle.Prereqs == addonmgrv1alpha1.Pending || instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Pending) {
+		return ctrl.Result{RequeueAfter: r.requeueJitter.Apply(r.WorkflowRecheckPeriod)}, nil
+	}
+
+	// Resource events can be missed as well, observe the resources periodically to detect drift.
+	// Refs of git sources are resolved again on the poll interval.
+	if period := r.gitRecheckPeriod(instance, r.DriftCheckPeriod); period > 0 {
+		return ctrl.Result{RequeueAfter: r.requeueJitter.Apply(period)}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// recordTimings emits the reconcile phase breakdown as an event, at most once per timingEventInterval for each addon
+func (r *AddonReconciler) recordTimings(instance *addonmgrv1alpha1.Addon, timings *metrics.PhaseTimings) {
+	if timings.Empty() {
+		return
+	}
+
+	key := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}.String()
+	now := time.Now()
+
+	r.timingEventsMu.Lock()
+	last, ok := r.timingEvents[key]
+	if ok && now.Sub(last) < timingEventInterval {
+		r.timingEventsMu.Unlock()
+		return
+	}
+	r.timingEvents[key] = now
+	r.timingEventsMu.Unlock()
+
+	r.recorder.Event(instance, "Normal", "ReconcileTiming", fmt.Sprintf("Addon %s/%s reconcile timing %s.", instance.Namespace, instance.Name, timings))
+}
+
+// requeueAddons enqueues the addons other than self which have not already failed validation
+func (r *AddonReconciler) requeueAddons(log logr.Logger, self types.NamespacedName, addons []types.NamespacedName) {
+	for _, name := range addons {
+		if name == self {
+			continue
+		}
+
+		if v := r.cachedVersion(name); v != nil && v.PkgPhase == addonmgrv1alpha1.ValidationFailed {
+			continue
+		}
+
+		select {
+		case r.requeueEvents <- event.GenericEvent{Meta: &metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace}, Object: &addonmgrv1alpha1.Addon{}}:
+		default:
+			log.Info("Requeue channel is full, addon will be reconciled on the next event.", "requeue", name.String())
+		}
+	}
+}
+
 func ignoreNotFound(err error) error {
 	if apierrors.IsNotFound(err) {
 		return nil
@@ -361,53 +1014,173 @@ func ignoreNotFound(err error) error {
 	return err
 }
 
-func (r *AddonReconciler) runWorkflow(lifecycleStep addonmgrv1alpha1.LifecycleStep, addon *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
-	log := r.Log.WithValues("addon", fmt.Sprintf("%s/%s", addon.Namespace, addon.Name))
+func (r *AddonReconciler) runWorkflow(lifecycleStep addonmgrv1alpha1.LifecycleStep, addon *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle, params map[string]string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	log := workflowLogger(addonLogger(r.Log, addon), addon, lifecycleStep)
 
 	wt, err := addon.GetWorkflowType(lifecycleStep)
 	if err != nil {
-		log.Error(err, "lifecycleStep is not a field in LifecycleWorkflowSpec", "lifecycleStep", lifecycleStep)
+		log.Error(err, "lifecycleStep is not a field in LifecycleWorkflowSpec")
 		return addonmgrv1alpha1.Failed, err
 	}
 
+	if wt.Suspend {
+		// Suspended steps are skipped as if no workflow was provided
+		r.recorder.Event(addon, "Normal", "Skipped", fmt.Sprintf("Addon %s/%s %s workflow was skipped, the step is suspended.", addon.Namespace, addon.Name, lifecycleStep))
+		log.Info("Addon workflow step is suspended, skipping.")
+		return addonmgrv1alpha1.Succeeded, nil
+	}
+
+	if lifecycleStep == addonmgrv1alpha1.Install && addon.Spec.Source.Kustomize.Path != "" {
+		// Resources are rendered from the kustomize source by a generated workflow
+		template, err := workflows.KustomizeTemplate(addon)
+		if err != nil {
+			return addonmgrv1alpha1.Failed, err
+		}
+		wt = wt.DeepCopy()
+		wt.Template = template
+	}
+
+	if lifecycleStep == addonmgrv1alpha1.Install && addon.Spec.Source.Git.Repo != "" {
+		// Resources are checked out at the resolved commit of the git source by a generated workflow
+		template, err := workflows.GitTemplate(addon)
+		if err != nil {
+			return addonmgrv1alpha1.Failed, err
+		}
+		wt = wt.DeepCopy()
+		wt.Template = template
+	}
+
 	if wt.Template == "" {
 		// No workflow was provided, so mark as succeeded
 		return addonmgrv1alpha1.Succeeded, nil
 	}
 
+	if addon.Spec.SuspendWorkflows {
+		// Keep the current phase so that the workflow is submitted once workflows are resumed
+		r.recorder.Event(addon, "Normal", "Suspended", fmt.Sprintf("Addon %s/%s %s workflow was not submitted, workflows are suspended.", addon.Namespace, addon.Name, lifecycleStep))
+		return lifecyclePhase(addon, lifecycleStep), nil
+	}
+
 	wfIdentifierName := addon.GetFormattedWorkflowName(lifecycleStep)
 	if wfIdentifierName == "" {
 		return addonmgrv1alpha1.Failed, fmt.Errorf("could not generate workflow template name")
 	}
-	phase, err := wfl.Install(context.TODO(), wt, wfIdentifierName)
+
+	if oci.IsReference(wt.Template) {
+		// Render the template hosted in the registry, the spec keeps the reference
+		template, err := r.templates.Fetch(context.TODO(), wt.Template)
+		if err != nil {
+			return addonmgrv1alpha1.Pending, err
+		}
+		wt = wt.DeepCopy()
+		wt.Template = template
+	}
+
+	phase, err := wfl.Install(context.TODO(), wt, wfIdentifierName, params)
+	trackActiveWorkflow(addon, lifecycleStep, wfIdentifierName, phase, err)
 	if err != nil {
 		return phase, err
 	}
+	log.Info("Addon workflow is submitted", "phase", phase)
 	r.recorder.Event(addon, "Normal", "Completed", fmt.Sprintf("Completed %s workflow %s/%s.", strings.Title(string(lifecycleStep)), addon.Namespace, wfIdentifierName))
 	return phase, nil
 }
 
+// lifecyclePhase returns the phase currently recorded in status for the lifecycle step
+func lifecyclePhase(addon *addonmgrv1alpha1.Addon, lifecycleStep addonmgrv1alpha1.LifecycleStep) addonmgrv1alpha1.ApplicationAssemblyPhase {
+	if lifecycleStep == addonmgrv1alpha1.Prereqs {
+		return addon.Status.Lifecycle.Prereqs
+	}
+	if lifecycleStep == addonmgrv1alpha1.PostInstall {
+		if addon.Status.Lifecycle.PostInstall == "" {
+			return addonmgrv1alpha1.Pending
+		}
+		return addon.Status.Lifecycle.PostInstall
+	}
+	return addon.Status.Lifecycle.Installed
+}
+
 func (r *AddonReconciler) validateSecrets(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
-	foundSecrets, err := r.dynClient.Resource(common.SecretGVR()).Namespace(addon.Spec.Params.Namespace).List(ctx, metav1.ListOptions{})
+	var names = make([]string, 0, len(addon.Spec.Secrets))
+	for _, secret := range addon.Spec.Secrets {
+		names = append(names, secret.Name)
+	}
+
+	secrets, err := r.requireSecrets(ctx, addon, addon.Spec.Params.Namespace, names...)
 	if err != nil {
 		return err
 	}
 
-	secretsList := make(map[string]struct{}, len(foundSecrets.Items))
+	// Only the presence of keys is checked, values must not end up in errors or logs
+	for _, ref := range addon.Spec.Secrets {
+		secret := secrets[ref.Name]
+		data, _, _ := unstructured.NestedFieldNoCopy(secret.Object, "data")
+		values, _ := data.(map[string]interface{})
+		for _, key := range ref.RequiredKeys {
+			if v, _ := values[key].(string); v == "" {
+				return fmt.Errorf("addon %s needs key \"%s\" in secret \"%s\" that was not found or is empty in namespace %s", addon.Name, key, ref.Name, addon.Spec.Params.Namespace)
+			}
+		}
+	}
+
+	return nil
+}
+
+// requireSecrets returns the named secrets of the namespace, or an error naming the first secret that was not found
+func (r *AddonReconciler) requireSecrets(ctx context.Context, addon *addonmgrv1alpha1.Addon, namespace string, names ...string) (map[string]unstructured.Unstructured, error) {
+	foundSecrets, err := r.dynClient.Resource(common.SecretGVR()).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	secretsList := make(map[string]unstructured.Unstructured, len(foundSecrets.Items))
 	for _, foundSecret := range foundSecrets.Items {
-		secretsList[foundSecret.UnstructuredContent()["metadata"].(map[string]interface{})["name"].(string)] = struct{}{}
+		secretsList[foundSecret.GetName()] = foundSecret
 	}
 
-	for _, secret := range addon.Spec.Secrets {
-		if _, ok := secretsList[secret.Name]; !ok {
-			return fmt.Errorf("addon %s needs secret \"%s\" that was not found in namespace %s", addon.Name, secret.Name, addon.Spec.Params.Namespace)
+	var secrets = make(map[string]unstructured.Unstructured, len(names))
+	for _, name := range names {
+		secret, ok := secretsList[name]
+		if !ok {
+			return nil, fmt.Errorf("addon %s needs secret \"%s\" that was not found in namespace %s", addon.Name, name, namespace)
 		}
+		secrets[name] = secret
 	}
 
-	return nil
+	return secrets, nil
+}
+
+// watchesNamespace returns true if workflows in the namespace are watched
+func (r *AddonReconciler) watchesNamespace(namespace string) bool {
+	return len(r.WatchNamespaces) == 0 || common.ContainsString(r.WatchNamespaces, namespace)
 }
 
-func (r *AddonReconciler) updateAddonStatus(ctx context.Context, log logr.Logger, addon *addonmgrv1alpha1.Addon) error {
+func (r *AddonReconciler) validateServiceAccount(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	name := addon.Spec.Lifecycle.ServiceAccount
+	if name == "" {
+		return nil
+	}
+
+	_, err := r.dynClient.Resource(common.ServiceAccountGVR()).Namespace(addon.GetWorkflowNamespace()).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("addon %s needs service account \"%s\" that was not found in workflow namespace %s", addon.Name, name, addon.GetWorkflowNamespace())
+	}
+
+	return err
+}
+
+// validateImagePullSecrets validates that the image pull secrets of workflow pods exist in the workflow namespace
+func (r *AddonReconciler) validateImagePullSecrets(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	if len(addon.Spec.Lifecycle.ImagePullSecrets) == 0 {
+		return nil
+	}
+
+	_, err := r.requireSecrets(ctx, addon, addon.GetWorkflowNamespace(), addon.Spec.Lifecycle.ImagePullSecrets...)
+	return err
+}
+
+// updateAddonStatus persists the addon status and notifies when the install phase changed from prevPhase
+func (r *AddonReconciler) updateAddonStatus(ctx context.Context, log logr.Logger, addon *addonmgrv1alpha1.Addon, prevPhase addonmgrv1alpha1.ApplicationAssemblyPhase) error {
 	addonName := types.NamespacedName{Name: addon.Name, Namespace: addon.Namespace}.String()
 	wg, ok := r.statusWGMap[addonName]
 	if !ok {
@@ -419,7 +1192,22 @@ func (r *AddonReconciler) updateAddonStatus(ctx context.Context, log logr.Logger
 	wg.Add(1)
 	defer wg.Done()
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		return r.Status().Update(ctx, addon, &client.UpdateOptions{})
+		err := r.Status().Update(ctx, addon, &client.UpdateOptions{})
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+
+		// The addon was modified during the reconcile, re-apply the computed status on the latest version so that
+		// a terminal phase is not lost with the requeue.
+		var latest = &addonmgrv1alpha1.Addon{}
+		if getErr := r.apiReader.Get(ctx, types.NamespacedName{Name: addon.Name, Namespace: addon.Namespace}, latest); getErr != nil {
+			return getErr
+		}
+		status := addon.Status
+		latest.DeepCopyInto(addon)
+		addon.Status = status
+
+		return err
 	})
 	if err != nil {
 		log.Error(err, "Addon status could not be updated.")
@@ -427,124 +1215,438 @@ func (r *AddonReconciler) updateAddonStatus(ctx context.Context, log logr.Logger
 		return err
 	}
 
+	metrics.StatusWrites.Inc()
+	r.statusWritten(addon)
+
+	r.Notifications.Send(notify.Transition{
+		Name:      addon.Name,
+		Namespace: addon.Namespace,
+		From:      prevPhase,
+		To:        addon.Status.Lifecycle.Installed,
+		Reason:    addon.Status.Reason,
+	})
+	r.StatusStream.Send(addon)
+
 	return nil
 }
 
 func (r *AddonReconciler) addAddonToCache(log logr.Logger, instance *addonmgrv1alpha1.Addon) {
+	// Conflicting addons would replace the addon installing their package version in the cache
+	if instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Conflict {
+		return
+	}
+
 	var version = addon.Version{
 		Name:        instance.GetName(),
 		Namespace:   instance.GetNamespace(),
-		PackageSpec: instance.GetPackageSpec(),
+		UID:         instance.GetUID(),
+		PackageSpec: cachedPackageSpec(instance),
 		PkgPhase:    instance.GetInstallStatus(),
+		Selector:    addon.SelectorLabels(instance),
+		Wave:        instance.Spec.Wave,
+	}
+	if instance.Spec.Rollout.Name != "" {
+		version.Rollout = instance.Spec.Rollout.Name
+		version.RolloutStage = addon.RolloutStage(instance)
 	}
 	r.versionCache.AddVersion(version)
 	log.Info("Adding version cache", "phase", version.PkgPhase)
 }
 
+// cachedVersion returns the cached version of the addon, or nil when it is not cached
+func (r *AddonReconciler) cachedVersion(name types.NamespacedName) *addon.Version {
+	for _, v := range r.versionCache.GetVersionsWithName(name.Name) {
+		if v.Namespace == name.Namespace {
+			return &v
+		}
+	}
+	return nil
+}
+
+// removeFromCache evicts the cached version of a deleted addon. The live object is consulted so that a stale request
+// for an addon that was deleted and quickly recreated does not evict the version cached for the new instance.
+func (r *AddonReconciler) removeFromCache(ctx context.Context, log logr.Logger, name types.NamespacedName) {
+	// Forget per addon controller state
+	r.rateLimiter.Forget(name)
+	r.eventThrottle.Forget(name)
+	r.timingEventsMu.Lock()
+	delete(r.timingEvents, name.String())
+	r.timingEventsMu.Unlock()
+	r.gitPolls.Delete(name)
+	r.deletedWorkflows.Delete(name)
+	r.lastObserved.Delete(name)
+	r.inventories.Delete(name)
+	r.generations.Delete(name)
+	r.statusWrites.Delete(name)
+	r.priorities.Delete(name)
+	r.StatusStream.Forget(name)
+
+	v := r.cachedVersion(name)
+	if v == nil {
+		return
+	}
+
+	var live = &addonmgrv1alpha1.Addon{}
+	if err := r.apiReader.Get(ctx, name, live); err == nil && live.GetUID() == v.UID {
+		log.Info("Addon was recreated, keeping version cache", "uid", v.UID)
+		return
+	}
+
+	if r.versionCache.RemoveVersionWithUID(v.PkgName, v.PkgVersion, v.UID) {
+		log.Info("Removed version cache", "uid", v.UID)
+	}
+}
+
 func (r *AddonReconciler) executePrereqAndInstall(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) error {
 	// Always reset reason when executing
 	instance.Status.Reason = ""
-	prereqsPhase, err := r.runWorkflow(addonmgrv1alpha1.Prereqs, instance, wfl)
+	instance.Status.FailureCategory = ""
+
+	// The failed install is not run again while it is rolled back
+	if instance.Status.RollbackAttempts > 0 {
+		r.rollback(ctx, log, instance)
+		return nil
+	}
+
+	// Active workflows deleted externally are submitted again
+	r.recoverDeletedWorkflow(log, instance)
+
+	if !r.watchesNamespace(instance.GetWorkflowNamespace()) {
+		reason := fmt.Sprintf("Addon %s/%s workflow namespace %s is not watched by addon-manager.", instance.Namespace, instance.Name, instance.GetWorkflowNamespace())
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
+		instance.Status.Reason = reason
+
+		return fmt.Errorf(reason)
+	}
+
+	if err := r.validateServiceAccount(ctx, instance); common.IsRetryable(err) {
+		log.Info("Addon could not validate service account, retrying.", "error", err.Error())
+		return &transientError{err: err}
+	} else if err != nil {
+		reason := fmt.Sprintf("Addon %s/%s could not validate service account. %v", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		log.Error(err, "Addon could not validate service account.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
+		instance.Status.Reason = reason
+
+		return err
+	}
+
+	if err := r.validateImagePullSecrets(ctx, instance); common.IsRetryable(err) {
+		log.Info("Addon could not validate image pull secrets, retrying.", "error", err.Error())
+		return &transientError{err: err}
+	} else if err != nil {
+		reason := fmt.Sprintf("Addon %s/%s could not validate image pull secrets. %v", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		log.Error(err, "Addon could not validate image pull secrets.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureSecret
+		instance.Status.Reason = reason
+
+		return err
+	}
+
+	if err := r.grantPermissions(ctx, log, instance); err != nil {
+		return err
+	}
+
+	if instance.RunsCombinedWorkflow() {
+		return r.executeCombined(ctx, log, instance, wfl)
+	}
+
+	prereqsPhase, err := r.runWorkflow(addonmgrv1alpha1.Prereqs, instance, wfl, nil)
+	if oci.IsPullError(err) {
+		r.templatePullFailed(log, instance, addonmgrv1alpha1.Prereqs, err)
+		return err
+	}
 	if err != nil {
 		reason := fmt.Sprintf("Addon %s/%s prereqs failed. %v", instance.Namespace, instance.Name, err)
 		r.recorder.Event(instance, "Warning", "Failed", reason)
-		log.Error(err, "Addon prereqs workflow failed.")
+		workflowLogger(log, instance, addonmgrv1alpha1.Prereqs).Error(err, "Addon prereqs workflow failed.")
 		// if prereqs failed, set install status to failed as well so that STATUS is updated
 		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
 		instance.Status.Reason = reason
 
 		return err
 	}
 	instance.Status.Lifecycle.Prereqs = prereqsPhase
+	if prereqsPhase == addonmgrv1alpha1.Pending && stepTimedOut(instance, addonmgrv1alpha1.Prereqs) {
+		return r.failTimedOutStep(log, instance, addonmgrv1alpha1.Prereqs)
+	}
 
 	//handle Prereqs failure
 	if instance.Status.Lifecycle.Prereqs == addonmgrv1alpha1.Failed {
-		reason := fmt.Sprintf("Addon %s/%s Prereqs status is Failed", instance.Namespace, instance.Name)
-		log.Error(err, "Addon prereqs workflow failed.")
+		reason := r.workflowFailureReason(ctx, log, instance, wfl, addonmgrv1alpha1.Prereqs)
+		workflowLogger(log, instance, addonmgrv1alpha1.Prereqs).Error(err, "Addon prereqs workflow failed.")
 		r.recorder.Event(instance, "Warning", "Failed", reason)
 		// if prereqs failed, set install status to failed as well so that STATUS is updated
 		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
 		instance.Status.Reason = reason
 
 		return fmt.Errorf(reason)
 	}
 
 	if instance.Status.Lifecycle.Prereqs == addonmgrv1alpha1.Succeeded {
-		if err := r.validateSecrets(ctx, instance); err != nil {
+		if err := r.waitForNamespace(ctx, log, instance); err != nil {
+			return err
+		}
+
+		if err := r.validateSecrets(ctx, instance); common.IsRetryable(err) {
+			log.Info("Addon could not validate secrets, retrying.", "error", err.Error())
+			return &transientError{err: err}
+		} else if err != nil {
 			reason := fmt.Sprintf("Addon %s/%s could not validate secrets. %v", instance.Namespace, instance.Name, err)
 			r.recorder.Event(instance, "Warning", "Failed", reason)
 			log.Error(err, "Addon could not validate secrets.")
 			instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+			instance.Status.FailureCategory = addonmgrv1alpha1.FailureSecret
 			instance.Status.Reason = reason
 
 			return err
 		}
 
-		phase, err := r.runWorkflow(addonmgrv1alpha1.Install, instance, wfl)
-		instance.Status.Lifecycle.Installed = phase
+		// Permissions could not be granted before the namespace existed
+		if err := r.grantPermissions(ctx, log, instance); err != nil {
+			return err
+		}
+
+		outputs, err := r.prereqsOutputs(ctx, instance, wfl)
 		if err != nil {
-			reason := fmt.Sprintf("Addon %s/%s could not be installed due to error. %v", instance.Namespace, instance.Name, err)
+			reason := fmt.Sprintf("Addon %s/%s prereqs outputs could not be passed to install. %v", instance.Namespace, instance.Name, err)
 			r.recorder.Event(instance, "Warning", "Failed", reason)
-			log.Error(err, "Addon install workflow failed.")
+			log.Error(err, "Addon prereqs outputs could not be passed to install.")
+			instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+			instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
 			instance.Status.Reason = reason
 
 			return err
 		}
+
+		if err := r.waitForRequiredCRDs(ctx, log, instance); err != nil {
+			return err
+		}
+
+		phase, err := r.install(ctx, instance, wfl, outputs)
+		if err == nil && phase == addonmgrv1alpha1.Pending && stepTimedOut(instance, addonmgrv1alpha1.Install) {
+			return r.failTimedOutStep(log, instance, addonmgrv1alpha1.Install)
+		}
+		return r.installCompleted(ctx, log, instance, wfl, addonmgrv1alpha1.Install, phase, outputs, err)
 	}
 
 	return nil
 }
 
-func (r *AddonReconciler) observeResources(ctx context.Context, a *addonmgrv1alpha1.Addon) ([]addonmgrv1alpha1.ObjectStatus, error) {
-	var observed []addonmgrv1alpha1.ObjectStatus
-	var labelSelector = a.Spec.Selector
-
-	if len(labelSelector.MatchLabels) == 0 {
-		labelSelector.MatchLabels = make(map[string]string)
+// installCompleted records the phase of the install lifecycle step in the addon status, running the post-install
+// workflow of a successful install and rolling back a failed install when the addon allows it
+func (r *AddonReconciler) installCompleted(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle, lifecycleStep addonmgrv1alpha1.LifecycleStep, phase addonmgrv1alpha1.ApplicationAssemblyPhase, outputs map[string]string, err error) error {
+	// Install only completes once the post-install workflow succeeded
+	if err == nil && phase == addonmgrv1alpha1.Succeeded && instance.Spec.Lifecycle.PostInstall.Template != "" {
+		if phase, err = r.postInstall(ctx, log, instance, wfl); phase != addonmgrv1alpha1.Succeeded {
+			instance.Status.Lifecycle.Installed = phase
+			if phase == addonmgrv1alpha1.Failed {
+				instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
+			}
+			if phase == addonmgrv1alpha1.Failed && canRollback(instance) {
+				r.rollback(ctx, log, instance)
+			}
+			return err
+		}
 	}
-	// Always add app.kubernetes.io/managed-by and app.kubernetes.io/name to label selector
-	labelSelector.MatchLabels["app.kubernetes.io/managed-by"] = common.AddonGVR().Group
-	labelSelector.MatchLabels["app.kubernetes.io/name"] = fmt.Sprintf("%s", a.GetName())
 
-	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	instance.Status.Lifecycle.Installed = phase
+	if phase == addonmgrv1alpha1.Failed {
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
+	}
+	if phase == addonmgrv1alpha1.Succeeded && instance.Status.CompletionTime == 0 {
+		instance.Status.CompletionTime = common.GetCurretTimestamp()
+		instance.Status.InstallDuration = installDuration(instance.Status.StartTime, instance.Status.CompletionTime)
+	}
+	if phase == addonmgrv1alpha1.Succeeded {
+		instance.Status.AppliedOverrides = addon.OverrideTargets(instance)
+		r.applyReplicas(ctx, log, instance)
+	}
+	if phase == addonmgrv1alpha1.Succeeded && outputs != nil {
+		if err := r.deletePrereqsOutputs(ctx, instance); err != nil {
+			log.Error(err, "Addon prereqs outputs could not be deleted.")
+		}
+	}
+	if phase == addonmgrv1alpha1.Succeeded && instance.Status.LastAppliedChecksum != instance.Status.Checksum {
+		if instance.Spec.Lifecycle.RollbackOnFailure {
+			if err := r.saveLastApplied(ctx, instance); err != nil {
+				r.recorder.Event(instance, "Warning", "Failed", fmt.Sprintf("Addon %s/%s could not save the applied spec, it cannot be rolled back to. %v", instance.Namespace, instance.Name, err))
+				log.Error(err, "Addon could not save the applied spec.")
+			}
+		}
+		instance.Status.LastAppliedChecksum = instance.Status.Checksum
+	}
+	if oci.IsPullError(err) {
+		r.templatePullFailed(log, instance, lifecycleStep, err)
+		return err
+	}
 	if err != nil {
-		return observed, fmt.Errorf("label selector is invalid. %v", err)
+		reason := fmt.Sprintf("Addon %s/%s could not be installed due to error. %v", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		workflowLogger(log, instance, lifecycleStep).Error(err, "Addon install workflow failed.")
+		instance.Status.Reason = reason
+
+		return err
 	}
 
-	for _, resc := range resources {
+	if phase == addonmgrv1alpha1.Failed && len(instance.Spec.Source.Manifests) == 0 {
+		reason := r.workflowFailureReason(ctx, log, instance, wfl, lifecycleStep)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		workflowLogger(log, instance, lifecycleStep).Info("Addon install workflow failed.", "reason", reason)
+		instance.Status.Reason = reason
+	}
 
-		gvk := resc.GetObjectKind().GroupVersionKind()
-		_, kind := gvk.ToAPIVersionAndKind()
+	if phase == addonmgrv1alpha1.Failed && canRollback(instance) {
+		r.rollback(ctx, log, instance)
+	}
 
-		gvr := schema.GroupVersionResource{
-			Group:    gvk.Group,
-			Version:  gvk.Version,
-			Resource: inflection.Plural(strings.ToLower(kind)),
-		}
+	return nil
+}
 
-		inf, err := generatedInformers.ForResource(gvr)
-		if err != nil {
-			return observed, err
+// templatePullFailed keeps the addon Pending so that the workflow is retried once the template can be pulled
+func (r *AddonReconciler) templatePullFailed(log logr.Logger, instance *addonmgrv1alpha1.Addon, lifecycleStep addonmgrv1alpha1.LifecycleStep, err error) {
+	reason := fmt.Sprintf("Addon %s/%s %s template could not be pulled, retrying. %v", instance.Namespace, instance.Name, lifecycleStep, err)
+	r.recorder.Event(instance, "Warning", "TemplatePullFailed", reason)
+	workflowLogger(log, instance, lifecycleStep).Error(err, "Addon workflow template pull failed.")
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+	instance.Status.Reason = reason
+}
+
+func (r *AddonReconciler) observeResources(ctx context.Context, a *addonmgrv1alpha1.Addon) ([]addonmgrv1alpha1.ObjectStatus, error) {
+	var observed []addonmgrv1alpha1.ObjectStatus
+
+	selector, err := addon.ObservationSelector(a)
+	if err != nil {
+		return observed, err
+	}
+
+	if key := addon.ObservationLabelKey(a, common.NameLabel); key != "" && key != common.NameLabel {
+		r.nameLabelKeys.Store(key, struct{}{})
+	}
+
+	for _, ns := range addon.ObservedNamespaces(a) {
+		for _, resc := range r.watched {
+			statuses, err := observeKind(resc, ns, selector)
+			if err != nil {
+				return observed, err
+			}
+			observed = append(observed, statuses...)
 		}
+	}
 
-		objs, err := inf.Lister().ByNamespace(a.Spec.Params.Namespace).List(selector)
+	// Cluster-scoped resources are reported without a namespace
+	for _, resc := range clusterResources {
+		statuses, err := observeKind(resc, "", selector)
 		if err != nil {
 			return observed, err
 		}
+		observed = append(observed, statuses...)
+	}
+
+	return observed, nil
+}
+
+// observeKind lists the resources of a watched kind matching the selector, an empty namespace lists cluster-scoped resources
+func observeKind(resc runtime.Object, namespace string, selector labels.Selector) ([]addonmgrv1alpha1.ObjectStatus, error) {
+	var observed []addonmgrv1alpha1.ObjectStatus
+
+	gvk := resc.GetObjectKind().GroupVersionKind()
+	inf, err := generatedInformers.ForResource(informerResource(resc))
+	if err != nil {
+		return observed, err
+	}
+
+	var objs []runtime.Object
+	if namespace == "" {
+		objs, err = inf.Lister().List(selector)
+	} else {
+		objs, err = inf.Lister().ByNamespace(namespace).List(selector)
+	}
+	if err != nil {
+		return observed, err
+	}
 
-		for _, item := range objs {
-			observed = append(observed, addonmgrv1alpha1.ObjectStatus{
-				Kind:  gvk.Kind,
-				Group: gvk.Group,
-				Name:  item.(metav1.Object).GetName(),
-				Link:  item.(metav1.Object).GetSelfLink(),
-			})
+	for _, item := range objs {
+		status := addonmgrv1alpha1.ObjectStatus{
+			Kind:      gvk.Kind,
+			Group:     gvk.Group,
+			Name:      item.(metav1.Object).GetName(),
+			Namespace: namespace,
+			Link:      item.(metav1.Object).GetSelfLink(),
 		}
+		if owner := metav1.GetControllerOf(item.(metav1.Object)); owner != nil {
+			status.Owner = fmt.Sprintf("%s/%s", owner.Kind, owner.Name)
+		}
+		observeStatus(item, &status)
+		observed = append(observed, status)
 	}
 
 	return observed, nil
 }
 
+// observeStatus sets the status of objects that report one, claims which are not bound surface storage problems and
+// cron jobs which stopped succeeding are Degraded
+func observeStatus(obj runtime.Object, status *addonmgrv1alpha1.ObjectStatus) {
+	switch o := obj.(type) {
+	case *v1.PersistentVolumeClaim:
+		switch o.Status.Phase {
+		case v1.ClaimBound:
+			status.Status = "Ready"
+		case v1.ClaimPending:
+			status.Status = "InProgress"
+		default:
+			status.Status = "Unknown"
+		}
+	case *autoscalingv2beta2.HorizontalPodAutoscaler:
+		status.CurrentReplicas, status.DesiredReplicas = o.Status.CurrentReplicas, o.Status.DesiredReplicas
+		status.Status = replicasStatus(status.CurrentReplicas, status.DesiredReplicas)
+	case *autoscalingv1.HorizontalPodAutoscaler:
+		status.CurrentReplicas, status.DesiredReplicas = o.Status.CurrentReplicas, o.Status.DesiredReplicas
+		status.Status = replicasStatus(status.CurrentReplicas, status.DesiredReplicas)
+	case *batchv1beta1.CronJob:
+		observeCronJob(o, cronJobJobs(o), time.Now(), status)
+	case *appsv1.Deployment:
+		observeDeployment(o, status)
+	case *appsv1.StatefulSet:
+		observeStatefulSet(o, status)
+	case *batchv1.Job:
+		observeJob(o, status)
+	case *policyv1beta1.PodDisruptionBudget:
+		observePodDisruptionBudget(o, status)
+	}
+}
+
+// observePodDisruptionBudget reports the allowed disruptions and healthy pods of the budget, it is Degraded while fewer
+// pods are healthy than it requires
+func observePodDisruptionBudget(pdb *policyv1beta1.PodDisruptionBudget, status *addonmgrv1alpha1.ObjectStatus) {
+	status.DisruptionsAllowed = pdb.Status.DisruptionsAllowed
+	status.CurrentHealthy, status.DesiredHealthy = pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy
+	switch {
+	case pdb.Status.ObservedGeneration < pdb.Generation:
+		status.Status = string(addonmgrv1alpha1.InProgress)
+	case pdb.Status.CurrentHealthy < pdb.Status.DesiredHealthy:
+		status.Status = string(addonmgrv1alpha1.Degraded)
+		status.Reason = fmt.Sprintf("%d of %d required pods are healthy", pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+	default:
+		status.Status = string(addonmgrv1alpha1.Ready)
+	}
+}
+
+func replicasStatus(current, desired int32) string {
+	if current == desired {
+		return "Ready"
+	}
+	return "InProgress"
+}
+
 // Calculates new checksum and validates if there is a diff
 func (r *AddonReconciler) validateChecksum(instance *addonmgrv1alpha1.Addon) (bool, string) {
 	newCheckSum := instance.CalculateChecksum()
@@ -558,15 +1660,44 @@ func (r *AddonReconciler) validateChecksum(instance *addonmgrv1alpha1.Addon) (bo
 
 // Finalize runs finalizer for addon
 func (r *AddonReconciler) Finalize(ctx context.Context, addon *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle, finalizerName string) error {
+	log := addonLogger(r.Log, addon)
+
+	// Protected addons keep their finalizer and nothing is deleted until protection is removed from the spec
+	if addon.Spec.Lifecycle.DeleteProtection {
+		reason := fmt.Sprintf("Addon %s/%s is delete protected, remove spec.lifecycle.deleteProtection to delete it.", addon.Namespace, addon.Name)
+		if addon.Status.Reason != reason {
+			r.recorder.Event(addon, "Warning", "DeleteProtected", reason)
+			log.Info("Addon is delete protected, keeping finalizer.")
+			addon.Status.Lifecycle.Installed = addonmgrv1alpha1.DeleteFailed
+			addon.Status.Reason = reason
+		}
+		return nil
+	}
+
+	// Addons are deleted after the installed addons which depend on them, unless the delete is forced
+	if _, force := addon.GetAnnotations()[common.ForceDeleteAnnotation]; !force && !addon.ValidateOnly() {
+		if dependents := r.installedDependents(addon); len(dependents) > 0 {
+			reason := fmt.Sprintf("Addon %s/%s is waiting on addons %s which depend on it to be deleted, set the %s annotation to delete it anyway.",
+				addon.Namespace, addon.Name, strings.Join(dependents, ", "), common.ForceDeleteAnnotation)
+			if addon.Status.Reason != reason {
+				r.recorder.Event(addon, "Warning", "DeleteBlocked", reason)
+				log.Info("Addon is required by installed addons, keeping finalizer.", "dependents", dependents)
+				addon.Status.Reason = reason
+			}
+			return &dependentsInstalledError{dependents: dependents}
+		}
+	}
+
 	// Has Delete workflow defined, let's run it.
 	var removeFinalizer = true
 
-	if addon.Spec.Lifecycle.Delete.Template != "" {
+	// Validate-only addons have nothing to delete, a finalizer set before is only removed
+	if addon.Spec.Lifecycle.Delete.Template != "" && !addon.ValidateOnly() {
 
 		removeFinalizer = false
 
 		// Run delete workflow
-		phase, err := r.runWorkflow(addonmgrv1alpha1.Delete, addon, wfl)
+		phase, err := r.runWorkflow(addonmgrv1alpha1.Delete, addon, wfl, nil)
 		if err != nil {
 			return err
 		}
@@ -574,29 +1705,211 @@ func (r *AddonReconciler) Finalize(ctx context.Context, addon *addonmgrv1alpha1.
 		if phase == addonmgrv1alpha1.Succeeded || phase == addonmgrv1alpha1.Failed {
 			// Wait for workflow to succeed or fail.
 			removeFinalizer = true
+		} else if r.deleteTimedOut(addon) {
+			if addon.Status.Lifecycle.Installed != addonmgrv1alpha1.DeleteFailed {
+				reason := fmt.Sprintf("Addon %s/%s delete workflow did not complete within %ds.", addon.Namespace, addon.Name, addon.Spec.Lifecycle.Delete.TimeoutSeconds)
+				r.recorder.Event(addon, "Warning", "Failed", reason)
+				workflowLogger(log, addon, addonmgrv1alpha1.Delete).Info("Addon delete workflow timed out.", "forceRemove", addon.Spec.Lifecycle.Delete.ForceRemoveOnTimeout)
+				addon.Status.Lifecycle.Installed = addonmgrv1alpha1.DeleteFailed
+				addon.Status.Reason = reason
+			}
+
+			// Only give up on the delete workflow when requested, otherwise keep waiting.
+			removeFinalizer = addon.Spec.Lifecycle.Delete.ForceRemoveOnTimeout
 		}
 	}
 
 	// Remove version from cache
-	r.versionCache.RemoveVersion(addon.Spec.PkgName, addon.Spec.PkgVersion)
+	r.versionCache.RemoveVersionWithUID(addon.Spec.PkgName, addon.Spec.PkgVersion, addon.GetUID())
+
+	// Remove finalizer from the list and update it, including finalizers set under a previous name.
+	finalizers := r.finalizersOf(addon, finalizerName)
+	if removeFinalizer && len(finalizers) > 0 {
+		if err := r.finalizeResources(ctx, addon); err != nil {
+			return err
+		}
 
-	// Remove finalizer from the list and update it.
-	if removeFinalizer && common.ContainsString(addon.ObjectMeta.Finalizers, finalizerName) {
-		addon.ObjectMeta.Finalizers = common.RemoveString(addon.ObjectMeta.Finalizers, finalizerName)
+		for _, f := range finalizers {
+			addon.ObjectMeta.Finalizers = common.RemoveString(addon.ObjectMeta.Finalizers, f)
+		}
 		if err := r.Update(ctx, addon); err != nil {
 			return err
 		}
+		log.Info("Addon is finalized", "finalizers", finalizers)
+	}
+
+	return nil
+}
+
+// finalizeResources cleans up the resources of the addon which are not deleted with it, validate-only addons have none
+func (r *AddonReconciler) finalizeResources(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	if addon.ValidateOnly() {
+		return nil
+	}
+
+	if err := r.finalizePVCs(ctx, addon); err != nil {
+		return err
+	}
+
+	if err := r.finalizeClusterResources(ctx, addon); err != nil {
+		return err
+	}
+
+	if err := r.deleteInventory(ctx, addon); err != nil {
+		return err
+	}
+
+	if err := r.deletePermissions(ctx, addon); err != nil {
+		return err
+	}
+
+	return r.deleteManifests(ctx, addon)
+}
+
+// finalizePVCs deletes the persistent volume claims labeled for the addon if requested, otherwise reports them as orphaned
+func (r *AddonReconciler) finalizePVCs(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	if addon.Spec.Params.Namespace == "" {
+		return nil
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{
+		common.ManagedByLabel: common.AddonGVR().Group,
+		common.NameLabel:      addon.GetName(),
+	})
+
+	pvcClient := r.generatedClient.CoreV1().PersistentVolumeClaims(addon.Spec.Params.Namespace)
+	pvcs, err := pvcClient.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("failed to list persistent volume claims. %v", err)
+	}
+
+	if len(pvcs.Items) == 0 {
+		return nil
+	}
+
+	var names = make([]string, 0, len(pvcs.Items))
+	for _, pvc := range pvcs.Items {
+		names = append(names, pvc.GetName())
+	}
+
+	if !addon.Spec.Lifecycle.Delete.DeletePVCs {
+		r.recorder.Event(addon, "Warning", "Orphaned", fmt.Sprintf("Addon %s/%s left persistent volume claims %s in namespace %s.", addon.Namespace, addon.Name, strings.Join(names, ", "), addon.Spec.Params.Namespace))
+		return nil
+	}
+
+	for _, name := range names {
+		if err := pvcClient.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete persistent volume claim %s. %v", name, err)
+		}
+	}
+	r.recorder.Event(addon, "Normal", "Completed", fmt.Sprintf("Addon %s/%s deleted persistent volume claims %s in namespace %s.", addon.Namespace, addon.Name, strings.Join(names, ", "), addon.Spec.Params.Namespace))
+
+	return nil
+}
+
+// finalizeClusterResources deletes the cluster-scoped resources labeled for the addon. They are reported as orphaned
+// instead while another addon with the same name exists, since the labels cannot tell both addons apart.
+func (r *AddonReconciler) finalizeClusterResources(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	selector := labels.SelectorFromSet(labels.Set{
+		common.ManagedByLabel: common.AddonGVR().Group,
+		common.NameLabel:      addon.GetName(),
+	})
+	listOpts := metav1.ListOptions{LabelSelector: selector.String()}
+
+	bindingClient := r.generatedClient.RbacV1().ClusterRoleBindings()
+	bindings, err := bindingClient.List(ctx, listOpts)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster role bindings. %v", err)
+	}
+
+	roleClient := r.generatedClient.RbacV1().ClusterRoles()
+	roles, err := roleClient.List(ctx, listOpts)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster roles. %v", err)
+	}
+
+	var names = make([]string, 0, len(bindings.Items)+len(roles.Items))
+	for _, b := range bindings.Items {
+		names = append(names, fmt.Sprintf("ClusterRoleBinding/%s", b.GetName()))
+	}
+	for _, cr := range roles.Items {
+		names = append(names, fmt.Sprintf("ClusterRole/%s", cr.GetName()))
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	if len(r.versionCache.GetVersionsWithName(addon.GetName())) > 0 {
+		r.recorder.Event(addon, "Warning", "Orphaned", fmt.Sprintf("Addon %s/%s left cluster resources %s, another addon named %s exists.", addon.Namespace, addon.Name, strings.Join(names, ", "), addon.Name))
+		return nil
+	}
+
+	// Delete bindings first so that no binding is left referring to a deleted role
+	for _, b := range bindings.Items {
+		if err := bindingClient.Delete(ctx, b.GetName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete cluster role binding %s. %v", b.GetName(), err)
+		}
+	}
+	for _, cr := range roles.Items {
+		if err := roleClient.Delete(ctx, cr.GetName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete cluster role %s. %v", cr.GetName(), err)
+		}
 	}
+	r.recorder.Event(addon, "Normal", "Completed", fmt.Sprintf("Addon %s/%s deleted cluster resources %s.", addon.Namespace, addon.Name, strings.Join(names, ", ")))
 
 	return nil
 }
 
-// SetFinalizer adds finalizer to addon instances
+// trackActiveWorkflow records the workflow in the addon status while it runs, and clears it once it has completed
+func trackActiveWorkflow(addon *addonmgrv1alpha1.Addon, lifecycleStep addonmgrv1alpha1.LifecycleStep, name string, phase addonmgrv1alpha1.ApplicationAssemblyPhase, err error) {
+	if err == nil && phase == addonmgrv1alpha1.Pending {
+		addon.Status.ActiveWorkflow = &addonmgrv1alpha1.ActiveWorkflow{
+			Name:          name,
+			LifecycleStep: lifecycleStep,
+			Phase:         phase,
+		}
+		return
+	}
+
+	if addon.Status.ActiveWorkflow != nil && addon.Status.ActiveWorkflow.Name == name {
+		addon.Status.ActiveWorkflow = nil
+	}
+}
+
+// installDuration formats the time between the millisecond timestamps rounded to seconds, empty without a start
+func installDuration(start, end int64) string {
+	if start == 0 || end < start {
+		return ""
+	}
+	return (time.Duration(end-start) * time.Millisecond).Round(time.Second).String()
+}
+
+// deleteTimedOut returns true if the delete workflow has been running longer than the configured timeout
+func (r *AddonReconciler) deleteTimedOut(addon *addonmgrv1alpha1.Addon) bool {
+	timeout := time.Duration(addon.Spec.Lifecycle.Delete.TimeoutSeconds) * time.Second
+	if timeout <= 0 || addon.ObjectMeta.DeletionTimestamp.IsZero() {
+		return false
+	}
+
+	// Retried delete workflows get the whole timeout again
+	start := addon.ObjectMeta.DeletionTimestamp.Time
+	if retried := time.Unix(0, addon.Status.DeleteRetryTime*int64(time.Millisecond)); retried.After(start) {
+		start = retried
+	}
+	return time.Since(start) > timeout
+}
+
+// SetFinalizer adds finalizer to addon instances, replacing finalizers set under a previous name
 func (r *AddonReconciler) SetFinalizer(ctx context.Context, addon *addonmgrv1alpha1.Addon, finalizerName string) error {
 	// Resource is not being deleted
 	if addon.ObjectMeta.DeletionTimestamp.IsZero() {
-		// And does not contain finalizer
-		if !common.ContainsString(addon.ObjectMeta.Finalizers, finalizerName) {
+		finalizers := r.finalizersOf(addon, finalizerName)
+		// And does not contain finalizer, or still contains a previous one
+		if len(finalizers) != 1 || finalizers[0] != finalizerName {
+			for _, f := range finalizers {
+				addon.ObjectMeta.Finalizers = common.RemoveString(addon.ObjectMeta.Finalizers, f)
+			}
 			// Set Finalizer
 			addon.ObjectMeta.Finalizers = append(addon.ObjectMeta.Finalizers, finalizerName)
 			if err := r.Update(ctx, addon); err != nil {
@@ -607,3 +1920,14 @@ func (r *AddonReconciler) SetFinalizer(ctx context.Context, addon *addonmgrv1alp
 
 	return nil
 }
+
+// finalizersOf returns the finalizers of the addon owned by the controller, the given name and previous names
+func (r *AddonReconciler) finalizersOf(addon *addonmgrv1alpha1.Addon, finalizerName string) []string {
+	var owned []string
+	for _, f := range addon.ObjectMeta.Finalizers {
+		if f == finalizerName || common.ContainsString(r.PreviousFinalizerNames, f) {
+			owned = append(owned, f)
+		}
+	}
+	return owned
+}
@@ -137,6 +137,123 @@ var _ = Describe("AddonController", func() {
 			Expect(wfv1.GetName()).Should(Equal(wfName))
 		})
 
+		It("instance with suspended workflows should not create workflows", func() {
+			addonYaml, err := ioutil.ReadFile("../docs/examples/clusterautoscaler.yaml")
+			Expect(err).ToNot(HaveOccurred())
+
+			suspended, err := parseAddonYaml(addonYaml)
+			Expect(err).ToNot(HaveOccurred())
+			suspended.SetName("suspended-addon")
+			suspended.SetNamespace(addonNamespace)
+			suspended.Spec.PkgName = "test/suspended-addon"
+			suspended.Spec.SuspendWorkflows = true
+			var suspendedKey = types.NamespacedName{Namespace: addonNamespace, Name: "suspended-addon"}
+
+			Expect(k8sClient.Create(context.TODO(), suspended)).NotTo(HaveOccurred())
+			defer k8sClient.Delete(context.TODO(), suspended)
+
+			By("Verify addon status is still updated")
+			Eventually(func() error {
+				if err := k8sClient.Get(context.TODO(), suspendedKey, suspended); err != nil {
+					return err
+				}
+
+				if suspended.Status.Checksum != "" && suspended.Status.Lifecycle.Installed == v1alpha1.Pending {
+					return nil
+				}
+				return fmt.Errorf("addon status is not updated")
+			}, timeout).Should(Succeed())
+			Expect(suspended.Status.Lifecycle.Prereqs).To(BeEmpty())
+
+			By("Verify prereqs workflow is not created")
+			wfName := suspended.GetFormattedWorkflowName(v1alpha1.Prereqs)
+			var wfv1Key = types.NamespacedName{Name: wfName, Namespace: addonNamespace}
+			Consistently(func() error {
+				return k8sClient.Get(context.TODO(), wfv1Key, wfv1)
+			}, time.Second*2).ShouldNot(Succeed())
+		})
+
+		It("instance in validate mode should pass validation without workflows or finalizer", func() {
+			addonYaml, err := ioutil.ReadFile("../docs/examples/clusterautoscaler.yaml")
+			Expect(err).ToNot(HaveOccurred())
+
+			validated, err := parseAddonYaml(addonYaml)
+			Expect(err).ToNot(HaveOccurred())
+			validated.SetName("validated-addon")
+			validated.SetNamespace(addonNamespace)
+			validated.Spec.PkgName = "test/validated-addon"
+			validated.Spec.Mode = v1alpha1.ValidateMode
+			var validatedKey = types.NamespacedName{Namespace: addonNamespace, Name: "validated-addon"}
+
+			Expect(k8sClient.Create(context.TODO(), validated)).NotTo(HaveOccurred())
+			defer k8sClient.Delete(context.TODO(), validated)
+
+			By("Verify addon passes validation")
+			Eventually(func() error {
+				if err := k8sClient.Get(context.TODO(), validatedKey, validated); err != nil {
+					return err
+				}
+
+				if validated.Status.Lifecycle.Installed == v1alpha1.ValidationPassed {
+					return nil
+				}
+				return fmt.Errorf("addon is not validated")
+			}, timeout).Should(Succeed())
+			Expect(validated.ObjectMeta.Finalizers).To(BeEmpty())
+			Expect(validated.Status.Lifecycle.Prereqs).To(BeEmpty())
+
+			By("Verify prereqs workflow is not created")
+			wfName := validated.GetFormattedWorkflowName(v1alpha1.Prereqs)
+			var wfv1Key = types.NamespacedName{Name: wfName, Namespace: addonNamespace}
+			Consistently(func() error {
+				return k8sClient.Get(context.TODO(), wfv1Key, wfv1)
+			}, time.Second*2).ShouldNot(Succeed())
+		})
+
+		It("instance with a changed reconcile token should refresh status without running workflows", func() {
+			addonYaml, err := ioutil.ReadFile("../docs/examples/clusterautoscaler.yaml")
+			Expect(err).ToNot(HaveOccurred())
+
+			refreshed, err := parseAddonYaml(addonYaml)
+			Expect(err).ToNot(HaveOccurred())
+			refreshed.SetName("refreshed-addon")
+			refreshed.SetNamespace(addonNamespace)
+			refreshed.Spec.PkgName = "test/refreshed-addon"
+			var refreshedKey = types.NamespacedName{Namespace: addonNamespace, Name: "refreshed-addon"}
+
+			Expect(k8sClient.Create(context.TODO(), refreshed)).NotTo(HaveOccurred())
+			defer k8sClient.Delete(context.TODO(), refreshed)
+
+			wfName := refreshed.GetFormattedWorkflowName(v1alpha1.Prereqs)
+			var wfv1Key = types.NamespacedName{Name: wfName, Namespace: addonNamespace}
+			Eventually(func() error {
+				return k8sClient.Get(context.TODO(), wfv1Key, wfv1)
+			}, timeout).Should(Succeed())
+			Expect(k8sClient.Get(context.TODO(), refreshedKey, refreshed)).To(Succeed())
+			checksum, startTime, wfUID := refreshed.Status.Checksum, refreshed.Status.StartTime, wfv1.GetUID()
+
+			By("Verify bumping the reconcile token refreshes status")
+			refreshed.SetAnnotations(map[string]string{"addonmgr.keikoproj.io/reconcile-token": "build-1"})
+			Expect(k8sClient.Update(context.TODO(), refreshed)).To(Succeed())
+			Eventually(func() error {
+				if err := k8sClient.Get(context.TODO(), refreshedKey, refreshed); err != nil {
+					return err
+				}
+
+				if refreshed.Status.ObservedReconcileToken == "build-1" {
+					return nil
+				}
+				return fmt.Errorf("addon status is not refreshed")
+			}, timeout).Should(Succeed())
+
+			By("Verify the addon is not reinstalled")
+			Expect(refreshed.Status.Checksum).To(Equal(checksum))
+			Expect(refreshed.Status.StartTime).To(Equal(startTime))
+			Expect(k8sClient.Get(context.TODO(), wfv1Key, wfv1)).To(Succeed())
+			Expect(wfv1.GetUID()).To(Equal(wfUID))
+			Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: refreshed.GetFormattedWorkflowName(v1alpha1.Install), Namespace: addonNamespace}, wfv1)).NotTo(Succeed())
+		})
+
 		It("instance with dependencies should succeed", func() {
 			instance = &v1alpha1.Addon{
 				ObjectMeta: metav1.ObjectMeta{Name: "addon-1", Namespace: addonNamespace},
@@ -0,0 +1,117 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// requiredCRDsTimeout is how long after the install started the required CRDs of an addon must be established
+const requiredCRDsTimeout = 10 * time.Minute
+
+// crdsPendingError is returned while required CRDs are not established, the addon is kept Pending
+type crdsPendingError struct {
+	names []string
+}
+
+func (e *crdsPendingError) Error() string {
+	return fmt.Sprintf("required CRDs %s are not established", strings.Join(e.names, ", "))
+}
+
+func isCRDsPending(err error) bool {
+	var pendingErr *crdsPendingError
+	return errors.As(err, &pendingErr)
+}
+
+// waitForRequiredCRDs keeps the addon Pending until its required CRDs are established, and fails it when they are
+// not established within requiredCRDsTimeout
+func (r *AddonReconciler) waitForRequiredCRDs(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon) error {
+	missing, err := r.unestablishedCRDs(ctx, instance.Spec.RequiredCRDs)
+	if err != nil {
+		reason := fmt.Sprintf("Addon %s/%s could not check required CRDs. %v", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		log.Error(err, "Addon could not check required CRDs.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureDependency
+		instance.Status.Reason = reason
+
+		return err
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if common.IsExpired(instance.Status.StartTime, requiredCRDsTimeout.Milliseconds()) {
+		reason := fmt.Sprintf("Addon %s/%s required CRDs %s were not established within %s.", instance.Namespace, instance.Name, strings.Join(missing, ", "), requiredCRDsTimeout)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureTimeout
+		instance.Status.Reason = reason
+
+		return fmt.Errorf(reason)
+	}
+
+	reason := fmt.Sprintf("Addon %s/%s is waiting on required CRDs %s to be established.", instance.Namespace, instance.Name, strings.Join(missing, ", "))
+	r.recorder.Event(instance, "Normal", "Pending", reason)
+	log.Info("Addon is waiting on required CRDs.", "crds", missing)
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+	instance.Status.Reason = reason
+
+	return &crdsPendingError{names: missing}
+}
+
+// unestablishedCRDs returns the named CRDs which do not exist or are not established yet
+func (r *AddonReconciler) unestablishedCRDs(ctx context.Context, names []string) ([]string, error) {
+	var missing []string
+	for _, name := range names {
+		crd, err := r.dynClient.Resource(common.CustomResourceDefinitionGVR()).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !crdEstablished(crd) {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// crdEstablished returns true if the CRD has the Established condition, its resources are served once it has
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
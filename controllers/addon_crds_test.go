@@ -0,0 +1,81 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+func newCRD(name string, established string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName(name)
+	if established != "" {
+		_ = unstructured.SetNestedSlice(crd.Object, []interface{}{
+			map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+			map[string]interface{}{"type": "Established", "status": established},
+		}, "status", "conditions")
+	}
+	return crd
+}
+
+func TestWaitForRequiredCRDs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	dynClient := dynfake.NewSimpleDynamicClient(sch,
+		newCRD("certificates.cert-manager.io", "True"),
+		newCRD("issuers.cert-manager.io", "False"),
+	)
+	r := &AddonReconciler{
+		dynClient: dynClient,
+		recorder:  record.NewFakeRecorder(10),
+	}
+	log := zap.New(zap.UseDevMode(true))
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "cert-manager-webhook", "system"
+	instance.Spec.RequiredCRDs = []string{"certificates.cert-manager.io", "issuers.cert-manager.io", "orders.acme.cert-manager.io"}
+	instance.Status.StartTime = common.GetCurretTimestamp()
+
+	err := r.waitForRequiredCRDs(context.TODO(), log, instance)
+	g.Expect(isCRDsPending(err)).To(BeTrue())
+	g.Expect(err).To(MatchError("required CRDs issuers.cert-manager.io, orders.acme.cert-manager.io are not established"))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("is waiting on required CRDs"))
+
+	// Waiting fails once the timeout passed
+	instance.Status.StartTime -= requiredCRDsTimeout.Milliseconds()
+	err = r.waitForRequiredCRDs(context.TODO(), log, instance)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(isCRDsPending(err)).To(BeFalse())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.FailureCategory).To(Equal(addonmgrv1alpha1.FailureTimeout))
+
+	instance.Spec.RequiredCRDs = []string{"certificates.cert-manager.io"}
+	g.Expect(r.waitForRequiredCRDs(context.TODO(), log, instance)).To(Succeed())
+}
@@ -0,0 +1,87 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// observeCronJob sets the last schedule, last success and active jobs of the cron job, the last success is taken from
+// the jobs it owns. A cron job is Degraded when none of its jobs succeeded for twice the interval between its last two
+// schedules, so that a run in progress may still complete. Without two jobs in its history the interval is unknown and
+// the cron job is not checked.
+func observeCronJob(cj *batchv1beta1.CronJob, jobs []*batchv1.Job, now time.Time, status *addonmgrv1alpha1.ObjectStatus) {
+	status.LastScheduleTime = cj.Status.LastScheduleTime.DeepCopy()
+	status.ActiveJobs = int32(len(cj.Status.Active))
+	status.Status = string(addonmgrv1alpha1.Ready)
+
+	var owned []*batchv1.Job
+	for _, job := range jobs {
+		if metav1.IsControlledBy(job, cj) {
+			owned = append(owned, job)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[i].CreationTimestamp.Before(&owned[j].CreationTimestamp)
+	})
+
+	for _, job := range owned {
+		if job.Status.Succeeded == 0 || job.Status.CompletionTime == nil {
+			continue
+		}
+		if status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(job.Status.CompletionTime) {
+			status.LastSuccessfulTime = job.Status.CompletionTime.DeepCopy()
+		}
+	}
+
+	if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
+		return
+	}
+	if status.LastSuccessfulTime == nil && status.ActiveJobs > 0 {
+		status.Status = string(addonmgrv1alpha1.InProgress)
+	}
+	if len(owned) < 2 {
+		return
+	}
+
+	interval := owned[len(owned)-1].CreationTimestamp.Sub(owned[len(owned)-2].CreationTimestamp.Time)
+	since := owned[0].CreationTimestamp.Time
+	if status.LastSuccessfulTime != nil {
+		since = status.LastSuccessfulTime.Time
+	}
+	if interval > 0 && now.Sub(since) > 2*interval {
+		status.Status = string(addonmgrv1alpha1.Degraded)
+	}
+}
+
+// cronJobJobs returns the jobs in the namespace of the cron job from the shared informer cache
+func cronJobJobs(cj *batchv1beta1.CronJob) []*batchv1.Job {
+	if generatedInformers == nil {
+		return nil
+	}
+	jobs, err := generatedInformers.Batch().V1().Jobs().Lister().Jobs(cj.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil
+	}
+	return jobs
+}
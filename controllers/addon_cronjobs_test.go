@@ -0,0 +1,116 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestObserveCronJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2021, 6, 5, 12, 0, 0, 0, time.UTC)
+	cj := &batchv1beta1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "curator", Namespace: "logging", UID: types.UID("cj")}}
+	cj.Status.LastScheduleTime = &metav1.Time{Time: now.Add(-10 * time.Minute)}
+	cj.Status.Active = []v1.ObjectReference{{Name: "curator-3"}}
+
+	job := func(name string, created time.Time, completed *time.Time) *batchv1.Job {
+		j := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "logging", CreationTimestamp: metav1.Time{Time: created}}}
+		j.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(cj, batchv1beta1.SchemeGroupVersion.WithKind("CronJob"))})
+		if completed != nil {
+			j.Status.Succeeded = 1
+			j.Status.CompletionTime = &metav1.Time{Time: *completed}
+		}
+		return j
+	}
+	succeeded := now.Add(-65 * time.Minute)
+	other := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "logging"}}
+	other.Status.Succeeded, other.Status.CompletionTime = 1, &metav1.Time{Time: now}
+
+	// Scheduled every hour, the latest job is still running
+	var status addonmgrv1alpha1.ObjectStatus
+	observeCronJob(cj, []*batchv1.Job{job("curator-1", now.Add(-70*time.Minute), &succeeded), job("curator-3", now.Add(-10*time.Minute), nil), other}, now, &status)
+	g.Expect(status.Status).To(Equal("Ready"))
+	g.Expect(status.ActiveJobs).To(Equal(int32(1)))
+	g.Expect(status.LastScheduleTime.Time).To(Equal(now.Add(-10 * time.Minute)))
+	g.Expect(status.LastSuccessfulTime.Time).To(Equal(succeeded))
+
+	// No job succeeded for more than two intervals
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeCronJob(cj, []*batchv1.Job{job("curator-1", now.Add(-130*time.Minute), &succeeded), job("curator-2", now.Add(-70*time.Minute), nil), job("curator-3", now.Add(-10*time.Minute), nil)}, now.Add(time.Hour), &status)
+	g.Expect(status.Status).To(Equal("Degraded"))
+
+	// Suspended cron jobs are not expected to run
+	suspend := true
+	cj.Spec.Suspend = &suspend
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeCronJob(cj, []*batchv1.Job{job("curator-1", now.Add(-130*time.Minute), &succeeded), job("curator-2", now.Add(-70*time.Minute), nil), job("curator-3", now.Add(-10*time.Minute), nil)}, now.Add(time.Hour), &status)
+	g.Expect(status.Status).To(Equal("Ready"))
+
+	// A first run in progress without history
+	cj.Spec.Suspend = nil
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeCronJob(cj, []*batchv1.Job{job("curator-3", now.Add(-10*time.Minute), nil)}, now, &status)
+	g.Expect(status.Status).To(Equal("InProgress"))
+	g.Expect(status.LastSuccessfulTime).To(BeNil())
+}
+
+func TestCheckDrift_DegradedCronJobs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &AddonReconciler{recorder: recorder}
+	log := zap.New(zap.UseDevMode(true))
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "curator", "addon-manager-system"
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Succeeded
+
+	degraded := degradedResources([]addonmgrv1alpha1.ObjectStatus{
+		{Kind: "CronJob", Group: "batch", Name: "curator", Namespace: "logging", Status: "Degraded"},
+		{Kind: "ConfigMap", Name: "curator", Namespace: "logging", Status: "Ready"},
+	})
+	g.Expect(degraded).To(HaveLen(1))
+	g.Expect(degradedNames(degraded)).To(Equal([]string{"logging/CronJob.batch/curator"}))
+
+	reinstall, err := r.checkDrift(context.TODO(), log, instance, nil, degraded)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reinstall).To(BeFalse())
+	c := meta.FindStatusCondition(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition)
+	g.Expect(c.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(c.Reason).To(Equal("CronJobsFailing"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("logging/CronJob.batch/curator have not succeeded"))
+
+	// The event is only recorded when the degraded cron jobs change
+	_, _ = r.checkDrift(context.TODO(), log, instance, nil, degraded)
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	_, _ = r.checkDrift(context.TODO(), log, instance, nil, nil)
+	g.Expect(meta.IsStatusConditionFalse(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition)).To(BeTrue())
+}
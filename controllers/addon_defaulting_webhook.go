@@ -0,0 +1,111 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// DefaultingWebhookPath is the path the defaulting webhook of addons is served on. The webhook is opt-in, its
+// MutatingWebhookConfiguration is kept in config/webhook-defaulting instead of being generated into config/webhook.
+const DefaultingWebhookPath = "/mutate-addonmgr-keikoproj-io-v1alpha1-addon"
+
+// AddonDefaulter defaults common spec fields of addons before they are persisted, so that specs are consistent
+// before they are reconciled
+type AddonDefaulter struct {
+	// Namespace is set as spec.params.namespace of addons without one, empty leaves it unset
+	Namespace string
+
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &AddonDefaulter{}
+
+// SetupDefaultingWebhookWithManager registers the defaulting webhook of addons with the manager
+func SetupDefaultingWebhookWithManager(mgr ctrl.Manager, defaulter *AddonDefaulter) {
+	mgr.GetWebhookServer().Register(DefaultingWebhookPath, &webhook.Admission{Handler: defaulter})
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (d *AddonDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler, it patches the addon with its defaulted fields
+func (d *AddonDefaulter) Handle(_ context.Context, req admission.Request) admission.Response {
+	var a = &addonmgrv1alpha1.Addon{}
+	if err := d.decoder.Decode(req, a); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	d.Default(a)
+
+	marshalled, err := json.Marshal(a)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshalled)
+}
+
+// Default sets the default namespace, labels the addon with the managed-by and name labels its resources are
+// observed by and normalizes lifecycle fields. Fields which are set are kept, so defaulting a defaulted addon does
+// not change it.
+func (d *AddonDefaulter) Default(a *addonmgrv1alpha1.Addon) {
+	if a.Spec.Params.Namespace == "" {
+		a.Spec.Params.Namespace = d.Namespace
+	}
+
+	var labels = a.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string, 2)
+	}
+	if _, ok := labels[common.ManagedByLabel]; !ok {
+		labels[common.ManagedByLabel] = common.AddonGVR().Group
+	}
+	if _, ok := labels[common.NameLabel]; !ok && a.GetName() != "" {
+		labels[common.NameLabel] = a.GetName()
+	}
+	a.SetLabels(labels)
+
+	lifecycle := &a.Spec.Lifecycle
+	lifecycle.ServiceAccount = strings.TrimSpace(lifecycle.ServiceAccount)
+	lifecycle.WorkflowInstanceID = strings.TrimSpace(lifecycle.WorkflowInstanceID)
+	for _, wt := range []*addonmgrv1alpha1.WorkflowType{&lifecycle.Prereqs, &lifecycle.Install, &lifecycle.Delete.WorkflowType,
+		&lifecycle.Validate, &lifecycle.PostInstall, &lifecycle.CombinedTemplate.WorkflowType} {
+		wt.NamePrefix = strings.TrimSpace(wt.NamePrefix)
+	}
+
+	// Image pull secrets are referenced by name, empty and repeated names are dropped
+	if len(lifecycle.ImagePullSecrets) > 0 {
+		var secrets = make([]string, 0, len(lifecycle.ImagePullSecrets))
+		for _, s := range lifecycle.ImagePullSecrets {
+			if s = strings.TrimSpace(s); s != "" && !common.ContainsString(secrets, s) {
+				secrets = append(secrets, s)
+			}
+		}
+		lifecycle.ImagePullSecrets = secrets
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestAddonDefaulter_Default(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	d := &AddonDefaulter{Namespace: "addons"}
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Name, a.Namespace = "fluentd", "addon-manager-system"
+	a.Spec.Lifecycle.ServiceAccount = " installer "
+	a.Spec.Lifecycle.Install.NamePrefix = "main "
+	a.Spec.Lifecycle.CombinedTemplate.NamePrefix = " all"
+	a.Spec.Lifecycle.ImagePullSecrets = []string{"registry", " ", "registry ", "mirror"}
+
+	d.Default(a)
+	g.Expect(a.Spec.Params.Namespace).To(Equal("addons"))
+	g.Expect(a.GetLabels()).To(Equal(map[string]string{
+		"app.kubernetes.io/managed-by": "addonmgr.keikoproj.io",
+		"app.kubernetes.io/name":       "fluentd",
+	}))
+	g.Expect(a.Spec.Lifecycle.ServiceAccount).To(Equal("installer"))
+	g.Expect(a.Spec.Lifecycle.Install.NamePrefix).To(Equal("main"))
+	g.Expect(a.Spec.Lifecycle.CombinedTemplate.NamePrefix).To(Equal("all"))
+	g.Expect(a.Spec.Lifecycle.ImagePullSecrets).To(Equal([]string{"registry", "mirror"}))
+
+	// Defaulting a defaulted addon does not change it, set fields are kept
+	defaulted := a.DeepCopy()
+	d.Default(a)
+	g.Expect(a).To(Equal(defaulted))
+
+	b := &addonmgrv1alpha1.Addon{}
+	b.Name = "external-dns"
+	b.Spec.Params.Namespace = "dns"
+	b.SetLabels(map[string]string{"app.kubernetes.io/name": "dns"})
+	(&AddonDefaulter{}).Default(b)
+	g.Expect(b.Spec.Params.Namespace).To(Equal("dns"))
+	g.Expect(b.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/name", "dns"))
+}
+
+func TestAddonDefaulter_Handle(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+	decoder, err := admission.NewDecoder(sch)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	d := &AddonDefaulter{Namespace: "addons"}
+	g.Expect(d.InjectDecoder(decoder)).To(Succeed())
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.APIVersion, a.Kind = "addonmgr.keikoproj.io/v1alpha1", "Addon"
+	a.Name, a.Namespace = "fluentd", "addon-manager-system"
+	raw, err := json.Marshal(a)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	resp := d.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+		Object: runtime.RawExtension{Raw: raw},
+	}})
+	g.Expect(resp.Allowed).To(BeTrue())
+	var paths []string
+	for _, p := range resp.Patches {
+		paths = append(paths, p.Path)
+	}
+	g.Expect(paths).To(ContainElement("/spec/params/namespace"))
+}
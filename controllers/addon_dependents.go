@@ -0,0 +1,118 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+)
+
+// dependencyCompleted enqueues the pending dependents of an addon whose install completed, so that they are validated
+// again right away instead of on their next pending requeue
+func (r *AddonReconciler) dependencyCompleted(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	prev, ok := e.ObjectOld.(*addonmgrv1alpha1.Addon)
+	if !ok {
+		return
+	}
+	cur, ok := e.ObjectNew.(*addonmgrv1alpha1.Addon)
+	if !ok {
+		return
+	}
+	if prev.GetInstallStatus().Completed() || !cur.GetInstallStatus().Completed() {
+		return
+	}
+
+	for _, req := range r.dependentAddonRequests(cur) {
+		q.Add(req)
+	}
+}
+
+// dependentAddonRequests returns the cached addons which are pending on the package of the addon
+func (r *AddonReconciler) dependentAddonRequests(a *addonmgrv1alpha1.Addon) []reconcile.Request {
+	var pkg = cachedPackageSpec(a)
+	var reqs []reconcile.Request
+	for _, versions := range r.versionCache.GetAllVersions() {
+		for _, v := range versions {
+			if v.PkgPhase != addonmgrv1alpha1.Pending || !dependsOn(v, pkg.PkgName, pkg.PkgVersion) {
+				continue
+			}
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v.Namespace, Name: v.Name}})
+		}
+	}
+	return reqs
+}
+
+// dependsOn reports whether the version requires the package version, or any version of the package
+func dependsOn(v addon.Version, pkgName, pkgVersion string) bool {
+	for name, version := range v.PkgDeps {
+		if strings.TrimSpace(name) != pkgName {
+			continue
+		}
+		version = strings.TrimSpace(version)
+		if version == "*" || version == pkgVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// dependentsInstalledError is returned by Finalize while installed addons depend on the deleted addon, its finalizer
+// is kept until they are deleted
+type dependentsInstalledError struct {
+	dependents []string
+}
+
+func (e *dependentsInstalledError) Error() string {
+	return fmt.Sprintf("addons %s depend on the addon", strings.Join(e.dependents, ", "))
+}
+
+func isDependentsInstalled(err error) bool {
+	var dependentsErr *dependentsInstalledError
+	return errors.As(err, &dependentsErr)
+}
+
+// installedDependents returns the namespace/name of the cached addons which depend on the package of the addon and
+// may have installed something, including dependents which are being deleted since their delete workflows may still
+// need the addon
+func (r *AddonReconciler) installedDependents(a *addonmgrv1alpha1.Addon) []string {
+	var pkg = cachedPackageSpec(a)
+	var dependents []string
+	for _, versions := range r.versionCache.GetAllVersions() {
+		for _, v := range versions {
+			if v.UID == a.GetUID() && v.Name == a.GetName() && v.Namespace == a.GetNamespace() {
+				continue
+			}
+			switch v.PkgPhase {
+			case "", addonmgrv1alpha1.ValidationPassed, addonmgrv1alpha1.Skipped, addonmgrv1alpha1.Conflict:
+				continue
+			}
+			if dependsOn(v, pkg.PkgName, pkg.PkgVersion) {
+				dependents = append(dependents, v.Namespace+"/"+v.Name)
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+func TestDependencyCompleted(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := &AddonReconciler{versionCache: addon.NewAddonVersionCacheClient()}
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Name, a.Namespace = "core", "addon-manager-system"
+	a.Spec.PkgName, a.Spec.PkgVersion = "core", "v1.0.0"
+	a.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+
+	// b depends on a, c depends on any version of another package
+	r.versionCache.AddVersion(addon.Version{
+		Name:        "b",
+		Namespace:   "addon-manager-system",
+		PackageSpec: addonmgrv1alpha1.PackageSpec{PkgName: "b", PkgVersion: "v1.0.0", PkgDeps: map[string]string{"core": "v1.0.0"}},
+		PkgPhase:    addonmgrv1alpha1.Pending,
+	})
+	r.versionCache.AddVersion(addon.Version{
+		Name:        "c",
+		Namespace:   "addon-manager-system",
+		PackageSpec: addonmgrv1alpha1.PackageSpec{PkgName: "c", PkgVersion: "v1.0.0", PkgDeps: map[string]string{"other": "*"}},
+		PkgPhase:    addonmgrv1alpha1.Pending,
+	})
+
+	completed := a.DeepCopy()
+	completed.Status.Lifecycle.Installed = addonmgrv1alpha1.Succeeded
+
+	// Status updates which do not complete the install do not enqueue dependents
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	r.dependencyCompleted(event.UpdateEvent{ObjectOld: a, ObjectNew: a.DeepCopy()}, q)
+	r.dependencyCompleted(event.UpdateEvent{ObjectOld: completed, ObjectNew: completed.DeepCopy()}, q)
+	g.Expect(q.Len()).To(BeZero())
+
+	// b is reconciled as soon as a completes
+	r.dependencyCompleted(event.UpdateEvent{ObjectOld: a, ObjectNew: completed}, q)
+	g.Expect(q.Len()).To(Equal(1))
+	req, _ := q.Get()
+	g.Expect(req).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "addon-manager-system", Name: "b"}}))
+}
+
+func TestDependsOn(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	v := addon.Version{PackageSpec: addonmgrv1alpha1.PackageSpec{PkgDeps: map[string]string{" core ": " v1.0.0 ", "dns": "*"}}}
+	g.Expect(dependsOn(v, "core", "v1.0.0")).To(BeTrue())
+	g.Expect(dependsOn(v, "core", "v2.0.0")).To(BeFalse())
+	g.Expect(dependsOn(v, "dns", "v2.0.0")).To(BeTrue())
+	g.Expect(dependsOn(v, "other", "v1.0.0")).To(BeFalse())
+}
+
+func TestFinalize_InstalledDependents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &AddonReconciler{
+		Log:           zap.New(zap.UseDevMode(true)),
+		versionCache:  addon.NewAddonVersionCacheClient(),
+		recorder:      recorder,
+		FinalizerName: DefaultFinalizerName,
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "core", "addon-manager-system"
+	instance.Finalizers = []string{DefaultFinalizerName}
+	instance.Spec.PkgName, instance.Spec.PkgVersion = "core", "v1.0.0"
+	instance.Spec.Lifecycle.Delete.Template = "kind: Workflow"
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Deleting
+
+	// b is installed and depends on core, c was only validated
+	r.versionCache.AddVersion(addon.Version{
+		Name:        "b",
+		Namespace:   "addon-manager-system",
+		PackageSpec: addonmgrv1alpha1.PackageSpec{PkgName: "b", PkgVersion: "v1.0.0", PkgDeps: map[string]string{"core": "*"}},
+		PkgPhase:    addonmgrv1alpha1.Succeeded,
+	})
+	r.versionCache.AddVersion(addon.Version{
+		Name:        "c",
+		Namespace:   "addon-manager-system",
+		PackageSpec: addonmgrv1alpha1.PackageSpec{PkgName: "c", PkgVersion: "v1.0.0", PkgDeps: map[string]string{"core": "v1.0.0"}},
+		PkgPhase:    addonmgrv1alpha1.ValidationPassed,
+	})
+
+	// The delete workflow is not run and the finalizer is kept while b is installed
+	err := r.Finalize(context.TODO(), instance, &failingLifecycle{}, r.FinalizerName)
+	g.Expect(isDependentsInstalled(err)).To(BeTrue())
+	g.Expect(instance.Finalizers).To(Equal([]string{DefaultFinalizerName}))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Deleting))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("waiting on addons addon-manager-system/b"))
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("DeleteBlocked")))
+
+	// Forced deletes run the delete workflow, which fails to be submitted by the lifecycle
+	instance.SetAnnotations(map[string]string{common.ForceDeleteAnnotation: "true"})
+	err = r.Finalize(context.TODO(), instance, &failingLifecycle{}, r.FinalizerName)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(isDependentsInstalled(err)).To(BeFalse())
+}
@@ -0,0 +1,94 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long shutdown waits for in-flight reconciles and queued notifications
+const DefaultShutdownTimeout = 20 * time.Second
+
+// reconcileDrain counts in-flight reconciles, once draining no new reconciles are started
+type reconcileDrain struct {
+	sync.Mutex
+	draining bool
+	inflight int
+	idle     chan struct{}
+}
+
+// begin registers a reconcile, it returns false once draining started
+func (d *reconcileDrain) begin() bool {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.draining {
+		return false
+	}
+	d.inflight++
+	return true
+}
+
+// end unregisters a reconcile registered with begin
+func (d *reconcileDrain) end() {
+	d.Lock()
+	defer d.Unlock()
+
+	d.inflight--
+	if d.inflight == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+// drain stops new reconciles and waits until in-flight reconciles ended or the context is done
+func (d *reconcileDrain) drain(ctx context.Context) error {
+	d.Lock()
+	d.draining = true
+	if d.inflight == 0 {
+		d.Unlock()
+		return nil
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		d.Lock()
+		defer d.Unlock()
+		return fmt.Errorf("%d addon reconciles did not finish. %v", d.inflight, ctx.Err())
+	}
+}
+
+// Shutdown stops new reconciles and waits for in-flight reconciles to persist the addon status, then sends the
+// queued notifications and status changes. It is called once the manager stopped, and gives up when the context is
+// done.
+func (r *AddonReconciler) Shutdown(ctx context.Context) error {
+	if err := r.drain.drain(ctx); err != nil {
+		return err
+	}
+	if err := r.Notifications.Flush(ctx); err != nil {
+		return err
+	}
+	return r.StatusStream.Flush(ctx)
+}
@@ -0,0 +1,123 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// blockingStatusClient blocks status updates until released
+type blockingStatusClient struct {
+	client.Client
+	updating chan struct{}
+	release  chan struct{}
+}
+
+func (c *blockingStatusClient) Status() client.StatusWriter {
+	return &blockingStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type blockingStatusWriter struct {
+	client.StatusWriter
+	c *blockingStatusClient
+}
+
+func (w *blockingStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	w.c.updating <- struct{}{}
+	<-w.c.release
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func TestShutdown_DrainsInFlightReconciles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	deleted := metav1.Now()
+	key := types.NamespacedName{Namespace: "default", Name: "my-addon"}
+	other := types.NamespacedName{Namespace: "default", Name: "other-addon"}
+	fake := runtimefake.NewFakeClientWithScheme(sch,
+		&addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, DeletionTimestamp: &deleted}},
+		&addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: other.Name, Namespace: other.Namespace, DeletionTimestamp: &deleted}},
+	)
+	c := &blockingStatusClient{Client: fake, updating: make(chan struct{}, 2), release: make(chan struct{})}
+	r := &AddonReconciler{
+		Client:      c,
+		Log:         zap.New(zap.UseDevMode(true)),
+		apiReader:   c,
+		recorder:    record.NewFakeRecorder(10),
+		statusWGMap: map[string]*sync.WaitGroup{},
+	}
+
+	// The reconcile of a deleted addon persists the Deleting status, shutdown is signaled while it does
+	go func() { _, _ = r.Reconcile(ctrl.Request{NamespacedName: key}) }()
+	g.Eventually(c.updating, time.Second).Should(Receive())
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- r.Shutdown(context.TODO()) }()
+	g.Consistently(shutdown, 100*time.Millisecond).ShouldNot(Receive())
+
+	// No new reconciles start while draining
+	g.Eventually(func() bool {
+		r.drain.Lock()
+		defer r.drain.Unlock()
+		return r.drain.draining
+	}, time.Second).Should(BeTrue())
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: other})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.updating).NotTo(Receive())
+
+	close(c.release)
+	g.Eventually(shutdown, time.Second).Should(Receive(BeNil()))
+
+	var persisted = &addonmgrv1alpha1.Addon{}
+	g.Expect(fake.Get(context.TODO(), key, persisted)).To(Succeed())
+	g.Expect(persisted.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Deleting))
+	persisted = &addonmgrv1alpha1.Addon{}
+	g.Expect(fake.Get(context.TODO(), other, persisted)).To(Succeed())
+	g.Expect(persisted.Status.Lifecycle.Installed).To(BeEmpty())
+}
+
+func TestReconcileDrain_Timeout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var d reconcileDrain
+	g.Expect(d.begin()).To(BeTrue())
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	g.Expect(d.drain(ctx)).To(MatchError(ContainSubstring("1 addon reconciles did not finish")))
+	g.Expect(d.begin()).To(BeFalse())
+
+	// The reconcile may still end after the drain gave up
+	d.end()
+	g.Expect(d.drain(context.TODO())).To(Succeed())
+}
@@ -0,0 +1,149 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// DefaultDriftCheckPeriod is how often the resources of addons are observed when no event is seen
+const DefaultDriftCheckPeriod = 10 * time.Minute
+
+// objectName returns the resource as kind.group/name, prefixed with the namespace of namespaced resources
+func objectName(o addonmgrv1alpha1.ObjectStatus) string {
+	name := fmt.Sprintf("%s/%s", o.Kind, o.Name)
+	if o.Group != "" {
+		name = fmt.Sprintf("%s.%s/%s", o.Kind, o.Group, o.Name)
+	}
+	if o.Namespace != "" {
+		name = fmt.Sprintf("%s/%s", o.Namespace, name)
+	}
+	return name
+}
+
+// removeCondition removes the condition type from the conditions, meta.RemoveStatusCondition panics on an empty list
+func removeCondition(conditions *[]metav1.Condition, conditionType string) {
+	if meta.FindStatusCondition(*conditions, conditionType) != nil {
+		meta.RemoveStatusCondition(conditions, conditionType)
+	}
+}
+
+// detectDrift returns the observed resources along with the expected resources which are no longer observed,
+// reported as Missing, and the names of the missing resources. Resources owned by another resource, e.g. replica sets
+// of old deployment revisions or jobs of cron jobs, are removed by their owner and not expected. Missing resources stay
+// expected until they are observed again.
+func detectDrift(expected, observed []addonmgrv1alpha1.ObjectStatus) ([]addonmgrv1alpha1.ObjectStatus, []string) {
+	var seen = make(map[string]bool, len(observed))
+	for _, o := range observed {
+		seen[objectName(o)] = true
+	}
+
+	var missing []string
+	for _, e := range expected {
+		name := objectName(e)
+		if seen[name] || e.Owner != "" {
+			continue
+		}
+		seen[name] = true
+		missing = append(missing, name)
+
+		e.Status = string(addonmgrv1alpha1.Missing)
+		e.CurrentReplicas, e.DesiredReplicas = 0, 0
+		e.LastScheduleTime, e.LastSuccessfulTime, e.ActiveJobs = nil, nil, 0
+		e.Reason = ""
+		observed = append(observed, e)
+	}
+
+	return observed, missing
+}
+
+// checkDrift sets the Degraded condition of the installed addon from its missing and degraded resources. Addons with
+// self heal are reinstalled like addons with the force reinstall annotation when resources are missing, it returns true
+// when a reinstall was requested.
+func (r *AddonReconciler) checkDrift(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, missing []string, degraded []addonmgrv1alpha1.ObjectStatus) (bool, error) {
+	if len(missing) == 0 && len(degraded) > 0 {
+		reason, message := degradedMessage(instance, degraded)
+		if c := meta.FindStatusCondition(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition); c == nil || c.Status != metav1.ConditionTrue || c.Message != message {
+			r.recorder.Event(instance, "Warning", reason, message)
+			log.Info("Addon resources are degraded.", "degraded", degradedNames(degraded))
+		}
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:               addonmgrv1alpha1.DegradedCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: instance.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+		return false, nil
+	}
+
+	if len(missing) == 0 {
+		if meta.IsStatusConditionTrue(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition) {
+			r.recorder.Event(instance, "Normal", "Recovered", fmt.Sprintf("Addon %s/%s resources are no longer missing.", instance.Namespace, instance.Name))
+		}
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:               addonmgrv1alpha1.DegradedCondition,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: instance.Generation,
+			Reason:             "ResourcesObserved",
+			Message:            "All resources of the installed addon are observed.",
+		})
+		return false, nil
+	}
+
+	// Resources stay missing across checks, drift is only reported when the missing resources change
+	message := fmt.Sprintf("Addon %s/%s resources %s are missing.", instance.Namespace, instance.Name, strings.Join(missing, ", "))
+	if c := meta.FindStatusCondition(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition); c == nil || c.Status != metav1.ConditionTrue || c.Message != message {
+		r.recorder.Event(instance, "Warning", "Drifted", message)
+		log.Info("Addon resources are missing.", "missing", missing)
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               addonmgrv1alpha1.DegradedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: instance.Generation,
+		Reason:             "ResourcesMissing",
+		Message:            message,
+	})
+
+	if !instance.Spec.Lifecycle.SelfHeal {
+		return false, nil
+	}
+
+	// Keep the computed status, the update returns the persisted one
+	status := instance.Status
+	annotations := instance.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[common.ForceReinstallAnnotation] = "self-heal"
+	instance.SetAnnotations(annotations)
+	if err := r.Update(ctx, instance); err != nil {
+		return false, err
+	}
+	instance.Status = status
+
+	r.recorder.Event(instance, "Normal", "SelfHeal", fmt.Sprintf("Addon %s/%s is reinstalled to restore missing resources.", instance.Namespace, instance.Name))
+	return true, nil
+}
@@ -0,0 +1,133 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+func TestDetectDrift(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deployment := addonmgrv1alpha1.ObjectStatus{Kind: "Deployment", Group: "apps", Name: "fluentd", Namespace: "logging", Status: "Ready"}
+	hpa := addonmgrv1alpha1.ObjectStatus{Kind: "HorizontalPodAutoscaler", Group: "autoscaling", Name: "fluentd", Namespace: "logging", CurrentReplicas: 2, DesiredReplicas: 2}
+	role := addonmgrv1alpha1.ObjectStatus{Kind: "ClusterRole", Group: "rbac.authorization.k8s.io", Name: "fluentd"}
+
+	observed, missing := detectDrift([]addonmgrv1alpha1.ObjectStatus{deployment, hpa, role}, []addonmgrv1alpha1.ObjectStatus{deployment})
+	g.Expect(missing).To(Equal([]string{"logging/HorizontalPodAutoscaler.autoscaling/fluentd", "ClusterRole.rbac.authorization.k8s.io/fluentd"}))
+	g.Expect(observed).To(HaveLen(3))
+	g.Expect(observed[1]).To(Equal(addonmgrv1alpha1.ObjectStatus{Kind: "HorizontalPodAutoscaler", Group: "autoscaling", Name: "fluentd", Namespace: "logging", Status: "Missing"}))
+
+	// Missing resources stay expected until they are observed again
+	observed, missing = detectDrift(observed, []addonmgrv1alpha1.ObjectStatus{deployment, hpa})
+	g.Expect(missing).To(Equal([]string{"ClusterRole.rbac.authorization.k8s.io/fluentd"}))
+	g.Expect(observed).To(HaveLen(3))
+	g.Expect(observed[2]).To(Equal(addonmgrv1alpha1.ObjectStatus{Kind: "ClusterRole", Group: "rbac.authorization.k8s.io", Name: "fluentd", Status: "Missing"}))
+
+	observed, missing = detectDrift(observed, []addonmgrv1alpha1.ObjectStatus{deployment, hpa})
+	g.Expect(missing).To(Equal([]string{"ClusterRole.rbac.authorization.k8s.io/fluentd"}))
+	g.Expect(observed).To(HaveLen(3))
+
+	observed, missing = detectDrift(observed, []addonmgrv1alpha1.ObjectStatus{deployment, hpa, role})
+	g.Expect(missing).To(BeEmpty())
+	g.Expect(observed).To(Equal([]addonmgrv1alpha1.ObjectStatus{deployment, hpa, role}))
+
+	// Resources removed by their owner, like replica sets of old revisions, are not missing
+	replicaSet := addonmgrv1alpha1.ObjectStatus{Kind: "ReplicaSet", Group: "apps", Name: "fluentd-1a2b", Namespace: "logging", Owner: "Deployment/fluentd"}
+	job := addonmgrv1alpha1.ObjectStatus{Kind: "Job", Group: "batch", Name: "cleanup-1622851200", Namespace: "logging", Owner: "CronJob/cleanup"}
+	observed, missing = detectDrift([]addonmgrv1alpha1.ObjectStatus{deployment, replicaSet, job}, []addonmgrv1alpha1.ObjectStatus{deployment})
+	g.Expect(missing).To(BeEmpty())
+	g.Expect(observed).To(Equal([]addonmgrv1alpha1.ObjectStatus{deployment}))
+}
+
+func TestCheckDrift(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	key := types.NamespacedName{Namespace: "default", Name: "fluentd"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+	})
+	r := &AddonReconciler{
+		Client:   c,
+		recorder: record.NewFakeRecorder(10),
+	}
+	log := zap.New(zap.UseDevMode(true))
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Succeeded
+
+	reinstall, err := r.checkDrift(context.TODO(), log, instance, []string{"logging/Deployment.apps/fluentd"}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reinstall).To(BeFalse())
+	g.Expect(meta.IsStatusConditionTrue(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition)).To(BeTrue())
+	g.Expect(meta.FindStatusCondition(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition).Message).To(ContainSubstring("logging/Deployment.apps/fluentd are missing"))
+
+	// Resources which are still missing keep the addon degraded without reporting the drift again
+	recorder := r.recorder.(*record.FakeRecorder)
+	g.Expect(recorder.Events).To(HaveLen(1))
+	reinstall, err = r.checkDrift(context.TODO(), log, instance, []string{"logging/Deployment.apps/fluentd"}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reinstall).To(BeFalse())
+	g.Expect(meta.IsStatusConditionTrue(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition)).To(BeTrue())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring("Drifted"))
+
+	reinstall, err = r.checkDrift(context.TODO(), log, instance, nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reinstall).To(BeFalse())
+	g.Expect(meta.IsStatusConditionFalse(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition)).To(BeTrue())
+	g.Expect(<-recorder.Events).To(ContainSubstring("Recovered"))
+
+	// Self healing addons are reinstalled with the force reinstall annotation, the computed status is kept
+	instance.Spec.Lifecycle.SelfHeal = true
+	reinstall, err = r.checkDrift(context.TODO(), log, instance, []string{"logging/Deployment.apps/fluentd"}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reinstall).To(BeTrue())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(meta.IsStatusConditionTrue(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition)).To(BeTrue())
+
+	var persisted = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, persisted)).To(Succeed())
+	g.Expect(persisted.GetAnnotations()).To(HaveKey(common.ForceReinstallAnnotation))
+}
+
+func TestRemoveCondition(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var conditions []metav1.Condition
+	removeCondition(&conditions, addonmgrv1alpha1.DegradedCondition)
+	g.Expect(conditions).To(BeEmpty())
+
+	meta.SetStatusCondition(&conditions, metav1.Condition{Type: addonmgrv1alpha1.DegradedCondition, Status: metav1.ConditionTrue, Reason: "ResourcesMissing"})
+	removeCondition(&conditions, addonmgrv1alpha1.DegradedCondition)
+	g.Expect(conditions).To(BeEmpty())
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// eventThrottleWindow is how long identical events of an addon are coalesced for
+const eventThrottleWindow = 5 * time.Minute
+
+type eventKey struct {
+	eventtype, reason, message string
+}
+
+type eventCount struct {
+	// first is when the event was last recorded, it starts the window
+	first time.Time
+	// suppressed is the number of identical events dropped since
+	suppressed int
+}
+
+// eventThrottle records the first of identical events of an addon and drops the repeats within the window. A repeat
+// after the window is recorded with the number of dropped events, so that addons waiting on something, like a pending
+// dependency, report that they are still waiting without flooding the namespace events.
+type eventThrottle struct {
+	record.EventRecorder
+	sync.Mutex
+	window time.Duration
+	now    func() time.Time
+	events map[types.NamespacedName]map[eventKey]*eventCount
+}
+
+func newEventThrottle(recorder record.EventRecorder, window time.Duration) *eventThrottle {
+	return &eventThrottle{
+		EventRecorder: recorder,
+		window:        window,
+		now:           time.Now,
+		events:        map[types.NamespacedName]map[eventKey]*eventCount{},
+	}
+}
+
+// Event records the event unless an identical event was recorded for the object within the window
+func (t *eventThrottle) Event(object runtime.Object, eventtype, reason, message string) {
+	if message, ok := t.allow(object, eventtype, reason, message); ok {
+		t.EventRecorder.Event(object, eventtype, reason, message)
+	}
+}
+
+// Eventf is like Event, identical formatted messages are throttled
+func (t *eventThrottle) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	t.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (t *eventThrottle) allow(object runtime.Object, eventtype, reason, message string) (string, bool) {
+	obj, err := meta.Accessor(object)
+	if err != nil {
+		return message, true
+	}
+	name := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+	t.Lock()
+	defer t.Unlock()
+
+	now := t.now()
+	counts, ok := t.events[name]
+	if !ok {
+		counts = map[eventKey]*eventCount{}
+		t.events[name] = counts
+	}
+
+	key := eventKey{eventtype: eventtype, reason: reason, message: message}
+	for k, c := range counts {
+		// Drop expired events that were never repeated, e.g. messages with errors in them
+		if k != key && c.suppressed == 0 && now.Sub(c.first) >= t.window {
+			delete(counts, k)
+		}
+	}
+
+	c, ok := counts[key]
+	if !ok {
+		counts[key] = &eventCount{first: now}
+		return message, true
+	}
+
+	if now.Sub(c.first) < t.window {
+		c.suppressed++
+		return message, false
+	}
+
+	if c.suppressed > 0 {
+		summary := "Repeated"
+		if reason == "Pending" {
+			summary = "Still waiting"
+		}
+		message = fmt.Sprintf("%s %s (x%d).", message, summary, c.suppressed+1)
+	}
+	c.first = now
+	c.suppressed = 0
+
+	return message, true
+}
+
+// Forget removes the throttle state of a deleted addon
+func (t *eventThrottle) Forget(name types.NamespacedName) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.events, name)
+}
@@ -0,0 +1,65 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestEventThrottle(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fake := record.NewFakeRecorder(10)
+	throttle := newEventThrottle(fake, time.Minute)
+	now := time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC)
+	throttle.now = func() time.Time { return now }
+
+	a := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "system"}}
+	b := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "system"}}
+
+	for i := 0; i < 3; i++ {
+		throttle.Event(a, "Normal", "Pending", "Addon system/fluentd is waiting on dependencies.")
+		now = now.Add(10 * time.Second)
+	}
+	throttle.Event(b, "Normal", "Pending", "Addon system/dns is waiting on dependencies.")
+	throttle.Eventf(a, "Warning", "Failed", "Addon %s/%s failed.", a.Namespace, a.Name)
+	g.Expect(fake.Events).To(HaveLen(3))
+	g.Expect(<-fake.Events).To(Equal("Normal Pending Addon system/fluentd is waiting on dependencies."))
+	g.Expect(<-fake.Events).To(Equal("Normal Pending Addon system/dns is waiting on dependencies."))
+	g.Expect(<-fake.Events).To(Equal("Warning Failed Addon system/fluentd failed."))
+
+	// The first repeat after the window summarizes the dropped events
+	now = now.Add(time.Minute)
+	throttle.Event(a, "Normal", "Pending", "Addon system/fluentd is waiting on dependencies.")
+	throttle.Event(a, "Normal", "Pending", "Addon system/fluentd is waiting on dependencies.")
+	throttle.Event(a, "Warning", "Failed", "Addon system/fluentd failed.")
+	g.Expect(fake.Events).To(HaveLen(2))
+	g.Expect(<-fake.Events).To(Equal("Normal Pending Addon system/fluentd is waiting on dependencies. Still waiting (x3)."))
+	g.Expect(<-fake.Events).To(Equal("Warning Failed Addon system/fluentd failed."))
+
+	// Deleted addons start over
+	throttle.Forget(types.NamespacedName{Namespace: "system", Name: "fluentd"})
+	g.Expect(throttle.events).NotTo(HaveKey(types.NamespacedName{Namespace: "system", Name: "fluentd"}))
+	throttle.Event(a, "Normal", "Pending", "Addon system/fluentd is waiting on dependencies.")
+	g.Expect(<-fake.Events).To(Equal("Normal Pending Addon system/fluentd is waiting on dependencies."))
+}
@@ -0,0 +1,42 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+// workflowFailureReason returns the reason of a failed lifecycle workflow with the step that failed and its message,
+// so that the cause is found without looking up the workflow in Argo.
+func (r *AddonReconciler) workflowFailureReason(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle, lifecycleStep addonmgrv1alpha1.LifecycleStep) string {
+	wfName := instance.GetFormattedWorkflowName(lifecycleStep)
+	reason := fmt.Sprintf("Addon %s/%s %s workflow %s failed.", instance.Namespace, instance.Name, lifecycleStep, wfName)
+
+	failure, err := wfl.Failure(ctx, wfName)
+	if err != nil {
+		workflowLogger(log, instance, lifecycleStep).Error(err, "Addon workflow failure could not be read.")
+		return reason
+	}
+	if failure == "" {
+		return reason
+	}
+	return fmt.Sprintf("%s %s", reason, failure)
+}
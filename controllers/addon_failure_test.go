@@ -0,0 +1,56 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// failedLifecycle reports the failed step of its workflows
+type failedLifecycle struct {
+	fakeLifecycle
+	failure string
+	err     error
+}
+
+func (f *failedLifecycle) Failure(context.Context, string) (string, error) {
+	return f.failure, f.err
+}
+
+func TestWorkflowFailureReason(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := &AddonReconciler{}
+	log := zap.New(zap.UseDevMode(true))
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "my-addon", "default"
+	wfName := instance.GetFormattedWorkflowName(addonmgrv1alpha1.Install)
+
+	wfl := &failedLifecycle{failure: "step apply (template apply-manifests): Error (exit code 1)"}
+	g.Expect(r.workflowFailureReason(context.TODO(), log, instance, wfl, addonmgrv1alpha1.Install)).To(Equal(
+		"Addon default/my-addon install workflow " + wfName + " failed. step apply (template apply-manifests): Error (exit code 1)"))
+
+	wfl = &failedLifecycle{err: errors.New("not found")}
+	g.Expect(r.workflowFailureReason(context.TODO(), log, instance, wfl, addonmgrv1alpha1.Install)).To(Equal(
+		"Addon default/my-addon install workflow " + wfName + " failed."))
+}
@@ -0,0 +1,132 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jinzhu/inflection"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// fastPath skips the full reconcile of installed addons whose spec did not change since their resources were last
+// fully observed, within the drift check period. Only the resources listed in status are re-checked in the informer
+// caches, a missing resource or a changed resource status falls back to the full reconcile so that drift is still
+// detected. It returns the requeue of the next full reconcile and true when the reconcile was skipped.
+func (r *AddonReconciler) fastPath(log logr.Logger, instance *addonmgrv1alpha1.Addon) (ctrl.Result, bool) {
+	if instance.Status.Lifecycle.Installed != addonmgrv1alpha1.Succeeded || instance.Generation != instance.Status.ObservedGeneration {
+		return ctrl.Result{}, false
+	}
+
+	if _, ok := instance.GetAnnotations()[common.ForceReinstallAnnotation]; ok {
+		return ctrl.Result{}, false
+	}
+	if token, ok := instance.GetAnnotations()[common.ReconcileTokenAnnotation]; ok && token != instance.Status.ObservedReconcileToken {
+		return ctrl.Result{}, false
+	}
+	if meta.IsStatusConditionTrue(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition) {
+		return ctrl.Result{}, false
+	}
+
+	period := r.gitRecheckPeriod(instance, r.DriftCheckPeriod)
+	last, ok := r.lastObserved.Load(types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace})
+	if period <= 0 || !ok {
+		return ctrl.Result{}, false
+	}
+	since := time.Since(last.(time.Time))
+	if since >= period {
+		return ctrl.Result{}, false
+	}
+
+	if instance.CalculateChecksum() != instance.Status.Checksum || !r.resourcesUnchanged(instance) {
+		return ctrl.Result{}, false
+	}
+
+	log.V(1).Info("Addon is unchanged, skipping reconcile.")
+	return ctrl.Result{RequeueAfter: period - since}, true
+}
+
+// observedFully records that the resources of the addon were fully observed
+func (r *AddonReconciler) observedFully(instance *addonmgrv1alpha1.Addon) {
+	r.lastObserved.Store(types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, time.Now())
+}
+
+// resourcesUnchanged returns true when the resources listed in the addon status are still in the informer caches
+// with the same status. Resources are looked up by name, rather than listing every watched kind.
+func (r *AddonReconciler) resourcesUnchanged(instance *addonmgrv1alpha1.Addon) bool {
+	if generatedInformers == nil {
+		return false
+	}
+
+	for _, o := range instance.Status.Resources {
+		resc := r.watchedKind(o.Group, o.Kind)
+		if resc == nil {
+			return false
+		}
+
+		inf, err := generatedInformers.ForResource(informerResource(resc))
+		if err != nil {
+			return false
+		}
+
+		var obj runtime.Object
+		if o.Namespace == "" {
+			obj, err = inf.Lister().Get(o.Name)
+		} else {
+			obj, err = inf.Lister().ByNamespace(o.Namespace).Get(o.Name)
+		}
+		if err != nil {
+			return false
+		}
+
+		var status addonmgrv1alpha1.ObjectStatus
+		observeStatus(obj, &status)
+		if status.Status != o.Status {
+			return false
+		}
+	}
+
+	return true
+}
+
+// watchedKind returns the watched resource of the group and kind, or nil when the kind is not watched
+func (r *AddonReconciler) watchedKind(group, kind string) runtime.Object {
+	for _, resc := range append(clusterResources[:], r.watched...) {
+		gvk := resc.GetObjectKind().GroupVersionKind()
+		if gvk.Group == group && gvk.Kind == kind {
+			return resc
+		}
+	}
+	return nil
+}
+
+// informerResource returns the resource of the informer of a watched kind
+func informerResource(resc runtime.Object) schema.GroupVersionResource {
+	gvk := resc.GetObjectKind().GroupVersionKind()
+	return schema.GroupVersionResource{
+		Group:    gvk.Group,
+		Version:  gvk.Version,
+		Resource: inflection.Plural(strings.ToLower(gvk.Kind)),
+	}
+}
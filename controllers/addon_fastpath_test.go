@@ -0,0 +1,96 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+func TestFastPath(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	clientset := fake.NewSimpleClientset(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "logging"}})
+
+	previous := generatedInformers
+	defer func() { generatedInformers = previous }()
+	generatedInformers = informers.NewSharedInformerFactory(clientset, 0)
+
+	var stop = make(chan struct{})
+	defer close(stop)
+	_, _ = generatedInformers.ForResource(informerResource(resources[0]))
+	generatedInformers.Start(stop)
+	generatedInformers.WaitForCacheSync(stop)
+
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{Log: log, DriftCheckPeriod: 10 * time.Minute, watched: resources[:]}
+
+	instance := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "addon-manager-system", Generation: 2}}
+	instance.Spec.Params.Namespace = "logging"
+	instance.Status.Checksum = instance.CalculateChecksum()
+	instance.Status.ObservedGeneration = 2
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Succeeded
+	instance.Status.Resources = []addonmgrv1alpha1.ObjectStatus{{Kind: "Service", Name: "fluentd", Namespace: "logging"}}
+
+	// Addons are fully reconciled until their resources were observed
+	_, ok := r.fastPath(log, instance)
+	g.Expect(ok).To(BeFalse())
+
+	r.observedFully(instance)
+	ret, ok := r.fastPath(log, instance)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(ret.RequeueAfter).To(BeNumerically(">", 9*time.Minute))
+	g.Expect(ret.RequeueAfter).To(BeNumerically("<=", 10*time.Minute))
+
+	// Spec changes are fully reconciled
+	instance.Generation = 3
+	_, ok = r.fastPath(log, instance)
+	g.Expect(ok).To(BeFalse())
+	instance.Generation = 2
+
+	// As are refresh and reinstall requests
+	instance.SetAnnotations(map[string]string{common.ReconcileTokenAnnotation: "1"})
+	_, ok = r.fastPath(log, instance)
+	g.Expect(ok).To(BeFalse())
+	instance.SetAnnotations(nil)
+
+	// Resources that are not cached anymore are drift
+	instance.Status.Resources = append(instance.Status.Resources, addonmgrv1alpha1.ObjectStatus{Kind: "Service", Name: "fluentd-metrics", Namespace: "logging"})
+	_, ok = r.fastPath(log, instance)
+	g.Expect(ok).To(BeFalse())
+	instance.Status.Resources = instance.Status.Resources[:1]
+
+	// Resources are fully observed again once the drift check period passed
+	r.lastObserved.Store(types.NamespacedName{Name: "fluentd", Namespace: "addon-manager-system"}, time.Now().Add(-11*time.Minute))
+	_, ok = r.fastPath(log, instance)
+	g.Expect(ok).To(BeFalse())
+
+	// Without a drift check period resources are always fully observed
+	r.observedFully(instance)
+	r.DriftCheckPeriod = 0
+	_, ok = r.fastPath(log, instance)
+	g.Expect(ok).To(BeFalse())
+}
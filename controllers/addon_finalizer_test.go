@@ -0,0 +1,270 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+const deleteWorkflowTemplate = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+spec:
+  entrypoint: delete
+  templates:
+  - name: delete
+    container:
+      image: alpine
+`
+
+func TestSetFinalizer_ReplacesPreviousName(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	key := types.NamespacedName{Namespace: "default", Name: "my-addon"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       key.Name,
+			Namespace:  key.Namespace,
+			Finalizers: []string{"other.example.com", DefaultFinalizerName},
+		},
+	})
+	r := &AddonReconciler{
+		Client:                 c,
+		FinalizerName:          "delete.addonmgr.example.com",
+		PreviousFinalizerNames: []string{DefaultFinalizerName},
+	}
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+	g.Expect(r.finalizersOf(instance, r.FinalizerName)).To(Equal([]string{DefaultFinalizerName}))
+
+	g.Expect(r.SetFinalizer(context.TODO(), instance, r.FinalizerName)).To(Succeed())
+
+	var persisted = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, persisted)).To(Succeed())
+	g.Expect(persisted.ObjectMeta.Finalizers).To(Equal([]string{"other.example.com", "delete.addonmgr.example.com"}))
+	g.Expect(r.finalizersOf(persisted, r.FinalizerName)).To(Equal([]string{"delete.addonmgr.example.com"}))
+
+	// Finalizers of other controllers are never owned
+	r.PreviousFinalizerNames = nil
+	persisted.ObjectMeta.Finalizers = []string{"other.example.com", DefaultFinalizerName}
+	g.Expect(r.finalizersOf(persisted, r.FinalizerName)).To(BeEmpty())
+}
+
+func TestFinalize_ValidateOnly(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	key := types.NamespacedName{Namespace: "default", Name: "my-addon"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              key.Name,
+			Namespace:         key.Namespace,
+			Finalizers:        []string{DefaultFinalizerName},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: addonmgrv1alpha1.AddonSpec{
+			Mode: addonmgrv1alpha1.ValidateMode,
+			Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+				Delete: addonmgrv1alpha1.DeleteWorkflowType{WorkflowType: addonmgrv1alpha1.WorkflowType{Template: "kind: Workflow"}},
+			},
+		},
+	})
+	r := &AddonReconciler{
+		Client:        c,
+		Log:           zap.New(zap.UseDevMode(true)),
+		versionCache:  addon.NewAddonVersionCacheClient(),
+		FinalizerName: DefaultFinalizerName,
+	}
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+
+	// The delete workflow is not run, the lifecycle would fail to submit it
+	g.Expect(r.Finalize(context.TODO(), instance, &failingLifecycle{}, r.FinalizerName)).To(Succeed())
+	g.Expect(instance.ObjectMeta.Finalizers).To(BeEmpty())
+	g.Expect(instance.Status.ActiveWorkflow).To(BeNil())
+}
+
+func TestFinalize_DeleteProtection(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	key := types.NamespacedName{Namespace: "default", Name: "my-addon"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              key.Name,
+			Namespace:         key.Namespace,
+			Finalizers:        []string{DefaultFinalizerName},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: addonmgrv1alpha1.AddonSpec{
+			Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+				Delete:           addonmgrv1alpha1.DeleteWorkflowType{WorkflowType: addonmgrv1alpha1.WorkflowType{Template: "kind: Workflow"}},
+				DeleteProtection: true,
+			},
+		},
+	})
+	recorder := record.NewFakeRecorder(10)
+	r := &AddonReconciler{
+		Client:        c,
+		Log:           zap.New(zap.UseDevMode(true)),
+		versionCache:  addon.NewAddonVersionCacheClient(),
+		recorder:      recorder,
+		FinalizerName: DefaultFinalizerName,
+	}
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+
+	// The delete workflow is not run and the finalizer is kept
+	g.Expect(r.Finalize(context.TODO(), instance, &failingLifecycle{}, r.FinalizerName)).To(Succeed())
+	g.Expect(instance.ObjectMeta.Finalizers).To(Equal([]string{DefaultFinalizerName}))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.DeleteFailed))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("delete protected"))
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("DeleteProtected")))
+
+	// The event is recorded once
+	g.Expect(r.Finalize(context.TODO(), instance, &failingLifecycle{}, r.FinalizerName)).To(Succeed())
+	g.Expect(recorder.Events).NotTo(Receive())
+
+	// Removing protection finalizes the addon, validate-only addons have no resources to clean up
+	instance.Spec.Lifecycle.DeleteProtection = false
+	instance.Spec.Mode = addonmgrv1alpha1.ValidateMode
+	g.Expect(r.Finalize(context.TODO(), instance, &failingLifecycle{}, r.FinalizerName)).To(Succeed())
+	g.Expect(instance.ObjectMeta.Finalizers).To(BeEmpty())
+}
+
+func TestRunWorkflow_SuspendedStep(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &AddonReconciler{
+		Log:      zap.New(zap.UseDevMode(true)),
+		recorder: recorder,
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Lifecycle.Install = addonmgrv1alpha1.WorkflowType{Template: "kind: Workflow", Suspend: true}
+	instance.Spec.Lifecycle.Delete.WorkflowType = addonmgrv1alpha1.WorkflowType{Template: "kind: Workflow"}
+	checksum := instance.CalculateChecksum()
+
+	// Suspended steps succeed without submitting a workflow, the lifecycle would fail to submit it
+	phase, err := r.runWorkflow(addonmgrv1alpha1.Install, instance, &failingLifecycle{}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(<-recorder.Events).To(ContainSubstring("install workflow was skipped"))
+
+	_, err = r.runWorkflow(addonmgrv1alpha1.Delete, instance, &failingLifecycle{}, nil)
+	g.Expect(err).To(HaveOccurred())
+
+	// Suspending the delete workflow does not reinstall the addon
+	instance.Spec.Lifecycle.Delete.Suspend = true
+	g.Expect(instance.CalculateChecksum()).To(Equal(checksum))
+	phase, err = r.runWorkflow(addonmgrv1alpha1.Delete, instance, &failingLifecycle{}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(addonmgrv1alpha1.Succeeded))
+
+	// Resuming the install workflow runs it again
+	instance.Spec.Lifecycle.Install.Suspend = false
+	g.Expect(instance.CalculateChecksum()).NotTo(Equal(checksum))
+}
+
+func TestFinalize_ResubmitsMissingDeleteWorkflow(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+	sch.AddKnownTypeWithName(common.WorkflowGVR().GroupVersion().WithKind("Workflow"), &unstructured.Unstructured{})
+	sch.AddKnownTypeWithName(common.WorkflowGVR().GroupVersion().WithKind("WorkflowList"), &unstructured.UnstructuredList{})
+
+	// The controller crashed after the addon was set Deleting and before its delete workflow was submitted
+	key := types.NamespacedName{Namespace: "default", Name: "my-addon"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              key.Name,
+			Namespace:         key.Namespace,
+			Finalizers:        []string{DefaultFinalizerName},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: addonmgrv1alpha1.AddonSpec{
+			Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+				Delete: addonmgrv1alpha1.DeleteWorkflowType{WorkflowType: addonmgrv1alpha1.WorkflowType{Template: deleteWorkflowTemplate}},
+			},
+		},
+		Status: addonmgrv1alpha1.AddonStatus{
+			Lifecycle: addonmgrv1alpha1.AddonStatusLifecycle{Installed: addonmgrv1alpha1.Deleting},
+		},
+	})
+	dynClient := dynfake.NewSimpleDynamicClient(sch)
+	r := &AddonReconciler{
+		Client:        c,
+		Log:           zap.New(zap.UseDevMode(true)),
+		versionCache:  addon.NewAddonVersionCacheClient(),
+		recorder:      record.NewFakeRecorder(10),
+		FinalizerName: DefaultFinalizerName,
+	}
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+	wfl := workflows.NewWorkflowLifecycle(c, dynClient, instance, r.recorder, sch, workflows.ClusterValues{}, 0)
+
+	// The delete workflow is submitted on the next reconcile and the finalizer is kept until it completes
+	g.Expect(r.Finalize(context.TODO(), instance, wfl, r.FinalizerName)).To(Succeed())
+	g.Expect(instance.ObjectMeta.Finalizers).To(ConsistOf(DefaultFinalizerName))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Deleting))
+
+	name := instance.GetFormattedWorkflowName(addonmgrv1alpha1.Delete)
+	wf := &unstructured.Unstructured{}
+	wf.SetGroupVersionKind(common.WorkflowGVR().GroupVersion().WithKind("Workflow"))
+	g.Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: key.Namespace, Name: name}, wf)).To(Succeed())
+
+	// A submitted workflow which is not observed yet is not submitted twice
+	g.Expect(r.Finalize(context.TODO(), instance, wfl, r.FinalizerName)).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Deleting))
+
+	// The delete step completes with the live workflow
+	wf.Object["status"] = map[string]interface{}{"phase": "Succeeded", "startedAt": time.Now().UTC().Format(time.RFC3339)}
+	_, err := dynClient.Resource(common.WorkflowGVR()).Namespace(key.Namespace).Create(context.TODO(), wf, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	phase, err := r.runWorkflow(addonmgrv1alpha1.Delete, instance, wfl, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(addonmgrv1alpha1.Succeeded))
+}
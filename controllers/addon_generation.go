@@ -0,0 +1,49 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// reconciledGeneration is the latest generation of an addon instance that was reconciled
+type reconciledGeneration struct {
+	uid        types.UID
+	generation int64
+}
+
+// staleGeneration returns true if the addon is older than a generation of the same instance that was already
+// reconciled. Requests are queued by the addon watch and by the mappers of workflows and resources, a request may
+// read the addon from a cache which has not received the latest spec yet.
+func (r *AddonReconciler) staleGeneration(instance *addonmgrv1alpha1.Addon) bool {
+	reconciled := instance.Status.ObservedGeneration
+	if v, ok := r.generations.Load(types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}); ok {
+		if g := v.(reconciledGeneration); g.uid == instance.UID && g.generation > reconciled {
+			reconciled = g.generation
+		}
+	}
+	return instance.Generation < reconciled
+}
+
+// reconciled records the generation of the addon as reconciled
+func (r *AddonReconciler) reconciled(instance *addonmgrv1alpha1.Addon) {
+	instance.Status.ObservedGeneration = instance.Generation
+	r.generations.Store(types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, reconciledGeneration{
+		uid:        instance.UID,
+		generation: instance.Generation,
+	})
+}
@@ -0,0 +1,123 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
+)
+
+func TestStaleGeneration(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := &AddonReconciler{}
+	instance := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "addon-manager-system", UID: "1", Generation: 3}}
+	instance.Status.ObservedGeneration = 2
+	g.Expect(r.staleGeneration(instance)).To(BeFalse())
+
+	r.reconciled(instance)
+	g.Expect(instance.Status.ObservedGeneration).To(Equal(int64(3)))
+
+	// A request reading the previous generation from a lagging cache is stale
+	previous := instance.DeepCopy()
+	previous.Generation = 2
+	previous.Status.ObservedGeneration = 2
+	g.Expect(r.staleGeneration(previous)).To(BeTrue())
+	g.Expect(r.staleGeneration(instance)).To(BeFalse())
+
+	// As is an addon older than its own status
+	previous.Status.ObservedGeneration = 3
+	r.generations.Delete(types.NamespacedName{Name: "fluentd", Namespace: "addon-manager-system"})
+	g.Expect(r.staleGeneration(previous)).To(BeTrue())
+
+	// Recreated addons start over at generation 1
+	r.reconciled(instance)
+	recreated := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "addon-manager-system", UID: "2", Generation: 1}}
+	g.Expect(r.staleGeneration(recreated)).To(BeFalse())
+}
+
+func TestProcessAddon_StaleGeneration(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addonLabels := map[string]string{
+		"app.kubernetes.io/managed-by": "addonmgr.keikoproj.io",
+		"app.kubernetes.io/name":       "fluentd",
+	}
+	clientset := fake.NewSimpleClientset(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "logging", Labels: addonLabels}})
+
+	previous := generatedInformers
+	defer func() { generatedInformers = previous }()
+	generatedInformers = informers.NewSharedInformerFactory(clientset, 0)
+
+	var stop = make(chan struct{})
+	defer close(stop)
+	_, _ = generatedInformers.ForResource(informerResource(resources[0]))
+	generatedInformers.Start(stop)
+	generatedInformers.WaitForCacheSync(stop)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+	_ = v1.AddToScheme(sch)
+	c := runtimefake.NewFakeClientWithScheme(sch)
+
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{
+		Client:        c,
+		Log:           log,
+		Scheme:        sch,
+		FinalizerName: "delete.addonmgr.keikoproj.io",
+		apiReader:     c,
+		recorder:      record.NewFakeRecorder(10),
+		versionCache:  addon.NewAddonVersionCacheClient(),
+		watched:       resources[:1],
+	}
+
+	instance := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "addon-manager-system", UID: "1", Generation: 3}}
+	instance.Finalizers = []string{r.FinalizerName}
+	instance.Spec.PkgName, instance.Spec.PkgVersion = "fluentd", "v1"
+	instance.Spec.Params.Namespace = "logging"
+	r.reconciled(instance)
+
+	// The previous generation read from a lagging cache is pending on its workflows
+	stale := instance.DeepCopy()
+	stale.Generation = 2
+	stale.Status.Checksum = stale.CalculateChecksum()
+	stale.Status.Lifecycle.Prereqs = addonmgrv1alpha1.Pending
+	stale.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+	stale.Status.StartTime = time.Now().UnixNano() / int64(time.Millisecond)
+	g.Expect(r.staleGeneration(stale)).To(BeTrue())
+
+	wfl := &combinedLifecycle{}
+	_, err := r.processAddon(context.TODO(), log, stale, wfl, metrics.NewPhaseTimings())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(wfl.submitted).To(BeEmpty())
+	g.Expect(stale.Status.Resources).To(Equal([]addonmgrv1alpha1.ObjectStatus{{Kind: "Service", Name: "fluentd", Namespace: "logging"}}))
+}
@@ -0,0 +1,141 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/git"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+// DefaultGitPollInterval is the default interval the refs of git sources are resolved at
+const DefaultGitPollInterval = 5 * time.Minute
+
+// gitPoll is the last resolution of the ref of a git source
+type gitPoll struct {
+	repo string
+	ref  string
+	at   time.Time
+}
+
+// resolveGitSource resolves the ref of the git source of the addon to the commit that is installed, at most once per
+// GitPollInterval. The commit is part of the checksum, so a moved ref reinstalls the addon. When the ref cannot be
+// resolved the previous commit is kept, an error is only returned when no commit was resolved yet.
+func (r *AddonReconciler) resolveGitSource(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon) error {
+	src := instance.Spec.Source.Git
+	if src.Repo == "" || instance.ValidateOnly() {
+		return nil
+	}
+
+	// Invalid repositories fail validation, which reports them
+	if err := git.ValidateRepo(src.Repo); err != nil {
+		return nil
+	}
+
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+	if instance.Status.ResolvedCommit != "" && !r.gitPollDue(key, src) {
+		return nil
+	}
+
+	commit, err := r.resolveGitRef(ctx, instance)
+	if err != nil && instance.Status.ResolvedCommit == "" {
+		return err
+	}
+	r.gitPolls.Store(key, gitPoll{repo: src.Repo, ref: src.Ref, at: time.Now()})
+	if err != nil {
+		r.recorder.Event(instance, "Warning", "GitResolveFailed", fmt.Sprintf("Addon %s/%s git ref %q could not be resolved, commit %s is kept. %v", instance.Namespace, instance.Name, src.Ref, instance.Status.ResolvedCommit, err))
+		log.Error(err, "Addon git ref could not be resolved.", "commit", instance.Status.ResolvedCommit)
+		return nil
+	}
+
+	if commit != instance.Status.ResolvedCommit {
+		if instance.Status.ResolvedCommit != "" {
+			r.recorder.Event(instance, "Normal", "GitRefUpdated", fmt.Sprintf("Addon %s/%s git ref %q moved from commit %s to %s.", instance.Namespace, instance.Name, src.Ref, instance.Status.ResolvedCommit, commit))
+		}
+		log.Info("Addon git ref is resolved", "ref", src.Ref, "commit", commit)
+		instance.Status.ResolvedCommit = commit
+	}
+
+	return nil
+}
+
+// gitPollDue returns true if the ref of the git source was not resolved within GitPollInterval, or the source changed
+func (r *AddonReconciler) gitPollDue(key types.NamespacedName, src addonmgrv1alpha1.GitSource) bool {
+	v, ok := r.gitPolls.Load(key)
+	if !ok {
+		return true
+	}
+	last := v.(gitPoll)
+	return last.repo != src.Repo || last.ref != src.Ref || time.Since(last.at) >= r.GitPollInterval
+}
+
+// resolveGitRef returns the commit the ref of the git source points to, with the credentials of its secret
+func (r *AddonReconciler) resolveGitRef(ctx context.Context, instance *addonmgrv1alpha1.Addon) (string, error) {
+	src := instance.Spec.Source.Git
+
+	var auth *git.Auth
+	if src.SecretName != "" {
+		secrets, err := r.requireSecrets(ctx, instance, instance.GetWorkflowNamespace(), src.SecretName)
+		if err != nil {
+			return "", err
+		}
+		if auth, err = gitAuth(secrets[src.SecretName]); err != nil {
+			return "", fmt.Errorf("secret %q is invalid. %v", src.SecretName, err)
+		}
+	}
+
+	return r.gitResolver.Resolve(ctx, src.Repo, src.Ref, auth)
+}
+
+// gitAuth returns the basic auth credentials of a git source secret
+func gitAuth(secret unstructured.Unstructured) (*git.Auth, error) {
+	data, _, err := unstructured.NestedStringMap(secret.Object, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	var auth = &git.Auth{}
+	for key, value := range map[string]*string{workflows.GitUsernameKey: &auth.Username, workflows.GitPasswordKey: &auth.Password} {
+		decoded, err := base64.StdEncoding.DecodeString(data[key])
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not base64 encoded", key)
+		}
+		if len(decoded) == 0 {
+			return nil, fmt.Errorf("key %q is missing", key)
+		}
+		*value = string(decoded)
+	}
+	return auth, nil
+}
+
+// gitRecheckPeriod returns how often the addon is reconciled without events, so that git refs are polled on time
+func (r *AddonReconciler) gitRecheckPeriod(instance *addonmgrv1alpha1.Addon, period time.Duration) time.Duration {
+	if instance.Spec.Source.Git.Repo == "" || r.GitPollInterval <= 0 {
+		return period
+	}
+	if period <= 0 || r.GitPollInterval < period {
+		return r.GitPollInterval
+	}
+	return period
+}
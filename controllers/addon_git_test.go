@@ -0,0 +1,121 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/git"
+)
+
+type fakeResolver struct {
+	commit   string
+	err      error
+	resolves int
+	auth     *git.Auth
+}
+
+func (f *fakeResolver) Resolve(_ context.Context, _, _ string, auth *git.Auth) (string, error) {
+	f.resolves++
+	f.auth = auth
+	return f.commit, f.err
+}
+
+func TestResolveGitSource(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName("git-creds")
+	secret.SetNamespace("addon-manager-system")
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{
+		"username": base64.StdEncoding.EncodeToString([]byte("git")),
+		"password": base64.StdEncoding.EncodeToString([]byte("token")),
+	}, "data")
+
+	resolver := &fakeResolver{commit: "1111111111111111111111111111111111111111"}
+	recorder := record.NewFakeRecorder(10)
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{
+		Log:             log,
+		dynClient:       dynfake.NewSimpleDynamicClient(runtime.NewScheme(), secret),
+		recorder:        recorder,
+		gitResolver:     resolver,
+		GitPollInterval: time.Hour,
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "event-router", "addon-manager-system"
+	instance.Spec.Source.Git = addonmgrv1alpha1.GitSource{Repo: "https://github.com/org/addons.git", Ref: "main", SecretName: "git-creds"}
+
+	g.Expect(r.resolveGitSource(context.TODO(), log, instance)).To(Succeed())
+	g.Expect(instance.Status.ResolvedCommit).To(Equal(resolver.commit))
+	g.Expect(resolver.auth).To(Equal(&git.Auth{Username: "git", Password: "token"}))
+	checksum := instance.CalculateChecksum()
+
+	// The ref is not resolved again within the poll interval
+	resolver.commit = "2222222222222222222222222222222222222222"
+	g.Expect(r.resolveGitSource(context.TODO(), log, instance)).To(Succeed())
+	g.Expect(resolver.resolves).To(Equal(1))
+
+	// A moved ref changes the checksum, which reinstalls the addon
+	r.GitPollInterval = 0
+	g.Expect(r.resolveGitSource(context.TODO(), log, instance)).To(Succeed())
+	g.Expect(instance.Status.ResolvedCommit).To(Equal(resolver.commit))
+	g.Expect(instance.CalculateChecksum()).NotTo(Equal(checksum))
+	g.Expect(<-recorder.Events).To(ContainSubstring("GitRefUpdated"))
+
+	// Failed resolutions keep the resolved commit
+	resolver.err = fmt.Errorf("unexpected status 503")
+	g.Expect(r.resolveGitSource(context.TODO(), log, instance)).To(Succeed())
+	g.Expect(instance.Status.ResolvedCommit).To(Equal(resolver.commit))
+	g.Expect(<-recorder.Events).To(ContainSubstring("GitResolveFailed"))
+
+	// Without a resolved commit the addon cannot be installed
+	instance.Status.ResolvedCommit = ""
+	g.Expect(r.resolveGitSource(context.TODO(), log, instance)).To(MatchError("unexpected status 503"))
+
+	// The secret must exist in the workflow namespace
+	resolver.err = nil
+	instance.Spec.Source.Git.SecretName = "missing"
+	err := r.resolveGitSource(context.TODO(), log, instance)
+	g.Expect(err).To(MatchError(ContainSubstring(`needs secret "missing" that was not found in namespace addon-manager-system`)))
+}
+
+func TestGitRecheckPeriod(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := &AddonReconciler{GitPollInterval: 5 * time.Minute}
+	instance := &addonmgrv1alpha1.Addon{}
+	g.Expect(r.gitRecheckPeriod(instance, 10*time.Minute)).To(Equal(10 * time.Minute))
+
+	instance.Spec.Source.Git.Repo = "https://github.com/org/addons.git"
+	g.Expect(r.gitRecheckPeriod(instance, 10*time.Minute)).To(Equal(5 * time.Minute))
+	g.Expect(r.gitRecheckPeriod(instance, 0)).To(Equal(5 * time.Minute))
+	g.Expect(r.gitRecheckPeriod(instance, time.Minute)).To(Equal(time.Minute))
+}
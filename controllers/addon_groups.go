@@ -0,0 +1,54 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
+)
+
+// registerGroupMetrics registers the addon group metric counted from the cached addons, it is registered once per
+// process as the metrics registry is global
+func (r *AddonReconciler) registerGroupMetrics() error {
+	err := ctrlmetrics.Registry.Register(metrics.NewAddonGroupCollector(r.groupCounts))
+	if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		return nil
+	}
+	return err
+}
+
+// groupCounts returns the number of cached addons of each group by install status
+func (r *AddonReconciler) groupCounts() (map[string]map[string]int, error) {
+	var list = &addonmgrv1alpha1.AddonList{}
+	if err := r.Client.List(context.Background(), list); err != nil {
+		return nil, err
+	}
+
+	var counts = map[string]map[string]int{}
+	for _, s := range addon.SummarizeGroups(list.Items) {
+		counts[s.Group] = map[string]int{
+			metrics.GroupInstalled: s.Installed,
+			metrics.GroupFailed:    s.Failed,
+			metrics.GroupPending:   s.Pending,
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,61 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
+)
+
+func TestGroupCounts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	c := runtimefake.NewFakeClientWithScheme(sch,
+		&addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "metrics-server"},
+			Spec:       addonmgrv1alpha1.AddonSpec{Group: "monitoring"},
+			Status:     addonmgrv1alpha1.AddonStatus{Lifecycle: addonmgrv1alpha1.AddonStatusLifecycle{Installed: addonmgrv1alpha1.Succeeded}},
+		},
+		&addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"},
+			Spec:       addonmgrv1alpha1.AddonSpec{Group: "monitoring"},
+			Status:     addonmgrv1alpha1.AddonStatus{Lifecycle: addonmgrv1alpha1.AddonStatusLifecycle{Installed: addonmgrv1alpha1.Failed}},
+		},
+		&addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "event-router"},
+		},
+	)
+	r := &AddonReconciler{Client: c}
+
+	counts, err := r.groupCounts()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(counts).To(Equal(map[string]map[string]int{
+		"monitoring": {metrics.GroupInstalled: 1, metrics.GroupFailed: 1, metrics.GroupPending: 0},
+	}))
+
+	// Registering twice is not an error
+	g.Expect(r.registerGroupMetrics()).To(Succeed())
+	g.Expect(r.registerGroupMetrics()).To(Succeed())
+}
@@ -0,0 +1,143 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// observeDeployment sets the available replicas of the deployment. A deployment is Degraded while it is not
+// available or its rollout stopped progressing, the failing condition is the reason.
+func observeDeployment(d *appsv1.Deployment, status *addonmgrv1alpha1.ObjectStatus) {
+	status.CurrentReplicas, status.DesiredReplicas = d.Status.AvailableReplicas, desiredReplicas(d.Spec.Replicas)
+
+	for _, c := range d.Status.Conditions {
+		if (c.Type == appsv1.DeploymentAvailable || c.Type == appsv1.DeploymentProgressing) && c.Status == v1.ConditionFalse {
+			status.Status = string(addonmgrv1alpha1.Degraded)
+			status.Reason = conditionReason(string(c.Type), c.Reason, c.Message)
+			return
+		}
+	}
+
+	if d.Status.ObservedGeneration < d.Generation || d.Status.UpdatedReplicas < status.DesiredReplicas {
+		status.Status = string(addonmgrv1alpha1.InProgress)
+		return
+	}
+	status.Status = replicasStatus(status.CurrentReplicas, status.DesiredReplicas)
+}
+
+// observeStatefulSet sets the ready replicas of the stateful set. A stateful set is Degraded when its rollout is
+// complete but not all of its replicas are ready.
+func observeStatefulSet(s *appsv1.StatefulSet, status *addonmgrv1alpha1.ObjectStatus) {
+	status.CurrentReplicas, status.DesiredReplicas = s.Status.ReadyReplicas, desiredReplicas(s.Spec.Replicas)
+
+	switch {
+	case status.CurrentReplicas >= status.DesiredReplicas:
+		status.Status = string(addonmgrv1alpha1.Ready)
+	case s.Status.ObservedGeneration >= s.Generation && s.Status.UpdateRevision == s.Status.CurrentRevision:
+		status.Status = string(addonmgrv1alpha1.Degraded)
+		status.Reason = fmt.Sprintf("%d of %d replicas are ready", status.CurrentReplicas, status.DesiredReplicas)
+	default:
+		status.Status = string(addonmgrv1alpha1.InProgress)
+	}
+}
+
+// observeJob sets the status of the job, failed jobs are Degraded. Jobs of cron jobs are not checked, the cron job
+// reports whether its jobs succeed.
+func observeJob(job *batchv1.Job, status *addonmgrv1alpha1.ObjectStatus) {
+	if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+		return
+	}
+
+	status.Status = string(addonmgrv1alpha1.InProgress)
+	for _, c := range job.Status.Conditions {
+		if c.Status != v1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobFailed:
+			status.Status = string(addonmgrv1alpha1.Degraded)
+			status.Reason = conditionReason(string(c.Type), c.Reason, c.Message)
+			return
+		case batchv1.JobComplete:
+			status.Status = string(addonmgrv1alpha1.Ready)
+		}
+	}
+}
+
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// conditionReason returns the condition as "<type> <reason>: <message>", leaving out what is not set
+func conditionReason(conditionType, reason, message string) string {
+	var s = conditionType
+	if reason != "" {
+		s = fmt.Sprintf("%s %s", s, reason)
+	}
+	if message != "" {
+		s = fmt.Sprintf("%s: %s", s, message)
+	}
+	return s
+}
+
+// degradedResources returns the observed resources which are Degraded
+func degradedResources(observed []addonmgrv1alpha1.ObjectStatus) []addonmgrv1alpha1.ObjectStatus {
+	var degraded []addonmgrv1alpha1.ObjectStatus
+	for _, o := range observed {
+		if o.Status == string(addonmgrv1alpha1.Degraded) {
+			degraded = append(degraded, o)
+		}
+	}
+	return degraded
+}
+
+// degradedNames returns the names of the degraded resources, followed by the reason when they report one
+func degradedNames(degraded []addonmgrv1alpha1.ObjectStatus) []string {
+	var names = make([]string, 0, len(degraded))
+	for _, o := range degraded {
+		name := objectName(o)
+		if o.Reason != "" {
+			name = fmt.Sprintf("%s (%s)", name, o.Reason)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// degradedMessage returns the reason and message of the Degraded condition of the addon with degraded resources
+func degradedMessage(instance *addonmgrv1alpha1.Addon, degraded []addonmgrv1alpha1.ObjectStatus) (string, string) {
+	var cronJobsOnly = true
+	for _, o := range degraded {
+		cronJobsOnly = cronJobsOnly && o.Kind == "CronJob"
+	}
+
+	names := strings.Join(degradedNames(degraded), ", ")
+	if cronJobsOnly {
+		return "CronJobsFailing", fmt.Sprintf("Addon %s/%s cron jobs %s have not succeeded within their schedule interval.", instance.Namespace, instance.Name, names)
+	}
+	return "ResourcesDegraded", fmt.Sprintf("Addon %s/%s resources %s are degraded.", instance.Namespace, instance.Name, names)
+}
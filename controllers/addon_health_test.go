@@ -0,0 +1,144 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestObserveDeployment(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var replicas int32 = 2
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "logging", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+
+	var status = addonmgrv1alpha1.ObjectStatus{}
+	observeDeployment(d, &status)
+	g.Expect(status.Status).To(Equal("Ready"))
+	g.Expect(status.CurrentReplicas).To(Equal(int32(2)))
+	g.Expect(status.DesiredReplicas).To(Equal(int32(2)))
+
+	d.Status.UpdatedReplicas, d.Status.AvailableReplicas = 1, 1
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeDeployment(d, &status)
+	g.Expect(status.Status).To(Equal("InProgress"))
+
+	d.Status.Conditions = []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentProgressing, Status: v1.ConditionTrue, Reason: "ReplicaSetUpdated"},
+		{Type: appsv1.DeploymentAvailable, Status: v1.ConditionFalse, Reason: "MinimumReplicasUnavailable", Message: "Deployment does not have minimum availability."},
+	}
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeDeployment(d, &status)
+	g.Expect(status.Status).To(Equal("Degraded"))
+	g.Expect(status.Reason).To(Equal("Available MinimumReplicasUnavailable: Deployment does not have minimum availability."))
+}
+
+func TestObserveStatefulSet(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var replicas int32 = 3
+	s := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "logging", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 1, CurrentRevision: "es-1", UpdateRevision: "es-2"},
+	}
+
+	var status = addonmgrv1alpha1.ObjectStatus{}
+	observeStatefulSet(s, &status)
+	g.Expect(status.Status).To(Equal("InProgress"))
+
+	s.Status.CurrentRevision = "es-2"
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeStatefulSet(s, &status)
+	g.Expect(status.Status).To(Equal("Degraded"))
+	g.Expect(status.Reason).To(Equal("1 of 3 replicas are ready"))
+
+	s.Status.ReadyReplicas = 3
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeStatefulSet(s, &status)
+	g.Expect(status.Status).To(Equal("Ready"))
+	g.Expect(status.Reason).To(BeEmpty())
+}
+
+func TestObserveJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "db"}}
+
+	var status = addonmgrv1alpha1.ObjectStatus{}
+	observeJob(job, &status)
+	g.Expect(status.Status).To(Equal("InProgress"))
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeJob(job, &status)
+	g.Expect(status.Status).To(Equal("Ready"))
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"}}
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeJob(job, &status)
+	g.Expect(status.Status).To(Equal("Degraded"))
+	g.Expect(status.Reason).To(Equal("Failed BackoffLimitExceeded: Job has reached the specified backoff limit"))
+
+	// Jobs of cron jobs are reported by their cron job
+	controller := true
+	job.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: "curator", Controller: &controller}}
+	status = addonmgrv1alpha1.ObjectStatus{}
+	observeJob(job, &status)
+	g.Expect(status.Status).To(BeEmpty())
+}
+
+func TestCheckDrift_DegradedResources(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &AddonReconciler{recorder: recorder}
+	log := zap.New(zap.UseDevMode(true))
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "logging", "addon-manager-system"
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Succeeded
+
+	degraded := degradedResources([]addonmgrv1alpha1.ObjectStatus{
+		{Kind: "Deployment", Group: "apps", Name: "fluentd", Namespace: "logging", Status: "Degraded", Reason: "Available MinimumReplicasUnavailable"},
+		{Kind: "CronJob", Group: "batch", Name: "curator", Namespace: "logging", Status: "Degraded"},
+		{Kind: "ConfigMap", Name: "fluentd", Namespace: "logging", Status: "Ready"},
+	})
+
+	reinstall, err := r.checkDrift(context.TODO(), log, instance, nil, degraded)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reinstall).To(BeFalse())
+	c := meta.FindStatusCondition(instance.Status.Conditions, addonmgrv1alpha1.DegradedCondition)
+	g.Expect(c.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(c.Reason).To(Equal("ResourcesDegraded"))
+	g.Expect(c.Message).To(Equal("Addon addon-manager-system/logging resources logging/Deployment.apps/fluentd (Available MinimumReplicasUnavailable), logging/CronJob.batch/curator are degraded."))
+	g.Expect(<-recorder.Events).To(ContainSubstring("ResourcesDegraded"))
+}
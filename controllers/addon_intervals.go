@@ -0,0 +1,59 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReconcileIntervals are the requeue intervals of addons which wait on something, shorter intervals make addons
+// progress sooner at the cost of more reconciles and API requests
+type ReconcileIntervals struct {
+	// Pending is how often an addon waiting on dependencies, lower install waves, required CRDs, a template pull or
+	// a git ref is reconciled
+	Pending time.Duration
+	// WorkflowsNotServed is how often discovery is checked for the Workflow CRD while Argo Workflows is not installed
+	WorkflowsNotServed time.Duration
+	// Validation is how often addons in Validate mode are validated again
+	Validation time.Duration
+	// StatusRetry is how soon the reconcile is retried after the addon status could not be updated
+	StatusRetry time.Duration
+}
+
+// DefaultReconcileIntervals returns the requeue intervals used when none are configured
+func DefaultReconcileIntervals() ReconcileIntervals {
+	return ReconcileIntervals{
+		Pending:            10 * time.Second,
+		WorkflowsNotServed: 30 * time.Second,
+		Validation:         5 * time.Minute,
+		StatusRetry:        1 * time.Second,
+	}
+}
+
+// Validate returns an error if an interval is not positive, a zero interval would requeue addons immediately
+func (i ReconcileIntervals) Validate() error {
+	for name, d := range map[string]time.Duration{
+		"pending":              i.Pending,
+		"workflows-not-served": i.WorkflowsNotServed,
+		"validation":           i.Validation,
+		"status-retry":         i.StatusRetry,
+	} {
+		if d <= 0 {
+			return fmt.Errorf("requeue interval %s must be positive, got %s", name, d)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestReconcileIntervals_Validate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	intervals := DefaultReconcileIntervals()
+	g.Expect(intervals.Validate()).To(Succeed())
+	g.Expect(intervals.Pending).To(Equal(10 * time.Second))
+
+	intervals.StatusRetry = 0
+	g.Expect(intervals.Validate()).To(MatchError("requeue interval status-retry must be positive, got 0s"))
+}
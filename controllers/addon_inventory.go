@@ -0,0 +1,136 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+const inventoryKey = "resources.json"
+
+// inventoryName is the name of the ConfigMap holding the inventory of the addon resources
+func inventoryName(addon *addonmgrv1alpha1.Addon) string {
+	return fmt.Sprintf("%s-inventory", addon.GetName())
+}
+
+// inventoryEntry identifies a resource of the addon in its inventory
+type inventoryEntry struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Link      string `json:"link,omitempty"`
+}
+
+// inventory returns the observed resources as a JSON list sorted by resource, missing resources are left out. The
+// status of the resources is not part of the inventory so that it only changes with the set of resources.
+func inventory(observed []addonmgrv1alpha1.ObjectStatus) (string, error) {
+	var entries = make([]inventoryEntry, 0, len(observed))
+	for _, o := range observed {
+		if o.Status == string(addonmgrv1alpha1.Missing) {
+			continue
+		}
+		entries = append(entries, inventoryEntry{Group: o.Group, Kind: o.Kind, Namespace: o.Namespace, Name: o.Name, Link: o.Link})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// saveInventory writes all observed resources of the addon, including those left out of a truncated status, to a
+// ConfigMap owned by the addon. The ConfigMap is only written when the set of resources changed since the last write.
+func (r *AddonReconciler) saveInventory(ctx context.Context, addon *addonmgrv1alpha1.Addon, observed []addonmgrv1alpha1.ObjectStatus) error {
+	data, err := inventory(observed)
+	if err != nil {
+		return err
+	}
+
+	name := types.NamespacedName{Name: addon.GetName(), Namespace: addon.GetNamespace()}
+	if saved, ok := r.inventories.Load(name); ok && saved.(string) == data {
+		return nil
+	}
+
+	cm := &v1.ConfigMap{}
+	err = r.apiReader.Get(ctx, types.NamespacedName{Name: inventoryName(addon), Namespace: addon.GetNamespace()}, cm)
+	switch {
+	case apierrors.IsNotFound(err):
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      inventoryName(addon),
+				Namespace: addon.GetNamespace(),
+				Labels: map[string]string{
+					common.ManagedByLabel: common.AddonGVR().Group,
+					common.NameLabel:      addon.GetName(),
+				},
+			},
+			Data: map[string]string{inventoryKey: data},
+		}
+		if err := controllerutil.SetControllerReference(addon, cm, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, cm); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case cm.Data[inventoryKey] != data:
+		cm.Data = map[string]string{inventoryKey: data}
+		if err := r.Update(ctx, cm); err != nil {
+			return err
+		}
+	}
+
+	r.inventories.Store(name, data)
+	return nil
+}
+
+// deleteInventory deletes the inventory ConfigMap of the addon
+func (r *AddonReconciler) deleteInventory(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	r.inventories.Delete(types.NamespacedName{Name: addon.GetName(), Namespace: addon.GetNamespace()})
+
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: inventoryName(addon), Namespace: addon.GetNamespace()}}
+	if err := r.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete inventory %s. %v", cm.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestInventory(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	data, err := inventory([]addonmgrv1alpha1.ObjectStatus{
+		{Kind: "Deployment", Group: "apps", Name: "fluentd", Namespace: "logging", Status: "Ready", CurrentReplicas: 2},
+		{Kind: "ClusterRole", Group: "rbac.authorization.k8s.io", Name: "fluentd", Status: "Ready"},
+		{Kind: "ConfigMap", Name: "fluentd", Namespace: "logging", Status: "Missing"},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(MatchJSON(`[
+		{"group": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "fluentd"},
+		{"group": "apps", "kind": "Deployment", "namespace": "logging", "name": "fluentd"}
+	]`))
+
+	data, err = inventory(nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(Equal("[]"))
+}
+
+func TestSaveInventory(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(sch)
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	instance := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "addon-manager-system", UID: "1234"}}
+	c := runtimefake.NewFakeClientWithScheme(sch, instance)
+	r := &AddonReconciler{Client: c, apiReader: c, Scheme: sch}
+
+	observed := []addonmgrv1alpha1.ObjectStatus{{Kind: "Deployment", Group: "apps", Name: "fluentd", Namespace: "logging", Status: "Ready"}}
+	g.Expect(r.saveInventory(context.TODO(), instance, observed)).To(Succeed())
+
+	key := types.NamespacedName{Name: "fluentd-inventory", Namespace: "addon-manager-system"}
+	cm := &v1.ConfigMap{}
+	g.Expect(c.Get(context.TODO(), key, cm)).To(Succeed())
+	g.Expect(cm.Data[inventoryKey]).To(MatchJSON(`[{"group": "apps", "kind": "Deployment", "namespace": "logging", "name": "fluentd"}]`))
+	g.Expect(metav1.IsControlledBy(cm, instance)).To(BeTrue())
+
+	// A changed set of resources updates the inventory
+	observed = append(observed, addonmgrv1alpha1.ObjectStatus{Kind: "Service", Name: "fluentd", Namespace: "logging", Status: "Ready"})
+	g.Expect(r.saveInventory(context.TODO(), instance, observed)).To(Succeed())
+	g.Expect(c.Get(context.TODO(), key, cm)).To(Succeed())
+	g.Expect(cm.Data[inventoryKey]).To(ContainSubstring(`"kind":"Service"`))
+
+	g.Expect(r.deleteInventory(context.TODO(), instance)).To(Succeed())
+	g.Expect(apierrors.IsNotFound(c.Get(context.TODO(), key, cm))).To(BeTrue())
+	g.Expect(r.deleteInventory(context.TODO(), instance)).To(Succeed())
+
+	// The inventory is written again once it was deleted
+	g.Expect(r.saveInventory(context.TODO(), instance, observed)).To(Succeed())
+	g.Expect(c.Get(context.TODO(), key, cm)).To(Succeed())
+}
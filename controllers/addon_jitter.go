@@ -0,0 +1,51 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// requeueJitterFactor is the maximum fraction a requeue delay is shortened or lengthened by
+const requeueJitterFactor = 0.2
+
+// requeueJitter spreads out the requeues of addons that would otherwise be reconciled in lockstep, e.g. after a restart
+type requeueJitter struct {
+	sync.Mutex
+	rnd    *rand.Rand
+	factor float64
+}
+
+func newRequeueJitter(src rand.Source, factor float64) *requeueJitter {
+	return &requeueJitter{
+		rnd:    rand.New(src),
+		factor: factor,
+	}
+}
+
+// Apply returns d changed by a random amount of at most ±factor, a nil jitter returns d unchanged
+func (j *requeueJitter) Apply(d time.Duration) time.Duration {
+	if j == nil || d <= 0 {
+		return d
+	}
+
+	j.Lock()
+	f := j.rnd.Float64()
+	j.Unlock()
+
+	return d + time.Duration((2*f-1)*j.factor*float64(d))
+}
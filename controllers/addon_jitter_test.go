@@ -0,0 +1,45 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRequeueJitter(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	j := newRequeueJitter(rand.NewSource(1), requeueJitterFactor)
+	var seen = map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		d := j.Apply(10 * time.Second)
+		g.Expect(d).To(BeNumerically(">=", 8*time.Second))
+		g.Expect(d).To(BeNumerically("<=", 12*time.Second))
+		seen[d] = true
+	}
+	g.Expect(len(seen)).To(BeNumerically(">", 1))
+
+	// The same source yields the same delays
+	a, b := newRequeueJitter(rand.NewSource(42), requeueJitterFactor), newRequeueJitter(rand.NewSource(42), requeueJitterFactor)
+	g.Expect(a.Apply(time.Minute)).To(Equal(b.Apply(time.Minute)))
+
+	var disabled *requeueJitter
+	g.Expect(disabled.Apply(time.Minute)).To(Equal(time.Minute))
+	g.Expect(j.Apply(0)).To(Equal(time.Duration(0)))
+}
@@ -0,0 +1,40 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// Log keys correlating a reconcile with the workflows it produced
+const (
+	logKeyAddon         = "addon"
+	logKeyNamespace     = "namespace"
+	logKeyChecksum      = "checksum"
+	logKeyLifecycleStep = "lifecycleStep"
+	logKeyWorkflow      = "workflow"
+)
+
+// addonLogger returns the logger with the name, namespace and spec checksum of the addon
+func addonLogger(log logr.Logger, addon *addonmgrv1alpha1.Addon) logr.Logger {
+	return log.WithValues(logKeyAddon, addon.GetName(), logKeyNamespace, addon.GetNamespace(), logKeyChecksum, addon.Status.Checksum)
+}
+
+// workflowLogger returns the addon logger with the lifecycle step and the name of the workflow running it
+func workflowLogger(log logr.Logger, addon *addonmgrv1alpha1.Addon, lifecycleStep addonmgrv1alpha1.LifecycleStep) logr.Logger {
+	return log.WithValues(logKeyLifecycleStep, lifecycleStep, logKeyWorkflow, addon.GetFormattedWorkflowName(lifecycleStep))
+}
@@ -0,0 +1,135 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+)
+
+type logEntry struct {
+	msg    string
+	values map[string]interface{}
+}
+
+// recordingLogger keeps the messages logged with their key/values
+type recordingLogger struct {
+	entries *[]logEntry
+	values  []interface{}
+}
+
+func newRecordingLogger() recordingLogger {
+	return recordingLogger{entries: &[]logEntry{}}
+}
+
+func (l recordingLogger) record(msg string, keysAndValues []interface{}) {
+	var values = make(map[string]interface{})
+	kvs := append(append([]interface{}{}, l.values...), keysAndValues...)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		values[kvs[i].(string)] = kvs[i+1]
+	}
+	*l.entries = append(*l.entries, logEntry{msg: msg, values: values})
+}
+
+func (l recordingLogger) Enabled() bool { return true }
+
+func (l recordingLogger) Info(msg string, keysAndValues ...interface{}) { l.record(msg, keysAndValues) }
+
+func (l recordingLogger) Error(_ error, msg string, keysAndValues ...interface{}) {
+	l.record(msg, keysAndValues)
+}
+
+func (l recordingLogger) V(int) logr.Logger { return l }
+
+func (l recordingLogger) WithName(string) logr.Logger { return l }
+
+func (l recordingLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return recordingLogger{entries: l.entries, values: append(append([]interface{}{}, l.values...), keysAndValues...)}
+}
+
+// failingLifecycle fails to submit workflows
+type failingLifecycle struct {
+	fakeLifecycle
+}
+
+func (f *failingLifecycle) Install(context.Context, *addonmgrv1alpha1.WorkflowType, string, map[string]string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	return addonmgrv1alpha1.Failed, errors.New("workflow could not be created")
+}
+
+func TestWorkflowLogging(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	log := newRecordingLogger()
+	r := &AddonReconciler{
+		Log:          log,
+		recorder:     record.NewFakeRecorder(10),
+		versionCache: addon.NewAddonVersionCacheClient(),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Lifecycle.Prereqs.Template = "kind: Workflow"
+	instance.Spec.Lifecycle.Install.Template = "kind: Workflow"
+	instance.Status.Checksum = instance.CalculateChecksum()
+
+	expected := map[string]interface{}{
+		logKeyAddon:         "fluentd",
+		logKeyNamespace:     "addon-manager-system",
+		logKeyChecksum:      instance.Status.Checksum,
+		logKeyLifecycleStep: addonmgrv1alpha1.Prereqs,
+		logKeyWorkflow:      instance.GetFormattedWorkflowName(addonmgrv1alpha1.Prereqs),
+	}
+
+	// runWorkflow logs the submitted workflow
+	_, err := r.runWorkflow(addonmgrv1alpha1.Prereqs, instance, &fakeLifecycle{}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*log.entries).To(HaveLen(1))
+	for k, v := range expected {
+		g.Expect((*log.entries)[0].values).To(HaveKeyWithValue(k, v))
+	}
+
+	// executePrereqAndInstall logs the failed workflow
+	*log.entries = nil
+	g.Expect(r.executePrereqAndInstall(context.TODO(), addonLogger(log, instance), instance, &failingLifecycle{})).NotTo(Succeed())
+	g.Expect(*log.entries).To(HaveLen(1))
+	g.Expect((*log.entries)[0].msg).To(Equal("Addon prereqs workflow failed."))
+	for k, v := range expected {
+		g.Expect((*log.entries)[0].values).To(HaveKeyWithValue(k, v))
+	}
+
+	// Finalize logs the timed out delete workflow
+	*log.entries = nil
+	instance.Spec.Lifecycle.Delete.Template = "kind: Workflow"
+	instance.Spec.Lifecycle.Delete.TimeoutSeconds = 60
+	instance.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	g.Expect(r.Finalize(context.TODO(), instance, &fakeLifecycle{}, DefaultFinalizerName)).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.DeleteFailed))
+
+	entry := (*log.entries)[len(*log.entries)-1]
+	g.Expect(entry.msg).To(Equal("Addon delete workflow timed out."))
+	g.Expect(entry.values).To(HaveKeyWithValue(logKeyLifecycleStep, addonmgrv1alpha1.Delete))
+	g.Expect(entry.values).To(HaveKeyWithValue(logKeyWorkflow, instance.GetFormattedWorkflowName(addonmgrv1alpha1.Delete)))
+	g.Expect(entry.values).To(HaveKeyWithValue(logKeyChecksum, instance.Status.Checksum))
+}
@@ -0,0 +1,270 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+// manifestFieldManager is the field manager owning the fields applied from addon manifests
+const manifestFieldManager = "addon-manager"
+
+// usesWorkflows returns true if any lifecycle step of the addon runs a workflow
+func usesWorkflows(a *addonmgrv1alpha1.Addon) bool {
+	if a.ValidateOnly() {
+		return false
+	}
+	if len(a.Spec.Source.Manifests) == 0 && (a.Spec.Lifecycle.Install.Template != "" || a.Spec.Source.Kustomize.Path != "" || a.Spec.Source.Git.Repo != "") {
+		return true
+	}
+	return a.Spec.Lifecycle.Prereqs.Template != "" || a.Spec.Lifecycle.Delete.Template != "" || a.Spec.Lifecycle.Validate.Template != "" ||
+		a.Spec.Lifecycle.PostInstall.Template != "" || a.RunsCombinedWorkflow()
+}
+
+// install runs the install workflow of the addon, addons with manifests are applied directly instead and addons with
+// a combined workflow run it
+func (r *AddonReconciler) install(ctx context.Context, a *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle, params map[string]string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	if len(a.Spec.Source.Manifests) > 0 {
+		return r.applyManifests(ctx, a)
+	}
+	if a.RunsCombinedWorkflow() {
+		_, phase, err := r.runCombinedWorkflow(ctx, a, wfl)
+		return phase, err
+	}
+	return r.runWorkflow(addonmgrv1alpha1.Install, a, wfl, params)
+}
+
+// Manifests are applied in tiers, namespaces and CRDs first so that resources in them and custom resources of them
+// can be applied in the next tier
+const (
+	definitionsTier = "Definitions"
+	resourcesTier   = "Resources"
+)
+
+// manifestTier returns the tier the object of a manifest is applied in
+func manifestTier(obj *unstructured.Unstructured) string {
+	switch obj.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Kind: "Namespace"}, schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:
+		return definitionsTier
+	}
+	return resourcesTier
+}
+
+// manifestReady returns false while an applied namespace is not active or an applied CRD is not established, other
+// objects are ready once they are applied
+func manifestReady(obj *unstructured.Unstructured) bool {
+	if obj == nil {
+		return true
+	}
+	switch manifestTier(obj) {
+	case definitionsTier:
+		if obj.GetKind() == "Namespace" {
+			phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+			return phase == "Active"
+		}
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			cond, _ := c.(map[string]interface{})
+			if cond["type"] == "Established" && cond["status"] == "True" {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// applyManifests server-side applies the manifests of the addon with the addon labels, so that they are observed
+// like resources of install workflows. Override patches are applied to the manifests first, and nothing is applied
+// when a patch fails. Namespaces and CRDs are applied before the other manifests, which are only applied once they
+// are ready, the progress of each tier is reported in status. Applying unchanged manifests again leaves the resources
+// as they are.
+func (r *AddonReconciler) applyManifests(ctx context.Context, a *addonmgrv1alpha1.Addon) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	var patched = make([]bool, len(a.Spec.Overrides.Patches))
+	var tiers = map[string][]*unstructured.Unstructured{}
+	for i := range a.Spec.Source.Manifests {
+		obj, err := manifestObject(a, i)
+		if err != nil {
+			return addonmgrv1alpha1.Failed, err
+		}
+
+		for j, p := range a.Spec.Overrides.Patches {
+			if !addon.OverrideMatches(p, obj) {
+				continue
+			}
+			if err := addon.ApplyOverride(obj, p); err != nil {
+				return addonmgrv1alpha1.Failed, fmt.Errorf("override %s could not be applied. %v", p.Target, err)
+			}
+			patched[j] = true
+		}
+
+		tier := manifestTier(obj)
+		tiers[tier] = append(tiers[tier], obj)
+	}
+
+	// Nothing is applied when an override misses its target, it would be applied without the override otherwise
+	for j, p := range a.Spec.Overrides.Patches {
+		if !patched[j] {
+			return addonmgrv1alpha1.Failed, fmt.Errorf("override target %s is not a manifest", p.Target)
+		}
+	}
+
+	a.Status.ManifestTiers = nil
+	for _, tier := range []string{definitionsTier, resourcesTier} {
+		if len(tiers[tier]) > 0 {
+			a.Status.ManifestTiers = append(a.Status.ManifestTiers, addonmgrv1alpha1.ManifestTierStatus{Name: tier, Manifests: len(tiers[tier])})
+		}
+	}
+
+	force := true
+	for i := range a.Status.ManifestTiers {
+		tier := &a.Status.ManifestTiers[i]
+		tier.Phase = addonmgrv1alpha1.Pending
+
+		var notReady []string
+		for _, obj := range tiers[tier.Name] {
+			resource, err := r.manifestClient(a, obj)
+			if err != nil {
+				tier.Phase = addonmgrv1alpha1.Failed
+				return addonmgrv1alpha1.Failed, err
+			}
+
+			data, err := obj.MarshalJSON()
+			if err != nil {
+				tier.Phase = addonmgrv1alpha1.Failed
+				return addonmgrv1alpha1.Failed, fmt.Errorf("manifest %s %s could not be encoded. %v", obj.GetKind(), obj.GetName(), err)
+			}
+
+			applied, err := resource.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: manifestFieldManager, Force: &force})
+			if err != nil {
+				tier.Phase = addonmgrv1alpha1.Failed
+				return addonmgrv1alpha1.Failed, fmt.Errorf("manifest %s %s could not be applied. %v", obj.GetKind(), obj.GetName(), err)
+			}
+			if !manifestReady(applied) {
+				notReady = append(notReady, fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName()))
+			}
+		}
+
+		// Later tiers are applied on the next reconcile, once the resources of this tier are ready
+		if len(notReady) > 0 {
+			reason := fmt.Sprintf("Addon %s/%s is waiting on %s manifests %s to be ready.", a.Namespace, a.Name, tier.Name, strings.Join(notReady, ", "))
+			if a.Status.Reason != reason {
+				r.recorder.Event(a, "Normal", "Pending", reason)
+			}
+			a.Status.Reason = reason
+			return addonmgrv1alpha1.Pending, nil
+		}
+		tier.Phase = addonmgrv1alpha1.Succeeded
+	}
+
+	r.recorder.Event(a, "Normal", "Completed", fmt.Sprintf("Addon %s/%s applied %d manifests.", a.Namespace, a.Name, len(a.Spec.Source.Manifests)))
+	return addonmgrv1alpha1.Succeeded, nil
+}
+
+// deleteManifests deletes the resources applied from the manifests of the addon
+func (r *AddonReconciler) deleteManifests(ctx context.Context, a *addonmgrv1alpha1.Addon) error {
+	for i := range a.Spec.Source.Manifests {
+		obj, resource, err := r.manifestResource(a, i)
+		if meta.IsNoMatchError(err) {
+			// The kind is no longer served, so neither are its resources
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := resource.Delete(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete manifest %s %s. %v", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// manifestResource returns the object of the i-th addon manifest labeled and, in the addon namespace, owned by the
+// addon, and the client of its resource. Namespaced objects without a namespace are placed in the addon params namespace.
+func (r *AddonReconciler) manifestResource(a *addonmgrv1alpha1.Addon, i int) (*unstructured.Unstructured, dynamic.ResourceInterface, error) {
+	obj, err := manifestObject(a, i)
+	if err != nil {
+		return nil, nil, err
+	}
+	resource, err := r.manifestClient(a, obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, resource, nil
+}
+
+// manifestObject returns the object of the i-th addon manifest with the common metadata and observation labels of
+// the addon
+func manifestObject(a *addonmgrv1alpha1.Addon, i int) (*unstructured.Unstructured, error) {
+	obj, err := addon.DecodeManifest(a.Spec.Source.Manifests[i])
+	if err != nil {
+		return nil, fmt.Errorf("manifest %d is invalid. %v", i, err)
+	}
+
+	common.SetCommonMetadata(obj, a.Spec.CommonLabels, a.Spec.CommonAnnotations)
+
+	var objLabels = obj.GetLabels()
+	if objLabels == nil {
+		objLabels = make(map[string]string)
+	}
+	if key := addon.ObservationLabelKey(a, common.ManagedByLabel); key != "" {
+		objLabels[key] = common.AddonGVR().Group
+	}
+	if key := addon.ObservationLabelKey(a, common.NameLabel); key != "" {
+		objLabels[key] = a.GetName()
+	}
+	obj.SetLabels(objLabels)
+	return obj, nil
+}
+
+// manifestClient returns the client of the resource of the manifest object. Kinds are mapped when the object is
+// applied, so that custom resources can be mapped once their CRD of a previous tier is established.
+func (r *AddonReconciler) manifestClient(a *addonmgrv1alpha1.Addon, obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := r.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("manifest %s %s kind is not served. %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		obj.SetNamespace("")
+		return r.dynClient.Resource(mapping.Resource), nil
+	}
+
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(a.Spec.Params.Namespace)
+	}
+	// Owner references cannot cross namespaces, resources in other namespaces are owned by their labels only
+	if obj.GetNamespace() == a.GetNamespace() {
+		setAddonOwnerReference(a, obj)
+	}
+	return r.dynClient.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
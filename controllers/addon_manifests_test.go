@@ -0,0 +1,180 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestApplyManifests(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+
+	var applied []clienttesting.PatchActionImpl
+	dynClient := dynfake.NewSimpleDynamicClient(runtime.NewScheme())
+	dynClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		applied = append(applied, action.(clienttesting.PatchActionImpl))
+		return true, nil, nil
+	})
+	r := &AddonReconciler{
+		dynClient:  dynClient,
+		restMapper: mapper,
+		recorder:   record.NewFakeRecorder(10),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "event-router", "addon-manager-system"
+	instance.Spec.Params.Namespace = "event-router"
+	instance.Spec.Source.Manifests = []runtime.RawExtension{
+		{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"event-router-config","labels":{"app":"event-router"}},"data":{"sink":"stdout"}}`)},
+		{Raw: []byte(`{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRole","metadata":{"name":"event-router","namespace":"event-router"}}`)},
+	}
+	instance.Spec.Overrides.Patches = []addonmgrv1alpha1.OverridePatch{
+		{Target: addonmgrv1alpha1.OverrideTarget{Version: "v1", Kind: "ConfigMap", Name: "event-router-config"}, Patch: "data:\n  sink: kafka\n"},
+	}
+
+	phase, err := r.applyManifests(context.TODO(), instance)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(applied).To(HaveLen(2))
+
+	g.Expect(applied[0].GetPatchType()).To(Equal(types.ApplyPatchType))
+	g.Expect(applied[0].GetNamespace()).To(Equal("event-router"))
+	g.Expect(applied[0].GetName()).To(Equal("event-router-config"))
+	var cm map[string]interface{}
+	g.Expect(json.Unmarshal(applied[0].GetPatch(), &cm)).To(Succeed())
+	g.Expect(cm["metadata"]).To(HaveKeyWithValue("labels", map[string]interface{}{
+		"app":                          "event-router",
+		"app.kubernetes.io/managed-by": "addonmgr.keikoproj.io",
+		"app.kubernetes.io/name":       "event-router",
+	}))
+	g.Expect(cm["data"]).To(Equal(map[string]interface{}{"sink": "kafka"}))
+
+	// Cluster-scoped objects are applied without a namespace
+	g.Expect(applied[1].GetResource().Resource).To(Equal("clusterroles"))
+	g.Expect(applied[1].GetNamespace()).To(BeEmpty())
+
+	// Overrides of targets that are not manifests fail the install before anything is applied
+	applied = nil
+	instance.Spec.Overrides.Patches[0].Target.Name = "event-router"
+	phase, err = r.applyManifests(context.TODO(), instance)
+	g.Expect(err).To(MatchError("override target ConfigMap/event-router is not a manifest"))
+	g.Expect(phase).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(applied).To(BeEmpty())
+	instance.Spec.Overrides.Patches = nil
+
+	// Kinds that are not served fail the install
+	instance.Spec.Source.Manifests = append(instance.Spec.Source.Manifests, runtime.RawExtension{
+		Raw: []byte(`{"apiVersion":"cert-manager.io/v1","kind":"Certificate","metadata":{"name":"event-router"}}`),
+	})
+	phase, err = r.applyManifests(context.TODO(), instance)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(phase).To(Equal(addonmgrv1alpha1.Failed))
+}
+
+func TestApplyManifests_Tiers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+
+	var applied []string
+	var phase = "Pending"
+	dynClient := dynfake.NewSimpleDynamicClient(runtime.NewScheme())
+	dynClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchActionImpl)
+		applied = append(applied, patch.GetResource().Resource)
+		obj := &unstructured.Unstructured{}
+		g.Expect(obj.UnmarshalJSON(patch.GetPatch())).To(Succeed())
+		if obj.GetKind() == "Namespace" {
+			g.Expect(unstructured.SetNestedField(obj.Object, phase, "status", "phase")).To(Succeed())
+		}
+		return true, obj, nil
+	})
+	r := &AddonReconciler{
+		dynClient:  dynClient,
+		restMapper: mapper,
+		recorder:   record.NewFakeRecorder(10),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "event-router", "addon-manager-system"
+	instance.Spec.Params.Namespace = "event-router"
+	instance.Spec.Source.Manifests = []runtime.RawExtension{
+		{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"event-router-config"}}`)},
+		{Raw: []byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"event-router"}}`)},
+	}
+
+	// Other manifests wait for the namespace to be active
+	result, err := r.applyManifests(context.TODO(), instance)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(applied).To(Equal([]string{"namespaces"}))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("waiting on Definitions manifests Namespace event-router"))
+	g.Expect(instance.Status.ManifestTiers).To(Equal([]addonmgrv1alpha1.ManifestTierStatus{
+		{Name: "Definitions", Manifests: 1, Phase: addonmgrv1alpha1.Pending},
+		{Name: "Resources", Manifests: 1},
+	}))
+
+	applied, phase = nil, "Active"
+	result, err = r.applyManifests(context.TODO(), instance)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(applied).To(Equal([]string{"namespaces", "configmaps"}))
+	g.Expect(instance.Status.ManifestTiers).To(Equal([]addonmgrv1alpha1.ManifestTierStatus{
+		{Name: "Definitions", Manifests: 1, Phase: addonmgrv1alpha1.Succeeded},
+		{Name: "Resources", Manifests: 1, Phase: addonmgrv1alpha1.Succeeded},
+	}))
+}
+
+func TestManifestReady(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	g.Expect(manifestTier(crd)).To(Equal(definitionsTier))
+	g.Expect(manifestReady(crd)).To(BeFalse())
+
+	g.Expect(unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+		map[string]interface{}{"type": "Established", "status": "True"},
+	}, "status", "conditions")).To(Succeed())
+	g.Expect(manifestReady(crd)).To(BeTrue())
+
+	cm := &unstructured.Unstructured{}
+	cm.SetAPIVersion("v1")
+	cm.SetKind("ConfigMap")
+	g.Expect(manifestTier(cm)).To(Equal(resourcesTier))
+	g.Expect(manifestReady(cm)).To(BeTrue())
+}
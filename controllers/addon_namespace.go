@@ -0,0 +1,110 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+const (
+	// minNamespaceRetryDelay and maxNamespaceRetryDelay bound the requeue delay while waiting on the addon namespace
+	minNamespaceRetryDelay = 5 * time.Second
+	maxNamespaceRetryDelay = 2 * time.Minute
+)
+
+// namespacePendingError is returned while the addon params namespace does not exist, the addon is kept Pending
+type namespacePendingError struct {
+	namespace string
+}
+
+func (e *namespacePendingError) Error() string {
+	return fmt.Sprintf("namespace %s does not exist", e.namespace)
+}
+
+func isNamespacePending(err error) bool {
+	var pendingErr *namespacePendingError
+	return errors.As(err, &pendingErr)
+}
+
+// defaultNamespace sets spec.params.namespace of an addon without one to the default addon namespace. It is written to
+// the addon, so that changing the default later does not move installed addons to another namespace.
+func (r *AddonReconciler) defaultNamespace(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon) error {
+	if instance.Spec.Params.Namespace != "" || r.DefaultAddonNamespace == "" {
+		return nil
+	}
+
+	patch := client.MergeFrom(instance.DeepCopy())
+	instance.Spec.Params.Namespace = r.DefaultAddonNamespace
+	if err := r.Patch(ctx, instance, patch); err != nil {
+		instance.Spec.Params.Namespace = ""
+		return err
+	}
+
+	r.recorder.Event(instance, "Normal", "Defaulted", fmt.Sprintf("Addon %s/%s has no spec.params.namespace, it is installed in the default namespace %s.", instance.Namespace, instance.Name, r.DefaultAddonNamespace))
+	log.Info("Addon namespace is defaulted.", "namespace", r.DefaultAddonNamespace)
+	return nil
+}
+
+// waitForNamespace keeps the addon Pending until its params namespace exists, it may still be created by the prereqs
+// workflow or another addon. The addon fails once the install ttl expires.
+func (r *AddonReconciler) waitForNamespace(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon) error {
+	namespace := instance.Spec.Params.Namespace
+	_, err := r.dynClient.Resource(common.NamespaceGVR()).Get(ctx, namespace, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		reason := fmt.Sprintf("Addon %s/%s could not check namespace %s. %v", instance.Namespace, instance.Name, namespace, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		log.Error(err, "Addon could not check namespace.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureDependency
+		instance.Status.Reason = reason
+
+		return err
+	}
+
+	reason := fmt.Sprintf("Addon %s/%s is waiting for namespace %s to be created.", instance.Namespace, instance.Name, namespace)
+	r.recorder.Event(instance, "Normal", "Pending", reason)
+	log.Info("Addon is waiting for namespace.", "namespace", namespace)
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+	instance.Status.Reason = reason
+
+	return &namespacePendingError{namespace: namespace}
+}
+
+// namespaceRetryDelay returns the requeue delay of an addon waiting on its namespace since start, it grows with the
+// time waited so that namespaces which take long to appear are checked less often
+func namespaceRetryDelay(start int64) time.Duration {
+	delay := time.Duration(common.GetCurretTimestamp()-start) * time.Millisecond / 2
+	if delay < minNamespaceRetryDelay {
+		return minNamespaceRetryDelay
+	}
+	if delay > maxNamespaceRetryDelay {
+		return maxNamespaceRetryDelay
+	}
+	return delay
+}
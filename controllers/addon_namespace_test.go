@@ -0,0 +1,116 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+func TestExecutePrereqAndInstall_WaitsForNamespace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dynClient := dynfake.NewSimpleDynamicClient(runtime.NewScheme())
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{
+		Log:       log,
+		dynClient: dynClient,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Params.Namespace = "logging"
+	instance.Status.StartTime = common.GetCurretTimestamp()
+
+	err := r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})
+	g.Expect(isNamespacePending(err)).To(BeTrue())
+	g.Expect(instance.Status.Lifecycle.Prereqs).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("is waiting for namespace logging to be created"))
+
+	// The addon installs once the namespace is created
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName("logging")
+	_, err = dynClient.Resource(common.NamespaceGVR()).Create(context.TODO(), ns, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(instance.Status.Reason).To(BeEmpty())
+}
+
+func TestNamespaceRetryDelay(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := common.GetCurretTimestamp()
+	g.Expect(namespaceRetryDelay(now)).To(Equal(minNamespaceRetryDelay))
+	g.Expect(namespaceRetryDelay(now - time.Minute.Milliseconds())).To(BeNumerically("~", 30*time.Second, time.Second))
+	g.Expect(namespaceRetryDelay(now - time.Hour.Milliseconds())).To(Equal(maxNamespaceRetryDelay))
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	key := types.NamespacedName{Namespace: "addon-manager-system", Name: "fluentd"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+	})
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{
+		Client:   c,
+		Log:      log,
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	// Without a default the namespace is left empty and fails validation
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+	g.Expect(r.defaultNamespace(context.TODO(), log, instance)).To(Succeed())
+	g.Expect(instance.Spec.Params.Namespace).To(BeEmpty())
+
+	// The default is written to the addon
+	r.DefaultAddonNamespace = "addons"
+	g.Expect(r.defaultNamespace(context.TODO(), log, instance)).To(Succeed())
+	g.Expect(instance.Spec.Params.Namespace).To(Equal("addons"))
+
+	var persisted = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, persisted)).To(Succeed())
+	g.Expect(persisted.Spec.Params.Namespace).To(Equal("addons"))
+
+	// The namespace of the addon takes precedence over the default
+	r.DefaultAddonNamespace = "other"
+	g.Expect(r.defaultNamespace(context.TODO(), log, instance)).To(Succeed())
+	g.Expect(instance.Spec.Params.Namespace).To(Equal("addons"))
+}
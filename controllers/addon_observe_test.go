@@ -0,0 +1,143 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestObserveStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name string
+		obj  runtime.Object
+		want addonmgrv1alpha1.ObjectStatus
+	}{
+		{name: "pvc-bound", obj: &v1.PersistentVolumeClaim{Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound}},
+			want: addonmgrv1alpha1.ObjectStatus{Status: "Ready"}},
+		{name: "pvc-lost", obj: &v1.PersistentVolumeClaim{Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimLost}},
+			want: addonmgrv1alpha1.ObjectStatus{Status: "Unknown"}},
+		{name: "hpa-v2beta2-scaling", obj: &autoscalingv2beta2.HorizontalPodAutoscaler{Status: autoscalingv2beta2.HorizontalPodAutoscalerStatus{CurrentReplicas: 2, DesiredReplicas: 4}},
+			want: addonmgrv1alpha1.ObjectStatus{Status: "InProgress", CurrentReplicas: 2, DesiredReplicas: 4}},
+		{name: "hpa-v1-ready", obj: &autoscalingv1.HorizontalPodAutoscaler{Status: autoscalingv1.HorizontalPodAutoscalerStatus{CurrentReplicas: 3, DesiredReplicas: 3}},
+			want: addonmgrv1alpha1.ObjectStatus{Status: "Ready", CurrentReplicas: 3, DesiredReplicas: 3}},
+		{name: "pdb-ready", obj: &policyv1beta1.PodDisruptionBudget{Status: policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1, CurrentHealthy: 3, DesiredHealthy: 2}},
+			want: addonmgrv1alpha1.ObjectStatus{Status: "Ready", DisruptionsAllowed: 1, CurrentHealthy: 3, DesiredHealthy: 2}},
+		{name: "pdb-unhealthy", obj: &policyv1beta1.PodDisruptionBudget{Status: policyv1beta1.PodDisruptionBudgetStatus{CurrentHealthy: 1, DesiredHealthy: 2}},
+			want: addonmgrv1alpha1.ObjectStatus{Status: "Degraded", Reason: "1 of 2 required pods are healthy", CurrentHealthy: 1, DesiredHealthy: 2}},
+		{name: "pdb-not-observed", obj: &policyv1beta1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: policyv1beta1.PodDisruptionBudgetStatus{ObservedGeneration: 1}},
+			want: addonmgrv1alpha1.ObjectStatus{Status: "InProgress"}},
+		{name: "service", obj: &v1.Service{}, want: addonmgrv1alpha1.ObjectStatus{}},
+	}
+	for _, tt := range tests {
+		var status addonmgrv1alpha1.ObjectStatus
+		observeStatus(tt.obj, &status)
+		g.Expect(status).To(Equal(tt.want), tt.name)
+	}
+}
+
+func TestObserveKind_ClusterScoped(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addonLabels := map[string]string{
+		"app.kubernetes.io/managed-by": "addonmgr.keikoproj.io",
+		"app.kubernetes.io/name":       "fluentd",
+	}
+	clientset := fake.NewSimpleClientset(
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Labels: addonLabels}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "logging", Labels: addonLabels}},
+	)
+
+	previous := generatedInformers
+	defer func() { generatedInformers = previous }()
+	generatedInformers = informers.NewSharedInformerFactory(clientset, 0)
+
+	var stop = make(chan struct{})
+	defer close(stop)
+	for _, resc := range []runtime.Object{clusterResources[0], resources[0]} {
+		_, err := observeKind(resc, "", labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	generatedInformers.Start(stop)
+	generatedInformers.WaitForCacheSync(stop)
+
+	selector := labels.SelectorFromSet(addonLabels)
+	observed, err := observeKind(clusterResources[0], "", selector)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(observed).To(Equal([]addonmgrv1alpha1.ObjectStatus{
+		{Kind: "ClusterRole", Group: "rbac.authorization.k8s.io", Name: "fluentd"},
+	}))
+
+	observed, err = observeKind(resources[0], "logging", selector)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(observed).To(Equal([]addonmgrv1alpha1.ObjectStatus{
+		{Kind: "Service", Name: "fluentd", Namespace: "logging"},
+	}))
+}
+
+func TestObserveKind_NetworkPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addonLabels := map[string]string{
+		"app.kubernetes.io/managed-by": "addonmgr.keikoproj.io",
+		"app.kubernetes.io/name":       "calico",
+	}
+	clientset := fake.NewSimpleClientset(
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "calico-system", Labels: addonLabels}},
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "calico-system"}},
+	)
+
+	previous := generatedInformers
+	defer func() { generatedInformers = previous }()
+	generatedInformers = informers.NewSharedInformerFactory(clientset, 0)
+
+	var policies runtime.Object
+	for _, resc := range optionalResources {
+		if resc.GetObjectKind().GroupVersionKind().Kind == "NetworkPolicy" {
+			policies = resc
+		}
+	}
+	g.Expect(policies).NotTo(BeNil())
+
+	var stop = make(chan struct{})
+	defer close(stop)
+	_, err := observeKind(policies, "calico-system", labels.Everything())
+	g.Expect(err).NotTo(HaveOccurred())
+	generatedInformers.Start(stop)
+	generatedInformers.WaitForCacheSync(stop)
+
+	observed, err := observeKind(policies, "calico-system", labels.SelectorFromSet(addonLabels))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(observed).To(Equal([]addonmgrv1alpha1.ObjectStatus{
+		{Kind: "NetworkPolicy", Group: "networking.k8s.io", Name: "default-deny", Namespace: "calico-system"},
+	}))
+}
@@ -0,0 +1,144 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+const (
+	prereqsOutputsChecksumAnnotation = "addonmgr.keikoproj.io/checksum"
+	redacted                         = "<redacted>"
+)
+
+// prereqsOutputsName is the name of the Secret holding the prereqs outputs until install completes
+func prereqsOutputsName(addon *addonmgrv1alpha1.Addon) string {
+	return fmt.Sprintf("%s-prereqs-outputs", addon.GetName())
+}
+
+// selectOutputs returns the declared outputs from the workflow outputs, it fails when a required output is missing
+func selectOutputs(declared []addonmgrv1alpha1.WorkflowOutput, values map[string]string) (map[string]string, error) {
+	var selected = make(map[string]string, len(declared))
+	var missing []string
+	for _, o := range declared {
+		value, ok := values[o.Name]
+		if !ok {
+			if !o.Optional {
+				missing = append(missing, o.Name)
+			}
+			continue
+		}
+		selected[o.Name] = value
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("required outputs %s are missing from the prereqs workflow", strings.Join(missing, ", "))
+	}
+
+	return selected, nil
+}
+
+// redactOutputs formats the outputs in declared order with the values of sensitive outputs redacted
+func redactOutputs(declared []addonmgrv1alpha1.WorkflowOutput, values map[string]string) string {
+	var pairs []string
+	for _, o := range declared {
+		value, ok := values[o.Name]
+		if !ok {
+			continue
+		}
+		if o.Sensitive {
+			value = redacted
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%s", o.Name, value))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// prereqsOutputs returns the declared outputs of the succeeded prereqs workflow, or nil when none are declared.
+// Outputs are kept in a Secret owned by the addon so that they survive until the install workflow completes.
+func (r *AddonReconciler) prereqsOutputs(ctx context.Context, addon *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) (map[string]string, error) {
+	declared := addon.Spec.Lifecycle.Prereqs.Outputs
+	if len(declared) == 0 {
+		return nil, nil
+	}
+
+	secretClient := r.generatedClient.CoreV1().Secrets(addon.GetNamespace())
+	secret, err := secretClient.Get(ctx, prereqsOutputsName(addon), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil && secret.GetAnnotations()[prereqsOutputsChecksumAnnotation] == addon.Status.Checksum {
+		var values = make(map[string]string, len(secret.Data))
+		for k, v := range secret.Data {
+			values[k] = string(v)
+		}
+		return selectOutputs(declared, values)
+	}
+
+	values, err := wfl.Outputs(ctx, addon.GetFormattedWorkflowName(addonmgrv1alpha1.Prereqs))
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := selectOutputs(declared, values)
+	if err != nil {
+		return nil, err
+	}
+
+	secret = &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        prereqsOutputsName(addon),
+			Namespace:   addon.GetNamespace(),
+			Annotations: map[string]string{prereqsOutputsChecksumAnnotation: addon.Status.Checksum},
+			Labels: map[string]string{
+				common.ManagedByLabel: common.AddonGVR().Group,
+				common.NameLabel:      addon.GetName(),
+			},
+		},
+		StringData: outputs,
+	}
+	if err := controllerutil.SetControllerReference(addon, secret, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	_, err = secretClient.Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = secretClient.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.recorder.Event(addon, "Normal", "Completed", fmt.Sprintf("Addon %s/%s passes prereqs outputs to install: %s.", addon.Namespace, addon.Name, redactOutputs(declared, outputs)))
+
+	return outputs, nil
+}
+
+// deletePrereqsOutputs removes the prereqs outputs once they are no longer needed by install
+func (r *AddonReconciler) deletePrereqsOutputs(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	err := r.generatedClient.CoreV1().Secrets(addon.GetNamespace()).Delete(ctx, prereqsOutputsName(addon), metav1.DeleteOptions{})
+	return ignoreNotFound(err)
+}
@@ -0,0 +1,41 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestSelectOutputs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	declared := []addonmgrv1alpha1.WorkflowOutput{
+		{Name: "endpoint"},
+		{Name: "password", Sensitive: true},
+		{Name: "region", Optional: true},
+	}
+
+	outputs, err := selectOutputs(declared, map[string]string{"endpoint": "db:5432", "password": "s3cret", "other": "x"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(outputs).To(Equal(map[string]string{"endpoint": "db:5432", "password": "s3cret"}))
+	g.Expect(redactOutputs(declared, outputs)).To(Equal("endpoint=db:5432, password=<redacted>"))
+
+	_, err = selectOutputs(declared, map[string]string{"region": "us-west-2"})
+	g.Expect(err).To(MatchError("required outputs endpoint, password are missing from the prereqs workflow"))
+}
@@ -0,0 +1,58 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// setAddonOwnerReference adds an owner reference to the addon to the object, so that it is mapped to the addon when
+// its labels are removed. It is not a controller reference, the object may be controlled by another owner.
+func setAddonOwnerReference(a *addonmgrv1alpha1.Addon, obj metav1.Object) {
+	refs := obj.GetOwnerReferences()
+	for _, ref := range refs {
+		if ref.UID == a.GetUID() {
+			return
+		}
+	}
+	obj.SetOwnerReferences(append(refs, metav1.OwnerReference{
+		APIVersion: addonmgrv1alpha1.GroupVersion.String(),
+		Kind:       "Addon",
+		Name:       a.GetName(),
+		UID:        a.GetUID(),
+	}))
+}
+
+// getAddonRequestsFromOwners returns the addons which own the object by owner reference, owners are always in the
+// namespace of the object
+func getAddonRequestsFromOwners(obj metav1.Object) []reconcile.Request {
+	var reqs = make([]reconcile.Request, 0)
+	for _, ref := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != addonmgrv1alpha1.GroupVersion.Group || ref.Kind != "Addon" {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+			Name:      ref.Name,
+			Namespace: obj.GetNamespace(),
+		}})
+	}
+	return reqs
+}
@@ -0,0 +1,77 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+func TestGetAddonRequestsFromOwners(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	r := &AddonReconciler{
+		dynClient:    dynfake.NewSimpleDynamicClient(runtime.NewScheme()),
+		restMapper:   mapper,
+		versionCache: addon.NewAddonVersionCacheClient(),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace, instance.UID = "event-router", "event-router", types.UID("1a2b")
+	instance.Spec.Params.Namespace = "event-router"
+	instance.Spec.Source.Manifests = []runtime.RawExtension{
+		{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"event-router-config"}}`)},
+	}
+
+	// Manifests in the addon namespace are owned by the addon
+	obj, _, err := r.manifestResource(instance, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(obj.GetOwnerReferences()).To(Equal([]metav1.OwnerReference{{
+		APIVersion: "addonmgr.keikoproj.io/v1alpha1",
+		Kind:       "Addon",
+		Name:       "event-router",
+		UID:        types.UID("1a2b"),
+	}}))
+	setAddonOwnerReference(instance, obj)
+	g.Expect(obj.GetOwnerReferences()).To(HaveLen(1))
+
+	// The owner is used once the labels were removed
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "event-router", Name: "event-router"}}}
+	g.Expect(obj.GetLabels()).To(HaveKey(common.NameLabel))
+	obj.SetLabels(nil)
+	g.Expect(r.getAddonRequestsFromLabels(handler.MapObject{Meta: obj})).To(Equal(want))
+
+	// Manifests in other namespaces are only labeled
+	instance.Namespace = "addon-manager-system"
+	obj, _, err = r.manifestResource(instance, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(obj.GetOwnerReferences()).To(BeEmpty())
+	g.Expect(getAddonRequestsFromOwners(obj)).To(BeEmpty())
+}
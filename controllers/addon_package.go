@@ -0,0 +1,51 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// guardPackage records the package of the addon in its status and returns an error when spec.pkgName was changed
+// since, which the validating webhook rejects when it is enabled. Version changes are allowed and recorded as an event.
+func (r *AddonReconciler) guardPackage(log logr.Logger, instance *addonmgrv1alpha1.Addon) error {
+	if err := addonmgrv1alpha1.ValidatePkgNameChange(instance.Status.PkgName, instance.Spec.PkgName); err != nil {
+		return err
+	}
+	instance.Status.PkgName = instance.Spec.PkgName
+
+	if prev := instance.Status.PkgVersion; prev != "" && prev != instance.Spec.PkgVersion {
+		msg := fmt.Sprintf("Addon %s/%s package %s version changed from %s to %s.", instance.Namespace, instance.Name, instance.Spec.PkgName, prev, instance.Spec.PkgVersion)
+		r.recorder.Event(instance, "Normal", "VersionChanged", msg)
+		log.Info("Addon package version changed.", "from", prev, "to", instance.Spec.PkgVersion)
+	}
+	instance.Status.PkgVersion = instance.Spec.PkgVersion
+
+	return nil
+}
+
+// cachedPackageSpec returns the package of the addon for the version cache. The recorded package name is kept while a
+// changed spec.pkgName is rejected, so dependencies on the addon still resolve to its installed package.
+func cachedPackageSpec(instance *addonmgrv1alpha1.Addon) addonmgrv1alpha1.PackageSpec {
+	pkg := instance.GetPackageSpec()
+	if instance.Status.PkgName != "" {
+		pkg.PkgName = instance.Status.PkgName
+	}
+	return pkg
+}
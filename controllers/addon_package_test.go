@@ -0,0 +1,55 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestGuardPackage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &AddonReconciler{recorder: recorder}
+	log := zap.New(zap.UseDevMode(true))
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "my-addon", "default"
+	instance.Spec.PkgName, instance.Spec.PkgVersion = "my-addon", "1.0.0"
+
+	// The package is recorded on first reconcile
+	g.Expect(r.guardPackage(log, instance)).To(Succeed())
+	g.Expect(instance.Status.PkgName).To(Equal("my-addon"))
+	g.Expect(instance.Status.PkgVersion).To(Equal("1.0.0"))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// Version changes are allowed
+	instance.Spec.PkgVersion = "1.1.0"
+	g.Expect(r.guardPackage(log, instance)).To(Succeed())
+	g.Expect(instance.Status.PkgVersion).To(Equal("1.1.0"))
+	g.Expect(<-recorder.Events).To(Equal("Normal VersionChanged Addon default/my-addon package my-addon version changed from 1.0.0 to 1.1.0."))
+
+	// Package name changes are rejected and the recorded package is cached
+	instance.Spec.PkgName = "other-addon"
+	g.Expect(r.guardPackage(log, instance)).To(HaveOccurred())
+	g.Expect(instance.Status.PkgName).To(Equal("my-addon"))
+	g.Expect(cachedPackageSpec(instance).PkgName).To(Equal("my-addon"))
+}
@@ -0,0 +1,156 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// workflowPermissionsName is the name of the Role and RoleBinding granting the permissions of the addon workflows
+func workflowPermissionsName(addon *addonmgrv1alpha1.Addon) string {
+	return fmt.Sprintf("%s-workflow", addon.GetName())
+}
+
+// ensurePermissions grants spec.lifecycle.permissions to the workflow service account of the addon with a Role and
+// RoleBinding in the addon namespace, which are recorded in status. Permissions granted before are revoked once the
+// addon has none or moved to another namespace. A NotFound error is returned while the namespace does not exist.
+func (r *AddonReconciler) ensurePermissions(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	var granted string
+	if len(addon.Spec.Lifecycle.Permissions) > 0 {
+		granted = types.NamespacedName{Name: workflowPermissionsName(addon), Namespace: addon.Spec.Params.Namespace}.String()
+	}
+	if addon.Status.WorkflowPermissions != "" && addon.Status.WorkflowPermissions != granted {
+		if err := r.deletePermissions(ctx, addon); err != nil {
+			return err
+		}
+	}
+	if granted == "" {
+		return nil
+	}
+
+	meta := metav1.ObjectMeta{
+		Name:      workflowPermissionsName(addon),
+		Namespace: addon.Spec.Params.Namespace,
+		Labels: map[string]string{
+			common.ManagedByLabel: common.AddonGVR().Group,
+			common.NameLabel:      addon.GetName(),
+		},
+	}
+	key := types.NamespacedName{Name: meta.Name, Namespace: meta.Namespace}
+
+	role := &rbacv1.Role{}
+	err := r.apiReader.Get(ctx, key, role)
+	switch {
+	case apierrors.IsNotFound(err):
+		role = &rbacv1.Role{ObjectMeta: meta, Rules: addon.Spec.Lifecycle.Permissions}
+		if err := r.Create(ctx, role); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case !equality.Semantic.DeepEqual(role.Rules, addon.Spec.Lifecycle.Permissions):
+		role.Rules = addon.Spec.Lifecycle.Permissions
+		if err := r.Update(ctx, role); err != nil {
+			return err
+		}
+	}
+
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      addon.Spec.Lifecycle.ServiceAccount,
+		Namespace: addon.GetWorkflowNamespace(),
+	}}
+	binding := &rbacv1.RoleBinding{}
+	err = r.apiReader.Get(ctx, key, binding)
+	switch {
+	case apierrors.IsNotFound(err):
+		binding = &rbacv1.RoleBinding{
+			ObjectMeta: meta,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: meta.Name},
+			Subjects:   subjects,
+		}
+		if err := r.Create(ctx, binding); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case !equality.Semantic.DeepEqual(binding.Subjects, subjects):
+		binding.Subjects = subjects
+		if err := r.Update(ctx, binding); err != nil {
+			return err
+		}
+	}
+
+	addon.Status.WorkflowPermissions = granted
+	return nil
+}
+
+// deletePermissions revokes the permissions recorded in the addon status by deleting their Role and RoleBinding
+func (r *AddonReconciler) deletePermissions(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	if addon.Status.WorkflowPermissions == "" {
+		return nil
+	}
+
+	var key types.NamespacedName
+	key.Namespace, key.Name, _ = cache.SplitMetaNamespaceKey(addon.Status.WorkflowPermissions)
+
+	// Delete the binding first so that no binding is left referring to a deleted role
+	binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Delete(ctx, binding); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete role binding %s. %v", key, err)
+	}
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Delete(ctx, role); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete role %s. %v", key, err)
+	}
+
+	addon.Status.WorkflowPermissions = ""
+	return nil
+}
+
+// grantPermissions grants the permissions of the addon workflows, the addon fails when they cannot be granted. They
+// are granted on a later reconcile when the namespace does not exist yet, e.g. before the prereqs workflow created it.
+func (r *AddonReconciler) grantPermissions(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon) error {
+	err := r.ensurePermissions(ctx, instance)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if common.IsRetryable(err) {
+		log.Info("Addon could not grant workflow permissions, retrying.", "error", err.Error())
+		return &transientError{err: err}
+	} else if err != nil {
+		reason := fmt.Sprintf("Addon %s/%s could not grant workflow permissions. %v", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance, "Warning", "Failed", reason)
+		log.Error(err, "Addon could not grant workflow permissions.")
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+		instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
+		instance.Status.Reason = reason
+
+		return err
+	}
+	return nil
+}
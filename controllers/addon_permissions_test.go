@@ -0,0 +1,108 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestEnsurePermissions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(sch)
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	c := runtimefake.NewFakeClientWithScheme(sch)
+	r := &AddonReconciler{Client: c, apiReader: c, Scheme: sch}
+
+	instance := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "addon-manager-system"}}
+	instance.Spec.Params.Namespace = "logging"
+	instance.Spec.Lifecycle.ServiceAccount = "fluentd-installer"
+	instance.Spec.Lifecycle.Permissions = []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"daemonsets"}, Verbs: []string{"get", "create", "update"}}}
+
+	g.Expect(r.ensurePermissions(context.TODO(), instance)).To(Succeed())
+
+	key := types.NamespacedName{Name: "fluentd-workflow", Namespace: "logging"}
+	role := &rbacv1.Role{}
+	g.Expect(c.Get(context.TODO(), key, role)).To(Succeed())
+	g.Expect(role.Rules).To(Equal(instance.Spec.Lifecycle.Permissions))
+	binding := &rbacv1.RoleBinding{}
+	g.Expect(c.Get(context.TODO(), key, binding)).To(Succeed())
+	g.Expect(binding.RoleRef).To(Equal(rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "fluentd-workflow"}))
+	g.Expect(binding.Subjects).To(Equal([]rbacv1.Subject{{Kind: "ServiceAccount", Name: "fluentd-installer", Namespace: "addon-manager-system"}}))
+	g.Expect(instance.Status.WorkflowPermissions).To(Equal("logging/fluentd-workflow"))
+
+	// Changed permissions and service accounts are updated
+	instance.Spec.Lifecycle.Permissions[0].Verbs = []string{"get"}
+	instance.Spec.Lifecycle.ServiceAccount = "installer"
+	g.Expect(r.ensurePermissions(context.TODO(), instance)).To(Succeed())
+	g.Expect(c.Get(context.TODO(), key, role)).To(Succeed())
+	g.Expect(role.Rules[0].Verbs).To(Equal([]string{"get"}))
+	g.Expect(c.Get(context.TODO(), key, binding)).To(Succeed())
+	g.Expect(binding.Subjects[0].Name).To(Equal("installer"))
+
+	// Permissions are moved with the addon namespace
+	instance.Spec.Params.Namespace = "monitoring"
+	g.Expect(r.ensurePermissions(context.TODO(), instance)).To(Succeed())
+	g.Expect(apierrors.IsNotFound(c.Get(context.TODO(), key, role))).To(BeTrue())
+	g.Expect(apierrors.IsNotFound(c.Get(context.TODO(), key, binding))).To(BeTrue())
+	key.Namespace = "monitoring"
+	g.Expect(c.Get(context.TODO(), key, role)).To(Succeed())
+	g.Expect(instance.Status.WorkflowPermissions).To(Equal("monitoring/fluentd-workflow"))
+
+	// Removed permissions are revoked
+	instance.Spec.Lifecycle.Permissions = nil
+	g.Expect(r.ensurePermissions(context.TODO(), instance)).To(Succeed())
+	g.Expect(apierrors.IsNotFound(c.Get(context.TODO(), key, role))).To(BeTrue())
+	g.Expect(apierrors.IsNotFound(c.Get(context.TODO(), key, binding))).To(BeTrue())
+	g.Expect(instance.Status.WorkflowPermissions).To(BeEmpty())
+}
+
+func TestGrantPermissions_Forbidden(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	// Roles are not known to the client, like a controller missing the permissions to grant them
+	c := runtimefake.NewFakeClientWithScheme(sch)
+	recorder := record.NewFakeRecorder(10)
+	r := &AddonReconciler{Client: c, apiReader: c, Scheme: sch, recorder: recorder}
+
+	instance := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "addon-manager-system"}}
+	instance.Spec.Params.Namespace = "logging"
+	instance.Spec.Lifecycle.ServiceAccount = "fluentd-installer"
+	instance.Spec.Lifecycle.Permissions = []rbacv1.PolicyRule{{Resources: []string{"configmaps"}, Verbs: []string{"get"}}}
+
+	g.Expect(r.grantPermissions(context.TODO(), zap.New(zap.UseDevMode(true)), instance)).NotTo(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("could not grant workflow permissions"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("could not grant workflow permissions"))
+}
@@ -0,0 +1,58 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
+)
+
+// registerPhaseMetrics registers the addon lifecycle phase metric listed from the cached addons and labeled with the
+// MetricsLabelKeys, it is registered once per process as the metrics registry is global
+func (r *AddonReconciler) registerPhaseMetrics() error {
+	collector, err := metrics.NewAddonPhaseCollector(r.MetricsLabelKeys, r.addonPhases)
+	if err != nil {
+		return err
+	}
+	err = ctrlmetrics.Registry.Register(collector)
+	if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		return nil
+	}
+	return err
+}
+
+// addonPhases returns the install phase and common labels of the cached addons
+func (r *AddonReconciler) addonPhases() ([]metrics.AddonPhase, error) {
+	var list = &addonmgrv1alpha1.AddonList{}
+	if err := r.Client.List(context.Background(), list); err != nil {
+		return nil, err
+	}
+
+	var phases = make([]metrics.AddonPhase, 0, len(list.Items))
+	for _, a := range list.Items {
+		phases = append(phases, metrics.AddonPhase{
+			Name:      a.Name,
+			Namespace: a.Namespace,
+			Phase:     string(a.Status.Lifecycle.Installed),
+			Labels:    a.Spec.CommonLabels,
+		})
+	}
+	return phases, nil
+}
@@ -0,0 +1,57 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/oci"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+// postInstall runs the post-install workflow of an addon whose install succeeded and returns the install phase, which
+// stays Pending until the post-install workflow succeeded. A failed post-install fails the install with the reason of
+// the post-install workflow.
+func (r *AddonReconciler) postInstall(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	phase, err := r.runWorkflow(addonmgrv1alpha1.PostInstall, instance, wfl, nil)
+	if oci.IsPullError(err) {
+		instance.Status.Lifecycle.PostInstall = addonmgrv1alpha1.Pending
+		r.templatePullFailed(log, instance, addonmgrv1alpha1.PostInstall, err)
+		return addonmgrv1alpha1.Pending, err
+	}
+	if err != nil {
+		reason := fmt.Sprintf("Addon %s/%s post-install failed. %v", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance, "Warning", "PostInstallFailed", reason)
+		workflowLogger(log, instance, addonmgrv1alpha1.PostInstall).Error(err, "Addon post-install workflow failed.")
+		instance.Status.Lifecycle.PostInstall = addonmgrv1alpha1.Failed
+		instance.Status.Reason = reason
+
+		return addonmgrv1alpha1.Failed, err
+	}
+	instance.Status.Lifecycle.PostInstall = phase
+
+	if phase == addonmgrv1alpha1.Failed {
+		reason := r.workflowFailureReason(ctx, log, instance, wfl, addonmgrv1alpha1.PostInstall)
+		r.recorder.Event(instance, "Warning", "PostInstallFailed", reason)
+		workflowLogger(log, instance, addonmgrv1alpha1.PostInstall).Info("Addon post-install workflow failed.", "reason", reason)
+		instance.Status.Reason = reason
+	}
+
+	return phase, nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// phasedLifecycle reports the same phase for all of its workflows
+type phasedLifecycle struct {
+	failedLifecycle
+	phase addonmgrv1alpha1.ApplicationAssemblyPhase
+}
+
+func (f *phasedLifecycle) Install(context.Context, *addonmgrv1alpha1.WorkflowType, string, map[string]string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	return f.phase, nil
+}
+
+func TestInstallCompleted_PostInstall(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{Log: log, recorder: recorder}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Lifecycle.PostInstall.Template = "kind: Workflow"
+	instance.Status.StartTime = 1622851200000
+
+	// Install stays Pending while the post-install workflow runs
+	wfl := &phasedLifecycle{phase: addonmgrv1alpha1.Pending}
+	g.Expect(r.installCompleted(context.TODO(), log, instance, wfl, addonmgrv1alpha1.Install, addonmgrv1alpha1.Succeeded, nil, nil)).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.Lifecycle.PostInstall).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.CompletionTime).To(BeZero())
+
+	// A failed post-install fails the install with its own reason
+	recorder = record.NewFakeRecorder(10)
+	r.recorder = recorder
+	wfl = &phasedLifecycle{phase: addonmgrv1alpha1.Failed}
+	wfl.failure = "step smoke-test (template curl): Error (exit code 7)"
+	g.Expect(r.installCompleted(context.TODO(), log, instance, wfl, addonmgrv1alpha1.Install, addonmgrv1alpha1.Succeeded, nil, nil)).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.Lifecycle.PostInstall).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.Reason).To(Equal("Addon addon-manager-system/fluentd postinstall workflow " + instance.GetFormattedWorkflowName(addonmgrv1alpha1.PostInstall) + " failed. " + wfl.failure))
+	g.Expect(<-recorder.Events).To(ContainSubstring("Completed Postinstall workflow"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("PostInstallFailed"))
+
+	// The addon is installed once the post-install workflow succeeded
+	wfl = &phasedLifecycle{phase: addonmgrv1alpha1.Succeeded}
+	g.Expect(r.installCompleted(context.TODO(), log, instance, wfl, addonmgrv1alpha1.Install, addonmgrv1alpha1.Succeeded, nil, nil)).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(instance.Status.Lifecycle.PostInstall).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(instance.Status.CompletionTime).NotTo(BeZero())
+
+	// Nothing runs after a failed install
+	instance.Status.Lifecycle.PostInstall = ""
+	g.Expect(r.installCompleted(context.TODO(), log, instance, wfl, addonmgrv1alpha1.Install, addonmgrv1alpha1.Failed, nil, nil)).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.Lifecycle.PostInstall).To(BeEmpty())
+}
@@ -0,0 +1,384 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+const (
+	// priorityHandOffPoll is how often the priority queue checks if the controller queue can take the next addon
+	priorityHandOffPoll = 10 * time.Millisecond
+	// maxConcurrentReconciles is the number of addons reconciled at the same time, the controller-runtime default
+	maxConcurrentReconciles = 1
+)
+
+// queuedByPriority drops the events of the addon watch of the controller builder, addon events are queued by priority
+// by another watch instead
+var queuedByPriority = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// priorityQueue is a workqueue.Interface handing out the item with the highest priority first, items of equal priority
+// in the order they were added. Like workqueue.Type an item is queued at most once, and an item added while it is
+// processed is queued again once it is done.
+type priorityQueue struct {
+	cond     *sync.Cond
+	priority func(item interface{}) int
+
+	items        priorityItems
+	seq          uint64
+	dirty        map[interface{}]int
+	processing   map[interface{}]struct{}
+	shuttingDown bool
+}
+
+// newPriorityQueue returns a queue ordered by the priority of the items when they are added
+func newPriorityQueue(priority func(item interface{}) int) *priorityQueue {
+	return &priorityQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		priority:   priority,
+		dirty:      map[interface{}]int{},
+		processing: map[interface{}]struct{}{},
+	}
+}
+
+// Add queues the item unless it is queued already
+func (q *priorityQueue) Add(item interface{}) {
+	// Priorities are looked up outside of the lock
+	p := q.priority(item)
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if _, ok := q.dirty[item]; ok {
+		return
+	}
+
+	q.dirty[item] = p
+	if _, ok := q.processing[item]; ok {
+		return
+	}
+	q.push(item, p)
+	q.cond.Signal()
+}
+
+// Len returns the number of queued items
+func (q *priorityQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.items.Len()
+}
+
+// Get blocks until it can return the item with the highest priority, shutdown is true once the queue is shut down
+func (q *priorityQueue) Get() (item interface{}, shutdown bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for q.items.Len() == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if q.items.Len() == 0 {
+		return nil, true
+	}
+
+	item = heap.Pop(&q.items).(*priorityItem).item
+	q.processing[item] = struct{}{}
+	delete(q.dirty, item)
+	return item, false
+}
+
+// Done marks the item as processed, it is queued again if it was added while it was processed
+func (q *priorityQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	delete(q.processing, item)
+	if p, ok := q.dirty[item]; ok {
+		q.push(item, p)
+		q.cond.Signal()
+	}
+}
+
+// ShutDown stops the queue from accepting items and releases the workers waiting on it
+func (q *priorityQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+// ShuttingDown returns true once the queue is shut down
+func (q *priorityQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
+
+func (q *priorityQueue) push(item interface{}, priority int) {
+	q.seq++
+	heap.Push(&q.items, &priorityItem{item: item, priority: priority, seq: q.seq})
+}
+
+type priorityItem struct {
+	item     interface{}
+	priority int
+	seq      uint64
+}
+
+// priorityItems is a heap of queued items ordered by priority, then by the order they were added
+type priorityItems []*priorityItem
+
+func (h priorityItems) Len() int { return len(h) }
+
+func (h priorityItems) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityItems) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *priorityItems) Push(x interface{}) { *h = append(*h, x.(*priorityItem)) }
+
+func (h *priorityItems) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// rateLimitingQueue adds rate limited requeues to a delaying queue, like the queue of workqueue.NewRateLimitingQueue
+type rateLimitingQueue struct {
+	workqueue.DelayingInterface
+	rateLimiter workqueue.RateLimiter
+}
+
+// newPriorityRateLimitingQueue returns a rate limiting queue which hands out items by priority
+func newPriorityRateLimitingQueue(rateLimiter workqueue.RateLimiter, name string, priority func(item interface{}) int) workqueue.RateLimitingInterface {
+	return &rateLimitingQueue{
+		DelayingInterface: workqueue.NewDelayingQueueWithCustomQueue(newPriorityQueue(priority), name),
+		rateLimiter:       rateLimiter,
+	}
+}
+
+// AddRateLimited adds the item once the rate limiter allows it
+func (q *rateLimitingQueue) AddRateLimited(item interface{}) {
+	q.DelayingInterface.AddAfter(item, q.rateLimiter.When(item))
+}
+
+// NumRequeues returns how often the item was requeued with rate limiting
+func (q *rateLimitingQueue) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+// Forget resets the rate limiting of the item
+func (q *rateLimitingQueue) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}
+
+// priorityFront queues the requests of the controller by addon priority. The controller-runtime version in use does
+// not allow to replace the controller queue, requests are queued here first and handed to the controller queue once it
+// has fewer queued requests than workers, so that a backlog is kept and ordered in front of the controller.
+type priorityFront struct {
+	workqueue.RateLimitingInterface
+	workers int
+
+	mu     sync.Mutex
+	target workqueue.RateLimitingInterface
+}
+
+// newPriorityFront returns a priority queue feeding a controller with the given number of concurrent reconciles
+func newPriorityFront(name string, workers int, priority func(item interface{}) int) *priorityFront {
+	return &priorityFront{
+		RateLimitingInterface: newPriorityRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name, priority),
+		workers:               workers,
+	}
+}
+
+// wrap returns a queue for event handlers which queues requests by priority in front of the controller queue q
+func (f *priorityFront) wrap(q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.target = q
+	return &frontQueue{RateLimitingInterface: q, front: f}
+}
+
+// controllerQueue returns the controller queue the requests are handed to, it is known once a handler queued a request
+func (f *priorityFront) controllerQueue() workqueue.RateLimitingInterface {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.target
+}
+
+// Start hands the queued requests to the controller queue by priority until stop is closed
+func (f *priorityFront) Start(stop <-chan struct{}) error {
+	go func() {
+		<-stop
+		f.ShutDown()
+	}()
+
+	for f.waitForRoom(stop) {
+		item, shutdown := f.Get()
+		if shutdown {
+			return nil
+		}
+		f.controllerQueue().Add(item)
+		f.Done(item)
+	}
+	return nil
+}
+
+// waitForRoom waits until the controller queue has fewer queued requests than workers, the next request is only taken
+// once there is room for it so that requests queued in the meantime are still ordered by priority
+func (f *priorityFront) waitForRoom(stop <-chan struct{}) bool {
+	ticker := time.NewTicker(priorityHandOffPoll)
+	defer ticker.Stop()
+	for {
+		if q := f.controllerQueue(); q != nil && q.Len() < f.workers {
+			return true
+		}
+		select {
+		case <-stop:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// frontQueue is the controller queue as seen by event handlers, added requests are queued by priority first
+type frontQueue struct {
+	workqueue.RateLimitingInterface
+	front *priorityFront
+}
+
+// Add queues the item by priority
+func (q *frontQueue) Add(item interface{}) {
+	q.front.Add(item)
+}
+
+// AddAfter queues the item by priority once the duration passed
+func (q *frontQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.front.AddAfter(item, duration)
+}
+
+// priorityHandler records the priority of addons from their events and queues the requests of the event handler by
+// priority
+type priorityHandler struct {
+	handler.EventHandler
+	r *AddonReconciler
+}
+
+// prioritized returns the event handler queueing its requests on the priority queue of the reconciler
+func (r *AddonReconciler) prioritized(h handler.EventHandler) handler.EventHandler {
+	return &priorityHandler{EventHandler: h, r: r}
+}
+
+// Create queues the requests of created objects
+func (h *priorityHandler) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.r.recordPriority(e.Object)
+	h.EventHandler.Create(e, h.r.priorityQueue.wrap(q))
+}
+
+// Update queues the requests of updated objects
+func (h *priorityHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.r.recordPriority(e.ObjectNew)
+	h.EventHandler.Update(e, h.r.priorityQueue.wrap(q))
+}
+
+// Delete queues the requests of deleted objects
+func (h *priorityHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Delete(e, h.r.priorityQueue.wrap(q))
+}
+
+// Generic queues the requests of generic events
+func (h *priorityHandler) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.r.recordPriority(e.Object)
+	h.EventHandler.Generic(e, h.r.priorityQueue.wrap(q))
+}
+
+// priorityReconciler queues the requeues of the addon reconciler by priority, failed reconciles are retried with the
+// backoff of the controller
+type priorityReconciler struct {
+	reconcile.Reconciler
+	queue *priorityFront
+}
+
+// Reconcile reconciles the request and queues it again by priority when requested
+func (p *priorityReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	result, err := p.Reconciler.Reconcile(req)
+	if err != nil {
+		return result, err
+	}
+
+	switch {
+	case result.RequeueAfter > 0:
+		p.queue.Forget(req)
+		p.queue.AddAfter(req, result.RequeueAfter)
+	case result.Requeue:
+		p.queue.AddRateLimited(req)
+	default:
+		p.queue.Forget(req)
+	}
+	return reconcile.Result{}, nil
+}
+
+// addonPriority returns the priority of the addon of a reconcile request as recorded from its events, addons without
+// events yet have the default priority
+func (r *AddonReconciler) addonPriority(item interface{}) int {
+	req, ok := item.(reconcile.Request)
+	if !ok {
+		return 0
+	}
+	if p, ok := r.priorities.Load(req.NamespacedName); ok {
+		return p.(int)
+	}
+	return 0
+}
+
+// recordPriority keeps the priority of the addon of an event, requests are queued by priority without reading the
+// addon again
+func (r *AddonReconciler) recordPriority(obj runtime.Object) {
+	a, ok := obj.(*addonmgrv1alpha1.Addon)
+	if !ok {
+		return
+	}
+
+	name := types.NamespacedName{Name: a.Name, Namespace: a.Namespace}
+	if a.Spec.Priority == 0 {
+		r.priorities.Delete(name)
+		return
+	}
+	r.priorities.Store(name, a.Spec.Priority)
+}
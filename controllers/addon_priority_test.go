@@ -0,0 +1,171 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestPriorityQueue_SaturatedOrder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	priorities := map[string]int{"cni": 100, "dns": 50, "metrics-server": 0, "dashboard": -10, "fluentd": 0}
+	q := newPriorityQueue(func(item interface{}) int { return priorities[item.(string)] })
+
+	// The queue is backlogged while the worker processes another addon
+	q.Add("fluentd")
+	item, _ := q.Get()
+	g.Expect(item).To(Equal("fluentd"))
+	for _, name := range []string{"dashboard", "metrics-server", "dns", "fluentd", "cni", "dns"} {
+		q.Add(name)
+	}
+	// Queued items are not added twice, fluentd is queued again once it is done
+	g.Expect(q.Len()).To(Equal(4))
+	q.Done("fluentd")
+	g.Expect(q.Len()).To(Equal(5))
+
+	var order []interface{}
+	for q.Len() > 0 {
+		item, shutdown := q.Get()
+		g.Expect(shutdown).To(BeFalse())
+		order = append(order, item)
+		q.Done(item)
+	}
+	g.Expect(order).To(Equal([]interface{}{"cni", "dns", "metrics-server", "fluentd", "dashboard"}))
+
+	q.ShutDown()
+	_, shutdown := q.Get()
+	g.Expect(shutdown).To(BeTrue())
+	g.Expect(q.ShuttingDown()).To(BeTrue())
+}
+
+func TestPriorityFront_SaturatedOrder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := &AddonReconciler{}
+	r.priorityQueue = newPriorityFront("addon", 1, r.addonPriority)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() { _ = r.priorityQueue.Start(stop) }()
+
+	controllerQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "controller")
+	defer controllerQueue.ShutDown()
+	h := r.prioritized(&handler.EnqueueRequestForObject{})
+	create := func(name string, priority int) {
+		a := &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "addon-manager-system"},
+			Spec:       addonmgrv1alpha1.AddonSpec{Priority: priority},
+		}
+		h.Create(event.CreateEvent{Meta: a, Object: a}, controllerQueue)
+	}
+
+	// The controller queue is saturated by the first addon, the others are backlogged in front of it
+	create("fluentd", 0)
+	g.Eventually(controllerQueue.Len).Should(Equal(1))
+	create("dashboard", -10)
+	create("metrics-server", 0)
+	create("dns", 50)
+	create("cni", 100)
+	g.Eventually(r.priorityQueue.Len).Should(Equal(4))
+	g.Consistently(controllerQueue.Len, 5*priorityHandOffPoll).Should(Equal(1))
+
+	var order []string
+	for len(order) < 5 {
+		item, shutdown := controllerQueue.Get()
+		g.Expect(shutdown).To(BeFalse())
+		order = append(order, item.(reconcile.Request).Name)
+		controllerQueue.Done(item)
+	}
+	g.Expect(order).To(Equal([]string{"fluentd", "cni", "dns", "metrics-server", "dashboard"}))
+}
+
+type resultReconciler struct {
+	result reconcile.Result
+	err    error
+}
+
+func (r *resultReconciler) Reconcile(reconcile.Request) (reconcile.Result, error) {
+	return r.result, r.err
+}
+
+func TestPriorityReconciler_Requeue(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	q := newPriorityFront("addon", 1, func(interface{}) int { return 0 })
+	defer q.ShutDown()
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "addon-manager-system", Name: "cni"}}
+	inner := &resultReconciler{}
+	p := &priorityReconciler{Reconciler: inner, queue: q}
+
+	// Requeues are queued by priority instead of by the controller
+	inner.result = reconcile.Result{Requeue: true}
+	res, err := p.Reconcile(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res).To(Equal(reconcile.Result{}))
+	g.Expect(q.NumRequeues(req)).To(Equal(1))
+	g.Eventually(q.Len).Should(Equal(1))
+	item, _ := q.Get()
+	q.Done(item)
+
+	inner.result = reconcile.Result{RequeueAfter: 10 * time.Millisecond}
+	res, err = p.Reconcile(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res).To(Equal(reconcile.Result{}))
+	g.Expect(q.NumRequeues(req)).To(BeZero())
+	g.Eventually(q.Len).Should(Equal(1))
+	item, _ = q.Get()
+	q.Done(item)
+
+	// Failed reconciles are retried with the backoff of the controller
+	inner.result, inner.err = reconcile.Result{}, errors.New("failed")
+	_, err = p.Reconcile(req)
+	g.Expect(err).To(HaveOccurred())
+	g.Consistently(q.Len, 5*priorityHandOffPoll).Should(BeZero())
+}
+
+func TestAddonPriority(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := &AddonReconciler{}
+	key := types.NamespacedName{Namespace: "kube-system", Name: "cni"}
+	a := &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec:       addonmgrv1alpha1.AddonSpec{Priority: 100},
+	}
+
+	// Addons without events yet have the default priority
+	g.Expect(r.addonPriority(reconcile.Request{NamespacedName: key})).To(BeZero())
+
+	r.recordPriority(a)
+	g.Expect(r.addonPriority(reconcile.Request{NamespacedName: key})).To(Equal(100))
+	g.Expect(r.addonPriority("kube-system/cni")).To(BeZero())
+
+	a.Spec.Priority = 0
+	r.recordPriority(a)
+	_, ok := r.priorities.Load(key)
+	g.Expect(ok).To(BeFalse())
+}
@@ -0,0 +1,56 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// DefaultQuarantineAfter is the default number of consecutive failed reconciles after which an addon is quarantined,
+// with the backoff of failed reconciles this is about an hour of retries
+const DefaultQuarantineAfter = 20
+
+// trackFailure counts the consecutive reconciles which failed the addon and quarantines it after QuarantineAfter of
+// them. It returns true when the addon is quarantined, it is not retried until its spec changes or it is force
+// reinstalled.
+func (r *AddonReconciler) trackFailure(log logr.Logger, instance *addonmgrv1alpha1.Addon, err error) bool {
+	switch {
+	case err != nil && instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Failed:
+		instance.Status.FailedAttempts++
+	case instance.Status.Lifecycle.Installed == addonmgrv1alpha1.Succeeded:
+		instance.Status.FailedAttempts = 0
+		return false
+	default:
+		return false
+	}
+
+	if r.QuarantineAfter <= 0 || instance.Status.FailedAttempts < r.QuarantineAfter {
+		return false
+	}
+
+	reason := fmt.Sprintf("Addon %s/%s is quarantined after %d consecutive failures and is not retried, edit the spec or set annotation %s to retry it. %s",
+		instance.Namespace, instance.Name, instance.Status.FailedAttempts, common.ForceReinstallAnnotation, instance.Status.Reason)
+	r.recorder.Event(instance, "Warning", "Quarantined", reason)
+	log.Info("Addon is quarantined", "failedAttempts", instance.Status.FailedAttempts)
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Quarantined
+	instance.Status.Reason = reason
+
+	return true
+}
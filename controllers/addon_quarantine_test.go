@@ -0,0 +1,97 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
+)
+
+func TestTrackFailure(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{Log: log, recorder: recorder, QuarantineAfter: 3}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+	instance.Status.Reason = "install failed"
+
+	err := fmt.Errorf("install failed")
+	g.Expect(r.trackFailure(log, instance, err)).To(BeFalse())
+	g.Expect(r.trackFailure(log, instance, err)).To(BeFalse())
+
+	// Reconciles of failed addons which do not fail again are not counted
+	g.Expect(r.trackFailure(log, instance, nil)).To(BeFalse())
+	g.Expect(instance.Status.FailedAttempts).To(Equal(2))
+
+	g.Expect(r.trackFailure(log, instance, err)).To(BeTrue())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Quarantined))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("quarantined after 3 consecutive failures"))
+	g.Expect(instance.Status.Reason).To(HaveSuffix("install failed"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("addonmgr.keikoproj.io/force-reinstall"))
+
+	// A successful install resets the count
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Succeeded
+	g.Expect(r.trackFailure(log, instance, nil)).To(BeFalse())
+	g.Expect(instance.Status.FailedAttempts).To(BeZero())
+
+	// Quarantine can be disabled
+	r.QuarantineAfter = 0
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+	for i := 0; i < 5; i++ {
+		g.Expect(r.trackFailure(log, instance, err)).To(BeFalse())
+	}
+}
+
+func TestProcessAddon_Quarantined(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{Log: log, recorder: record.NewFakeRecorder(10)}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.PkgName = "fluentd"
+	instance.Status.Checksum = instance.CalculateChecksum()
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Quarantined
+	instance.Status.FailedAttempts = 20
+	instance.Status.Resources = []addonmgrv1alpha1.ObjectStatus{{Kind: "Deployment", Name: "fluentd"}}
+
+	// Quarantined addons are not retried
+	result, err := r.processAddon(context.TODO(), log, instance, &fakeLifecycle{}, metrics.NewPhaseTimings())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue || result.RequeueAfter > 0).To(BeFalse())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Quarantined))
+	g.Expect(instance.Status.Resources).To(HaveLen(1))
+
+	// A spec change clears the quarantine
+	instance.Spec.PkgVersion = "v2"
+	result, err = r.processAddon(context.TODO(), log, instance, &fakeLifecycle{}, metrics.NewPhaseTimings())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue).To(BeTrue())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.FailedAttempts).To(BeZero())
+}
@@ -0,0 +1,102 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultReconcilesPerMinute is the default number of reconciles allowed per minute for a single addon
+const DefaultReconcilesPerMinute = 60
+
+const (
+	reconcileBurst           = 10
+	rateLimitedEventInterval = time.Minute
+)
+
+// addonRateLimiter is a token bucket per addon, addons do not share tokens
+type addonRateLimiter struct {
+	sync.Mutex
+	limit    rate.Limit
+	limiters map[types.NamespacedName]*rate.Limiter
+	// last time a rate limited event was recorded for the addon
+	events map[types.NamespacedName]time.Time
+}
+
+func newAddonRateLimiter(perMinute int) *addonRateLimiter {
+	return &addonRateLimiter{
+		limit:    rate.Limit(float64(perMinute) / 60),
+		limiters: map[types.NamespacedName]*rate.Limiter{},
+		events:   map[types.NamespacedName]time.Time{},
+	}
+}
+
+// Allow returns true if the addon can be reconciled now, otherwise how long to wait for the next token. A nil limiter,
+// e.g. of a reconciler which was not set up with a manager, allows all reconciles.
+func (l *addonRateLimiter) Allow(name types.NamespacedName) (bool, time.Duration) {
+	if l == nil || l.limit <= 0 {
+		return true, 0
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	lim, ok := l.limiters[name]
+	if !ok {
+		lim = rate.NewLimiter(l.limit, reconcileBurst)
+		l.limiters[name] = lim
+	}
+
+	if lim.Allow() {
+		return true, 0
+	}
+
+	return false, time.Duration(float64(time.Second) / float64(l.limit))
+}
+
+// ShouldRecordEvent returns true at most once per rateLimitedEventInterval for the addon
+func (l *addonRateLimiter) ShouldRecordEvent(name types.NamespacedName) bool {
+	if l == nil {
+		return false
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+	if last, ok := l.events[name]; ok && now.Sub(last) < rateLimitedEventInterval {
+		return false
+	}
+	l.events[name] = now
+
+	return true
+}
+
+// Forget removes the limiter state of a deleted addon
+func (l *addonRateLimiter) Forget(name types.NamespacedName) {
+	if l == nil {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	delete(l.limiters, name)
+	delete(l.events, name)
+}
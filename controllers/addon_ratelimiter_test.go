@@ -0,0 +1,71 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestAddonRateLimiter(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	l := newAddonRateLimiter(60)
+	hot := types.NamespacedName{Namespace: "default", Name: "hot"}
+	other := types.NamespacedName{Namespace: "default", Name: "other"}
+
+	for i := 0; i < reconcileBurst; i++ {
+		ok, _ := l.Allow(hot)
+		g.Expect(ok).To(BeTrue())
+	}
+
+	ok, delay := l.Allow(hot)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(delay).To(Equal(time.Second))
+
+	// Other addons keep their own tokens
+	ok, _ = l.Allow(other)
+	g.Expect(ok).To(BeTrue())
+
+	g.Expect(l.ShouldRecordEvent(hot)).To(BeTrue())
+	g.Expect(l.ShouldRecordEvent(hot)).To(BeFalse())
+
+	l.Forget(hot)
+	ok, _ = l.Allow(hot)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(l.ShouldRecordEvent(hot)).To(BeTrue())
+}
+
+func TestAddonRateLimiter_Disabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	l := newAddonRateLimiter(0)
+	for i := 0; i < 100; i++ {
+		ok, _ := l.Allow(types.NamespacedName{Namespace: "default", Name: "addon"})
+		g.Expect(ok).To(BeTrue())
+	}
+
+	// Reconcilers which were not set up with a manager have no limiter
+	var unset *addonRateLimiter
+	name := types.NamespacedName{Namespace: "default", Name: "addon"}
+	ok, delay := unset.Allow(name)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(delay).To(BeZero())
+	g.Expect(unset.ShouldRecordEvent(name)).To(BeFalse())
+	unset.Forget(name)
+}
@@ -0,0 +1,67 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+// forceReinstall deletes the prereqs and install or combined workflows and the post-install workflow of the current spec
+// and removes the force reinstall annotation, it returns true when the addon should be installed again.
+func (r *AddonReconciler) forceReinstall(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) (bool, error) {
+	if _, ok := instance.GetAnnotations()[common.ForceReinstallAnnotation]; !ok {
+		return false, nil
+	}
+
+	steps := []addonmgrv1alpha1.LifecycleStep{addonmgrv1alpha1.Prereqs, addonmgrv1alpha1.Install}
+	if instance.RunsCombinedWorkflow() {
+		steps = []addonmgrv1alpha1.LifecycleStep{addonmgrv1alpha1.Combined}
+	}
+	if instance.Spec.Lifecycle.PostInstall.Template != "" {
+		steps = append(steps, addonmgrv1alpha1.PostInstall)
+	}
+	for _, step := range steps {
+		if err := wfl.Delete(ctx, instance.GetFormattedWorkflowName(step)); ignoreNotFound(err) != nil {
+			return false, err
+		}
+	}
+
+	// Outputs of the deleted prereqs workflow must not be passed to the new install workflow
+	if len(instance.Spec.Lifecycle.Prereqs.Outputs) > 0 {
+		if err := r.deletePrereqsOutputs(ctx, instance); err != nil {
+			return false, err
+		}
+	}
+
+	// Keep the computed status, the update returns the persisted one
+	status := instance.Status
+	delete(instance.Annotations, common.ForceReinstallAnnotation)
+	if err := r.Update(ctx, instance); err != nil {
+		return false, err
+	}
+	instance.Status = status
+
+	r.recorder.Event(instance, "Normal", "ForceReinstall", fmt.Sprintf("Addon %s/%s workflows were deleted to reinstall the addon.", instance.Namespace, instance.Name))
+	log.Info("Addon is force reinstalled")
+
+	return true, nil
+}
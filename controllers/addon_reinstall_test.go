@@ -0,0 +1,107 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// fakeLifecycle records deleted workflows, none of the workflows exist
+type fakeLifecycle struct {
+	deleted []string
+}
+
+func (f *fakeLifecycle) Install(context.Context, *addonmgrv1alpha1.WorkflowType, string, map[string]string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	return addonmgrv1alpha1.Pending, nil
+}
+
+func (f *fakeLifecycle) Delete(_ context.Context, name string) error {
+	f.deleted = append(f.deleted, name)
+	return apierrors.NewNotFound(schema.GroupResource{Group: "argoproj.io", Resource: "workflows"}, name)
+}
+
+func (f *fakeLifecycle) Outputs(context.Context, string) (map[string]string, error) {
+	return nil, nil
+}
+
+func (f *fakeLifecycle) Failure(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeLifecycle) TemplatePhases(context.Context, string) (map[string]addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	return nil, nil
+}
+
+func (f *fakeLifecycle) Prune(context.Context, addonmgrv1alpha1.LifecycleStep, int) error {
+	return nil
+}
+
+func TestForceReinstall(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	key := types.NamespacedName{Namespace: "default", Name: "my-addon"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        key.Name,
+			Namespace:   key.Namespace,
+			Annotations: map[string]string{common.ForceReinstallAnnotation: "true"},
+		},
+	})
+	r := &AddonReconciler{
+		Client:   c,
+		Log:      zap.New(zap.UseDevMode(true)),
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+	instance.Status.Checksum = instance.CalculateChecksum()
+
+	wfl := &fakeLifecycle{}
+	forced, err := r.forceReinstall(context.TODO(), r.Log, instance, wfl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(forced).To(BeTrue())
+	g.Expect(wfl.deleted).To(Equal([]string{
+		instance.GetFormattedWorkflowName(addonmgrv1alpha1.Prereqs),
+		instance.GetFormattedWorkflowName(addonmgrv1alpha1.Install),
+	}))
+	g.Expect(instance.Status.Checksum).To(Equal(instance.CalculateChecksum()))
+
+	// The annotation is removed so that the addon is reinstalled once
+	var persisted = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, persisted)).To(Succeed())
+	g.Expect(persisted.GetAnnotations()).NotTo(HaveKey(common.ForceReinstallAnnotation))
+
+	forced, err = r.forceReinstall(context.TODO(), r.Log, persisted, wfl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(forced).To(BeFalse())
+}
@@ -0,0 +1,86 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// replicaWorkloads are the resources of the workload kinds replica overrides scale
+var replicaWorkloads = map[string]schema.GroupVersionResource{
+	"Deployment":  {Group: "apps", Version: "v1", Resource: "deployments"},
+	"StatefulSet": {Group: "apps", Version: "v1", Resource: "statefulsets"},
+}
+
+// scaleWorkloads sets the replica counts of the replica overrides on workloads which run a different count and returns
+// the scaled workloads. Workloads which do not exist are skipped, missing resources are reported by drift detection.
+func (r *AddonReconciler) scaleWorkloads(ctx context.Context, a *addonmgrv1alpha1.Addon) ([]string, error) {
+	var scaled []string
+	for _, o := range a.Spec.Replicas {
+		namespace := o.Namespace
+		if namespace == "" {
+			namespace = a.Spec.Params.Namespace
+		}
+		resource := r.dynClient.Resource(replicaWorkloads[o.Kind]).Namespace(namespace)
+
+		obj, err := resource.Get(ctx, o.Name, metav1.GetOptions{})
+		if ignoreNotFound(err) != nil {
+			return scaled, fmt.Errorf("%s %s/%s could not be read. %v", o.Kind, namespace, o.Name, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if found && replicas == int64(o.Replicas) {
+			continue
+		}
+
+		patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, o.Replicas))
+		if _, err := resource.Patch(ctx, o.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return scaled, fmt.Errorf("%s %s/%s could not be scaled. %v", o.Kind, namespace, o.Name, err)
+		}
+		scaled = append(scaled, fmt.Sprintf("%s %s/%s to %d", o.Kind, namespace, o.Name, o.Replicas))
+	}
+	return scaled, nil
+}
+
+// applyReplicas scales the workloads of the installed addon to their replica overrides. Scaling is retried on the
+// next reconcile, a workload which could not be scaled does not fail the addon.
+func (r *AddonReconciler) applyReplicas(ctx context.Context, log logr.Logger, a *addonmgrv1alpha1.Addon) {
+	if len(a.Spec.Replicas) == 0 {
+		return
+	}
+
+	scaled, err := r.scaleWorkloads(ctx, a)
+	if len(scaled) > 0 {
+		r.recorder.Event(a, "Normal", "Scaled", fmt.Sprintf("Addon %s/%s scaled %s.", a.Namespace, a.Name, strings.Join(scaled, ", ")))
+		log.Info("Addon workloads were scaled to their replica overrides.", "scaled", scaled)
+	}
+	if err != nil {
+		r.recorder.Event(a, "Warning", "Failed", fmt.Sprintf("Addon %s/%s workloads could not be scaled. %v", a.Namespace, a.Name, err))
+		log.Error(err, "Addon workloads could not be scaled.")
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestScaleWorkloads(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetName("fluentd-aggregator")
+	deployment.SetNamespace("logging")
+	g.Expect(unstructured.SetNestedField(deployment.Object, int64(1), "spec", "replicas")).To(Succeed())
+
+	r := &AddonReconciler{
+		dynClient: dynfake.NewSimpleDynamicClient(runtime.NewScheme(), deployment),
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Params.Namespace = "logging"
+	instance.Spec.Replicas = []addonmgrv1alpha1.ReplicaOverride{
+		{Kind: "Deployment", Name: "fluentd-aggregator", Replicas: 3},
+		// Workloads which do not exist are skipped
+		{Kind: "StatefulSet", Name: "fluentd-buffer", Replicas: 2},
+	}
+
+	scaled, err := r.scaleWorkloads(context.TODO(), instance)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(scaled).To(Equal([]string{"Deployment logging/fluentd-aggregator to 3"}))
+
+	obj, err := r.dynClient.Resource(replicaWorkloads["Deployment"]).Namespace("logging").Get(context.TODO(), "fluentd-aggregator", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	g.Expect(replicas).To(Equal(int64(3)))
+
+	// Workloads at their replica count are left as they are
+	scaled, err = r.scaleWorkloads(context.TODO(), instance)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(scaled).To(BeEmpty())
+}
@@ -0,0 +1,70 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// DefaultMaxObservedResources is the default number of observed resources listed in addon status
+const DefaultMaxObservedResources = 500
+
+// truncateResources returns at most max of the observed resources, missing resources are kept first so that they are
+// still expected on the next drift check. A max of zero keeps all resources.
+func truncateResources(observed []addonmgrv1alpha1.ObjectStatus, max int) []addonmgrv1alpha1.ObjectStatus {
+	if max <= 0 || len(observed) <= max {
+		return observed
+	}
+
+	var truncated = make([]addonmgrv1alpha1.ObjectStatus, len(observed))
+	copy(truncated, observed)
+	sort.SliceStable(truncated, func(i, j int) bool {
+		return truncated[i].Status == string(addonmgrv1alpha1.Missing) && truncated[j].Status != string(addonmgrv1alpha1.Missing)
+	})
+	return truncated[:max]
+}
+
+// setResources records the observed resources in status, capped at MaxObservedResources. The ResourcesTruncated
+// condition reports when resources are left out, the count of all observed resources is kept in ResourceCount.
+func (r *AddonReconciler) setResources(instance *addonmgrv1alpha1.Addon, observed []addonmgrv1alpha1.ObjectStatus) {
+	if observed == nil {
+		observed = make([]addonmgrv1alpha1.ObjectStatus, 0)
+	}
+	instance.Status.Resources = truncateResources(observed, r.MaxObservedResources)
+	instance.Status.ResourceCount = len(observed)
+
+	if len(instance.Status.Resources) == len(observed) {
+		removeCondition(&instance.Status.Conditions, addonmgrv1alpha1.ResourcesTruncatedCondition)
+		return
+	}
+
+	message := fmt.Sprintf("Addon %s/%s status lists %d of %d observed resources.", instance.Namespace, instance.Name, len(instance.Status.Resources), len(observed))
+	if !meta.IsStatusConditionTrue(instance.Status.Conditions, addonmgrv1alpha1.ResourcesTruncatedCondition) {
+		r.recorder.Event(instance, "Warning", "ResourcesTruncated", message)
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               addonmgrv1alpha1.ResourcesTruncatedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: instance.Generation,
+		Reason:             "MaxObservedResources",
+		Message:            message,
+	})
+}
@@ -0,0 +1,70 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestSetResources_Truncates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &AddonReconciler{recorder: recorder, MaxObservedResources: 3}
+
+	var observed []addonmgrv1alpha1.ObjectStatus
+	for i := 0; i < 5; i++ {
+		observed = append(observed, addonmgrv1alpha1.ObjectStatus{Kind: "ConfigMap", Name: fmt.Sprintf("cm-%d", i), Status: "Ready"})
+	}
+	observed[4].Status = string(addonmgrv1alpha1.Missing)
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	r.setResources(instance, observed)
+
+	// Missing resources are kept so that they are still expected on the next drift check
+	g.Expect(instance.Status.Resources).To(HaveLen(3))
+	g.Expect(instance.Status.Resources[0].Name).To(Equal("cm-4"))
+	g.Expect(instance.Status.ResourceCount).To(Equal(5))
+	g.Expect(meta.IsStatusConditionTrue(instance.Status.Conditions, addonmgrv1alpha1.ResourcesTruncatedCondition)).To(BeTrue())
+	g.Expect(recorder.Events).To(Receive(Equal("Warning ResourcesTruncated Addon addon-manager-system/fluentd status lists 3 of 5 observed resources.")))
+
+	// The event is only recorded when the list becomes truncated
+	r.setResources(instance, observed)
+	g.Expect(recorder.Events).NotTo(Receive())
+
+	r.setResources(instance, observed[:2])
+	g.Expect(instance.Status.Resources).To(HaveLen(2))
+	g.Expect(instance.Status.ResourceCount).To(Equal(2))
+	g.Expect(meta.FindStatusCondition(instance.Status.Conditions, addonmgrv1alpha1.ResourcesTruncatedCondition)).To(BeNil())
+
+	// Nothing observed still lists an empty set of resources
+	r.setResources(instance, nil)
+	g.Expect(instance.Status.Resources).To(BeEmpty())
+	g.Expect(instance.Status.Resources).NotTo(BeNil())
+	g.Expect(instance.Status.ResourceCount).To(BeZero())
+
+	// Zero lists all resources
+	r.MaxObservedResources = 0
+	r.setResources(instance, observed)
+	g.Expect(instance.Status.Resources).To(HaveLen(5))
+}
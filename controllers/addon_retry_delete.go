@@ -0,0 +1,62 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+// retryDelete deletes the delete workflow of an addon which failed to delete and removes the retry delete annotation,
+// it returns true when the addon should be finalized again. The delete workflow is submitted again by Finalize, so
+// that cleanup still runs before the finalizer is removed. Delete protected addons are not retried, nothing is deleted
+// until protection is removed.
+func (r *AddonReconciler) retryDelete(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon, wfl workflows.AddonLifecycle) (bool, error) {
+	if _, ok := instance.GetAnnotations()[common.RetryDeleteAnnotation]; !ok {
+		return false, nil
+	}
+	if instance.Status.Lifecycle.Installed != addonmgrv1alpha1.DeleteFailed || instance.Spec.Lifecycle.DeleteProtection {
+		return false, nil
+	}
+
+	if instance.Spec.Lifecycle.Delete.Template != "" {
+		if err := wfl.Delete(ctx, instance.GetFormattedWorkflowName(addonmgrv1alpha1.Delete)); ignoreNotFound(err) != nil {
+			return false, err
+		}
+	}
+
+	// Keep the computed status, the update returns the persisted one
+	status := instance.Status
+	delete(instance.Annotations, common.RetryDeleteAnnotation)
+	if err := r.Update(ctx, instance); err != nil {
+		return false, err
+	}
+	instance.Status = status
+
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Deleting
+	instance.Status.Reason = ""
+	instance.Status.DeleteRetryTime = common.GetCurretTimestamp()
+
+	r.recorder.Event(instance, "Normal", "RetryDelete", fmt.Sprintf("Addon %s/%s delete workflow was deleted to delete the addon again.", instance.Namespace, instance.Name))
+	log.Info("Addon delete is retried")
+
+	return true, nil
+}
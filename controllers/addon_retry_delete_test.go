@@ -0,0 +1,87 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+func TestRetryDelete(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	key := types.NamespacedName{Namespace: "default", Name: "my-addon"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        key.Name,
+			Namespace:   key.Namespace,
+			Annotations: map[string]string{common.RetryDeleteAnnotation: "true"},
+		},
+		Spec: addonmgrv1alpha1.AddonSpec{
+			Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+				Delete: addonmgrv1alpha1.DeleteWorkflowType{WorkflowType: addonmgrv1alpha1.WorkflowType{Template: "kind: Workflow"}, TimeoutSeconds: 60},
+			},
+		},
+	})
+	r := &AddonReconciler{
+		Client:   c,
+		Log:      zap.New(zap.UseDevMode(true)),
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+
+	// Only addons which failed to delete are retried
+	wfl := &fakeLifecycle{}
+	retried, err := r.retryDelete(context.TODO(), r.Log, instance, wfl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(retried).To(BeFalse())
+
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.DeleteFailed
+	instance.Status.Reason = "Addon default/my-addon delete workflow did not complete within 60s."
+	instance.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	g.Expect(r.deleteTimedOut(instance)).To(BeTrue())
+
+	retried, err = r.retryDelete(context.TODO(), r.Log, instance, wfl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(retried).To(BeTrue())
+	g.Expect(wfl.deleted).To(Equal([]string{instance.GetFormattedWorkflowName(addonmgrv1alpha1.Delete)}))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Deleting))
+	g.Expect(instance.Status.Reason).To(BeEmpty())
+
+	// The delete timeout restarts with the retry
+	g.Expect(r.deleteTimedOut(instance)).To(BeFalse())
+
+	// The annotation is removed so that the delete is retried once
+	var persisted = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, persisted)).To(Succeed())
+	g.Expect(persisted.GetAnnotations()).NotTo(HaveKey(common.RetryDeleteAnnotation))
+}
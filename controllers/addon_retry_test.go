@@ -0,0 +1,128 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestExecutePrereqAndInstall_RetryableSecretsErrors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	gr := schema.GroupResource{Resource: "secrets"}
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "conflict", err: apierrors.NewConflict(gr, "db", errors.New("object was modified")), retryable: true},
+		{name: "timeout", err: apierrors.NewServerTimeout(gr, "list", 1), retryable: true},
+		{name: "throttled", err: apierrors.NewTooManyRequests("throttled", 1), retryable: true},
+		{name: "connection", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, retryable: true},
+		{name: "forbidden", err: apierrors.NewForbidden(gr, "", errors.New("denied")), retryable: false},
+	}
+	for _, tt := range tests {
+		ns := &unstructured.Unstructured{}
+		ns.SetAPIVersion("v1")
+		ns.SetKind("Namespace")
+		ns.SetName("logging")
+		dynClient := dynfake.NewSimpleDynamicClient(runtime.NewScheme(), ns)
+		injected := tt.err
+		dynClient.PrependReactor("list", "secrets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, injected
+		})
+
+		log := zap.New(zap.UseDevMode(true))
+		recorder := record.NewFakeRecorder(10)
+		r := &AddonReconciler{Log: log, dynClient: dynClient, recorder: recorder}
+
+		instance := &addonmgrv1alpha1.Addon{}
+		instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+		instance.Spec.Params.Namespace = "logging"
+		instance.Spec.Secrets = []addonmgrv1alpha1.SecretCmdSpec{{Name: "db"}}
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+
+		err := r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})
+		g.Expect(err).To(HaveOccurred(), tt.name)
+		g.Expect(isTransient(err)).To(Equal(tt.retryable), tt.name)
+		if tt.retryable {
+			g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending), tt.name)
+			g.Expect(recorder.Events).NotTo(Receive(), tt.name)
+		} else {
+			g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed), tt.name)
+			g.Expect(recorder.Events).To(Receive(ContainSubstring("could not validate secrets")), tt.name)
+		}
+	}
+}
+
+func TestExecutePrereqAndInstall_RetryableServiceAccountErrors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	gr := schema.GroupResource{Resource: "serviceaccounts"}
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "timeout", err: apierrors.NewServerTimeout(gr, "get", 1), retryable: true},
+		{name: "throttled", err: apierrors.NewTooManyRequests("throttled", 1), retryable: true},
+		{name: "conflict", err: apierrors.NewConflict(gr, "workflow", errors.New("object was modified")), retryable: true},
+		{name: "not found", err: apierrors.NewNotFound(gr, "workflow"), retryable: false},
+		{name: "forbidden", err: apierrors.NewForbidden(gr, "workflow", errors.New("denied")), retryable: false},
+	}
+	for _, tt := range tests {
+		dynClient := dynfake.NewSimpleDynamicClient(runtime.NewScheme())
+		injected := tt.err
+		dynClient.PrependReactor("get", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, injected
+		})
+
+		log := zap.New(zap.UseDevMode(true))
+		recorder := record.NewFakeRecorder(10)
+		r := &AddonReconciler{Log: log, dynClient: dynClient, recorder: recorder}
+
+		instance := &addonmgrv1alpha1.Addon{}
+		instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+		instance.Spec.Lifecycle.ServiceAccount = "workflow"
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+
+		err := r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})
+		g.Expect(err).To(HaveOccurred(), tt.name)
+		g.Expect(isTransient(err)).To(Equal(tt.retryable), tt.name)
+		if tt.retryable {
+			g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending), tt.name)
+			g.Expect(recorder.Events).NotTo(Receive(), tt.name)
+		} else {
+			g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed), tt.name)
+			g.Expect(recorder.Events).To(Receive(ContainSubstring("could not validate service account")), tt.name)
+		}
+	}
+}
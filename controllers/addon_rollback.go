@@ -0,0 +1,223 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
+)
+
+const (
+	lastAppliedChecksumKey = "checksum"
+	lastAppliedSpecKey     = "spec.json.gz"
+
+	// maxRollbackAttempts bounds the rollbacks of a failed spec, the addon fails once they all failed
+	maxRollbackAttempts = 3
+)
+
+// lastAppliedName is the name of the ConfigMap holding the spec of the last successful install
+func lastAppliedName(addon *addonmgrv1alpha1.Addon) string {
+	return fmt.Sprintf("%s-last-applied", addon.GetName())
+}
+
+// canRollback returns true if the addon asked for rollbacks and a different spec was installed successfully before
+func canRollback(addon *addonmgrv1alpha1.Addon) bool {
+	return addon.Spec.Lifecycle.RollbackOnFailure &&
+		addon.Status.LastAppliedChecksum != "" &&
+		addon.Status.LastAppliedChecksum != addon.Status.Checksum
+}
+
+func encodeSpec(spec *addonmgrv1alpha1.AddonSpec) ([]byte, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeSpec(data []byte) (*addonmgrv1alpha1.AddonSpec, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	raw, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &addonmgrv1alpha1.AddonSpec{}
+	if err := json.Unmarshal(raw, spec); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// saveLastApplied stores a compressed snapshot of the installed spec in a ConfigMap owned by the addon
+func (r *AddonReconciler) saveLastApplied(ctx context.Context, addon *addonmgrv1alpha1.Addon) error {
+	data, err := encodeSpec(&addon.Spec)
+	if err != nil {
+		return err
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      lastAppliedName(addon),
+			Namespace: addon.GetNamespace(),
+			Labels: map[string]string{
+				common.ManagedByLabel: common.AddonGVR().Group,
+				common.NameLabel:      addon.GetName(),
+			},
+		},
+		Data:       map[string]string{lastAppliedChecksumKey: addon.Status.Checksum},
+		BinaryData: map[string][]byte{lastAppliedSpecKey: data},
+	}
+	if err := controllerutil.SetControllerReference(addon, cm, r.Scheme); err != nil {
+		return err
+	}
+
+	cmClient := r.generatedClient.CoreV1().ConfigMaps(addon.GetNamespace())
+	_, err = cmClient.Create(ctx, cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = cmClient.Update(ctx, cm, metav1.UpdateOptions{})
+	}
+
+	return err
+}
+
+// loadLastApplied returns the spec snapshot matching the last applied checksum of the addon
+func (r *AddonReconciler) loadLastApplied(ctx context.Context, addon *addonmgrv1alpha1.Addon) (*addonmgrv1alpha1.AddonSpec, error) {
+	cm, err := r.generatedClient.CoreV1().ConfigMaps(addon.GetNamespace()).Get(ctx, lastAppliedName(addon), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum := cm.Data[lastAppliedChecksumKey]; checksum != addon.Status.LastAppliedChecksum {
+		return nil, fmt.Errorf("snapshot %s is for checksum %q, expected %q", cm.GetName(), checksum, addon.Status.LastAppliedChecksum)
+	}
+
+	return decodeSpec(cm.BinaryData[lastAppliedSpecKey])
+}
+
+// rollback runs the install workflow of the last successfully applied spec after the current install failed, along
+// with its prereqs workflow when the install needs its outputs. Each attempt runs fresh workflows, a failed attempt is
+// retried until maxRollbackAttempts, the addon is RolledBack when the workflow succeeds and Failed otherwise.
+func (r *AddonReconciler) rollback(ctx context.Context, log logr.Logger, instance *addonmgrv1alpha1.Addon) {
+	if instance.Status.RollbackAttempts == 0 {
+		instance.Status.RollbackAttempts = 1
+	}
+
+	spec, err := r.loadLastApplied(ctx, instance)
+	if err != nil {
+		r.rollbackFailed(log, instance, err)
+		return
+	}
+
+	previous := instance.DeepCopy()
+	previous.Spec = *spec
+	previous.Status.Checksum = instance.Status.LastAppliedChecksum
+	setRollbackNamePrefix(previous, instance)
+	wfl := workflows.NewWorkflowLifecycle(r.Client, r.dynClient, previous, r.recorder, r.Scheme, r.Cluster, r.WorkflowTTL)
+
+	var outputs map[string]string
+	phase := addonmgrv1alpha1.Succeeded
+	if len(previous.Spec.Lifecycle.Prereqs.Outputs) > 0 && !previous.RunsCombinedWorkflow() {
+		phase, err = r.runWorkflow(addonmgrv1alpha1.Prereqs, previous, wfl, nil)
+		if err == nil && phase == addonmgrv1alpha1.Succeeded {
+			outputs, err = r.prereqsOutputs(ctx, previous, wfl)
+		}
+	}
+	if err == nil && phase == addonmgrv1alpha1.Succeeded {
+		phase, err = r.install(ctx, previous, wfl, outputs)
+	}
+	if err != nil {
+		r.rollbackAttemptFailed(log, instance, err)
+		return
+	}
+
+	switch phase {
+	case addonmgrv1alpha1.Succeeded:
+		reason := fmt.Sprintf("Addon %s/%s install failed, rolled back to checksum %s.", instance.Namespace, instance.Name, instance.Status.LastAppliedChecksum)
+		r.recorder.Event(instance, "Warning", "RolledBack", reason)
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.RolledBack
+		instance.Status.Reason = reason
+	case addonmgrv1alpha1.Failed:
+		r.rollbackAttemptFailed(log, instance, fmt.Errorf("workflows of checksum %s failed", instance.Status.LastAppliedChecksum))
+	default:
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+		instance.Status.Reason = fmt.Sprintf("Addon %s/%s install failed, rolling back to checksum %s.", instance.Namespace, instance.Name, instance.Status.LastAppliedChecksum)
+	}
+}
+
+// setRollbackNamePrefix names the workflows of the previous spec after the rollback attempt of the failed spec, so
+// that a rollback does not find the workflows which installed the previous spec before and runs them again
+func setRollbackNamePrefix(previous, instance *addonmgrv1alpha1.Addon) {
+	prefix := fmt.Sprintf("rollback%d-%s", instance.Status.RollbackAttempts, strconv.FormatInt(instance.Status.StartTime, 36))
+	lifecycle := &previous.Spec.Lifecycle
+	for _, wt := range []*addonmgrv1alpha1.WorkflowType{&lifecycle.Prereqs, &lifecycle.Install, &lifecycle.CombinedTemplate.WorkflowType} {
+		wt.NamePrefix = prefix
+	}
+}
+
+// rollbackAttemptFailed retries the rollback with fresh workflows until maxRollbackAttempts, the addon fails after
+func (r *AddonReconciler) rollbackAttemptFailed(log logr.Logger, instance *addonmgrv1alpha1.Addon, err error) {
+	if instance.Status.RollbackAttempts >= maxRollbackAttempts {
+		r.rollbackFailed(log, instance, fmt.Errorf("%d attempts failed. %v", instance.Status.RollbackAttempts, err))
+		return
+	}
+
+	reason := fmt.Sprintf("Addon %s/%s rollback attempt %d failed, retrying. %v", instance.Namespace, instance.Name, instance.Status.RollbackAttempts, err)
+	r.recorder.Event(instance, "Warning", "RollbackFailed", reason)
+	log.Info("Addon rollback attempt failed, retrying.", "attempt", instance.Status.RollbackAttempts, "error", err.Error())
+	instance.Status.RollbackAttempts++
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+	instance.Status.Reason = reason
+}
+
+func (r *AddonReconciler) rollbackFailed(log logr.Logger, instance *addonmgrv1alpha1.Addon, err error) {
+	reason := fmt.Sprintf("Addon %s/%s install failed and could not be rolled back. %v", instance.Namespace, instance.Name, err)
+	r.recorder.Event(instance, "Warning", "Failed", reason)
+	log.Error(err, "Addon rollback failed.")
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+	instance.Status.FailureCategory = addonmgrv1alpha1.FailureWorkflow
+	instance.Status.Reason = reason
+}
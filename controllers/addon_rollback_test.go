@@ -0,0 +1,186 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+const installWorkflowTemplate = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+spec:
+  entrypoint: install
+  templates:
+  - name: install
+    container:
+      image: alpine
+`
+
+func TestEncodeSpec(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	spec := &addonmgrv1alpha1.AddonSpec{
+		PackageSpec: addonmgrv1alpha1.PackageSpec{
+			PkgName:    "test/addon",
+			PkgVersion: "1.0.0",
+			PkgType:    addonmgrv1alpha1.CompositePkg,
+		},
+		Params: addonmgrv1alpha1.AddonParams{Namespace: "addon-test-ns"},
+		Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+			Install:           addonmgrv1alpha1.WorkflowType{Template: "kind: Workflow"},
+			RollbackOnFailure: true,
+		},
+	}
+
+	data, err := encodeSpec(spec)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	decoded, err := decodeSpec(data)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(decoded).To(Equal(spec))
+
+	_, err = decodeSpec([]byte("not gzip"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCanRollback(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Spec.Lifecycle.RollbackOnFailure = true
+	a.Status.Checksum = "new"
+	g.Expect(canRollback(a)).To(BeFalse())
+
+	a.Status.LastAppliedChecksum = "new"
+	g.Expect(canRollback(a)).To(BeFalse())
+
+	a.Status.LastAppliedChecksum = "old"
+	g.Expect(canRollback(a)).To(BeTrue())
+
+	a.Spec.Lifecycle.RollbackOnFailure = false
+	g.Expect(canRollback(a)).To(BeFalse())
+}
+
+func TestInstallCompleted_Rollback(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+	sch.AddKnownTypeWithName(common.WorkflowGVR().GroupVersion().WithKind("Workflow"), &unstructured.Unstructured{})
+	sch.AddKnownTypeWithName(common.WorkflowGVR().GroupVersion().WithKind("WorkflowList"), &unstructured.UnstructuredList{})
+
+	c := runtimefake.NewFakeClientWithScheme(sch)
+	log := zap.New(zap.UseDevMode(true))
+	recorder := record.NewFakeRecorder(20)
+	r := &AddonReconciler{
+		Client:          c,
+		Log:             log,
+		Scheme:          sch,
+		dynClient:       dynfake.NewSimpleDynamicClient(sch),
+		generatedClient: fake.NewSimpleClientset(),
+		recorder:        recorder,
+	}
+
+	workflow := func(name string) *unstructured.Unstructured {
+		wf := &unstructured.Unstructured{}
+		wf.SetGroupVersionKind(common.WorkflowGVR().GroupVersion().WithKind("Workflow"))
+		wf.SetNamespace("addon-manager-system")
+		wf.SetName(name)
+		return wf
+	}
+	complete := func(name, phase string) {
+		wf := workflow(name)
+		g.Expect(c.Get(ctx, types.NamespacedName{Namespace: wf.GetNamespace(), Name: name}, wf)).To(Succeed())
+		wf.Object["status"] = map[string]interface{}{"phase": phase, "startedAt": time.Now().UTC().Format(time.RFC3339)}
+		g.Expect(c.Update(ctx, wf)).To(Succeed())
+	}
+
+	// Version 1.0.0 was installed successfully, its install workflow is still kept by history
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace, instance.UID = "fluentd", "addon-manager-system", "uid"
+	instance.Spec.PkgName, instance.Spec.PkgVersion = "fluentd", "1.0.0"
+	instance.Spec.Lifecycle.Install.Template = installWorkflowTemplate
+	instance.Spec.Lifecycle.RollbackOnFailure = true
+	instance.Status.Checksum = instance.CalculateChecksum()
+	g.Expect(r.saveLastApplied(ctx, instance)).To(Succeed())
+	instance.Status.LastAppliedChecksum = instance.Status.Checksum
+	previous := instance.DeepCopy()
+
+	installed := workflow(instance.GetFormattedWorkflowName(addonmgrv1alpha1.Install))
+	installed.Object["status"] = map[string]interface{}{"phase": "Succeeded", "startedAt": time.Now().UTC().Format(time.RFC3339)}
+	g.Expect(c.Create(ctx, installed)).To(Succeed())
+
+	// The install of version 1.1.0 fails and is rolled back with a fresh workflow of version 1.0.0
+	instance.Spec.PkgVersion = "1.1.0"
+	instance.Status.Checksum = instance.CalculateChecksum()
+	instance.Status.StartTime = 1622851200000
+	wfl := &phasedLifecycle{phase: addonmgrv1alpha1.Failed}
+	g.Expect(r.installCompleted(ctx, log, instance, wfl, addonmgrv1alpha1.Install, addonmgrv1alpha1.Failed, nil, nil)).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.RollbackAttempts).To(Equal(1))
+
+	setRollbackNamePrefix(previous, instance)
+	rollback := previous.GetFormattedWorkflowName(addonmgrv1alpha1.Install)
+	g.Expect(rollback).To(HavePrefix("fluentd-rollback1-"))
+	g.Expect(rollback).NotTo(Equal(installed.GetName()))
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: rollback}, workflow(rollback))).To(Succeed())
+
+	// A failed attempt is retried with another fresh workflow, the failed install is not run again meanwhile
+	complete(rollback, "Failed")
+	g.Expect(r.executePrereqAndInstall(ctx, log, instance, &failingLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.RollbackAttempts).To(Equal(2))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("rollback attempt 1 failed, retrying"))
+
+	g.Expect(r.executePrereqAndInstall(ctx, log, instance, &failingLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	setRollbackNamePrefix(previous, instance)
+	rollback = previous.GetFormattedWorkflowName(addonmgrv1alpha1.Install)
+	g.Expect(rollback).To(HavePrefix("fluentd-rollback2-"))
+
+	complete(rollback, "Succeeded")
+	g.Expect(r.executePrereqAndInstall(ctx, log, instance, &failingLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.RolledBack))
+	g.Expect(instance.Status.RollbackAttempts).To(Equal(2))
+
+	// Rollbacks are bounded, the addon fails once the last attempt failed
+	instance.Status.RollbackAttempts = maxRollbackAttempts
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+	setRollbackNamePrefix(previous, instance)
+	rollback = previous.GetFormattedWorkflowName(addonmgrv1alpha1.Install)
+	g.Expect(r.executePrereqAndInstall(ctx, log, instance, &failingLifecycle{})).To(Succeed())
+	complete(rollback, "Failed")
+	g.Expect(r.executePrereqAndInstall(ctx, log, instance, &failingLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.RollbackAttempts).To(Equal(maxRollbackAttempts))
+	g.Expect(instance.Status.Reason).To(ContainSubstring("3 attempts failed"))
+}
@@ -0,0 +1,62 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+)
+
+// rolloutBlocked holds the addon Pending while addons of previous stages of its rollout have not succeeded. Failed
+// addons pause the rollout, which the RolloutPaused condition reports until they are fixed or removed.
+func (r *AddonReconciler) rolloutBlocked(instance *addonmgrv1alpha1.Addon) bool {
+	stage, pending, failed := addon.BlockingRollout(r.versionCache, instance)
+
+	if len(failed) > 0 {
+		reason := fmt.Sprintf("Addon %s/%s rollout %s is paused at stage %d, addons %s failed.", instance.Namespace, instance.Name, instance.Spec.Rollout.Name, stage, strings.Join(failed, ", "))
+		if c := meta.FindStatusCondition(instance.Status.Conditions, addonmgrv1alpha1.RolloutPausedCondition); c == nil || c.Status != metav1.ConditionTrue || c.Message != reason {
+			r.recorder.Event(instance, "Warning", "RolloutPaused", reason)
+		}
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:               addonmgrv1alpha1.RolloutPausedCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: instance.Generation,
+			Reason:             "StageFailed",
+			Message:            reason,
+		})
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+		instance.Status.Reason = reason
+		return true
+	}
+
+	removeCondition(&instance.Status.Conditions, addonmgrv1alpha1.RolloutPausedCondition)
+	if len(pending) > 0 {
+		reason := fmt.Sprintf("Addon %s/%s is waiting on rollout %s addons %s to succeed.", instance.Namespace, instance.Name, instance.Spec.Rollout.Name, strings.Join(pending, ", "))
+		if instance.Status.Reason != reason {
+			r.recorder.Event(instance, "Normal", "Pending", reason)
+		}
+		instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+		instance.Status.Reason = reason
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+)
+
+func TestRolloutBlocked(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	cache := addon.NewAddonVersionCacheClient()
+	r := &AddonReconciler{recorder: recorder, versionCache: cache}
+
+	canary := addon.Version{Name: "ingress-canary", Namespace: "addon-manager-system", UID: "1", Rollout: "ingress", RolloutStage: 0, PkgPhase: addonmgrv1alpha1.Pending}
+	canary.PkgName, canary.PkgVersion = "ingress-canary", "1.0.0"
+	cache.AddVersion(canary)
+
+	instance := &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-teams", Namespace: "addon-manager-system", UID: "2"},
+		Spec: addonmgrv1alpha1.AddonSpec{
+			Params: addonmgrv1alpha1.AddonParams{Namespace: "teams"},
+			Rollout: addonmgrv1alpha1.RolloutSpec{
+				Name: "ingress",
+				Stages: []addonmgrv1alpha1.RolloutStage{
+					{Namespaces: []string{"canary"}},
+					{Namespaces: []string{"teams"}},
+				},
+			},
+		},
+	}
+
+	g.Expect(r.rolloutBlocked(instance)).To(BeTrue())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(recorder.Events).To(Receive(Equal("Normal Pending Addon addon-manager-system/ingress-teams is waiting on rollout ingress addons ingress-canary to succeed.")))
+
+	// Failures of a previous stage pause the rollout
+	canary.PkgPhase = addonmgrv1alpha1.Failed
+	cache.AddVersion(canary)
+	g.Expect(r.rolloutBlocked(instance)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(instance.Status.Conditions, addonmgrv1alpha1.RolloutPausedCondition)).To(BeTrue())
+	g.Expect(instance.Status.Reason).To(Equal("Addon addon-manager-system/ingress-teams rollout ingress is paused at stage 1, addons ingress-canary failed."))
+	g.Expect(recorder.Events).To(Receive(Equal("Warning RolloutPaused " + instance.Status.Reason)))
+
+	// The event is only recorded when the rollout becomes paused
+	g.Expect(r.rolloutBlocked(instance)).To(BeTrue())
+	g.Expect(recorder.Events).NotTo(Receive())
+
+	canary.PkgPhase = addonmgrv1alpha1.Succeeded
+	cache.AddVersion(canary)
+	g.Expect(r.rolloutBlocked(instance)).To(BeFalse())
+	g.Expect(meta.FindStatusCondition(instance.Status.Conditions, addonmgrv1alpha1.RolloutPausedCondition)).To(BeNil())
+}
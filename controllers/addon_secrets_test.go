@@ -0,0 +1,89 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynfake "k8s.io/client-go/dynamic/fake"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestValidateSecrets_RequiredKeys(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName("db-creds")
+	secret.SetNamespace("my-addon")
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{
+		"username": base64.StdEncoding.EncodeToString([]byte("admin")),
+		"password": "",
+	}, "data")
+
+	r := &AddonReconciler{dynClient: dynfake.NewSimpleDynamicClient(runtime.NewScheme(), secret)}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "my-addon", "addon-manager-system"
+	instance.Spec.Params.Namespace = "my-addon"
+	instance.Spec.Secrets = []addonmgrv1alpha1.SecretCmdSpec{{Name: "db-creds", RequiredKeys: []string{"username"}}}
+	g.Expect(r.validateSecrets(context.TODO(), instance)).To(Succeed())
+
+	// Empty keys are missing
+	instance.Spec.Secrets[0].RequiredKeys = []string{"username", "password"}
+	err := r.validateSecrets(context.TODO(), instance)
+	g.Expect(err).To(MatchError(`addon my-addon needs key "password" in secret "db-creds" that was not found or is empty in namespace my-addon`))
+
+	instance.Spec.Secrets[0].RequiredKeys = []string{"host"}
+	err = r.validateSecrets(context.TODO(), instance)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring(`key "host"`))
+	g.Expect(err.Error()).NotTo(ContainSubstring(base64.StdEncoding.EncodeToString([]byte("admin"))))
+
+	instance.Spec.Secrets = []addonmgrv1alpha1.SecretCmdSpec{{Name: "other", RequiredKeys: []string{"host"}}}
+	g.Expect(r.validateSecrets(context.TODO(), instance)).To(MatchError(ContainSubstring(`needs secret "other"`)))
+}
+
+func TestValidateImagePullSecrets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName("registry")
+	secret.SetNamespace("workflows")
+
+	r := &AddonReconciler{dynClient: dynfake.NewSimpleDynamicClient(runtime.NewScheme(), secret)}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "my-addon", "addon-manager-system"
+	instance.Spec.Params.Namespace = "my-addon"
+	g.Expect(r.validateImagePullSecrets(context.TODO(), instance)).To(Succeed())
+
+	// Pull secrets must exist in the workflow namespace
+	instance.Spec.WorkflowNamespace = "workflows"
+	instance.Spec.Lifecycle.ImagePullSecrets = []string{"registry"}
+	g.Expect(r.validateImagePullSecrets(context.TODO(), instance)).To(Succeed())
+
+	instance.Spec.WorkflowNamespace = ""
+	g.Expect(r.validateImagePullSecrets(context.TODO(), instance)).To(MatchError(`addon my-addon needs secret "registry" that was not found in namespace addon-manager-system`))
+}
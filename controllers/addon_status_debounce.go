@@ -0,0 +1,66 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// DefaultStatusDebounce is how long status changes of an addon are coalesced after a status write when none is configured
+const DefaultStatusDebounce = 2 * time.Second
+
+// statusWrite is the last status write of an addon
+type statusWrite struct {
+	checksum string
+	at       time.Time
+}
+
+// statusWritten records the status write of the addon, status changes within the debounce period are coalesced
+func (r *AddonReconciler) statusWritten(instance *addonmgrv1alpha1.Addon) {
+	name := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+	r.statusWrites.Store(name, statusWrite{checksum: instance.Status.Checksum, at: time.Now()})
+}
+
+// coalesceStatus returns how long the status write of the addon is delayed, or zero when it is written now. Changes
+// within the debounce period of the previous write are written by a later reconcile, unless the install phase changed
+// or is terminal, the spec changed or the reconcile failed, which are always written immediately.
+func (r *AddonReconciler) coalesceStatus(instance *addonmgrv1alpha1.Addon, prevPhase addonmgrv1alpha1.ApplicationAssemblyPhase, procErr error) time.Duration {
+	if r.StatusDebounce <= 0 || procErr != nil {
+		return 0
+	}
+
+	phase := instance.Status.Lifecycle.Installed
+	if phase != prevPhase || phase.Completed() {
+		return 0
+	}
+
+	v, ok := r.statusWrites.Load(types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name})
+	if !ok {
+		return 0
+	}
+	last := v.(statusWrite)
+	if last.checksum != instance.Status.Checksum {
+		return 0
+	}
+
+	if delay := r.StatusDebounce - time.Since(last.at); delay > 0 {
+		return delay
+	}
+	return 0
+}
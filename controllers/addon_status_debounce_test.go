@@ -0,0 +1,59 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestCoalesceStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := &AddonReconciler{StatusDebounce: time.Minute}
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Status.Checksum = "abc"
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+
+	// The first status is always written
+	g.Expect(r.coalesceStatus(instance, addonmgrv1alpha1.Pending, nil)).To(BeZero())
+	r.statusWritten(instance)
+
+	// Changes of a pending addon are coalesced until the debounce period passed
+	instance.Status.Reason = "waiting on dependencies"
+	delay := r.coalesceStatus(instance, addonmgrv1alpha1.Pending, nil)
+	g.Expect(delay).To(BeNumerically(">", 0))
+	g.Expect(delay).To(BeNumerically("<=", time.Minute))
+
+	// Failed reconciles, phase transitions, terminal phases and spec changes are written immediately
+	g.Expect(r.coalesceStatus(instance, addonmgrv1alpha1.Pending, errors.New("failed"))).To(BeZero())
+	g.Expect(r.coalesceStatus(instance, "", nil)).To(BeZero())
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Succeeded
+	g.Expect(r.coalesceStatus(instance, addonmgrv1alpha1.Succeeded, nil)).To(BeZero())
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+	instance.Status.Checksum = "def"
+	g.Expect(r.coalesceStatus(instance, addonmgrv1alpha1.Pending, nil)).To(BeZero())
+
+	// Coalescing is disabled without a debounce period
+	instance.Status.Checksum = "abc"
+	r.StatusDebounce = 0
+	g.Expect(r.coalesceStatus(instance, addonmgrv1alpha1.Pending, nil)).To(BeZero())
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestUpdateAddonStatus_Conflict(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+
+	key := types.NamespacedName{Namespace: "default", Name: "my-addon"}
+	c := runtimefake.NewFakeClientWithScheme(sch, &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Status: addonmgrv1alpha1.AddonStatus{
+			Lifecycle: addonmgrv1alpha1.AddonStatusLifecycle{Installed: addonmgrv1alpha1.Pending},
+		},
+	})
+	r := &AddonReconciler{
+		Client:      c,
+		Log:         zap.New(zap.UseDevMode(true)),
+		apiReader:   c,
+		recorder:    record.NewFakeRecorder(10),
+		statusWGMap: map[string]*sync.WaitGroup{},
+	}
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+
+	// Modify the addon while it is reconciled so that the status update conflicts
+	var other = instance.DeepCopy()
+	other.SetLabels(map[string]string{"team": "platform"})
+	g.Expect(c.Update(context.TODO(), other)).To(Succeed())
+
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+	instance.Status.Reason = "install failed"
+	g.Expect(r.updateAddonStatus(context.TODO(), r.Log, instance, addonmgrv1alpha1.Pending)).To(Succeed())
+
+	var persisted = &addonmgrv1alpha1.Addon{}
+	g.Expect(c.Get(context.TODO(), key, persisted)).To(Succeed())
+	g.Expect(persisted.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(persisted.Status.Reason).To(Equal("install failed"))
+	g.Expect(persisted.GetLabels()).To(HaveKeyWithValue("team", "platform"))
+}
+
+func TestInstallDuration(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(installDuration(1622851200000, 1622851283400)).To(Equal("1m23s"))
+	g.Expect(installDuration(1622851200000, 1622851200000)).To(Equal("0s"))
+	g.Expect(installDuration(0, 1622851200000)).To(Equal(""))
+	g.Expect(installDuration(1622851200000, 1622851100000)).To(Equal(""))
+}
+
+func TestTrackActiveWorkflow(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var a = &addonmgrv1alpha1.Addon{}
+	trackActiveWorkflow(a, addonmgrv1alpha1.Prereqs, "my-addon-prereqs-1a2b-wf", addonmgrv1alpha1.Pending, nil)
+	g.Expect(a.Status.ActiveWorkflow).To(Equal(&addonmgrv1alpha1.ActiveWorkflow{
+		Name:          "my-addon-prereqs-1a2b-wf",
+		LifecycleStep: addonmgrv1alpha1.Prereqs,
+		Phase:         addonmgrv1alpha1.Pending,
+	}))
+
+	// Completion of another workflow keeps the active one
+	trackActiveWorkflow(a, addonmgrv1alpha1.Install, "my-addon-install-1a2b-wf", addonmgrv1alpha1.Succeeded, nil)
+	g.Expect(a.Status.ActiveWorkflow).NotTo(BeNil())
+
+	trackActiveWorkflow(a, addonmgrv1alpha1.Prereqs, "my-addon-prereqs-1a2b-wf", addonmgrv1alpha1.Failed, nil)
+	g.Expect(a.Status.ActiveWorkflow).To(BeNil())
+}
+
+func TestExecutePrereqAndInstall_FailureCategory(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName("logging")
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{
+		Log:       log,
+		dynClient: dynfake.NewSimpleDynamicClient(runtime.NewScheme(), ns),
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Params.Namespace = "logging"
+	instance.Spec.Lifecycle.Prereqs.Template = "kind: Workflow"
+
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &failingLifecycle{})).NotTo(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.FailureCategory).To(Equal(addonmgrv1alpha1.FailureWorkflow))
+
+	// The category is cleared when the addon is installed again
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.FailureCategory).To(BeEmpty())
+}
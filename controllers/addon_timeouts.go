@@ -0,0 +1,59 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// stepTimedOut starts the timer of a pending prereqs or install step and returns true once the step ran longer than its
+// timeout. The timer restarts while workflows are suspended, like the addon ttl.
+func stepTimedOut(instance *addonmgrv1alpha1.Addon, lifecycleStep addonmgrv1alpha1.LifecycleStep) bool {
+	start, timeout := &instance.Status.InstallStartTime, instance.Spec.Lifecycle.InstallTimeoutSeconds
+	if lifecycleStep == addonmgrv1alpha1.Prereqs {
+		start, timeout = &instance.Status.PrereqsStartTime, instance.Spec.Lifecycle.PrereqsTimeoutSeconds
+	}
+
+	if *start == 0 || instance.Spec.SuspendWorkflows {
+		*start = common.GetCurretTimestamp()
+	}
+	return timeout > 0 && common.IsExpired(*start, (time.Duration(timeout)*time.Second).Milliseconds())
+}
+
+// failTimedOutStep marks the timed out step and install as Failed with a reason naming the step
+func (r *AddonReconciler) failTimedOutStep(log logr.Logger, instance *addonmgrv1alpha1.Addon, lifecycleStep addonmgrv1alpha1.LifecycleStep) error {
+	timeout := instance.Spec.Lifecycle.InstallTimeoutSeconds
+	if lifecycleStep == addonmgrv1alpha1.Prereqs {
+		timeout = instance.Spec.Lifecycle.PrereqsTimeoutSeconds
+		instance.Status.Lifecycle.Prereqs = addonmgrv1alpha1.Failed
+	}
+
+	reason := fmt.Sprintf("Addon %s/%s %s timed out, it ran longer than %s.", instance.Namespace, instance.Name, lifecycleStep, time.Duration(timeout)*time.Second)
+	r.recorder.Event(instance, "Warning", "TimedOut", reason)
+	err := fmt.Errorf(reason)
+	workflowLogger(log, instance, lifecycleStep).Error(err, "Addon lifecycle step timed out.")
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Failed
+	instance.Status.FailureCategory = addonmgrv1alpha1.FailureTimeout
+	instance.Status.Reason = reason
+	instance.Status.ActiveWorkflow = nil
+
+	return err
+}
@@ -0,0 +1,79 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+func TestExecutePrereqAndInstall_Timeouts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName("logging")
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{
+		Log:       log,
+		dynClient: dynfake.NewSimpleDynamicClient(runtime.NewScheme(), ns),
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Params.Namespace = "logging"
+	instance.Spec.Lifecycle.Prereqs.Template = "kind: Workflow"
+	instance.Spec.Lifecycle.PrereqsTimeoutSeconds = 60
+
+	// The timer starts with the first run of the step
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Prereqs).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.PrereqsStartTime).NotTo(BeZero())
+
+	instance.Status.PrereqsStartTime = common.GetCurretTimestamp() - (2 * time.Minute).Milliseconds()
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})).To(MatchError("Addon addon-manager-system/fluentd prereqs timed out, it ran longer than 1m0s."))
+	g.Expect(instance.Status.Lifecycle.Prereqs).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.FailureCategory).To(Equal(addonmgrv1alpha1.FailureTimeout))
+
+	// Install is timed out independently of prereqs
+	instance.Status = addonmgrv1alpha1.AddonStatus{}
+	instance.Spec.Lifecycle.Prereqs.Template = ""
+	instance.Spec.Lifecycle.Install.Template = "kind: Workflow"
+	instance.Spec.Lifecycle.InstallTimeoutSeconds = 300
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.PrereqsStartTime).To(BeZero())
+	g.Expect(instance.Status.FailureCategory).To(BeEmpty())
+
+	instance.Status.InstallStartTime = common.GetCurretTimestamp() - (10 * time.Minute).Milliseconds()
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})).To(MatchError("Addon addon-manager-system/fluentd install timed out, it ran longer than 5m0s."))
+	g.Expect(instance.Status.Lifecycle.Prereqs).To(Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(instance.Status.Lifecycle.Installed).To(Equal(addonmgrv1alpha1.Failed))
+	g.Expect(instance.Status.ActiveWorkflow).To(BeNil())
+}
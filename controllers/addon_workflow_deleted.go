@@ -0,0 +1,77 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// workflowDeleted remembers workflows which were deleted before they completed for the addons they belong to, so that
+// the next reconcile of the addon can tell that its active workflow was deleted externally
+func (r *AddonReconciler) workflowDeleted(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	if e.Meta == nil {
+		return
+	}
+	// Completed workflows are deleted by their ttl and history pruning
+	if wf, ok := e.Object.(*unstructured.Unstructured); ok {
+		phase, _, _ := unstructured.NestedString(wf.Object, "status", "phase")
+		if phase == "Succeeded" || phase == "Failed" || phase == "Error" {
+			return
+		}
+	}
+
+	for _, req := range r.workflowAddonRequests(e.Meta) {
+		r.deletedWorkflows.Store(req.NamespacedName, e.Meta.GetName())
+		q.Add(req)
+	}
+}
+
+// workflowAddonRequests returns the addons the workflow belongs to, by its owner or by its labels
+func (r *AddonReconciler) workflowAddonRequests(wf metav1.Object) []reconcile.Request {
+	if owner := metav1.GetControllerOf(wf); owner != nil {
+		if owner.Kind != "Addon" {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: wf.GetNamespace(), Name: owner.Name}}}
+	}
+	return r.getAddonRequestsFromLabels(handler.MapObject{Meta: wf})
+}
+
+// recoverDeletedWorkflow reports the active workflow of the addon when it was deleted externally, its lifecycle step is
+// still pending so that the workflow is submitted again instead of being waited on forever
+func (r *AddonReconciler) recoverDeletedWorkflow(log logr.Logger, instance *addonmgrv1alpha1.Addon) {
+	name, ok := r.deletedWorkflows.LoadAndDelete(types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name})
+	active := instance.Status.ActiveWorkflow
+	if !ok || active == nil || active.Name != name.(string) {
+		return
+	}
+
+	reason := fmt.Sprintf("Addon %s/%s %s workflow %s was deleted externally, it is submitted again.", instance.Namespace, instance.Name, active.LifecycleStep, active.Name)
+	r.recorder.Event(instance, "Warning", "WorkflowDeleted", reason)
+	workflowLogger(log, instance, active.LifecycleStep).Info("Addon workflow was deleted externally, submitting it again.")
+	instance.Status.ActiveWorkflow = nil
+	instance.Status.Reason = reason
+}
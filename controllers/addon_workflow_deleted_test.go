@@ -0,0 +1,86 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+)
+
+func TestWorkflowDeleted_Resubmits(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	log := zap.New(zap.UseDevMode(true))
+	r := &AddonReconciler{Log: log, recorder: recorder, versionCache: addon.NewAddonVersionCacheClient()}
+
+	instance := &addonmgrv1alpha1.Addon{}
+	instance.Name, instance.Namespace = "fluentd", "addon-manager-system"
+	instance.Spec.Lifecycle.Prereqs.Template = "kind: Workflow"
+	instance.Status.Checksum = instance.CalculateChecksum()
+	instance.Status.Lifecycle.Prereqs = addonmgrv1alpha1.Pending
+	instance.Status.Lifecycle.Installed = addonmgrv1alpha1.Pending
+
+	wfName := instance.GetFormattedWorkflowName(addonmgrv1alpha1.Prereqs)
+	instance.Status.ActiveWorkflow = &addonmgrv1alpha1.ActiveWorkflow{
+		Name:          wfName,
+		LifecycleStep: addonmgrv1alpha1.Prereqs,
+		Phase:         addonmgrv1alpha1.Pending,
+	}
+
+	wf := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Workflow",
+		"status":     map[string]interface{}{"phase": "Running"},
+	}}
+	wf.SetNamespace(instance.Namespace)
+	wf.SetName(wfName)
+	wf.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(instance, addonmgrv1alpha1.GroupVersion.WithKind("Addon"))})
+
+	// Workflows deleted once they completed are not reported
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	completed := wf.DeepCopy()
+	g.Expect(unstructured.SetNestedField(completed.Object, "Succeeded", "status", "phase")).To(Succeed())
+	r.workflowDeleted(event.DeleteEvent{Meta: completed, Object: completed}, q)
+	g.Expect(q.Len()).To(BeZero())
+
+	// The running workflow is deleted externally
+	r.workflowDeleted(event.DeleteEvent{Meta: wf, Object: wf}, q)
+	g.Expect(q.Len()).To(Equal(1))
+
+	// The next reconcile reports the deletion and submits the workflow again
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Reason).To(ContainSubstring("was deleted externally"))
+	g.Expect(instance.Status.Lifecycle.Prereqs).To(Equal(addonmgrv1alpha1.Pending))
+	g.Expect(instance.Status.ActiveWorkflow).NotTo(BeNil())
+	g.Expect(instance.Status.ActiveWorkflow.Name).To(Equal(wfName))
+	g.Expect(<-recorder.Events).To(ContainSubstring("WorkflowDeleted"))
+
+	// The deletion is only reported once
+	instance.Status.Reason = ""
+	g.Expect(r.executePrereqAndInstall(context.TODO(), log, instance, &fakeLifecycle{})).To(Succeed())
+	g.Expect(instance.Status.Reason).To(BeEmpty())
+}
@@ -107,8 +107,8 @@ var _ = AfterSuite(func() {
 func StartTestManager(mgr manager.Manager) (chan struct{}, *sync.WaitGroup) {
 	stop := make(chan struct{})
 	wg := &sync.WaitGroup{}
+	wg.Add(1)
 	go func() {
-		wg.Add(1)
 		Expect(mgr.Start(stop)).ToNot(HaveOccurred())
 		wg.Done()
 	}()
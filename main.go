@@ -15,17 +15,34 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
 	"github.com/keikoproj/addon-manager/controllers"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/diagnose"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
+	"github.com/keikoproj/addon-manager/pkg/notify"
+	"github.com/keikoproj/addon-manager/pkg/status"
 	"github.com/keikoproj/addon-manager/pkg/version"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -34,13 +51,117 @@ var (
 	setupLog             = ctrl.Log.WithName("setup")
 	debug                bool
 	metricsAddr          string
+	statusAddr           string
 	enableLeaderElection bool
+	leaderElectionNS     string
+	leaderElectionID     string
+	leaseDuration        time.Duration
+	renewDeadline        time.Duration
+	retryPeriod          time.Duration
+	workflowResync       time.Duration
+	workflowRecheck      time.Duration
+	driftCheck           time.Duration
+	gitPoll              time.Duration
+	reconcilesPerMinute  int
+	quarantineAfter      int
+	intervals            = controllers.DefaultReconcileIntervals()
+	watchNamespaces      string
+	workflowHistoryLimit int
+	workflowTTL          time.Duration
+	notifyURL            string
+	notifyType           string
+	notifyPhases         string
+	statusWebhookURL     string
+	statusWebhookRate    float64
+	metricsLabelKeys     string
+	workflowSA           string
+	shutdownTimeout      time.Duration
+	requireInstanceID    bool
+	finalizerName        string
+	maxObserved          int
+	statusDebounce       time.Duration
+	prevFinalizerNames   string
+	clusterName          string
+	environment          string
+	defaultNamespace     string
+	enableWebhooks       bool
+	enableDefaulting     bool
+	webhookPort          int
 )
 
 func init() {
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&statusAddr, "status-addr", "", "The address the read-only addon status endpoint binds to. Disabled when empty.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionNS, "leader-election-namespace", "",
+		"Namespace of the leader election lock. Defaults to the namespace the controller runs in, requires enable-leader-election.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "addonmgr.keikoproj.io",
+		"Name of the leader election lock, instances serving different namespaces need distinct ids.")
+	flag.DurationVar(&leaseDuration, "leader-election-lease-duration", 15*time.Second,
+		"Duration non-leader candidates wait before they force acquire leadership.")
+	flag.DurationVar(&renewDeadline, "leader-election-renew-deadline", 10*time.Second,
+		"Duration the leader retries refreshing leadership before giving it up.")
+	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second,
+		"Duration leader election clients wait between tries of actions.")
+	flag.DurationVar(&workflowResync, "workflow-resync-period", controllers.DefaultWorkflowResyncPeriod, "Resync period of the workflow informers.")
+	flag.DurationVar(&workflowRecheck, "workflow-recheck-period", controllers.DefaultWorkflowRecheckPeriod,
+		"How often the workflow phase of a pending addon is checked directly in case a workflow event was missed. Disabled when 0.")
+	flag.DurationVar(&driftCheck, "drift-check-period", controllers.DefaultDriftCheckPeriod,
+		"How often the resources of an addon are observed to detect missing resources when no event is seen. Disabled when 0.")
+	flag.DurationVar(&gitPoll, "git-poll-interval", controllers.DefaultGitPollInterval,
+		"How often the refs of addon git sources are resolved, a moved ref reinstalls the addon. Resolved on every reconcile when 0.")
+	flag.DurationVar(&intervals.Pending, "requeue-pending-interval", intervals.Pending,
+		"How often an addon waiting on dependencies, install waves, required CRDs, a template pull or a git ref is reconciled.")
+	flag.DurationVar(&intervals.WorkflowsNotServed, "requeue-workflows-not-served-interval", intervals.WorkflowsNotServed,
+		"How often discovery is checked for the Workflow CRD and waiting addons are reconciled while Argo Workflows is not installed.")
+	flag.DurationVar(&intervals.Validation, "requeue-validation-interval", intervals.Validation,
+		"How often addons in Validate mode are validated again.")
+	flag.DurationVar(&intervals.StatusRetry, "requeue-status-retry-interval", intervals.StatusRetry,
+		"How soon a reconcile is retried after the addon status could not be updated.")
+	flag.IntVar(&reconcilesPerMinute, "max-reconciles-per-minute", controllers.DefaultReconcilesPerMinute,
+		"Maximum reconciles per minute of a single addon. Disabled when 0.")
+	flag.IntVar(&quarantineAfter, "quarantine-after", controllers.DefaultQuarantineAfter,
+		"Number of consecutive failed reconciles after which an addon is quarantined and no longer retried. Disabled when 0.")
+	flag.IntVar(&workflowHistoryLimit, "workflow-history-limit", controllers.DefaultWorkflowHistoryLimit,
+		"Number of completed workflows of previous addon specs kept per lifecycle step. Keeps all when negative.")
+	flag.DurationVar(&workflowTTL, "workflow-ttl", workflows.DefaultWorkflowTTL,
+		"How long completed workflows are kept before Argo Workflows deletes them, unless their template sets a ttlStrategy. Disabled when 0.")
+	flag.IntVar(&maxObserved, "max-observed-resources", controllers.DefaultMaxObservedResources,
+		"Maximum number of observed resources listed in addon status, the count of all resources is still reported. Lists all when 0.")
+	flag.DurationVar(&statusDebounce, "status-debounce", controllers.DefaultStatusDebounce,
+		"How long status changes of an addon are coalesced into a single write, phase transitions are written immediately. Disabled when 0.")
+	flag.BoolVar(&requireInstanceID, "require-workflow-instance-id", false,
+		"Fail validation of addons running workflows without spec.lifecycle.workflowInstanceID, for clusters with sharded workflow controllers.")
+	flag.StringVar(&finalizerName, "finalizer-name", controllers.DefaultFinalizerName,
+		"Finalizer set on addons, controllers of different builds managing addons in the same cluster need distinct names.")
+	flag.StringVar(&prevFinalizerNames, "previous-finalizer-names", controllers.DefaultFinalizerName,
+		"Comma separated list of finalizers set by previous configurations, replaced with finalizer-name so addons are not stuck on delete.")
+	flag.StringVar(&clusterName, "cluster-name", "", "The name of the cluster workflow templates reference with {{cluster.name}}.")
+	flag.StringVar(&environment, "environment", "", "The environment of the cluster, e.g. prod, workflow templates reference it with {{cluster.environment}}.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the validating webhook of addons, requires serving certificates in /tmp/k8s-webhook-server/serving-certs.")
+	flag.BoolVar(&enableDefaulting, "enable-defaulting-webhook", false,
+		"Enable the defaulting webhook of addons, which sets default-addon-namespace, labels and normalizes lifecycle fields. Requires enable-webhooks.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server binds to.")
+	flag.StringVar(&defaultNamespace, "default-addon-namespace", "",
+		"The namespace set as spec.params.namespace of addons without one. Addons without a namespace fail validation when empty.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma separated list of namespaces addons are watched in. Watches all namespaces when empty.")
+	flag.StringVar(&notifyURL, "notify-url", os.Getenv("NOTIFY_URL"), "The endpoint addon phase transitions are posted to. Disabled when empty.")
+	flag.StringVar(&notifyType, "notify-type", envOrDefault("NOTIFY_TYPE", notify.WebhookType),
+		fmt.Sprintf("The type of the notify-url endpoint, %s or %s.", notify.WebhookType, notify.SlackType))
+	flag.StringVar(&notifyPhases, "notify-phases", envOrDefault("NOTIFY_PHASES", strings.Join(notify.DefaultPhases, ",")),
+		"Comma separated list of addon install phases which are notified when an addon transitions into them.")
+	flag.StringVar(&statusWebhookURL, "status-webhook-url", os.Getenv("STATUS_WEBHOOK_URL"),
+		"The endpoint every addon status change is posted to as a JSON diff. Disabled when empty.")
+	flag.Float64Var(&statusWebhookRate, "status-webhook-rate", notify.DefaultStreamRate,
+		"The maximum number of status changes posted to the status-webhook-url per second.")
+	flag.StringVar(&metricsLabelKeys, "metrics-label-keys", os.Getenv("METRICS_LABEL_KEYS"),
+		"Comma separated list of addon commonLabels keys which are added as labels to the addon_lifecycle_phase metric.")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", controllers.DefaultShutdownTimeout,
+		"How long shutdown waits for in-flight reconciles to persist addon status and queued notifications to be sent.")
+	flag.StringVar(&workflowSA, "diagnose-workflow-service-account", "addon-manager-system/addon-manager-workflow-installer-sa",
+		"The namespace/name of the service account workflows run with, checked by the diagnose command.")
 	flag.BoolVar(&debug, "debug", false, "Debug logging")
 	flag.Parse()
 
@@ -53,28 +174,254 @@ func main() {
 
 	setupLog.Info(version.ToString())
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	cfg := ctrl.GetConfigOrDie()
+	namespaces := parseList(watchNamespaces)
+
+	// The diagnose command checks the dependencies of the controller instead of running it, e.g.
+	// manager --enable-leader-election diagnose
+	if flag.Arg(0) == "diagnose" {
+		os.Exit(runDiagnostics(cfg, namespaces))
+	}
+
+	opts := ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
 		LeaderElection:     enableLeaderElection,
-		LeaderElectionID:   "addonmgr.keikoproj.io",
-	})
+		Port:               webhookPort,
+	}
+	if enableLeaderElection {
+		if err := validateLeaderElection(); err != nil {
+			setupLog.Error(err, "invalid leader election configuration")
+			os.Exit(1)
+		}
+		opts.LeaderElectionNamespace = leaderElectionNS
+		opts.LeaderElectionID = leaderElectionID
+		opts.LeaseDuration = &leaseDuration
+		opts.RenewDeadline = &renewDeadline
+		opts.RetryPeriod = &retryPeriod
+
+		hostname, _ := os.Hostname()
+		setupLog.Info("leader election enabled", "id", leaderElectionID, "namespace", leaderElectionNS, "identity", hostname,
+			"lease-duration", leaseDuration, "renew-deadline", renewDeadline, "retry-period", retryPeriod)
+	} else if leaderElectionNS != "" {
+		setupLog.Error(fmt.Errorf("leader-election-namespace %s is set", leaderElectionNS), "leader election namespace requires enable-leader-election")
+		os.Exit(1)
+	}
+	switch len(namespaces) {
+	case 0:
+		setupLog.Info("watching addons in all namespaces")
+	case 1:
+		setupLog.Info("watching addons in namespace", "namespace", namespaces[0])
+		opts.Namespace = namespaces[0]
+	default:
+		setupLog.Info("watching addons in namespaces", "namespaces", namespaces)
+		opts.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+	}
+
+	if err := verifyAccess(cfg, namespaces); err != nil {
+		setupLog.Error(err, "insufficient permissions for the watched namespaces")
+		os.Exit(1)
+	}
+
+	mgr, err := ctrl.NewManager(cfg, opts)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	err = controllers.NewAddonReconciler(mgr, ctrl.Log.WithName("controllers").WithName("Addon")).SetupWithManager(mgr)
+	if workflowResync <= 0 {
+		setupLog.Error(fmt.Errorf("invalid workflow-resync-period %s", workflowResync), "workflow informer resync period must be positive")
+		os.Exit(1)
+	}
+	if workflowTTL < 0 {
+		setupLog.Error(fmt.Errorf("invalid workflow-ttl %s", workflowTTL), "workflow ttl must not be negative")
+		os.Exit(1)
+	}
+	if err := intervals.Validate(); err != nil {
+		setupLog.Error(err, "invalid requeue intervals")
+		os.Exit(1)
+	}
+	if errs := validation.IsQualifiedName(finalizerName); len(errs) > 0 {
+		setupLog.Error(fmt.Errorf("invalid finalizer-name %s: %s", finalizerName, strings.Join(errs, ", ")), "finalizer name must be a qualified name")
+		os.Exit(1)
+	}
+	if errs := validation.IsDNS1123Label(defaultNamespace); defaultNamespace != "" && len(errs) > 0 {
+		setupLog.Error(fmt.Errorf("invalid default-addon-namespace %s: %s", defaultNamespace, strings.Join(errs, ", ")), "default addon namespace must be a valid namespace name")
+		os.Exit(1)
+	}
+	if workflowRecheck > workflowResync {
+		setupLog.Info("workflow-recheck-period is longer than workflow-resync-period, pending addons are re-checked on resync", "workflow-recheck-period", workflowRecheck, "workflow-resync-period", workflowResync)
+	}
+
+	r := controllers.NewAddonReconciler(mgr, ctrl.Log.WithName("controllers").WithName("Addon"))
+	r.WorkflowResyncPeriod = workflowResync
+	r.WorkflowRecheckPeriod = workflowRecheck
+	r.DriftCheckPeriod = driftCheck
+	r.GitPollInterval = gitPoll
+	r.ReconcilesPerMinute = reconcilesPerMinute
+	r.QuarantineAfter = quarantineAfter
+	r.Intervals = intervals
+	r.WorkflowHistoryLimit = workflowHistoryLimit
+	r.WorkflowTTL = workflowTTL
+	r.WatchNamespaces = namespaces
+	r.MaxObservedResources = maxObserved
+	r.StatusDebounce = statusDebounce
+	r.RequireWorkflowInstanceID = requireInstanceID
+	r.Cluster = workflows.ClusterValues{Name: clusterName, Environment: environment}
+	r.DefaultAddonNamespace = defaultNamespace
+	r.FinalizerName = finalizerName
+	r.PreviousFinalizerNames = common.RemoveString(parseList(prevFinalizerNames), finalizerName)
+	r.MetricsLabelKeys = parseList(metricsLabelKeys)
+	if err := metrics.ValidateLabelKeys(r.MetricsLabelKeys); err != nil {
+		setupLog.Error(err, "invalid metrics label keys")
+		os.Exit(1)
+	}
+
+	if notifyURL != "" {
+		notifier, err := notify.New(notifyType, notifyURL, &http.Client{Timeout: 10 * time.Second})
+		if err != nil {
+			setupLog.Error(err, "unable to create notifier")
+			os.Exit(1)
+		}
+		r.Notifications = notify.NewDispatcher(notifier, parseList(notifyPhases), ctrl.Log.WithName("notify"))
+		if err := mgr.Add(r.Notifications); err != nil {
+			setupLog.Error(err, "unable to add notifier")
+			os.Exit(1)
+		}
+	}
+
+	if statusWebhookURL != "" {
+		r.StatusStream = notify.NewStream(statusWebhookURL, &http.Client{Timeout: 10 * time.Second}, statusWebhookRate, ctrl.Log.WithName("status-stream"))
+		if err := mgr.Add(r.StatusStream); err != nil {
+			setupLog.Error(err, "unable to add status stream")
+			os.Exit(1)
+		}
+	}
+
+	// Addons are listed by install status from the field index of the manager cache
+	if err := addon.IndexInstallStatus(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to index addons", "field", addon.InstallStatusField)
+		os.Exit(1)
+	}
+
+	err = r.SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Addon")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err := (&addonmgrv1alpha1.Addon{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Addon")
+			os.Exit(1)
+		}
+		if enableDefaulting {
+			controllers.SetupDefaultingWebhookWithManager(mgr, &controllers.AddonDefaulter{Namespace: defaultNamespace})
+		}
+	} else if enableDefaulting {
+		setupLog.Error(fmt.Errorf("enable-defaulting-webhook is set"), "defaulting webhook requires enable-webhooks")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	if statusAddr != "" {
+		statusServer := status.NewServer(statusAddr, mgr.GetClient(), ctrl.Log.WithName("status"))
+		statusServer.VersionCache = r.VersionCache()
+		if err := mgr.Add(statusServer); err != nil {
+			setupLog.Error(err, "unable to add status server")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
-		setupLog.Error(err, "problem running manager")
+	startErr := mgr.Start(ctrl.SetupSignalHandler())
+
+	// The manager does not wait for in-flight reconciles, let them persist the addon status before exiting
+	setupLog.Info("draining in-flight reconciles", "shutdown-timeout", shutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	if err := r.Shutdown(ctx); err != nil {
+		setupLog.Error(err, "shutdown did not complete")
+	}
+	cancel()
+
+	if startErr != nil {
+		setupLog.Error(startErr, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// parseList splits a comma separated list and drops empty and duplicate items
+func parseList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" && !common.ContainsString(items, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateLeaderElection checks the lease timings, candidates must retry within the renew deadline of the leader
+// and the leader must renew before its lease expires
+func validateLeaderElection() error {
+	if retryPeriod <= 0 {
+		return fmt.Errorf("leader-election-retry-period %s must be positive", retryPeriod)
+	}
+	if renewDeadline <= time.Duration(leaderelection.JitterFactor*float64(retryPeriod)) {
+		return fmt.Errorf("leader-election-renew-deadline %s must be longer than %.1f times leader-election-retry-period %s", renewDeadline, leaderelection.JitterFactor, retryPeriod)
+	}
+	if leaseDuration <= renewDeadline {
+		return fmt.Errorf("leader-election-lease-duration %s must be longer than leader-election-renew-deadline %s", leaseDuration, renewDeadline)
+	}
+	return nil
+}
+
+func envOrDefault(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
+
+// verifyAccess checks that addons and workflows can be watched in the namespaces, or cluster wide when none are given
+func verifyAccess(cfg *rest.Config, namespaces []string) error {
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	return diagnose.VerifyAccess(context.TODO(), client, namespaces)
+}
+
+// runDiagnostics checks the dependencies the controller relies on with the controller flags and prints the results,
+// it returns the exit code of the diagnose command
+func runDiagnostics(cfg *rest.Config, namespaces []string) int {
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		return 1
+	}
+
+	sa := strings.Split(workflowSA, "/")
+	if len(sa) != 2 || sa[0] == "" || sa[1] == "" {
+		setupLog.Error(fmt.Errorf("invalid diagnose-workflow-service-account %s", workflowSA), "expected namespace/name")
+		return 1
+	}
+
+	checks := []diagnose.Check{
+		diagnose.APIServer(client.Discovery()),
+		diagnose.Permissions(client, namespaces),
+		diagnose.WorkflowCRD(client.Discovery()),
+		diagnose.ServiceAccount(client, sa[0], sa[1]),
+	}
+	if enableLeaderElection {
+		checks = append(checks, diagnose.LeaderElection(client, leaderElectionNS, leaderElectionID, leaseDuration))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if !diagnose.Print(os.Stdout, diagnose.Run(ctx, checks)) {
+		return 1
+	}
+	return 0
+}
@@ -15,19 +15,33 @@
 package addon
 
 import (
+	"errors"
 	"fmt"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 
 	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
 	"github.com/keikoproj/addon-manager/pkg/common"
+	"github.com/keikoproj/addon-manager/pkg/git"
+	"github.com/keikoproj/addon-manager/pkg/oci"
+	"github.com/keikoproj/addon-manager/pkg/workflows"
 )
 
+// featureKeyPattern matches the feature keys templates can reference with {{addon.features.<key>}}
+var featureKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
 const (
 	ErrDepNotInstalled = "required dependency is not installed"
 	ErrDepPending      = "required dependency is in pending state"
@@ -37,14 +51,16 @@ type addonValidator struct {
 	cache     VersionCacheClient
 	addon     *addonmgrv1alpha1.Addon
 	dynClient dynamic.Interface
+	discovery discovery.DiscoveryInterface
 }
 
 // NewAddonValidator returns an object implementing common.Validator
-func NewAddonValidator(addon *addonmgrv1alpha1.Addon, cache VersionCacheClient, dynClient dynamic.Interface) common.Validator {
+func NewAddonValidator(addon *addonmgrv1alpha1.Addon, cache VersionCacheClient, dynClient dynamic.Interface, discovery discovery.DiscoveryInterface) common.Validator {
 	return &addonValidator{
 		cache:     cache,
 		addon:     addon,
 		dynClient: dynClient,
+		discovery: discovery,
 	}
 }
 
@@ -54,6 +70,7 @@ func (av *addonValidator) Validate() (bool, error) {
 		Namespace:   av.addon.GetNamespace(),
 		PackageSpec: av.addon.GetPackageSpec(),
 		PkgPhase:    av.addon.Status.Lifecycle.Installed,
+		Selector:    SelectorLabels(av.addon),
 	}
 
 	// Validate version is not already set in cache, dupe.
@@ -62,6 +79,12 @@ func (av *addonValidator) Validate() (bool, error) {
 		return false, err
 	}
 
+	// Validate selector does not overlap with another addon selector
+	err = av.validateSelector(version)
+	if err != nil {
+		return false, err
+	}
+
 	// Validate length of addon name
 	err = av.validateAddonNameLength()
 	if err != nil {
@@ -73,51 +96,226 @@ func (av *addonValidator) Validate() (bool, error) {
 		return false, fmt.Errorf("namespace is empty in addon.spec.params.namespace")
 	}
 
+	// Validate required CRDs are named <plural>.<group>
+	for _, name := range av.addon.Spec.RequiredCRDs {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 || !strings.Contains(name, ".") {
+			return false, fmt.Errorf("required CRD %q is not a valid CRD name <plural>.<group>", name)
+		}
+	}
+
+	// Validate the requirements versions and API groups parse
+	if err := validateRequirements(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the workflow instance id can be set as the instance id label of workflows
+	if id := av.addon.Spec.Lifecycle.WorkflowInstanceID; id != "" {
+		if errs := validation.IsValidLabelValue(id); len(errs) > 0 {
+			return false, fmt.Errorf("invalid spec.lifecycle.workflowInstanceID %q. %s", id, strings.Join(errs, ", "))
+		}
+	}
+
+	// Validate the features are declared by the feature schema
+	if err := validateFeatures(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the common labels and annotations can be set on resources
+	if err := validateCommonMetadata(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the scheduling of workflow pods
+	if err := validatePodTemplate(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the permissions of the workflow service account can be granted with a Role
+	if err := validatePermissions(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate manifests name objects and are not combined with an install workflow
+	if err := validateManifests(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the git source is an http(s) repository and is the only install source
+	if err := validateGitSource(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate override patches parse and patch resources of the source
+	if err := validateOverrides(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the replica overrides name workloads
+	if err := validateReplicas(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the rollout stages the namespace of the addon
+	if err := validateRollout(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the observed resources are selected by at least one label
+	if _, err := ObservationSelector(av.addon); err != nil {
+		return false, err
+	}
+
+	// Validate the resources are observed in valid namespaces
+	if err := validateObservedNamespaces(av.addon); err != nil {
+		return false, err
+	}
+
 	// Validate workflow template is actually a workflow
 	err = av.validateWorkflow()
 	if err != nil {
 		return false, err
 	}
 
+	// Validate the cluster meets the requirements of the addon, dependencies of addons which do not apply to the
+	// cluster are not checked
+	if err := checkRequirements(av.discovery, av.addon); err != nil {
+		return false, err
+	}
+
 	// Validate dependencies are resolvable, no diamond dependency cycles.
 	var visited = make(map[string]*Version)
 	err = av.resolveDependencies(version, visited, 0)
 	if err != nil {
-		return false, err
+		return false, &dependencyError{err: err}
 	}
 
 	// Validate dependencies are installed.
 	err = av.validateDependencies()
 	if err != nil {
-		return false, err
+		return false, &dependencyError{err: err}
 	}
 
 	return true, nil
 }
 
+// ConflictError is returned when the package version of an addon is installed by another addon
+type ConflictError struct {
+	PkgName    string
+	PkgVersion string
+	// Owner is the namespace/name of the addon installing the package version
+	Owner string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("package version %s:%s already exists and cannot be installed as a duplicate, it is installed by addon %s", e.PkgName, e.PkgVersion, e.Owner)
+}
+
+// validateDuplicate checks that no other addon installs the package version, unless the addon allows duplicates to
+// co-manage the package intentionally
 func (av *addonValidator) validateDuplicate(version *Version) error {
-	if v := av.cache.GetVersion(version.PkgName, version.PkgVersion); v != nil && v.Name != version.Name {
-		return fmt.Errorf("package version %s:%s already exists and cannot be installed as a duplicate", av.addon.Spec.PkgName, av.addon.Spec.PkgVersion)
+	if av.addon.GetAnnotations()[common.AllowDuplicateAnnotation] == "true" {
+		return nil
+	}
+	if v := av.cache.GetVersion(version.PkgName, version.PkgVersion); v != nil && (v.Name != version.Name || v.Namespace != version.Namespace) {
+		return &ConflictError{PkgName: av.addon.Spec.PkgName, PkgVersion: av.addon.Spec.PkgVersion, Owner: v.Namespace + "/" + v.Name}
+	}
+
+	return nil
+}
+
+func (av *addonValidator) validateSelector(version *Version) error {
+	if len(version.Selector) == 0 {
+		return nil
+	}
+
+	for _, vmap := range av.cache.GetAllVersions() {
+		for _, v := range vmap {
+			if v.Name == version.Name && v.Namespace == version.Namespace {
+				continue
+			}
+
+			if len(v.Selector) == 0 {
+				continue
+			}
+
+			// Selectors overlap when all labels of one are matched by the other
+			if isSubset(version.Selector, v.Selector) || isSubset(v.Selector, version.Selector) {
+				return fmt.Errorf("selector %v overlaps with selector %v of addon %s/%s, use distinct selector labels", version.Selector, v.Selector, v.Namespace, v.Name)
+			}
+		}
 	}
 
 	return nil
 }
 
+func isSubset(a, b map[string]string) bool {
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectorLabels returns the addon selector match labels without the labels reserved by addon-manager
+func SelectorLabels(a *addonmgrv1alpha1.Addon) map[string]string {
+	var labels = make(map[string]string)
+	for k, v := range a.Spec.Selector.MatchLabels {
+		if common.IsReservedLabel(k) {
+			continue
+		}
+		labels[k] = v
+	}
+	return labels
+}
+
 func (av *addonValidator) validateWorkflow() error {
 	var data map[string]interface{}
 
 	workflowTypes := map[addonmgrv1alpha1.LifecycleStep]addonmgrv1alpha1.WorkflowType{
-		addonmgrv1alpha1.Prereqs:  av.addon.Spec.Lifecycle.Prereqs,
-		addonmgrv1alpha1.Install:  av.addon.Spec.Lifecycle.Install,
-		addonmgrv1alpha1.Delete:   av.addon.Spec.Lifecycle.Delete,
-		addonmgrv1alpha1.Validate: av.addon.Spec.Lifecycle.Validate,
+		addonmgrv1alpha1.Prereqs:     av.addon.Spec.Lifecycle.Prereqs,
+		addonmgrv1alpha1.Install:     av.addon.Spec.Lifecycle.Install,
+		addonmgrv1alpha1.Delete:      av.addon.Spec.Lifecycle.Delete.WorkflowType,
+		addonmgrv1alpha1.Validate:    av.addon.Spec.Lifecycle.Validate,
+		addonmgrv1alpha1.Combined:    av.addon.Spec.Lifecycle.CombinedTemplate.WorkflowType,
+		addonmgrv1alpha1.PostInstall: av.addon.Spec.Lifecycle.PostInstall,
+	}
+
+	if err := av.validateOutputs(workflowTypes); err != nil {
+		return err
+	}
+
+	if av.addon.Spec.Source.Kustomize.Path != "" && av.addon.Spec.Lifecycle.Install.Template != "" {
+		return fmt.Errorf("invalid workflow %q, a template cannot be used with a kustomize source", addonmgrv1alpha1.Install)
+	}
+
+	if err := validateCombinedWorkflow(av.addon); err != nil {
+		return err
 	}
 
 	for key, wt := range workflowTypes {
+		if wt.ActiveDeadlineSeconds < 0 {
+			return fmt.Errorf("invalid workflow %q, activeDeadlineSeconds must be positive", key)
+		}
 		if wt.Template == "" {
 			continue
 		}
 
+		// Registry hosted templates are only pulled when the workflow runs, validate the reference
+		if oci.IsReference(wt.Template) {
+			if _, err := oci.ParseReference(wt.Template); err != nil {
+				return fmt.Errorf("invalid workflow template %q. %v", key, err)
+			}
+			continue
+		}
+
+		if unresolved := workflows.UnresolvedAddonParams(wt.Template, workflows.AddonParamValues(av.addon)); len(unresolved) > 0 {
+			return fmt.Errorf("invalid workflow template %q, unresolved addon params %s", key, strings.Join(unresolved, ", "))
+		}
+		if unresolved := workflows.UnresolvedAddonFeatures(wt.Template, workflows.AddonFeatureValues(av.addon)); len(unresolved) > 0 {
+			return fmt.Errorf("invalid workflow template %q, unresolved addon features %s", key, strings.Join(unresolved, ", "))
+		}
+
 		wf := &unstructured.Unstructured{}
 
 		// Load workflow spec into data obj
@@ -141,6 +339,10 @@ func (av *addonValidator) validateWorkflow() error {
 			return fmt.Errorf("invalid workflow, missing spec")
 		}
 
+		if err := workflows.ValidateSpec(data["spec"]); err != nil {
+			return fmt.Errorf("invalid workflow template %q. %v", key, err)
+		}
+
 		_, found, _ := unstructured.NestedMap(wf.UnstructuredContent(), "spec", "arguments")
 		if !found {
 			continue
@@ -165,60 +367,118 @@ func (av *addonValidator) validateWorkflow() error {
 	return nil
 }
 
+// validateCombinedWorkflow checks that a combined workflow replaces the prereqs and install workflows and defines the
+// template prereqs are mapped from
+func validateCombinedWorkflow(a *addonmgrv1alpha1.Addon) error {
+	combined := a.Spec.Lifecycle.CombinedTemplate
+	if combined.Template == "" {
+		if combined.PrereqsTemplate != "" {
+			return fmt.Errorf("invalid workflow %q, prereqsTemplate is set without a template", addonmgrv1alpha1.Combined)
+		}
+		return nil
+	}
+
+	if a.Spec.Lifecycle.Prereqs.Template != "" || a.Spec.Lifecycle.Install.Template != "" {
+		return fmt.Errorf("invalid workflow %q, a combined template cannot be used with prereqs or install templates", addonmgrv1alpha1.Combined)
+	}
+	if a.Spec.Source.Kustomize.Path != "" || a.Spec.Source.Git.Repo != "" || len(a.Spec.Source.Manifests) > 0 {
+		return fmt.Errorf("invalid workflow %q, a combined template cannot be used with a kustomize source, a git source or manifests", addonmgrv1alpha1.Combined)
+	}
+
+	// Registry hosted templates are only pulled when the workflow runs
+	if oci.IsReference(combined.Template) {
+		return nil
+	}
+
+	var data map[string]interface{}
+	if err := yaml.Unmarshal([]byte(combined.Template), &data); err != nil {
+		return fmt.Errorf("invalid workflow template %q. %v", addonmgrv1alpha1.Combined, err)
+	}
+	templates, _, _ := unstructured.NestedSlice(data, "spec", "templates")
+	for _, t := range templates {
+		if template, ok := t.(map[string]interface{}); ok && template["name"] == a.CombinedPrereqsTemplate() {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid workflow %q, the template %q running prereqs is not defined", addonmgrv1alpha1.Combined, a.CombinedPrereqsTemplate())
+}
+
+// validateOutputs checks that only the prereqs workflow declares outputs and that they do not shadow addon params
+func (av *addonValidator) validateOutputs(workflowTypes map[addonmgrv1alpha1.LifecycleStep]addonmgrv1alpha1.WorkflowType) error {
+	for key, wt := range workflowTypes {
+		if key != addonmgrv1alpha1.Prereqs && len(wt.Outputs) > 0 {
+			return fmt.Errorf("invalid workflow %q, only the prereqs workflow can declare outputs", key)
+		}
+	}
+
+	prereqs := workflowTypes[addonmgrv1alpha1.Prereqs]
+	if len(prereqs.Outputs) > 0 && prereqs.Template == "" {
+		return fmt.Errorf("invalid workflow %q, outputs are declared without a template", addonmgrv1alpha1.Prereqs)
+	}
+
+	addonParams := av.addon.GetAllAddonParameters()
+	var seen = make(map[string]bool, len(prereqs.Outputs))
+	for _, o := range prereqs.Outputs {
+		if _, in := addonParams[o.Name]; in {
+			return fmt.Errorf("invalid workflow %q, output named %q found in addon params", addonmgrv1alpha1.Prereqs, o.Name)
+		}
+		if seen[o.Name] {
+			return fmt.Errorf("invalid workflow %q, duplicate output %q", addonmgrv1alpha1.Prereqs, o.Name)
+		}
+		seen[o.Name] = true
+	}
+
+	return nil
+}
+
 func (av *addonValidator) validateAddonNameLength() error {
 	if len(av.addon.Name) > 31 {
 		return fmt.Errorf("Addon name %s must be less than 32 characters", av.addon.Name)
 	}
+
+	// Workflow names are copied to a label of their pods, a name prefix must keep them within the label value limit
+	for _, step := range []addonmgrv1alpha1.LifecycleStep{addonmgrv1alpha1.Prereqs, addonmgrv1alpha1.Install,
+		addonmgrv1alpha1.Delete, addonmgrv1alpha1.Validate, addonmgrv1alpha1.Combined, addonmgrv1alpha1.PostInstall} {
+		if wfName := av.addon.GetFormattedWorkflowName(step); len(wfName) > validation.LabelValueMaxLength {
+			return fmt.Errorf("invalid workflow %q, name %s must be no more than %d characters, shorten the addon name or namePrefix", step, wfName, validation.LabelValueMaxLength)
+		}
+	}
 	return nil
 }
 
+// validateDependencies checks that all dependencies are installed, dependencies which are not installed are reported
+// before pending ones so that an addon only waits on dependencies which can still be installed
 func (av *addonValidator) validateDependencies() error {
-	// Check addon cache to see that addon pkgName:pkgVersion was installed
-	for pkgName, pkgVersion := range av.addon.Spec.PkgDeps {
-		pkgName = strings.TrimSpace(pkgName)
-		pkgVersion = strings.TrimSpace(pkgVersion)
-
-		if pkgVersion == "*" {
-			// Ignore version
-			versions := av.cache.GetVersions(pkgName)
-			if versions == nil {
-				return fmt.Errorf("required dependency %s is not installed", pkgName)
-			}
-
-			// Look for any successfully installed version
-			var versionFound = false
-			for _, v := range versions {
-				if v.PkgPhase == addonmgrv1alpha1.Succeeded {
-					versionFound = true
-					break
-				}
-			}
-
-			if !versionFound {
-				return fmt.Errorf("required dependency %s has no valid versions installed", pkgName)
-			}
-		} else {
-			// Check for specific version
-			v := av.cache.GetVersion(pkgName, pkgVersion)
-			if v == nil {
-				return fmt.Errorf(ErrDepNotInstalled+": %q:%q", pkgName, pkgVersion)
+	var pending error
+	for _, dep := range DependencyStatuses(av.cache, av.addon) {
+		switch dep.State {
+		case addonmgrv1alpha1.DependencyInstalled:
+			continue
+		case addonmgrv1alpha1.DependencyPending:
+			if pending == nil {
+				pending = fmt.Errorf(ErrDepPending+": %q:%q", dep.PkgName, dep.PkgVersion)
 			}
+			continue
+		}
 
-			switch v.PkgPhase {
-			case addonmgrv1alpha1.Succeeded:
-				return nil
-			case addonmgrv1alpha1.Pending:
-				return fmt.Errorf(ErrDepPending+": %q:%q", pkgName, pkgVersion)
-			default:
-				return fmt.Errorf(ErrDepNotInstalled+": %q:%q", pkgName, pkgVersion)
-			}
+		if dep.PkgVersion != "*" {
+			return fmt.Errorf(ErrDepNotInstalled+": %q:%q", dep.PkgName, dep.PkgVersion)
+		}
+		if len(av.cache.GetVersions(dep.PkgName)) == 0 {
+			return fmt.Errorf("required dependency %s is not installed", dep.PkgName)
 		}
+		return fmt.Errorf("required dependency %s has no valid versions installed", dep.PkgName)
 	}
 
-	return nil
+	return pending
 }
 
 func (av *addonValidator) resolveDependencies(n *Version, visited map[string]*Version, depth int) error {
+	return av.resolveDependencyPath(n, visited, []Version{*n}, depth)
+}
+
+// resolveDependencyPath walks the dependency graph depth first, path holds the versions from the root to n
+func (av *addonValidator) resolveDependencyPath(n *Version, visited map[string]*Version, path []Version, depth int) error {
 	if depth >= 256 {
 		panic("Recursive max depth of 256 seen, this is bad!")
 	}
@@ -232,7 +492,7 @@ func (av *addonValidator) resolveDependencies(n *Version, visited map[string]*Ve
 		pkgVersion = strings.TrimSpace(pkgVersion)
 
 		if pkgName == n.PkgName {
-			return fmt.Errorf("invalid package dependency, addon cannot depend on it's own package name %s:%s", pkgName, pkgVersion)
+			return &CycleError{Path: []Version{*n, *n}}
 		}
 
 		v := av.cache.GetVersion(pkgName, pkgVersion)
@@ -244,11 +504,11 @@ func (av *addonValidator) resolveDependencies(n *Version, visited map[string]*Ve
 		// Validate it resolves without cyclic dependency
 		dname := v.PkgName + ":" + v.PkgVersion
 		if _, ok := visited[dname]; ok {
-			return fmt.Errorf("circular dependency was found in %s:%s", v.PkgName, v.PkgVersion)
+			return &CycleError{Path: cyclePath(path, *v)}
 		}
 
 		// Recursive - make sure we catch all cases.
-		err := av.resolveDependencies(v, visited, depth+1)
+		err := av.resolveDependencyPath(v, visited, append(path[:len(path):len(path)], *v), depth+1)
 		if err != nil {
 			return err
 		}
@@ -260,3 +520,355 @@ func (av *addonValidator) resolveDependencies(n *Version, visited map[string]*Ve
 
 	return nil
 }
+
+// cyclePath returns the part of path starting at v, closed with v
+func cyclePath(path []Version, v Version) []Version {
+	for i := range path {
+		if path[i].PkgName == v.PkgName && path[i].PkgVersion == v.PkgVersion {
+			return append(path[i:len(path):len(path)], v)
+		}
+	}
+	return append(path[:len(path):len(path)], v)
+}
+
+// dependencyError is returned when the dependencies of an addon cannot be resolved or are not installed
+type dependencyError struct {
+	err error
+}
+
+func (e *dependencyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *dependencyError) Unwrap() error {
+	return e.err
+}
+
+// IsDependencyError returns true if the addon is not valid because of its dependencies
+func IsDependencyError(err error) bool {
+	var depErr *dependencyError
+	return errors.As(err, &depErr)
+}
+
+// CycleError is returned when addon dependencies form a cycle, Path starts and ends with the same package
+type CycleError struct {
+	Path []Version
+}
+
+func (e *CycleError) Error() string {
+	var names = make([]string, 0, len(e.Path))
+	for _, v := range e.Path {
+		names = append(names, v.PkgName+":"+v.PkgVersion)
+	}
+
+	if len(e.Path) == 2 {
+		return fmt.Sprintf("invalid package dependency, addon cannot depend on it's own package name %s", names[0])
+	}
+	return fmt.Sprintf("circular dependency was found %s", strings.Join(names, " -> "))
+}
+
+// Addons returns the addons in the cycle
+func (e *CycleError) Addons() []types.NamespacedName {
+	var seen = make(map[types.NamespacedName]bool)
+	var addons []types.NamespacedName
+	for _, v := range e.Path {
+		key := types.NamespacedName{Namespace: v.Namespace, Name: v.Name}
+		if v.Name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		addons = append(addons, key)
+	}
+	return addons
+}
+
+// CheckResult is the outcome of a single offline validation check
+type CheckResult struct {
+	Name    string
+	Err     error
+	Skipped bool
+}
+
+// ValidateOffline runs the addon checks which do not require cluster access, checks that depend on
+// other addons or cluster state are returned as skipped.
+func ValidateOffline(a *addonmgrv1alpha1.Addon) []CheckResult {
+	av := &addonValidator{addon: a, cache: NewAddonVersionCacheClient()}
+
+	return []CheckResult{
+		{Name: "package", Err: validatePackage(a)},
+		{Name: "name-length", Err: av.validateAddonNameLength()},
+		{Name: "namespace", Err: validateNamespace(a)},
+		{Name: "workflows", Err: av.validateWorkflow()},
+		{Name: "dependencies", Err: validateDependencySyntax(a)},
+		{Name: "selector", Err: validateSelectorSyntax(a)},
+		{Name: "secrets", Err: validateSecretNames(a)},
+		{Name: "common-metadata", Err: validateCommonMetadata(a)},
+		{Name: "manifests", Err: validateManifests(a)},
+		{Name: "git", Err: validateGitSource(a)},
+		{Name: "pod-template", Err: validatePodTemplate(a)},
+		{Name: "permissions", Err: validatePermissions(a)},
+		{Name: "overrides", Err: validateOverrides(a)},
+		{Name: "replicas", Err: validateReplicas(a)},
+		{Name: "rollout", Err: validateRollout(a)},
+		{Name: "requirements", Err: validateRequirements(a)},
+		{Name: "features", Err: validateFeatures(a)},
+		{Name: "observed-namespaces", Err: validateObservedNamespaces(a)},
+		{Name: "duplicate-version", Skipped: true},
+		{Name: "selector-overlap", Skipped: true},
+		{Name: "dependencies-installed", Skipped: true},
+		{Name: "secrets-exist", Skipped: true},
+		{Name: "requirements-met", Skipped: true},
+	}
+}
+
+func validatePackage(a *addonmgrv1alpha1.Addon) error {
+	if a.Spec.PkgName == "" || a.Spec.PkgVersion == "" {
+		return fmt.Errorf("spec.pkgName and spec.pkgVersion are required")
+	}
+
+	switch a.Spec.PkgType {
+	case addonmgrv1alpha1.HelmPkg, addonmgrv1alpha1.ShipPkg, addonmgrv1alpha1.KustomizePkg, addonmgrv1alpha1.CnabPkg, addonmgrv1alpha1.CompositePkg:
+		return nil
+	}
+
+	return fmt.Errorf("unsupported package type %q in spec.pkgType", a.Spec.PkgType)
+}
+
+func validateNamespace(a *addonmgrv1alpha1.Addon) error {
+	if a.Spec.Params.Namespace == "" {
+		return fmt.Errorf("namespace is empty in addon.spec.params.namespace")
+	}
+
+	if errs := validation.IsDNS1123Label(a.Spec.Params.Namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q in addon.spec.params.namespace. %s", a.Spec.Params.Namespace, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func validateDependencySyntax(a *addonmgrv1alpha1.Addon) error {
+	for pkgName, pkgVersion := range a.Spec.PkgDeps {
+		pkgName = strings.TrimSpace(pkgName)
+		pkgVersion = strings.TrimSpace(pkgVersion)
+
+		if pkgName == "" || pkgVersion == "" {
+			return fmt.Errorf("invalid package dependency %q: %q, name and version are required", pkgName, pkgVersion)
+		}
+
+		if pkgName == a.Spec.PkgName {
+			return fmt.Errorf("invalid package dependency, addon cannot depend on it's own package name %s:%s", pkgName, pkgVersion)
+		}
+	}
+
+	return nil
+}
+
+func validateSelectorSyntax(a *addonmgrv1alpha1.Addon) error {
+	if _, err := metav1.LabelSelectorAsSelector(&a.Spec.Selector); err != nil {
+		return fmt.Errorf("label selector is invalid. %v", err)
+	}
+
+	return nil
+}
+
+func validateSecretNames(a *addonmgrv1alpha1.Addon) error {
+	for _, secret := range a.Spec.Secrets {
+		if errs := validation.IsDNS1123Subdomain(secret.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid secret name %q in spec.secrets. %s", secret.Name, strings.Join(errs, ", "))
+		}
+		for _, key := range secret.RequiredKeys {
+			if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+				return fmt.Errorf("invalid required key %q of secret %q in spec.secrets. %s", key, secret.Name, strings.Join(errs, ", "))
+			}
+		}
+	}
+
+	for _, name := range a.Spec.Lifecycle.ImagePullSecrets {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("invalid secret name %q in spec.lifecycle.imagePullSecrets. %s", name, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// validateFeatures checks that feature keys can be referenced by templates, that declared features are unique and
+// that only declared features are set when the addon declares a feature schema
+func validateFeatures(a *addonmgrv1alpha1.Addon) error {
+	var declared = make(map[string]bool, len(a.Spec.FeatureSchema))
+	for i, f := range a.Spec.FeatureSchema {
+		if !featureKeyPattern.MatchString(f.Name) {
+			return fmt.Errorf("invalid spec.featureSchema[%d], name %q must consist of alphanumeric characters, '-', '_' or '.'", i, f.Name)
+		}
+		if declared[f.Name] {
+			return fmt.Errorf("invalid spec.featureSchema[%d], feature %q is declared more than once", i, f.Name)
+		}
+		declared[f.Name] = true
+	}
+
+	for _, key := range sortedFeatureKeys(a.Spec.Params.Features) {
+		if !featureKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid feature %q in spec.params.features, keys must consist of alphanumeric characters, '-', '_' or '.'", key)
+		}
+		if len(declared) > 0 && !declared[key] {
+			return fmt.Errorf("invalid feature %q in spec.params.features, it is not declared in spec.featureSchema", key)
+		}
+	}
+
+	return nil
+}
+
+func sortedFeatureKeys(m map[string]bool) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validatePermissions checks that the permissions are granted to a service account and can be granted with a Role
+func validatePermissions(a *addonmgrv1alpha1.Addon) error {
+	if len(a.Spec.Lifecycle.Permissions) == 0 {
+		return nil
+	}
+
+	if a.Spec.Lifecycle.ServiceAccount == "" {
+		return fmt.Errorf("invalid spec.lifecycle.permissions, they are granted to spec.lifecycle.serviceAccount which is not set")
+	}
+	for i, rule := range a.Spec.Lifecycle.Permissions {
+		switch {
+		case len(rule.Verbs) == 0:
+			return fmt.Errorf("invalid spec.lifecycle.permissions[%d], verbs are required", i)
+		case len(rule.Resources) == 0:
+			return fmt.Errorf("invalid spec.lifecycle.permissions[%d], resources are required", i)
+		case len(rule.NonResourceURLs) > 0:
+			return fmt.Errorf("invalid spec.lifecycle.permissions[%d], nonResourceURLs cannot be granted in a namespace", i)
+		}
+	}
+
+	return nil
+}
+
+func validateCommonMetadata(a *addonmgrv1alpha1.Addon) error {
+	for _, key := range sortedKeys(a.Spec.CommonLabels) {
+		if common.IsReservedLabel(key) {
+			return fmt.Errorf("invalid spec.commonLabels, label %s is reserved", key)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid spec.commonLabels key %q. %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(a.Spec.CommonLabels[key]); len(errs) > 0 {
+			return fmt.Errorf("invalid spec.commonLabels value of %s. %s", key, strings.Join(errs, ", "))
+		}
+	}
+
+	for _, key := range sortedKeys(a.Spec.CommonAnnotations) {
+		if errs := validation.IsQualifiedName(strings.ToLower(key)); len(errs) > 0 {
+			return fmt.Errorf("invalid spec.commonAnnotations key %q. %s", key, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateManifests(a *addonmgrv1alpha1.Addon) error {
+	if len(a.Spec.Source.Manifests) == 0 {
+		return nil
+	}
+
+	if a.Spec.Lifecycle.Install.Template != "" || a.Spec.Source.Kustomize.Path != "" {
+		return fmt.Errorf("invalid spec.source.manifests, manifests cannot be used with an install template or a kustomize source")
+	}
+
+	for i, m := range a.Spec.Source.Manifests {
+		if _, err := DecodeManifest(m); err != nil {
+			return fmt.Errorf("invalid manifest %d in spec.source.manifests. %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+func validateGitSource(a *addonmgrv1alpha1.Addon) error {
+	src := a.Spec.Source.Git
+	if src.Repo == "" {
+		if src.Ref != "" || src.Path != "" || src.SecretName != "" {
+			return fmt.Errorf("invalid spec.source.git, repo is required")
+		}
+		return nil
+	}
+
+	if a.Spec.Lifecycle.Install.Template != "" || a.Spec.Source.Kustomize.Path != "" || len(a.Spec.Source.Manifests) > 0 {
+		return fmt.Errorf("invalid spec.source.git, a git source cannot be used with an install template, a kustomize source or manifests")
+	}
+
+	if err := git.ValidateRepo(src.Repo); err != nil {
+		return fmt.Errorf("invalid spec.source.git.repo. %v", err)
+	}
+
+	if path.IsAbs(src.Path) || common.ContainsString(strings.Split(src.Path, "/"), "..") {
+		return fmt.Errorf("invalid spec.source.git.path %q, the path must be relative to the repository root", src.Path)
+	}
+
+	if src.SecretName != "" {
+		if errs := validation.IsDNS1123Subdomain(src.SecretName); len(errs) > 0 {
+			return fmt.Errorf("invalid spec.source.git.secretName %q. %s", src.SecretName, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// validateReplicas checks that the replica overrides name workloads once and their replica counts are not negative
+func validateReplicas(a *addonmgrv1alpha1.Addon) error {
+	var seen = make(map[string]bool, len(a.Spec.Replicas))
+	for _, o := range a.Spec.Replicas {
+		if o.Kind != "Deployment" && o.Kind != "StatefulSet" {
+			return fmt.Errorf("invalid spec.replicas kind %q, only Deployment and StatefulSet are scaled", o.Kind)
+		}
+		if errs := validation.IsDNS1123Subdomain(o.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid spec.replicas name %q. %s", o.Name, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsDNS1123Label(o.Namespace); o.Namespace != "" && len(errs) > 0 {
+			return fmt.Errorf("invalid spec.replicas namespace %q. %s", o.Namespace, strings.Join(errs, ", "))
+		}
+		if o.Replicas < 0 {
+			return fmt.Errorf("invalid spec.replicas of %s %s, replicas must not be negative", o.Kind, o.Name)
+		}
+
+		key := fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+		if seen[key] {
+			return fmt.Errorf("invalid spec.replicas, %s %s is listed more than once", o.Kind, o.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func validateOverrides(a *addonmgrv1alpha1.Addon) error {
+	if len(a.Spec.Overrides.Patches) == 0 {
+		return nil
+	}
+
+	if len(a.Spec.Source.Manifests) == 0 && a.Spec.Source.Kustomize.Path == "" && a.Spec.Source.Git.Repo == "" {
+		return fmt.Errorf("invalid spec.overrides.patches, patches apply to resources of spec.source which is not set")
+	}
+
+	for _, p := range a.Spec.Overrides.Patches {
+		if err := ValidateOverridePatch(p); err != nil {
+			return fmt.Errorf("invalid override %s in spec.overrides.patches. %v", p.Target, err)
+		}
+	}
+
+	return nil
+}
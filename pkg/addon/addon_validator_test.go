@@ -15,17 +15,21 @@
 package addon
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic/fake"
 
 	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
 )
 
 var dynClient = fake.NewSimpleDynamicClient(runtime.NewScheme())
@@ -46,7 +50,7 @@ func TestNewAddonValidator(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewAddonValidator(tt.args.addon, cache, dynClient); !reflect.DeepEqual(got, tt.want) {
+			if got := NewAddonValidator(tt.args.addon, cache, dynClient, nil); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewAddonValidator() = %v, want %v", got, tt.want)
 			}
 		})
@@ -173,6 +177,190 @@ spec:
 				},
 			},
 		}}, want: true, wantErr: false},
+		{name: "workflow-outputs-not-prereqs", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+					Install: addonmgrv1alpha1.WorkflowType{
+						Outputs: []addonmgrv1alpha1.WorkflowOutput{{Name: "password"}},
+					},
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "workflow-outputs-shadow-params", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+					Prereqs: addonmgrv1alpha1.WorkflowType{
+						Template: "oci://registry.example.com/addons/prereqs:v1",
+						Outputs:  []addonmgrv1alpha1.WorkflowOutput{{Name: "namespace"}},
+					},
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "required-crd-invalid", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				RequiredCRDs: []string{"Certificate"},
+			},
+		}}, want: false, wantErr: true},
+		{name: "manifests-with-install-template", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+					Install: addonmgrv1alpha1.WorkflowType{
+						Template: "oci://registry.example.com/addons/install:v1",
+					},
+				},
+				Source: addonmgrv1alpha1.AddonSource{
+					Manifests: []runtime.RawExtension{
+						{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`)},
+					},
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "manifest-without-name", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Source: addonmgrv1alpha1.AddonSource{
+					Manifests: []runtime.RawExtension{
+						{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{}}`)},
+					},
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "manifests-valid", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Source: addonmgrv1alpha1.AddonSource{
+					Manifests: []runtime.RawExtension{
+						{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`)},
+					},
+				},
+			},
+		}}, want: true, wantErr: false},
+		{name: "override-without-source", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Overrides: addonmgrv1alpha1.AddonOverridesSpec{
+					Patches: []addonmgrv1alpha1.OverridePatch{
+						{Target: addonmgrv1alpha1.OverrideTarget{Version: "v1", Kind: "ConfigMap", Name: "foo"}, Patch: "data:\n  key: value\n"},
+					},
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "override-invalid-patch", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Source: addonmgrv1alpha1.AddonSource{
+					Manifests: []runtime.RawExtension{
+						{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`)},
+					},
+				},
+				Overrides: addonmgrv1alpha1.AddonOverridesSpec{
+					Patches: []addonmgrv1alpha1.OverridePatch{
+						{Target: addonmgrv1alpha1.OverrideTarget{Version: "v1", Kind: "ConfigMap", Name: "foo"}, Type: addonmgrv1alpha1.JSONPatch, Patch: "data: {}"},
+					},
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "workflow-instance-id-invalid", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+					WorkflowInstanceID: "shard b",
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "observation-selector-empty", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				ObservationLabels: map[string]string{
+					"app.kubernetes.io/managed-by": "",
+					"app.kubernetes.io/name":       "",
+				},
+			},
+		}}, want: false, wantErr: true},
 		{name: "workflow-template-invalid-kind", fields: fields{addon: &addonmgrv1alpha1.Addon{
 			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
 			Spec: addonmgrv1alpha1.AddonSpec{
@@ -211,12 +399,39 @@ spec:
 					Namespace: "addon-test-ns",
 				},
 				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
-					Delete: addonmgrv1alpha1.WorkflowType{
+					Delete: addonmgrv1alpha1.DeleteWorkflowType{WorkflowType: addonmgrv1alpha1.WorkflowType{
 						NamePrefix: "test",
 						Role:       "arn:12345",
 						Template: `
 apiVersion: argoproj.io/v1alpha1
 kind: Workflow
+`,
+					}},
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "workflow-template-invalid-undefined-entrypoint", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+					Install: addonmgrv1alpha1.WorkflowType{
+						Template: `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+spec:
+  entrypoint: entry
+  templates:
+  - name: main
+    container:
+      image: alpine
 `,
 					},
 				},
@@ -236,7 +451,7 @@ kind: Workflow
 					},
 				},
 				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
-					Delete: addonmgrv1alpha1.WorkflowType{
+					Delete: addonmgrv1alpha1.DeleteWorkflowType{WorkflowType: addonmgrv1alpha1.WorkflowType{
 						NamePrefix: "test",
 						Role:       "arn:12345",
 						Template: `
@@ -255,7 +470,7 @@ spec:
           - name: foo
             value: bar
 `,
-					},
+					}},
 				},
 			},
 		}}, want: false, wantErr: true},
@@ -274,7 +489,7 @@ spec:
 					},
 				},
 				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
-					Delete: addonmgrv1alpha1.WorkflowType{
+					Delete: addonmgrv1alpha1.DeleteWorkflowType{WorkflowType: addonmgrv1alpha1.WorkflowType{
 						NamePrefix: "test",
 						Role:       "arn:12345",
 						Template: `
@@ -293,6 +508,42 @@ spec:
       - - name: prereq-resources
           template: submit
 `,
+					}},
+				},
+			},
+		}}, want: false, wantErr: true},
+		{name: "workflow-template-oci-reference", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "oci", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-oci",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+					Install: addonmgrv1alpha1.WorkflowType{
+						Template: "oci://registry.example.com/templates/install:v1",
+					},
+				},
+			},
+		}}, want: true, wantErr: false},
+		{name: "workflow-template-oci-reference-invalid", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "oci-invalid", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-oci-invalid",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+					Install: addonmgrv1alpha1.WorkflowType{
+						Template: "oci://registry.example.com",
 					},
 				},
 			},
@@ -341,6 +592,25 @@ func Test_addonValidator_Validate_Fail_NameLength(t *testing.T) {
 				},
 			},
 		}}, want: false, wantErr: true},
+		{name: "addon-fails-with-workflow-name-too-long", fields: fields{addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+				Params: addonmgrv1alpha1.AddonParams{
+					Namespace: "addon-test-ns",
+				},
+				Lifecycle: addonmgrv1alpha1.LifecycleWorkflowSpec{
+					PostInstall: addonmgrv1alpha1.WorkflowType{
+						NamePrefix: "smoke-test",
+						Template:   "kind: Workflow",
+					},
+				},
+			},
+		}}, want: false, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -361,6 +631,28 @@ func Test_addonValidator_Validate_Fail_NameLength(t *testing.T) {
 	}
 }
 
+func Test_addonValidator_Validate_Fail_EmptyNamespace(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	av := &addonValidator{
+		addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon-1", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				PackageSpec: addonmgrv1alpha1.PackageSpec{
+					PkgType:    addonmgrv1alpha1.CompositePkg,
+					PkgName:    "test/addon-1",
+					PkgVersion: "1.0.0",
+				},
+			},
+		},
+		cache:     NewAddonVersionCacheClient(),
+		dynClient: dynClient,
+	}
+	ok, err := av.Validate()
+	g.Expect(ok).To(gomega.BeFalse())
+	g.Expect(err).To(gomega.MatchError("namespace is empty in addon.spec.params.namespace"))
+}
+
 func Test_addonValidator_Validate_NoDeps(t *testing.T) {
 	var cache = NewAddonVersionCacheClient()
 	type fields struct {
@@ -817,8 +1109,339 @@ func Test_validateDuplicate_Fail(t *testing.T) {
 		PkgPhase:    addonmgrv1alpha1.Pending,
 	})
 
-	errMsg := fmt.Sprintf("package version %s:%s already exists and cannot be installed as a duplicate", av.addon.Spec.PkgName, av.addon.Spec.PkgVersion)
+	errMsg := fmt.Sprintf("package version %s:%s already exists and cannot be installed as a duplicate, it is installed by addon default/test-addon-1", av.addon.Spec.PkgName, av.addon.Spec.PkgVersion)
 
 	g.Expect(err).Should(gomega.HaveOccurred(), "Should not validate")
 	g.Expect(err).Should(gomega.MatchError(errMsg))
+	var conflictErr *ConflictError
+	g.Expect(errors.As(err, &conflictErr)).To(gomega.BeTrue())
+	g.Expect(conflictErr.Owner).To(gomega.Equal("default/test-addon-1"))
+
+	// Duplicates are allowed when the addon co-manages the package intentionally
+	av.addon.SetAnnotations(map[string]string{common.AllowDuplicateAnnotation: "true"})
+	g.Expect(av.validateDuplicate(&Version{
+		Name:        av.addon.Name,
+		Namespace:   av.addon.Namespace,
+		PackageSpec: av.addon.GetPackageSpec(),
+		PkgPhase:    addonmgrv1alpha1.Pending,
+	})).To(gomega.Succeed())
+}
+
+func Test_validateSelector(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cached := NewAddonVersionCacheClient()
+
+	cached.AddVersion(Version{
+		Name:      "core-a",
+		Namespace: "default",
+		PackageSpec: addonmgrv1alpha1.PackageSpec{
+			PkgName:    "core/A",
+			PkgVersion: "1.0.0",
+		},
+		PkgPhase: addonmgrv1alpha1.Succeeded,
+		Selector: map[string]string{"app": "shared"},
+	})
+
+	av := &addonValidator{
+		addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "core-b", Namespace: "default"},
+		},
+		cache:     cached,
+		dynClient: dynClient,
+	}
+
+	tests := []struct {
+		name     string
+		selector map[string]string
+		wantErr  bool
+	}{
+		{name: "empty-selector", selector: map[string]string{}, wantErr: false},
+		{name: "distinct-selector", selector: map[string]string{"app": "other"}, wantErr: false},
+		{name: "same-selector", selector: map[string]string{"app": "shared"}, wantErr: true},
+		{name: "superset-selector", selector: map[string]string{"app": "shared", "tier": "web"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := av.validateSelector(&Version{Name: "core-b", Namespace: "default", Selector: tt.selector})
+			if tt.wantErr {
+				g.Expect(err).Should(gomega.HaveOccurred())
+				g.Expect(err.Error()).Should(gomega.ContainSubstring("default/core-a"))
+			} else {
+				g.Expect(err).ShouldNot(gomega.HaveOccurred())
+			}
+		})
+	}
+
+	// An addon never conflicts with its own cached selector
+	g.Expect(av.validateSelector(&Version{Name: "core-a", Namespace: "default", Selector: map[string]string{"app": "shared"}})).Should(gomega.Succeed())
+}
+
+func Test_SelectorLabels(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{
+		Spec: addonmgrv1alpha1.AddonSpec{
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app":                          "my-app",
+					"app.kubernetes.io/name":       "my-addon",
+					"app.kubernetes.io/managed-by": "addonmgr.keikoproj.io",
+				},
+			},
+		},
+	}
+
+	g.Expect(SelectorLabels(a)).Should(gomega.Equal(map[string]string{"app": "my-app"}))
+}
+
+func Test_resolveDependencies_Cycle(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cached := NewAddonVersionCacheClient()
+
+	// core/A -> core/B -> core/C -> core/A
+	for name, dep := range map[string]string{"A": "B", "B": "C", "C": "A"} {
+		cached.AddVersion(Version{
+			Name:      "core-" + strings.ToLower(name),
+			Namespace: "default",
+			PackageSpec: addonmgrv1alpha1.PackageSpec{
+				PkgName:    "core/" + name,
+				PkgVersion: "1.0.0",
+				PkgDeps: map[string]string{
+					"core/" + dep: "1.0.0",
+				},
+			},
+			PkgPhase: addonmgrv1alpha1.Pending,
+		})
+	}
+
+	av := &addonValidator{
+		addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "core-a", Namespace: "default"},
+		},
+		cache:     cached,
+		dynClient: dynClient,
+	}
+
+	root := cached.GetVersion("core/A", "1.0.0")
+	err := av.resolveDependencies(root, make(map[string]*Version), 0)
+	g.Expect(err).Should(gomega.HaveOccurred())
+	g.Expect(err.Error()).Should(gomega.Equal("circular dependency was found core/A:1.0.0 -> core/B:1.0.0 -> core/C:1.0.0 -> core/A:1.0.0"))
+
+	cycleErr, ok := err.(*CycleError)
+	g.Expect(ok).Should(gomega.BeTrue())
+	g.Expect(cycleErr.Addons()).Should(gomega.ConsistOf(
+		types.NamespacedName{Namespace: "default", Name: "core-a"},
+		types.NamespacedName{Namespace: "default", Name: "core-b"},
+		types.NamespacedName{Namespace: "default", Name: "core-c"},
+	))
+}
+
+func Test_resolveDependencies_SelfDependency(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	av := &addonValidator{
+		addon: &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		},
+		cache:     NewAddonVersionCacheClient(),
+		dynClient: dynClient,
+	}
+
+	self := &Version{
+		Name:      "foo",
+		Namespace: "default",
+		PackageSpec: addonmgrv1alpha1.PackageSpec{
+			PkgName:    "test/addon-1",
+			PkgVersion: "1.0.0",
+			PkgDeps: map[string]string{
+				"test/addon-1": "*",
+			},
+		},
+	}
+
+	err := av.resolveDependencies(self, make(map[string]*Version), 0)
+	g.Expect(err).Should(gomega.HaveOccurred())
+	g.Expect(err.Error()).Should(gomega.ContainSubstring("addon cannot depend on it's own package name test/addon-1:1.0.0"))
+	g.Expect(err.(*CycleError).Addons()).Should(gomega.Equal([]types.NamespacedName{{Namespace: "default", Name: "foo"}}))
+}
+
+func Test_validateGitSource(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	tests := []struct {
+		name    string
+		src     addonmgrv1alpha1.AddonSource
+		install string
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "valid", src: addonmgrv1alpha1.AddonSource{Git: addonmgrv1alpha1.GitSource{Repo: "https://github.com/org/addons.git", Ref: "v1.0.0", Path: "event-router/prod", SecretName: "git-creds"}}},
+		{name: "missing repo", src: addonmgrv1alpha1.AddonSource{Git: addonmgrv1alpha1.GitSource{Ref: "main"}}, wantErr: "repo is required"},
+		{name: "ssh repo", src: addonmgrv1alpha1.AddonSource{Git: addonmgrv1alpha1.GitSource{Repo: "git@github.com:org/addons.git"}}, wantErr: "invalid spec.source.git.repo"},
+		{name: "absolute path", src: addonmgrv1alpha1.AddonSource{Git: addonmgrv1alpha1.GitSource{Repo: "https://github.com/org/addons.git", Path: "/etc"}}, wantErr: "must be relative"},
+		{name: "parent path", src: addonmgrv1alpha1.AddonSource{Git: addonmgrv1alpha1.GitSource{Repo: "https://github.com/org/addons.git", Path: "addons/../.."}}, wantErr: "must be relative"},
+		{name: "secret name", src: addonmgrv1alpha1.AddonSource{Git: addonmgrv1alpha1.GitSource{Repo: "https://github.com/org/addons.git", SecretName: "Git_Creds"}}, wantErr: "invalid spec.source.git.secretName"},
+		{name: "with kustomize", src: addonmgrv1alpha1.AddonSource{Git: addonmgrv1alpha1.GitSource{Repo: "https://github.com/org/addons.git"}, Kustomize: addonmgrv1alpha1.KustomizeSource{Path: "github.com/org/repo"}}, wantErr: "cannot be used"},
+		{name: "with template", src: addonmgrv1alpha1.AddonSource{Git: addonmgrv1alpha1.GitSource{Repo: "https://github.com/org/addons.git"}}, install: "kind: Workflow", wantErr: "cannot be used"},
+	}
+
+	for _, tt := range tests {
+		a := &addonmgrv1alpha1.Addon{Spec: addonmgrv1alpha1.AddonSpec{Source: tt.src}}
+		a.Spec.Lifecycle.Install.Template = tt.install
+
+		err := validateGitSource(a)
+		if tt.wantErr == "" {
+			g.Expect(err).ShouldNot(gomega.HaveOccurred(), tt.name)
+			continue
+		}
+		g.Expect(err).Should(gomega.HaveOccurred(), tt.name)
+		g.Expect(err.Error()).Should(gomega.ContainSubstring(tt.wantErr), tt.name)
+	}
+}
+
+func Test_validateSecretNames(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Spec.Secrets = []addonmgrv1alpha1.SecretCmdSpec{{Name: "db-creds", RequiredKeys: []string{"username", "tls.crt"}}}
+	g.Expect(validateSecretNames(a)).ShouldNot(gomega.HaveOccurred())
+
+	a.Spec.Secrets[0].RequiredKeys = []string{"user name"}
+	g.Expect(validateSecretNames(a)).Should(gomega.MatchError(gomega.ContainSubstring(`invalid required key "user name" of secret "db-creds"`)))
+
+	a.Spec.Secrets = []addonmgrv1alpha1.SecretCmdSpec{{Name: "DB_Creds"}}
+	g.Expect(validateSecretNames(a)).Should(gomega.MatchError(gomega.ContainSubstring(`invalid secret name "DB_Creds"`)))
+
+	a.Spec.Secrets = nil
+	a.Spec.Lifecycle.ImagePullSecrets = []string{"Registry"}
+	g.Expect(validateSecretNames(a)).Should(gomega.MatchError(gomega.ContainSubstring(`invalid secret name "Registry" in spec.lifecycle.imagePullSecrets`)))
+}
+
+func Test_validateCommonMetadata(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Spec.CommonLabels = map[string]string{"team": "platform", "example.com/cost-center": "1234"}
+	a.Spec.CommonAnnotations = map[string]string{"example.com/owner": "Platform Team <platform@example.com>"}
+	g.Expect(validateCommonMetadata(a)).ShouldNot(gomega.HaveOccurred())
+
+	a.Spec.CommonLabels["app.kubernetes.io/name"] = "other"
+	g.Expect(validateCommonMetadata(a)).Should(gomega.MatchError("invalid spec.commonLabels, label app.kubernetes.io/name is reserved"))
+
+	a.Spec.CommonLabels = map[string]string{"team": "platform team"}
+	g.Expect(validateCommonMetadata(a)).Should(gomega.MatchError(gomega.ContainSubstring("invalid spec.commonLabels value of team")))
+
+	a.Spec.CommonLabels = nil
+	a.Spec.CommonAnnotations = map[string]string{"cost center": "1234"}
+	g.Expect(validateCommonMetadata(a)).Should(gomega.MatchError(gomega.ContainSubstring(`invalid spec.commonAnnotations key "cost center"`)))
+}
+
+func Test_validatePermissions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	g.Expect(validatePermissions(a)).ShouldNot(gomega.HaveOccurred())
+
+	a.Spec.Lifecycle.Permissions = []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "create", "update"}}}
+	g.Expect(validatePermissions(a)).Should(gomega.MatchError("invalid spec.lifecycle.permissions, they are granted to spec.lifecycle.serviceAccount which is not set"))
+
+	a.Spec.Lifecycle.ServiceAccount = "fluentd-installer"
+	g.Expect(validatePermissions(a)).ShouldNot(gomega.HaveOccurred())
+
+	a.Spec.Lifecycle.Permissions = append(a.Spec.Lifecycle.Permissions, rbacv1.PolicyRule{Resources: []string{"configmaps"}})
+	g.Expect(validatePermissions(a)).Should(gomega.MatchError("invalid spec.lifecycle.permissions[1], verbs are required"))
+
+	a.Spec.Lifecycle.Permissions[1] = rbacv1.PolicyRule{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}}
+	g.Expect(validatePermissions(a)).Should(gomega.MatchError("invalid spec.lifecycle.permissions[1], resources are required"))
+
+	a.Spec.Lifecycle.Permissions[1].Resources = []string{"pods"}
+	g.Expect(validatePermissions(a)).Should(gomega.MatchError("invalid spec.lifecycle.permissions[1], nonResourceURLs cannot be granted in a namespace"))
+}
+
+func Test_validateFeatures(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Spec.Params.Features = map[string]bool{"metrics": true, "tracing": false}
+	g.Expect(validateFeatures(a)).ShouldNot(gomega.HaveOccurred())
+
+	a.Spec.Params.Features[""] = true
+	g.Expect(validateFeatures(a)).Should(gomega.MatchError(`invalid feature "" in spec.params.features, keys must consist of alphanumeric characters, '-', '_' or '.'`))
+	delete(a.Spec.Params.Features, "")
+
+	a.Spec.FeatureSchema = []addonmgrv1alpha1.AddonFeature{{Name: "metrics"}, {Name: "ingress", Default: true}}
+	g.Expect(validateFeatures(a)).Should(gomega.MatchError(`invalid feature "tracing" in spec.params.features, it is not declared in spec.featureSchema`))
+
+	a.Spec.FeatureSchema = append(a.Spec.FeatureSchema, addonmgrv1alpha1.AddonFeature{Name: "tracing"})
+	g.Expect(validateFeatures(a)).ShouldNot(gomega.HaveOccurred())
+
+	a.Spec.FeatureSchema = append(a.Spec.FeatureSchema, addonmgrv1alpha1.AddonFeature{Name: "metrics"})
+	g.Expect(validateFeatures(a)).Should(gomega.MatchError(`invalid spec.featureSchema[3], feature "metrics" is declared more than once`))
+}
+
+func Test_validateCombinedWorkflow(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Spec.Lifecycle.CombinedTemplate.Template = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+spec:
+  entrypoint: main
+  templates:
+  - name: main
+    steps:
+    - - name: prereqs
+        template: prereqs
+    - - name: install
+        template: install
+  - name: prereqs
+    container:
+      image: bitnami/kubectl
+  - name: install
+    container:
+      image: bitnami/kubectl
+`
+	g.Expect(validateCombinedWorkflow(a)).To(gomega.Succeed())
+
+	a.Spec.Lifecycle.CombinedTemplate.PrereqsTemplate = "setup"
+	g.Expect(validateCombinedWorkflow(a)).To(gomega.MatchError(`invalid workflow "combined", the template "setup" running prereqs is not defined`))
+
+	a.Spec.Lifecycle.CombinedTemplate.PrereqsTemplate = ""
+	a.Spec.Lifecycle.Install.Template = "kind: Workflow"
+	g.Expect(validateCombinedWorkflow(a)).To(gomega.MatchError(`invalid workflow "combined", a combined template cannot be used with prereqs or install templates`))
+}
+
+func Test_validateWorkflow_ActiveDeadlineSeconds(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Spec.Lifecycle.Install.ActiveDeadlineSeconds = -1
+	av := &addonValidator{addon: a}
+	g.Expect(av.validateWorkflow()).To(gomega.MatchError(`invalid workflow "install", activeDeadlineSeconds must be positive`))
+
+	a.Spec.Lifecycle.Install.ActiveDeadlineSeconds = 600
+	g.Expect(av.validateWorkflow()).To(gomega.Succeed())
+}
+
+func Test_validateReplicas(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Spec.Replicas = []addonmgrv1alpha1.ReplicaOverride{
+		{Kind: "Deployment", Name: "fluentd-aggregator", Replicas: 3},
+		{Kind: "StatefulSet", Name: "fluentd-buffer", Namespace: "logging", Replicas: 0},
+	}
+	g.Expect(validateReplicas(a)).Should(gomega.Succeed())
+
+	a.Spec.Replicas = append(a.Spec.Replicas, addonmgrv1alpha1.ReplicaOverride{Kind: "DaemonSet", Name: "fluentd"})
+	g.Expect(validateReplicas(a)).Should(gomega.MatchError(`invalid spec.replicas kind "DaemonSet", only Deployment and StatefulSet are scaled`))
+
+	a.Spec.Replicas[2] = addonmgrv1alpha1.ReplicaOverride{Kind: "Deployment", Name: "fluentd-aggregator", Replicas: 5}
+	g.Expect(validateReplicas(a)).Should(gomega.MatchError(`invalid spec.replicas, Deployment fluentd-aggregator is listed more than once`))
+
+	a.Spec.Replicas[2] = addonmgrv1alpha1.ReplicaOverride{Kind: "Deployment", Name: "fluentd-forwarder", Replicas: -1}
+	g.Expect(validateReplicas(a)).Should(gomega.MatchError(`invalid spec.replicas of Deployment fluentd-forwarder, replicas must not be negative`))
 }
@@ -15,9 +15,13 @@
 package addon
 
 import (
+	"sync"
+
 	"github.com/Masterminds/semver/v3"
+	"k8s.io/apimachinery/pkg/types"
+
 	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
-	"sync"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
 )
 
 // VersionCacheClient interface clients must implement for addon version cache.
@@ -26,7 +30,9 @@ type VersionCacheClient interface {
 	GetVersions(pkgName string) map[string]Version
 	GetVersion(pkgName, pkgVersion string) *Version
 	HasVersionName(name string) (bool, *Version)
+	GetVersionsWithName(name string) []Version
 	RemoveVersion(pkgName, pkgVersion string)
+	RemoveVersionWithUID(pkgName, pkgVersion string, uid types.UID) bool
 	RemoveVersions(pkgName string)
 	GetAllVersions() map[string]map[string]Version
 }
@@ -35,10 +41,21 @@ type VersionCacheClient interface {
 type Version struct {
 	Name      string
 	Namespace string
+	UID       types.UID
 	addonmgrv1alpha1.PackageSpec
 	PkgPhase addonmgrv1alpha1.ApplicationAssemblyPhase
+	// Selector holds the user specified, non-reserved, match labels of the addon
+	Selector map[string]string
+	// Wave orders the install of the addon within its namespace
+	Wave int
+	// Rollout is the name of the staged rollout of the addon
+	Rollout string
+	// RolloutStage is the index of the rollout stage of the addon, or -1 when its namespace is not staged
+	RolloutStage int
 }
 
+// cached is safe for concurrent use by the watch mappers and reconciles. Versions are copied in and out of the cache,
+// so that callers never share the maps held by the cache.
 type cached struct {
 	sync.RWMutex
 	addons map[string]map[string]Version
@@ -60,23 +77,24 @@ func (c *cached) AddVersion(v Version) {
 		mm := make(map[string]Version)
 		c.addons[v.PkgName] = mm
 	}
-	c.addons[v.PkgName][v.PkgVersion] = v
+	c.addons[v.PkgName][v.PkgVersion] = copyVersion(v)
+	c.updateSize()
 }
 
 func (c *cached) GetVersions(pkgName string) map[string]Version {
-	c.RLock()
-	defer c.RUnlock()
-
-	m, ok := c.addons[pkgName]
-	if !ok {
-		return nil
-	}
-
-	return c.copyVersionMap(m)
+	vmap := c.versions(pkgName)
+	metrics.ObserveCacheLookup(vmap != nil)
+	return vmap
 }
 
 func (c *cached) GetVersion(pkgName, pkgVersion string) *Version {
-	var vmap = c.GetVersions(pkgName)
+	v := c.version(pkgName, pkgVersion)
+	metrics.ObserveCacheLookup(v != nil)
+	return v
+}
+
+func (c *cached) version(pkgName, pkgVersion string) *Version {
+	var vmap = c.versions(pkgName)
 
 	if vmap == nil {
 		return nil
@@ -90,6 +108,18 @@ func (c *cached) GetVersion(pkgName, pkgVersion string) *Version {
 	return &v
 }
 
+func (c *cached) versions(pkgName string) map[string]Version {
+	c.RLock()
+	defer c.RUnlock()
+
+	m, ok := c.addons[pkgName]
+	if !ok {
+		return nil
+	}
+
+	return c.copyVersionMap(m)
+}
+
 func (c *cached) RemoveVersion(pkgName, pkgVersion string) {
 	c.Lock()
 	defer c.Unlock()
@@ -97,9 +127,25 @@ func (c *cached) RemoveVersion(pkgName, pkgVersion string) {
 	if _, ok := c.addons[pkgName][pkgVersion]; ok {
 		// Remove version
 		delete(c.addons[pkgName], pkgVersion)
+		c.updateSize()
 	}
 }
 
+// RemoveVersionWithUID removes the version only if it is still cached for the addon with the given UID
+func (c *cached) RemoveVersionWithUID(pkgName, pkgVersion string, uid types.UID) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	v, ok := c.addons[pkgName][pkgVersion]
+	if !ok || v.UID != uid {
+		return false
+	}
+
+	delete(c.addons[pkgName], pkgVersion)
+	c.updateSize()
+	return true
+}
+
 func (c *cached) RemoveVersions(pkgName string) {
 	c.Lock()
 	defer c.Unlock()
@@ -107,6 +153,7 @@ func (c *cached) RemoveVersions(pkgName string) {
 	if _, ok := c.addons[pkgName]; ok {
 		// Remove all versions
 		delete(c.addons, pkgName)
+		c.updateSize()
 	}
 }
 
@@ -120,14 +167,32 @@ func (c *cached) HasVersionName(name string) (bool, *Version) {
 	for _, vmap := range vvmap {
 		for _, version := range vmap {
 			if version.Name == name {
+				metrics.ObserveCacheLookup(true)
 				return true, &version
 			}
 		}
 	}
 
+	metrics.ObserveCacheLookup(false)
 	return false, nil
 }
 
+// GetVersionsWithName returns the cached versions of all addons with the name, addons in different namespaces may share it
+func (c *cached) GetVersionsWithName(name string) []Version {
+	var versions []Version
+
+	for _, vmap := range c.GetAllVersions() {
+		for _, version := range vmap {
+			if version.Name == name {
+				versions = append(versions, version)
+			}
+		}
+	}
+
+	metrics.ObserveCacheLookup(len(versions) > 0)
+	return versions
+}
+
 func (c *cached) resolveVersion(m map[string]Version, pkgVersion string) *Version {
 	// Assume pkgVersion may be a semantic package description
 	ct, err := semver.NewConstraint(pkgVersion)
@@ -169,8 +234,36 @@ func (c *cached) copyVersionMap(m map[string]Version) map[string]Version {
 
 	// copy map by assigning elements to new map
 	for key, value := range m {
-		vmap[key] = value
+		vmap[key] = copyVersion(value)
 	}
 
 	return vmap
 }
+
+// updateSize sets the cache size metric, the cache must be locked for writing
+func (c *cached) updateSize() {
+	var size int
+	for _, vmap := range c.addons {
+		size += len(vmap)
+	}
+	metrics.VersionCacheSize.Set(float64(size))
+}
+
+// copyVersion returns a copy of the version which does not share its maps
+func copyVersion(v Version) Version {
+	if v.PkgDeps != nil {
+		deps := make(map[string]string, len(v.PkgDeps))
+		for key, value := range v.PkgDeps {
+			deps[key] = value
+		}
+		v.PkgDeps = deps
+	}
+	if v.Selector != nil {
+		selector := make(map[string]string, len(v.Selector))
+		for key, value := range v.Selector {
+			selector[key] = value
+		}
+		v.Selector = selector
+	}
+	return v
+}
@@ -15,10 +15,17 @@
 package addon
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+
 	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
 )
 
 func TestNewCachedClient(t *testing.T) {
@@ -172,6 +179,74 @@ func Test_cached_RemoveVersion(t *testing.T) {
 	}
 }
 
+func Test_cached_RemoveVersionWithUID(t *testing.T) {
+	type args struct {
+		pkgName    string
+		pkgVersion string
+		uid        types.UID
+	}
+	tests := []struct {
+		name        string
+		c           *cached
+		args        args
+		wantRemoved bool
+		want        *Version
+	}{
+		{name: "remove-matching-uid", c: &cached{addons: map[string]map[string]Version{
+			"test/addon-1": {
+				"1.0.1": Version{Name: "test-1", UID: "uid-1"},
+			},
+		}}, args: args{pkgName: "test/addon-1", pkgVersion: "1.0.1", uid: "uid-1"}, wantRemoved: true, want: nil},
+		{name: "keep-recreated-addon", c: &cached{addons: map[string]map[string]Version{
+			"test/addon-1": {
+				"1.0.1": Version{Name: "test-1", UID: "uid-2"},
+			},
+		}}, args: args{pkgName: "test/addon-1", pkgVersion: "1.0.1", uid: "uid-1"}, wantRemoved: false, want: &Version{Name: "test-1", UID: "uid-2"}},
+		{name: "not-cached", c: &cached{addons: map[string]map[string]Version{}},
+			args: args{pkgName: "test/addon-1", pkgVersion: "1.0.1", uid: "uid-1"}, wantRemoved: false, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.RemoveVersionWithUID(tt.args.pkgName, tt.args.pkgVersion, tt.args.uid); got != tt.wantRemoved {
+				t.Errorf("cached.RemoveVersionWithUID() = %v, want %v", got, tt.wantRemoved)
+			}
+			if got := tt.c.GetVersion(tt.args.pkgName, tt.args.pkgVersion); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cached.GetVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_cached_RemoveVersionWithUID_Recreated(t *testing.T) {
+	c := NewAddonVersionCacheClient()
+	old := Version{
+		Name:      "test-1",
+		Namespace: "default",
+		UID:       "uid-old",
+		PackageSpec: addonmgrv1alpha1.PackageSpec{
+			PkgName:    "test/addon-1",
+			PkgVersion: "1.0.0",
+		},
+		PkgPhase: addonmgrv1alpha1.Succeeded,
+	}
+	c.AddVersion(old)
+
+	// Addon is deleted and recreated with the same name before the stale request is processed
+	recreated := old
+	recreated.UID = "uid-new"
+	recreated.PkgPhase = addonmgrv1alpha1.Pending
+	c.AddVersion(recreated)
+
+	if c.RemoveVersionWithUID(old.PkgName, old.PkgVersion, old.UID) {
+		t.Errorf("cached.RemoveVersionWithUID() evicted the recreated addon")
+	}
+
+	ok, v := c.HasVersionName("test-1")
+	if !ok || v.UID != recreated.UID {
+		t.Errorf("cached.HasVersionName() = %v, %v, want %v", ok, v, recreated)
+	}
+}
+
 func Test_cached_RemoveVersions(t *testing.T) {
 	type fields struct {
 		addons map[string]map[string]Version
@@ -348,3 +423,102 @@ func Test_cached_HasVersionName(t *testing.T) {
 		})
 	}
 }
+
+func Test_cached_GetVersionsWithName(t *testing.T) {
+	c := &cached{
+		addons: map[string]map[string]Version{
+			"test/addon-1": {
+				"1.0.0": Version{Name: "addon-1", Namespace: "ns-1"},
+			},
+			"other/addon-1": {
+				"2.0.0": Version{Name: "addon-1", Namespace: "ns-2"},
+			},
+			"test/addon-2": {
+				"1.0.0": Version{Name: "addon-2", Namespace: "ns-1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "addon-1", want: []string{"ns-1", "ns-2"}},
+		{name: "addon-2", want: []string{"ns-1"}},
+		{name: "addon-3", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range c.GetVersionsWithName(tt.name) {
+				got = append(got, v.Namespace)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cached.GetVersionsWithName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCached_ConcurrentAccess(t *testing.T) {
+	c := NewAddonVersionCacheClient()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				version := fmt.Sprintf("1.0.%d", j%10)
+				c.AddVersion(Version{
+					Name:        fmt.Sprintf("addon-%d", i),
+					PackageSpec: addonmgrv1alpha1.PackageSpec{PkgName: "test/addon", PkgVersion: version, PkgDeps: map[string]string{"test/dep": "*"}},
+				})
+				c.RemoveVersion("test/addon", version)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if ok, v := c.HasVersionName(fmt.Sprintf("addon-%d", i)); ok {
+					// Returned versions are copies, mutating them does not race with the cache
+					v.PkgDeps["test/other"] = "1.0.0"
+				}
+				c.GetVersionsWithName(fmt.Sprintf("addon-%d", i))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := c.GetAllVersions()["test/addon"]; len(got) != 0 {
+		t.Errorf("cached.GetAllVersions() = %v, want no versions", got)
+	}
+}
+
+func TestCached_Metrics(t *testing.T) {
+	c := NewAddonVersionCacheClient()
+	hits := testutil.ToFloat64(metrics.VersionCacheLookups.WithLabelValues(metrics.CacheHit))
+	misses := testutil.ToFloat64(metrics.VersionCacheLookups.WithLabelValues(metrics.CacheMiss))
+
+	c.AddVersion(Version{Name: "a", PackageSpec: addonmgrv1alpha1.PackageSpec{PkgName: "test/a", PkgVersion: "1.0.0"}})
+	c.AddVersion(Version{Name: "b", PackageSpec: addonmgrv1alpha1.PackageSpec{PkgName: "test/b", PkgVersion: "1.0.0"}})
+	if got := testutil.ToFloat64(metrics.VersionCacheSize); got != 2 {
+		t.Errorf("addon_version_cache_size = %v, want 2", got)
+	}
+
+	c.GetVersion("test/a", "1.0.0")
+	c.GetVersion("test/a", "2.0.0")
+	c.HasVersionName("c")
+	if got := testutil.ToFloat64(metrics.VersionCacheLookups.WithLabelValues(metrics.CacheHit)) - hits; got != 1 {
+		t.Errorf("addon_version_cache_lookups_total{result=hit} increased by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.VersionCacheLookups.WithLabelValues(metrics.CacheMiss)) - misses; got != 2 {
+		t.Errorf("addon_version_cache_lookups_total{result=miss} increased by %v, want 2", got)
+	}
+
+	c.RemoveVersions("test/a")
+	if got := testutil.ToFloat64(metrics.VersionCacheSize); got != 1 {
+		t.Errorf("addon_version_cache_size = %v, want 1", got)
+	}
+}
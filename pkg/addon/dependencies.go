@@ -0,0 +1,80 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"sort"
+	"strings"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// DependencyStatuses returns the resolution state of each dependency of the addon from the cached versions, sorted by
+// package name so that all blocking dependencies are reported at once
+func DependencyStatuses(cache VersionCacheClient, a *addonmgrv1alpha1.Addon) []addonmgrv1alpha1.DependencyStatus {
+	if len(a.Spec.PkgDeps) == 0 {
+		return nil
+	}
+
+	var deps = make([]addonmgrv1alpha1.DependencyStatus, 0, len(a.Spec.PkgDeps))
+	for pkgName, pkgVersion := range a.Spec.PkgDeps {
+		pkgName = strings.TrimSpace(pkgName)
+		pkgVersion = strings.TrimSpace(pkgVersion)
+		deps = append(deps, addonmgrv1alpha1.DependencyStatus{
+			PkgName:    pkgName,
+			PkgVersion: pkgVersion,
+			State:      dependencyState(cache, pkgName, pkgVersion),
+		})
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].PkgName < deps[j].PkgName
+	})
+	return deps
+}
+
+func dependencyState(cache VersionCacheClient, pkgName, pkgVersion string) addonmgrv1alpha1.DependencyState {
+	if pkgVersion != "*" {
+		v := cache.GetVersion(pkgName, pkgVersion)
+		if v == nil {
+			if len(cache.GetVersions(pkgName)) > 0 {
+				return addonmgrv1alpha1.DependencyVersionMismatch
+			}
+			return addonmgrv1alpha1.DependencyMissing
+		}
+		return phaseState(v.PkgPhase)
+	}
+
+	// Any successfully installed version satisfies the dependency, otherwise one being installed may
+	var state = addonmgrv1alpha1.DependencyMissing
+	for _, v := range cache.GetVersions(pkgName) {
+		switch phaseState(v.PkgPhase) {
+		case addonmgrv1alpha1.DependencyInstalled:
+			return addonmgrv1alpha1.DependencyInstalled
+		case addonmgrv1alpha1.DependencyPending:
+			state = addonmgrv1alpha1.DependencyPending
+		}
+	}
+	return state
+}
+
+func phaseState(phase addonmgrv1alpha1.ApplicationAssemblyPhase) addonmgrv1alpha1.DependencyState {
+	switch phase {
+	case addonmgrv1alpha1.Succeeded:
+		return addonmgrv1alpha1.DependencyInstalled
+	case addonmgrv1alpha1.Pending:
+		return addonmgrv1alpha1.DependencyPending
+	}
+	return addonmgrv1alpha1.DependencyMissing
+}
@@ -0,0 +1,75 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestDependencyStatuses(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cache := NewAddonVersionCacheClient()
+	for _, v := range []struct {
+		name, version string
+		phase         addonmgrv1alpha1.ApplicationAssemblyPhase
+	}{
+		{"core/dns", "1.0.0", addonmgrv1alpha1.Succeeded},
+		{"core/cni", "1.0.0", addonmgrv1alpha1.Pending},
+		{"core/proxy", "1.0.0", addonmgrv1alpha1.Succeeded},
+		{"core/storage", "1.0.0", addonmgrv1alpha1.Failed},
+	} {
+		cache.AddVersion(Version{
+			Name:        v.name,
+			Namespace:   "default",
+			PackageSpec: addonmgrv1alpha1.PackageSpec{PkgName: v.name, PkgVersion: v.version},
+			PkgPhase:    v.phase,
+		})
+	}
+
+	a := &addonmgrv1alpha1.Addon{}
+	a.Spec.PkgDeps = map[string]string{
+		"core/dns":     "*",
+		"core/cni":     "1.0.0",
+		"core/proxy":   "2.0.0",
+		"core/storage": "*",
+		"core/mesh":    "1.0.0",
+	}
+	g.Expect(DependencyStatuses(cache, a)).To(gomega.Equal([]addonmgrv1alpha1.DependencyStatus{
+		{PkgName: "core/cni", PkgVersion: "1.0.0", State: addonmgrv1alpha1.DependencyPending},
+		{PkgName: "core/dns", PkgVersion: "*", State: addonmgrv1alpha1.DependencyInstalled},
+		{PkgName: "core/mesh", PkgVersion: "1.0.0", State: addonmgrv1alpha1.DependencyMissing},
+		{PkgName: "core/proxy", PkgVersion: "2.0.0", State: addonmgrv1alpha1.DependencyVersionMismatch},
+		{PkgName: "core/storage", PkgVersion: "*", State: addonmgrv1alpha1.DependencyMissing},
+	}))
+
+	// Dependencies which are not installed are reported before pending ones
+	av := &addonValidator{addon: a, cache: cache}
+	g.Expect(av.validateDependencies()).To(gomega.MatchError(ErrDepNotInstalled + `: "core/mesh":"1.0.0"`))
+
+	a.Spec.PkgDeps = map[string]string{"core/dns": "*", "core/cni": "1.0.0"}
+	g.Expect(av.validateDependencies()).To(gomega.MatchError(ErrDepPending + `: "core/cni":"1.0.0"`))
+
+	a.Spec.PkgDeps = map[string]string{"core/storage": "*"}
+	g.Expect(av.validateDependencies()).To(gomega.MatchError("required dependency core/storage has no valid versions installed"))
+
+	a.Spec.PkgDeps = nil
+	g.Expect(DependencyStatuses(cache, a)).To(gomega.BeNil())
+	g.Expect(av.validateDependencies()).To(gomega.Succeed())
+}
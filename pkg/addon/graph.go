@@ -0,0 +1,141 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// GraphNode is a cached addon version in the dependency graph
+type GraphNode struct {
+	ID         string                                    `json:"id"`
+	Name       string                                    `json:"name"`
+	Namespace  string                                    `json:"namespace"`
+	PkgName    string                                    `json:"pkgName"`
+	PkgVersion string                                    `json:"pkgVersion"`
+	Installed  addonmgrv1alpha1.ApplicationAssemblyPhase `json:"installed"`
+	// Error is why the dependencies of the addon do not resolve, as reported by validation
+	Error string `json:"error,omitempty"`
+	// InCycle is true if the addon is part of a dependency cycle
+	InCycle bool `json:"inCycle,omitempty"`
+}
+
+// GraphEdge is a dependency of an addon, To is empty when the dependency does not resolve to a cached version
+type GraphEdge struct {
+	From       string `json:"from"`
+	To         string `json:"to,omitempty"`
+	PkgName    string `json:"pkgName"`
+	PkgVersion string `json:"pkgVersion"`
+}
+
+// Graph is the dependency graph of the cached addon versions
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// nodeID returns the id of the graph node of an addon version
+func nodeID(pkgName, pkgVersion string) string {
+	return pkgName + ":" + pkgVersion
+}
+
+// DependencyGraph returns the dependency graph of the cached addon versions. Dependencies are resolved and cycles
+// are detected like validation does, so that the graph matches what is reconciled.
+func DependencyGraph(cache VersionCacheClient) *Graph {
+	var graph = &Graph{Nodes: make([]GraphNode, 0), Edges: make([]GraphEdge, 0)}
+	var cycles = make(map[string]bool)
+
+	for _, versions := range cache.GetAllVersions() {
+		for _, v := range versions {
+			v := v
+			node := GraphNode{
+				ID:         nodeID(v.PkgName, v.PkgVersion),
+				Name:       v.Name,
+				Namespace:  v.Namespace,
+				PkgName:    v.PkgName,
+				PkgVersion: v.PkgVersion,
+				Installed:  v.PkgPhase,
+			}
+
+			av := &addonValidator{cache: cache}
+			if err := av.resolveDependencies(&v, make(map[string]*Version), 0); err != nil {
+				node.Error = err.Error()
+				var cycleErr *CycleError
+				if errors.As(err, &cycleErr) {
+					for _, c := range cycleErr.Path {
+						cycles[nodeID(c.PkgName, c.PkgVersion)] = true
+					}
+				}
+			}
+			graph.Nodes = append(graph.Nodes, node)
+
+			for pkgName, pkgVersion := range v.PkgDeps {
+				edge := GraphEdge{From: node.ID, PkgName: strings.TrimSpace(pkgName), PkgVersion: strings.TrimSpace(pkgVersion)}
+				if dep := cache.GetVersion(edge.PkgName, edge.PkgVersion); dep != nil {
+					edge.To = nodeID(dep.PkgName, dep.PkgVersion)
+				}
+				graph.Edges = append(graph.Edges, edge)
+			}
+		}
+	}
+
+	for i := range graph.Nodes {
+		graph.Nodes[i].InCycle = cycles[graph.Nodes[i].ID]
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].PkgName < graph.Edges[j].PkgName
+	})
+
+	return graph
+}
+
+// DOT returns the graph in the Graphviz DOT format. Nodes are labeled with their addon and install status, addons in
+// a cycle and unresolved dependencies are drawn red.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph addons {\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, n := range g.Nodes {
+		attrs := fmt.Sprintf("label=%q", fmt.Sprintf("%s\n%s/%s\n%s", n.ID, n.Namespace, n.Name, n.Installed))
+		if n.InCycle {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", n.ID, attrs)
+	}
+
+	for _, e := range g.Edges {
+		if e.To == "" {
+			missing := nodeID(e.PkgName, e.PkgVersion)
+			fmt.Fprintf(&b, "  %q [label=%q, style=dashed, color=red];\n", missing, missing+"\nnot installed")
+			fmt.Fprintf(&b, "  %q -> %q [style=dashed, color=red];\n", e.From, missing)
+			continue
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.From, e.To, e.PkgVersion)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,69 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func graphVersion(name, pkgName string, phase addonmgrv1alpha1.ApplicationAssemblyPhase, deps map[string]string) Version {
+	return Version{
+		Name:      name,
+		Namespace: "addon-manager-system",
+		PackageSpec: addonmgrv1alpha1.PackageSpec{
+			PkgName:    pkgName,
+			PkgVersion: "1.0.0",
+			PkgDeps:    deps,
+		},
+		PkgPhase: phase,
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cache := NewAddonVersionCacheClient()
+	cache.AddVersion(graphVersion("core", "test/core", addonmgrv1alpha1.Succeeded, nil))
+	cache.AddVersion(graphVersion("app", "test/app", addonmgrv1alpha1.Pending, map[string]string{"test/core": "*", "test/missing": "1.0.0"}))
+	cache.AddVersion(graphVersion("a", "test/a", addonmgrv1alpha1.ValidationFailed, map[string]string{"test/b": "1.0.0"}))
+	cache.AddVersion(graphVersion("b", "test/b", addonmgrv1alpha1.ValidationFailed, map[string]string{"test/a": "1.0.0"}))
+
+	graph := DependencyGraph(cache)
+	g.Expect(graph.Nodes).To(gomega.HaveLen(4))
+
+	nodes := map[string]GraphNode{}
+	for _, n := range graph.Nodes {
+		nodes[n.ID] = n
+	}
+	g.Expect(nodes["test/core:1.0.0"].Installed).To(gomega.Equal(addonmgrv1alpha1.Succeeded))
+	g.Expect(nodes["test/core:1.0.0"].Error).To(gomega.BeEmpty())
+	g.Expect(nodes["test/app:1.0.0"].Error).To(gomega.Equal("unable to resolve required dependency test/missing:1.0.0"))
+	g.Expect(nodes["test/app:1.0.0"].InCycle).To(gomega.BeFalse())
+	g.Expect(nodes["test/a:1.0.0"].InCycle).To(gomega.BeTrue())
+	g.Expect(nodes["test/b:1.0.0"].Error).To(gomega.ContainSubstring("circular dependency was found"))
+
+	g.Expect(graph.Edges).To(gomega.ContainElement(GraphEdge{From: "test/app:1.0.0", To: "test/core:1.0.0", PkgName: "test/core", PkgVersion: "*"}))
+	g.Expect(graph.Edges).To(gomega.ContainElement(GraphEdge{From: "test/app:1.0.0", PkgName: "test/missing", PkgVersion: "1.0.0"}))
+
+	dot := graph.DOT()
+	g.Expect(dot).To(gomega.HavePrefix("digraph addons {"))
+	g.Expect(dot).To(gomega.ContainSubstring(`"test/app:1.0.0" -> "test/core:1.0.0" [label="*"];`))
+	g.Expect(dot).To(gomega.ContainSubstring(`"test/app:1.0.0" -> "test/missing:1.0.0" [style=dashed, color=red];`))
+	g.Expect(dot).To(gomega.ContainSubstring(`"test/a:1.0.0" [label="test/a:1.0.0\naddon-manager-system/a\nValidation Failed", color=red];`))
+}
@@ -0,0 +1,80 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"sort"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// GroupSummary is the aggregate install status of the addons of a group
+type GroupSummary struct {
+	Group string `json:"group"`
+	// Addons are the namespace/name of the addons in the group
+	Addons    []string                                          `json:"addons"`
+	Installed int                                               `json:"installed"`
+	Failed    int                                               `json:"failed"`
+	Pending   int                                               `json:"pending"`
+	Phases    map[addonmgrv1alpha1.ApplicationAssemblyPhase]int `json:"phases"`
+}
+
+// Total returns the number of addons in the group
+func (s *GroupSummary) Total() int {
+	return len(s.Addons)
+}
+
+// SummarizeGroups aggregates the install status of the addons by group, addons without a group are skipped. Summaries
+// are sorted by group and the addons of each group by namespace/name.
+func SummarizeGroups(addons []addonmgrv1alpha1.Addon) []GroupSummary {
+	var groups = map[string]*GroupSummary{}
+	for i := range addons {
+		a := &addons[i]
+		if a.Spec.Group == "" {
+			continue
+		}
+
+		s, ok := groups[a.Spec.Group]
+		if !ok {
+			s = &GroupSummary{Group: a.Spec.Group, Phases: map[addonmgrv1alpha1.ApplicationAssemblyPhase]int{}}
+			groups[a.Spec.Group] = s
+		}
+
+		// Addons which were not reconciled yet have no phase
+		phase := a.GetInstallStatus()
+		if phase == "" {
+			phase = addonmgrv1alpha1.Pending
+		}
+		s.Addons = append(s.Addons, a.GetNamespace()+"/"+a.GetName())
+		s.Phases[phase]++
+		switch phase {
+		case addonmgrv1alpha1.Succeeded, addonmgrv1alpha1.ValidationPassed, addonmgrv1alpha1.Skipped:
+			s.Installed++
+		case addonmgrv1alpha1.Failed, addonmgrv1alpha1.ValidationFailed, addonmgrv1alpha1.DeleteFailed,
+			addonmgrv1alpha1.RolledBack, addonmgrv1alpha1.Quarantined, addonmgrv1alpha1.Conflict:
+			s.Failed++
+		default:
+			s.Pending++
+		}
+	}
+
+	var summaries = make([]GroupSummary, 0, len(groups))
+	for _, s := range groups {
+		sort.Strings(s.Addons)
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Group < summaries[j].Group })
+	return summaries
+}
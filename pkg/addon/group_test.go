@@ -0,0 +1,71 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestSummarizeGroups(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	member := func(namespace, name, group string, phase addonmgrv1alpha1.ApplicationAssemblyPhase) addonmgrv1alpha1.Addon {
+		return addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       addonmgrv1alpha1.AddonSpec{Group: group},
+			Status:     addonmgrv1alpha1.AddonStatus{Lifecycle: addonmgrv1alpha1.AddonStatusLifecycle{Installed: phase}},
+		}
+	}
+
+	summaries := SummarizeGroups([]addonmgrv1alpha1.Addon{
+		member("addon-manager-system", "metrics-server", "monitoring", addonmgrv1alpha1.Succeeded),
+		member("addon-manager-system", "event-router", "", addonmgrv1alpha1.Failed),
+		member("addon-manager-system", "prometheus", "monitoring", addonmgrv1alpha1.Quarantined),
+		member("addon-manager-system", "grafana", "monitoring", addonmgrv1alpha1.Pending),
+		member("addon-manager-system", "cluster-autoscaler", "compute", ""),
+	})
+
+	g.Expect(summaries).To(gomega.Equal([]GroupSummary{
+		{
+			Group:   "compute",
+			Addons:  []string{"addon-manager-system/cluster-autoscaler"},
+			Pending: 1,
+			Phases:  map[addonmgrv1alpha1.ApplicationAssemblyPhase]int{addonmgrv1alpha1.Pending: 1},
+		},
+		{
+			Group: "monitoring",
+			Addons: []string{
+				"addon-manager-system/grafana",
+				"addon-manager-system/metrics-server",
+				"addon-manager-system/prometheus",
+			},
+			Installed: 1,
+			Failed:    1,
+			Pending:   1,
+			Phases: map[addonmgrv1alpha1.ApplicationAssemblyPhase]int{
+				addonmgrv1alpha1.Succeeded:   1,
+				addonmgrv1alpha1.Quarantined: 1,
+				addonmgrv1alpha1.Pending:     1,
+			},
+		},
+	}))
+	g.Expect(summaries[1].Total()).To(gomega.Equal(3))
+	g.Expect(SummarizeGroups(nil)).To(gomega.BeEmpty())
+}
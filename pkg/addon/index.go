@@ -0,0 +1,53 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// InstallStatusField is the field index of addons by their install status
+const InstallStatusField = "status.lifecycle.installed"
+
+// IndexInstallStatus registers the install status field index of addons, it must be registered before the cache of
+// the indexer is started
+func IndexInstallStatus(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &addonmgrv1alpha1.Addon{}, InstallStatusField, installStatusIndex)
+}
+
+// installStatusIndex indexes addons by their install status, addons which were not reconciled yet are not indexed
+func installStatusIndex(obj runtime.Object) []string {
+	a, ok := obj.(*addonmgrv1alpha1.Addon)
+	if !ok || a.GetInstallStatus() == "" {
+		return nil
+	}
+	return []string{string(a.GetInstallStatus())}
+}
+
+// ListByInstallStatus lists the addons with the install status from the field index of the (cache backed) reader,
+// opts such as client.InNamespace further restrict the list
+func ListByInstallStatus(ctx context.Context, reader client.Reader, phase addonmgrv1alpha1.ApplicationAssemblyPhase, opts ...client.ListOption) ([]addonmgrv1alpha1.Addon, error) {
+	var list = &addonmgrv1alpha1.AddonList{}
+	opts = append(opts, client.MatchingFields{InstallStatusField: string(phase)})
+	if err := reader.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
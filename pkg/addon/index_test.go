@@ -0,0 +1,84 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// indexedReader applies the install status field selector like the cache does, the fake client ignores it
+type indexedReader struct {
+	client.Reader
+}
+
+func (r indexedReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	if err := r.Reader.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	phase, ok := listOpts.FieldSelector.RequiresExactMatch(InstallStatusField)
+	if !ok {
+		return nil
+	}
+	addons := list.(*addonmgrv1alpha1.AddonList)
+	var items []addonmgrv1alpha1.Addon
+	for _, a := range addons.Items {
+		if values := installStatusIndex(&a); len(values) == 1 && values[0] == phase {
+			items = append(items, a)
+		}
+	}
+	addons.Items = items
+	return nil
+}
+
+func TestListByInstallStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := func(name, namespace string, phase addonmgrv1alpha1.ApplicationAssemblyPhase) *addonmgrv1alpha1.Addon {
+		a := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		a.Status.Lifecycle.Installed = phase
+		return a
+	}
+
+	sch := runtime.NewScheme()
+	_ = addonmgrv1alpha1.AddToScheme(sch)
+	reader := indexedReader{runtimefake.NewFakeClientWithScheme(sch,
+		addon("fluentd", "addon-manager-system", addonmgrv1alpha1.Failed),
+		addon("coredns", "addon-manager-system", addonmgrv1alpha1.Succeeded),
+		addon("metrics", "monitoring", addonmgrv1alpha1.Failed),
+		addon("new", "monitoring", ""),
+	)}
+
+	addons, err := ListByInstallStatus(context.TODO(), reader, addonmgrv1alpha1.Failed)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addons).To(HaveLen(2))
+
+	addons, err = ListByInstallStatus(context.TODO(), reader, addonmgrv1alpha1.Failed, client.InNamespace("monitoring"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addons).To(HaveLen(1))
+	g.Expect(addons[0].Name).To(Equal("metrics"))
+
+	g.Expect(installStatusIndex(addon("new", "monitoring", ""))).To(BeEmpty())
+	g.Expect(installStatusIndex(addon("rb", "monitoring", addonmgrv1alpha1.RolledBack))).To(Equal([]string{"Rolled Back"}))
+}
@@ -0,0 +1,37 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DecodeManifest returns the object of an addon manifest, it fails unless the manifest has an apiVersion, kind and name
+func DecodeManifest(m runtime.RawExtension) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(m.Raw); err != nil {
+		return nil, err
+	}
+	if obj.GetAPIVersion() == "" {
+		return nil, fmt.Errorf("apiVersion is required")
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("metadata.name is required")
+	}
+	return obj, nil
+}
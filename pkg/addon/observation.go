@@ -0,0 +1,103 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// ObservationLabelKey returns the key used by the addon resources for a default observation label, empty when dropped
+func ObservationLabelKey(a *addonmgrv1alpha1.Addon, key string) string {
+	if override, ok := a.Spec.ObservationLabels[key]; ok {
+		return override
+	}
+	return key
+}
+
+// ObservationSelector returns the selector of the resources observed for the addon, the addon selector combined with
+// the managed-by and name labels. It fails when the selector would match all resources of the namespace.
+func ObservationSelector(a *addonmgrv1alpha1.Addon) (labels.Selector, error) {
+	for key, override := range a.Spec.ObservationLabels {
+		if !common.IsReservedLabel(key) {
+			return nil, fmt.Errorf("observation label %q cannot be overridden, only %s and %s can", key, common.ManagedByLabel, common.NameLabel)
+		}
+		if override == "" {
+			continue
+		}
+		if errs := validation.IsQualifiedName(override); len(errs) > 0 {
+			return nil, fmt.Errorf("observation label %q is not a valid label key. %s", override, strings.Join(errs, ", "))
+		}
+	}
+
+	labelSelector := a.Spec.Selector.DeepCopy()
+	if labelSelector.MatchLabels == nil {
+		labelSelector.MatchLabels = make(map[string]string)
+	}
+	if key := ObservationLabelKey(a, common.ManagedByLabel); key != "" {
+		labelSelector.MatchLabels[key] = common.AddonGVR().Group
+	}
+	if key := ObservationLabelKey(a, common.NameLabel); key != "" {
+		labelSelector.MatchLabels[key] = a.GetName()
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("label selector is invalid. %v", err)
+	}
+
+	if selector.Empty() {
+		return nil, fmt.Errorf("observation selector is empty and would match all resources in namespace %s", a.Spec.Params.Namespace)
+	}
+
+	return selector, nil
+}
+
+// ObservedNamespaces returns the namespaces the resources of the addon are observed in, the observed namespaces of
+// the spec or else the namespace the addon installs into. Addons without either are observed in their own namespace.
+func ObservedNamespaces(a *addonmgrv1alpha1.Addon) []string {
+	if len(a.Spec.ObservedNamespaces) > 0 {
+		var namespaces []string
+		var seen = map[string]bool{}
+		for _, ns := range a.Spec.ObservedNamespaces {
+			if !seen[ns] {
+				seen[ns] = true
+				namespaces = append(namespaces, ns)
+			}
+		}
+		return namespaces
+	}
+	if a.Spec.Params.Namespace != "" {
+		return []string{a.Spec.Params.Namespace}
+	}
+	return []string{a.GetNamespace()}
+}
+
+// validateObservedNamespaces checks that the observed namespaces are namespace names
+func validateObservedNamespaces(a *addonmgrv1alpha1.Addon) error {
+	for _, ns := range a.Spec.ObservedNamespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q in spec.observedNamespaces. %s", ns, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
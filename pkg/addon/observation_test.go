@@ -0,0 +1,83 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestObservationSelector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name              string
+		selector          map[string]string
+		observationLabels map[string]string
+		want              string
+		wantErr           bool
+	}{
+		{name: "defaults", want: "app.kubernetes.io/managed-by=addonmgr.keikoproj.io,app.kubernetes.io/name=fluentd"},
+		{name: "with-selector", selector: map[string]string{"tier": "logging"},
+			want: "app.kubernetes.io/managed-by=addonmgr.keikoproj.io,app.kubernetes.io/name=fluentd,tier=logging"},
+		{name: "renamed", observationLabels: map[string]string{"app.kubernetes.io/name": "app", "app.kubernetes.io/managed-by": ""},
+			want: "app=fluentd"},
+		{name: "dropped-with-selector", selector: map[string]string{"release": "fluentd"},
+			observationLabels: map[string]string{"app.kubernetes.io/name": "", "app.kubernetes.io/managed-by": ""}, want: "release=fluentd"},
+		{name: "empty", observationLabels: map[string]string{"app.kubernetes.io/name": "", "app.kubernetes.io/managed-by": ""}, wantErr: true},
+		{name: "unknown-key", observationLabels: map[string]string{"app": "name"}, wantErr: true},
+		{name: "invalid-key", observationLabels: map[string]string{"app.kubernetes.io/name": "not a key"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		a := &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "default"},
+			Spec: addonmgrv1alpha1.AddonSpec{
+				Selector:          metav1.LabelSelector{MatchLabels: tt.selector},
+				ObservationLabels: tt.observationLabels,
+			},
+		}
+
+		selector, err := ObservationSelector(a)
+		if tt.wantErr {
+			g.Expect(err).To(HaveOccurred(), tt.name)
+			continue
+		}
+		g.Expect(err).NotTo(HaveOccurred(), tt.name)
+		g.Expect(selector.String()).To(Equal(tt.want), tt.name)
+		// The addon selector is not modified
+		g.Expect(a.Spec.Selector.MatchLabels).To(Equal(tt.selector), tt.name)
+	}
+}
+
+func TestObservedNamespaces(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "addon-manager-system"}}
+	g.Expect(ObservedNamespaces(a)).To(Equal([]string{"addon-manager-system"}))
+
+	a.Spec.Params.Namespace = "logging"
+	g.Expect(ObservedNamespaces(a)).To(Equal([]string{"logging"}))
+
+	a.Spec.ObservedNamespaces = []string{"logging", "monitoring", "logging"}
+	g.Expect(ObservedNamespaces(a)).To(Equal([]string{"logging", "monitoring"}))
+	g.Expect(validateObservedNamespaces(a)).To(Succeed())
+
+	a.Spec.ObservedNamespaces = []string{"Logging"}
+	g.Expect(validateObservedNamespaces(a)).To(HaveOccurred())
+}
@@ -0,0 +1,122 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// OverrideTargets returns the targets of the override patches of the addon
+func OverrideTargets(a *addonmgrv1alpha1.Addon) []string {
+	var targets []string
+	for _, p := range a.Spec.Overrides.Patches {
+		targets = append(targets, p.Target.String())
+	}
+	return targets
+}
+
+// OverrideMatches returns true if the object is the target of the override patch
+func OverrideMatches(p addonmgrv1alpha1.OverridePatch, obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == p.Target.Group && gvk.Version == p.Target.Version && gvk.Kind == p.Target.Kind && obj.GetName() == p.Target.Name
+}
+
+// ValidateOverridePatch checks the target is set and the patch parses as the patch type
+func ValidateOverridePatch(p addonmgrv1alpha1.OverridePatch) error {
+	if p.Target.Version == "" || p.Target.Kind == "" || p.Target.Name == "" {
+		return fmt.Errorf("target version, kind and name are required")
+	}
+
+	data, err := yaml.ToJSON([]byte(p.Patch))
+	if err != nil {
+		return fmt.Errorf("patch is not valid YAML. %v", err)
+	}
+
+	switch p.Type {
+	case "", addonmgrv1alpha1.StrategicMergePatch:
+		var patch map[string]interface{}
+		if err := json.Unmarshal(data, &patch); err != nil || len(patch) == 0 {
+			return fmt.Errorf("strategic merge patch must be a non-empty object")
+		}
+	case addonmgrv1alpha1.JSONPatch:
+		ops, err := jsonpatch.DecodePatch(data)
+		if err != nil || len(ops) == 0 {
+			return fmt.Errorf("JSON patch must be a non-empty list of operations")
+		}
+		for i, op := range ops {
+			switch op.Kind() {
+			case "add", "remove", "replace", "move", "copy", "test":
+			default:
+				return fmt.Errorf("JSON patch operation %d has unknown op %q", i, op.Kind())
+			}
+			if _, err := op.Path(); err != nil {
+				return fmt.Errorf("JSON patch operation %d has no path", i)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown patch type %q", p.Type)
+	}
+
+	return nil
+}
+
+// ApplyOverride applies the override patch to the object. Strategic merge patches of kinds without a known schema,
+// e.g. custom resources, are applied as JSON merge patches like kubectl does.
+func ApplyOverride(obj *unstructured.Unstructured, p addonmgrv1alpha1.OverridePatch) error {
+	patch, err := yaml.ToJSON([]byte(p.Patch))
+	if err != nil {
+		return err
+	}
+
+	original, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch p.Type {
+	case addonmgrv1alpha1.JSONPatch:
+		ops, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return err
+		}
+		patched, err = ops.Apply(original)
+		if err != nil {
+			return err
+		}
+	default:
+		typed, err := scheme.Scheme.New(obj.GroupVersionKind())
+		if runtime.IsNotRegisteredError(err) {
+			patched, err = jsonpatch.MergePatch(original, patch)
+		} else if err == nil {
+			patched, err = strategicpatch.StrategicMergePatch(original, patch, typed)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return obj.UnmarshalJSON(patched)
+}
@@ -0,0 +1,80 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+var deploymentTarget = addonmgrv1alpha1.OverrideTarget{Group: "apps", Version: "v1", Kind: "Deployment", Name: "fluentd"}
+
+func TestValidateOverridePatch(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name    string
+		patch   addonmgrv1alpha1.OverridePatch
+		wantErr bool
+	}{
+		{name: "strategic", patch: addonmgrv1alpha1.OverridePatch{Target: deploymentTarget, Patch: "spec:\n  replicas: 2\n"}},
+		{name: "strategic-not-object", patch: addonmgrv1alpha1.OverridePatch{Target: deploymentTarget, Patch: "- replicas: 2\n"}, wantErr: true},
+		{name: "json", patch: addonmgrv1alpha1.OverridePatch{Target: deploymentTarget, Type: addonmgrv1alpha1.JSONPatch,
+			Patch: "- op: replace\n  path: /spec/replicas\n  value: 2\n"}},
+		{name: "json-unknown-op", patch: addonmgrv1alpha1.OverridePatch{Target: deploymentTarget, Type: addonmgrv1alpha1.JSONPatch,
+			Patch: `[{"op": "merge", "path": "/spec/replicas", "value": 2}]`}, wantErr: true},
+		{name: "json-without-path", patch: addonmgrv1alpha1.OverridePatch{Target: deploymentTarget, Type: addonmgrv1alpha1.JSONPatch,
+			Patch: `[{"op": "remove"}]`}, wantErr: true},
+		{name: "target-without-name", patch: addonmgrv1alpha1.OverridePatch{Target: addonmgrv1alpha1.OverrideTarget{Version: "v1", Kind: "Service"},
+			Patch: "spec:\n  type: NodePort\n"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateOverridePatch(tt.patch)
+		g.Expect(err != nil).To(Equal(tt.wantErr), tt.name)
+	}
+}
+
+func TestApplyOverride(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := &unstructured.Unstructured{}
+	g.Expect(obj.UnmarshalJSON([]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"fluentd"},"spec":{"replicas":1,"template":{"spec":{"containers":[{"name":"fluentd","image":"fluentd:v1.12"},{"name":"exporter","image":"exporter:v0.3"}]}}}}`))).To(Succeed())
+	g.Expect(OverrideMatches(addonmgrv1alpha1.OverridePatch{Target: deploymentTarget}, obj)).To(BeTrue())
+
+	// Containers are merged by name
+	g.Expect(ApplyOverride(obj, addonmgrv1alpha1.OverridePatch{Target: deploymentTarget,
+		Patch: "spec:\n  template:\n    spec:\n      containers:\n      - name: fluentd\n        resources:\n          limits:\n            memory: 512Mi\n"})).To(Succeed())
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	g.Expect(containers).To(HaveLen(2))
+	g.Expect(containers[0]).To(HaveKeyWithValue("image", "fluentd:v1.12"))
+	g.Expect(containers[0]).To(HaveKeyWithValue("resources", map[string]interface{}{"limits": map[string]interface{}{"memory": "512Mi"}}))
+
+	g.Expect(ApplyOverride(obj, addonmgrv1alpha1.OverridePatch{Target: deploymentTarget, Type: addonmgrv1alpha1.JSONPatch,
+		Patch: `[{"op": "replace", "path": "/spec/replicas", "value": 3}]`})).To(Succeed())
+	replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	g.Expect(replicas).To(Equal(int64(3)))
+
+	// Kinds without a known schema are merged as JSON merge patches
+	cr := &unstructured.Unstructured{}
+	g.Expect(cr.UnmarshalJSON([]byte(`{"apiVersion":"cert-manager.io/v1","kind":"Issuer","metadata":{"name":"letsencrypt"},"spec":{"acme":{"server":"https://acme-staging-v02.api.letsencrypt.org/directory","email":"ops@example.com"}}}`))).To(Succeed())
+	g.Expect(ApplyOverride(cr, addonmgrv1alpha1.OverridePatch{Patch: "spec:\n  acme:\n    server: https://acme-v02.api.letsencrypt.org/directory\n"})).To(Succeed())
+	acme, _, _ := unstructured.NestedStringMap(cr.Object, "spec", "acme")
+	g.Expect(acme).To(Equal(map[string]string{"server": "https://acme-v02.api.letsencrypt.org/directory", "email": "ops@example.com"}))
+}
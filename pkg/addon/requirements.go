@@ -0,0 +1,123 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// RequirementsError is returned when the cluster does not meet the requirements of an addon
+type RequirementsError struct {
+	Unmet []string
+}
+
+func (e *RequirementsError) Error() string {
+	return fmt.Sprintf("cluster does not meet the addon requirements: %s", strings.Join(e.Unmet, ", "))
+}
+
+// validateRequirements validates that the Kubernetes versions of the requirements parse and the API groups are
+// valid group names
+func validateRequirements(a *addonmgrv1alpha1.Addon) error {
+	reqs := a.Spec.Requirements
+	var min, max *version.Version
+	var err error
+	if reqs.MinKubernetesVersion != "" {
+		if min, err = version.ParseGeneric(reqs.MinKubernetesVersion); err != nil {
+			return fmt.Errorf("invalid spec.requirements.minKubernetesVersion %q. %v", reqs.MinKubernetesVersion, err)
+		}
+	}
+	if reqs.MaxKubernetesVersion != "" {
+		if max, err = version.ParseGeneric(reqs.MaxKubernetesVersion); err != nil {
+			return fmt.Errorf("invalid spec.requirements.maxKubernetesVersion %q. %v", reqs.MaxKubernetesVersion, err)
+		}
+	}
+	if min != nil && max != nil && !withinMax(min, max) {
+		return fmt.Errorf("invalid spec.requirements, minKubernetesVersion %s is higher than maxKubernetesVersion %s", min, max)
+	}
+
+	for _, group := range reqs.APIGroups {
+		if errs := validation.IsDNS1123Subdomain(group); len(errs) > 0 {
+			return fmt.Errorf("invalid spec.requirements.apiGroups %q. %s", group, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// checkRequirements checks the requirements of the addon against the version and API groups served by the cluster,
+// the cluster is only queried for the requirements that are set
+func checkRequirements(d discovery.DiscoveryInterface, a *addonmgrv1alpha1.Addon) error {
+	reqs := a.Spec.Requirements
+	var unmet []string
+
+	if reqs.MinKubernetesVersion != "" || reqs.MaxKubernetesVersion != "" {
+		info, err := d.ServerVersion()
+		if err != nil {
+			return fmt.Errorf("cluster version could not be discovered. %v", err)
+		}
+		server, err := version.ParseGeneric(info.GitVersion)
+		if err != nil {
+			return fmt.Errorf("cluster version %q could not be parsed. %v", info.GitVersion, err)
+		}
+		if reqs.MinKubernetesVersion != "" && !server.AtLeast(version.MustParseGeneric(reqs.MinKubernetesVersion)) {
+			unmet = append(unmet, fmt.Sprintf("kubernetes version %s is lower than %s", server, reqs.MinKubernetesVersion))
+		}
+		if reqs.MaxKubernetesVersion != "" && !withinMax(server, version.MustParseGeneric(reqs.MaxKubernetesVersion)) {
+			unmet = append(unmet, fmt.Sprintf("kubernetes version %s is higher than %s", server, reqs.MaxKubernetesVersion))
+		}
+	}
+
+	if len(reqs.APIGroups) > 0 {
+		groups, err := d.ServerGroups()
+		if err != nil {
+			return fmt.Errorf("cluster API groups could not be discovered. %v", err)
+		}
+		served := sets.NewString()
+		for _, g := range groups.Groups {
+			served.Insert(g.Name)
+		}
+		for _, group := range reqs.APIGroups {
+			if !served.Has(group) {
+				unmet = append(unmet, fmt.Sprintf("API group %s is not served", group))
+			}
+		}
+	}
+
+	if len(unmet) > 0 {
+		return &RequirementsError{Unmet: unmet}
+	}
+	return nil
+}
+
+// withinMax returns true if v is not higher than max, only the components set in max are compared so that a
+// major.minor max includes its patch releases
+func withinMax(v, max *version.Version) bool {
+	components := v.Components()
+	if n := len(max.Components()); len(components) > n {
+		components = components[:n]
+	}
+	parts := make([]string, len(components))
+	for i, c := range components {
+		parts[i] = fmt.Sprint(c)
+	}
+	return !max.LessThan(version.MustParseGeneric(strings.Join(parts, ".")))
+}
@@ -0,0 +1,90 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestValidateRequirements(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	a := &addonmgrv1alpha1.Addon{}
+	g.Expect(validateRequirements(a)).To(Succeed())
+
+	a.Spec.Requirements = addonmgrv1alpha1.AddonRequirements{
+		MinKubernetesVersion: "1.19",
+		MaxKubernetesVersion: "v1.22",
+		APIGroups:            []string{"networking.istio.io"},
+	}
+	g.Expect(validateRequirements(a)).To(Succeed())
+
+	a.Spec.Requirements.MinKubernetesVersion = "latest"
+	g.Expect(validateRequirements(a)).To(HaveOccurred())
+
+	a.Spec.Requirements.MinKubernetesVersion = "1.23"
+	g.Expect(validateRequirements(a)).To(MatchError("invalid spec.requirements, minKubernetesVersion 1.23 is higher than maxKubernetesVersion 1.22"))
+
+	a.Spec.Requirements.MinKubernetesVersion = "1.22.4"
+	g.Expect(validateRequirements(a)).To(Succeed())
+
+	a.Spec.Requirements.APIGroups = []string{"Networking_Istio"}
+	g.Expect(validateRequirements(a)).To(HaveOccurred())
+}
+
+func TestCheckRequirements(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	d := &fakediscovery.FakeDiscovery{
+		Fake:               &clienttesting.Fake{},
+		FakedServerVersion: &version.Info{GitVersion: "v1.21.5-eks-bc4871b"},
+	}
+	d.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "apps/v1"},
+		{GroupVersion: "argoproj.io/v1alpha1"},
+	}
+
+	a := &addonmgrv1alpha1.Addon{}
+	g.Expect(checkRequirements(d, a)).To(Succeed())
+	g.Expect(d.Actions()).To(BeEmpty())
+
+	a.Spec.Requirements = addonmgrv1alpha1.AddonRequirements{
+		MinKubernetesVersion: "1.19",
+		MaxKubernetesVersion: "1.21",
+		APIGroups:            []string{"argoproj.io"},
+	}
+	g.Expect(checkRequirements(d, a)).To(Succeed())
+
+	a.Spec.Requirements = addonmgrv1alpha1.AddonRequirements{
+		MinKubernetesVersion: "1.22",
+		APIGroups:            []string{"argoproj.io", "networking.istio.io"},
+	}
+	err := checkRequirements(d, a)
+	var reqErr *RequirementsError
+	g.Expect(errors.As(err, &reqErr)).To(BeTrue())
+	g.Expect(reqErr.Unmet).To(Equal([]string{"kubernetes version 1.21.5 is lower than 1.22", "API group networking.istio.io is not served"}))
+
+	a.Spec.Requirements = addonmgrv1alpha1.AddonRequirements{MaxKubernetesVersion: "1.20.9"}
+	g.Expect(checkRequirements(d, a)).To(MatchError("cluster does not meet the addon requirements: kubernetes version 1.21.5 is higher than 1.20.9"))
+}
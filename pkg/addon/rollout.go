@@ -0,0 +1,108 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"fmt"
+	"sort"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// RolloutStage returns the index of the rollout stage listing the namespace the addon installs into, or -1
+func RolloutStage(a *addonmgrv1alpha1.Addon) int {
+	for i, stage := range a.Spec.Rollout.Stages {
+		for _, ns := range stage.Namespaces {
+			if ns == a.Spec.Params.Namespace {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// BlockingRollout returns the rollout stage of the addon and the names of the addons of previous stages of its
+// rollout that have not succeeded or been skipped yet, split into those still pending and those that failed. The
+// addon may install once neither is returned.
+func BlockingRollout(cache VersionCacheClient, a *addonmgrv1alpha1.Addon) (int, []string, []string) {
+	var stage = RolloutStage(a)
+	var pending, failed []string
+	if a.Spec.Rollout.Name == "" || stage <= 0 {
+		return stage, nil, nil
+	}
+
+	for _, vmap := range cache.GetAllVersions() {
+		for _, v := range vmap {
+			if v.Rollout != a.Spec.Rollout.Name || v.Namespace != a.GetNamespace() || v.UID == a.GetUID() ||
+				v.RolloutStage < 0 || v.RolloutStage >= stage || v.PkgPhase == addonmgrv1alpha1.Succeeded ||
+				v.PkgPhase == addonmgrv1alpha1.Skipped {
+				continue
+			}
+			if rolloutFailed(v.PkgPhase) {
+				failed = append(failed, v.Name)
+			} else {
+				pending = append(pending, v.Name)
+			}
+		}
+	}
+
+	sort.Strings(pending)
+	sort.Strings(failed)
+	return stage, pending, failed
+}
+
+// rolloutFailed is true for install phases that halt a rollout
+func rolloutFailed(phase addonmgrv1alpha1.ApplicationAssemblyPhase) bool {
+	switch phase {
+	case addonmgrv1alpha1.Failed, addonmgrv1alpha1.ValidationFailed, addonmgrv1alpha1.RolledBack, addonmgrv1alpha1.Quarantined,
+		addonmgrv1alpha1.Conflict:
+		return true
+	}
+	return false
+}
+
+// validateRollout validates that the stages of a named rollout list the namespace of the addon, and list each
+// namespace once
+func validateRollout(a *addonmgrv1alpha1.Addon) error {
+	rollout := a.Spec.Rollout
+	if rollout.Name == "" {
+		if len(rollout.Stages) > 0 {
+			return fmt.Errorf("invalid spec.rollout, name is required")
+		}
+		return nil
+	}
+
+	if len(rollout.Stages) == 0 {
+		return fmt.Errorf("invalid spec.rollout, at least one stage is required")
+	}
+
+	var staged = make(map[string]int)
+	for i, stage := range rollout.Stages {
+		if len(stage.Namespaces) == 0 {
+			return fmt.Errorf("invalid spec.rollout.stages[%d], at least one namespace is required", i)
+		}
+		for _, ns := range stage.Namespaces {
+			if j, ok := staged[ns]; ok {
+				return fmt.Errorf("invalid spec.rollout.stages[%d], namespace %s is already listed in stage %d", i, ns, j)
+			}
+			staged[ns] = i
+		}
+	}
+
+	if _, ok := staged[a.Spec.Params.Namespace]; !ok {
+		return fmt.Errorf("invalid spec.rollout, namespace %s of the addon is not listed in any stage", a.Spec.Params.Namespace)
+	}
+	return nil
+}
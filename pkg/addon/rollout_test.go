@@ -0,0 +1,99 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func rolloutAddon(namespace string) *addonmgrv1alpha1.Addon {
+	return &addonmgrv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon", Namespace: "system", UID: "0"},
+		Spec: addonmgrv1alpha1.AddonSpec{
+			Params: addonmgrv1alpha1.AddonParams{Namespace: namespace},
+			Rollout: addonmgrv1alpha1.RolloutSpec{
+				Name: "ingress",
+				Stages: []addonmgrv1alpha1.RolloutStage{
+					{Namespaces: []string{"canary"}},
+					{Namespaces: []string{"team-a", "team-b"}},
+					{Namespaces: []string{"team-c"}},
+				},
+			},
+		},
+	}
+}
+
+func TestBlockingRollout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := &cached{
+		addons: map[string]map[string]Version{
+			"ingress-canary": {"1.0.0": Version{Name: "canary", Namespace: "system", UID: "1", Rollout: "ingress", RolloutStage: 0, PkgPhase: addonmgrv1alpha1.Succeeded}},
+			"ingress-a":      {"1.0.0": Version{Name: "team-a", Namespace: "system", UID: "2", Rollout: "ingress", RolloutStage: 1, PkgPhase: addonmgrv1alpha1.Pending}},
+			"ingress-b":      {"1.0.0": Version{Name: "team-b", Namespace: "system", UID: "3", Rollout: "ingress", RolloutStage: 1, PkgPhase: addonmgrv1alpha1.Failed}},
+			"other":          {"1.0.0": Version{Name: "other", Namespace: "system", UID: "4", Rollout: "other", RolloutStage: 0, PkgPhase: addonmgrv1alpha1.Failed}},
+		},
+	}
+
+	stage, pending, failed := BlockingRollout(c, rolloutAddon("canary"))
+	g.Expect(stage).To(Equal(0))
+	g.Expect(pending).To(BeEmpty())
+	g.Expect(failed).To(BeEmpty())
+
+	// Addons of the same stage do not block each other
+	stage, pending, failed = BlockingRollout(c, rolloutAddon("team-a"))
+	g.Expect(stage).To(Equal(1))
+	g.Expect(pending).To(BeEmpty())
+	g.Expect(failed).To(BeEmpty())
+
+	stage, pending, failed = BlockingRollout(c, rolloutAddon("team-c"))
+	g.Expect(stage).To(Equal(2))
+	g.Expect(pending).To(Equal([]string{"team-a"}))
+	g.Expect(failed).To(Equal([]string{"team-b"}))
+
+	// Addons without a rollout are not blocked
+	a := rolloutAddon("team-c")
+	a.Spec.Rollout = addonmgrv1alpha1.RolloutSpec{}
+	stage, pending, failed = BlockingRollout(c, a)
+	g.Expect(stage).To(Equal(-1))
+	g.Expect(pending).To(BeEmpty())
+	g.Expect(failed).To(BeEmpty())
+}
+
+func TestValidateRollout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(validateRollout(rolloutAddon("team-b"))).To(Succeed())
+	g.Expect(validateRollout(&addonmgrv1alpha1.Addon{})).To(Succeed())
+
+	g.Expect(validateRollout(rolloutAddon("team-d"))).To(MatchError("invalid spec.rollout, namespace team-d of the addon is not listed in any stage"))
+
+	a := rolloutAddon("team-a")
+	a.Spec.Rollout.Stages[2].Namespaces = []string{"canary"}
+	g.Expect(validateRollout(a)).To(MatchError("invalid spec.rollout.stages[2], namespace canary is already listed in stage 0"))
+
+	a = rolloutAddon("team-a")
+	a.Spec.Rollout.Name = ""
+	g.Expect(validateRollout(a)).To(MatchError("invalid spec.rollout, name is required"))
+
+	a = rolloutAddon("team-a")
+	a.Spec.Rollout.Stages = nil
+	g.Expect(validateRollout(a)).To(MatchError("invalid spec.rollout, at least one stage is required"))
+}
@@ -0,0 +1,187 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// validatePodTemplate validates the scheduling of the lifecycle workflow pods, so that workflows are not submitted
+// with pods that can never be scheduled or are rejected
+func validatePodTemplate(a *addonmgrv1alpha1.Addon) error {
+	pt := a.Spec.Lifecycle.PodTemplate
+	if pt == nil {
+		return nil
+	}
+
+	for k, v := range pt.NodeSelector {
+		if errs := append(validation.IsQualifiedName(k), validation.IsValidLabelValue(v)...); len(errs) > 0 {
+			return fmt.Errorf("invalid spec.lifecycle.podTemplate.nodeSelector %s=%s. %s", k, v, strings.Join(errs, ", "))
+		}
+	}
+
+	for i, t := range pt.Tolerations {
+		if err := validateToleration(t); err != nil {
+			return fmt.Errorf("invalid spec.lifecycle.podTemplate.tolerations[%d]. %v", i, err)
+		}
+	}
+
+	if err := validateAffinity(pt.Affinity); err != nil {
+		return fmt.Errorf("invalid spec.lifecycle.podTemplate.affinity. %v", err)
+	}
+
+	return nil
+}
+
+func validateToleration(t corev1.Toleration) error {
+	if t.Key != "" {
+		if errs := validation.IsQualifiedName(t.Key); len(errs) > 0 {
+			return fmt.Errorf("key %q is invalid. %s", t.Key, strings.Join(errs, ", "))
+		}
+	}
+
+	switch t.Operator {
+	case corev1.TolerationOpEqual, "":
+		if t.Key == "" {
+			return fmt.Errorf("operator must be Exists when key is empty")
+		}
+	case corev1.TolerationOpExists:
+		if t.Value != "" {
+			return fmt.Errorf("value must be empty when operator is Exists")
+		}
+	default:
+		return fmt.Errorf("operator %q is not one of Equal, Exists", t.Operator)
+	}
+
+	switch t.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		return fmt.Errorf("effect %q is not one of NoSchedule, PreferNoSchedule, NoExecute", t.Effect)
+	}
+
+	if t.TolerationSeconds != nil && t.Effect != corev1.TaintEffectNoExecute {
+		return fmt.Errorf("tolerationSeconds requires effect NoExecute")
+	}
+
+	return nil
+}
+
+func validateAffinity(affinity *corev1.Affinity) error {
+	if affinity == nil {
+		return nil
+	}
+
+	if na := affinity.NodeAffinity; na != nil {
+		if required := na.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			if len(required.NodeSelectorTerms) == 0 {
+				return fmt.Errorf("nodeAffinity requires at least one node selector term")
+			}
+			for _, term := range required.NodeSelectorTerms {
+				if err := validateNodeSelectorTerm(term); err != nil {
+					return fmt.Errorf("nodeAffinity. %v", err)
+				}
+			}
+		}
+		for _, preferred := range na.PreferredDuringSchedulingIgnoredDuringExecution {
+			if err := validateWeight(preferred.Weight); err != nil {
+				return fmt.Errorf("nodeAffinity. %v", err)
+			}
+			if err := validateNodeSelectorTerm(preferred.Preference); err != nil {
+				return fmt.Errorf("nodeAffinity. %v", err)
+			}
+		}
+	}
+
+	if pa := affinity.PodAffinity; pa != nil {
+		if err := validatePodAffinityTerms(pa.RequiredDuringSchedulingIgnoredDuringExecution, pa.PreferredDuringSchedulingIgnoredDuringExecution); err != nil {
+			return fmt.Errorf("podAffinity. %v", err)
+		}
+	}
+
+	if paa := affinity.PodAntiAffinity; paa != nil {
+		if err := validatePodAffinityTerms(paa.RequiredDuringSchedulingIgnoredDuringExecution, paa.PreferredDuringSchedulingIgnoredDuringExecution); err != nil {
+			return fmt.Errorf("podAntiAffinity. %v", err)
+		}
+	}
+
+	return nil
+}
+
+func validateNodeSelectorTerm(term corev1.NodeSelectorTerm) error {
+	var reqs = append([]corev1.NodeSelectorRequirement{}, term.MatchExpressions...)
+	for _, req := range append(reqs, term.MatchFields...) {
+		if req.Key == "" {
+			return fmt.Errorf("node selector requirement key is empty")
+		}
+
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn:
+			if len(req.Values) == 0 {
+				return fmt.Errorf("operator %s of key %q requires values", req.Operator, req.Key)
+			}
+		case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+			if len(req.Values) > 0 {
+				return fmt.Errorf("operator %s of key %q does not take values", req.Operator, req.Key)
+			}
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if len(req.Values) != 1 {
+				return fmt.Errorf("operator %s of key %q requires a single value", req.Operator, req.Key)
+			}
+			if _, err := strconv.ParseInt(req.Values[0], 10, 64); err != nil {
+				return fmt.Errorf("operator %s of key %q requires an integer value", req.Operator, req.Key)
+			}
+		default:
+			return fmt.Errorf("operator %q of key %q is not a node selector operator", req.Operator, req.Key)
+		}
+	}
+	return nil
+}
+
+func validatePodAffinityTerms(required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm) error {
+	var terms = append([]corev1.PodAffinityTerm{}, required...)
+	for _, p := range preferred {
+		if err := validateWeight(p.Weight); err != nil {
+			return err
+		}
+		terms = append(terms, p.PodAffinityTerm)
+	}
+
+	for _, term := range terms {
+		if term.TopologyKey == "" {
+			return fmt.Errorf("topologyKey is required")
+		}
+		if term.LabelSelector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(term.LabelSelector); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateWeight(weight int32) error {
+	if weight < 1 || weight > 100 {
+		return fmt.Errorf("weight %d is not in the range 1-100", weight)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func Test_validatePodTemplate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	seconds := int64(60)
+	tests := []struct {
+		name    string
+		pt      *addonmgrv1alpha1.PodTemplate
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "valid", pt: &addonmgrv1alpha1.PodTemplate{
+			NodeSelector: map[string]string{"node.kubernetes.io/pool": "addons"},
+			Tolerations: []corev1.Toleration{
+				{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "addons", Effect: corev1.TaintEffectNoExecute, TolerationSeconds: &seconds},
+				{Operator: corev1.TolerationOpExists},
+			},
+			Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+				}},
+			}},
+		}},
+		{name: "node selector", pt: &addonmgrv1alpha1.PodTemplate{NodeSelector: map[string]string{"pool": "not a label value"}}, wantErr: "nodeSelector"},
+		{name: "toleration without key", pt: &addonmgrv1alpha1.PodTemplate{Tolerations: []corev1.Toleration{{Value: "addons"}}}, wantErr: "operator must be Exists when key is empty"},
+		{name: "toleration exists with value", pt: &addonmgrv1alpha1.PodTemplate{Tolerations: []corev1.Toleration{{Key: "a", Operator: corev1.TolerationOpExists, Value: "b"}}}, wantErr: "value must be empty"},
+		{name: "toleration effect", pt: &addonmgrv1alpha1.PodTemplate{Tolerations: []corev1.Toleration{{Key: "a", Effect: "Never"}}}, wantErr: "effect \"Never\""},
+		{name: "toleration seconds", pt: &addonmgrv1alpha1.PodTemplate{Tolerations: []corev1.Toleration{{Key: "a", TolerationSeconds: &seconds}}}, wantErr: "requires effect NoExecute"},
+		{name: "empty node selector terms", pt: &addonmgrv1alpha1.PodTemplate{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{},
+		}}}, wantErr: "at least one node selector term"},
+		{name: "node selector operator", pt: &addonmgrv1alpha1.PodTemplate{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{Weight: 10, Preference: corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "cpus", Operator: corev1.NodeSelectorOpGt, Values: []string{"many"}}},
+			}}},
+		}}}, wantErr: "requires an integer value"},
+		{name: "pod anti affinity", pt: &addonmgrv1alpha1.PodTemplate{Affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{Weight: 100}},
+		}}}, wantErr: "podAntiAffinity. topologyKey is required"},
+	}
+
+	for _, tt := range tests {
+		a := &addonmgrv1alpha1.Addon{}
+		a.Spec.Lifecycle.PodTemplate = tt.pt
+
+		err := validatePodTemplate(a)
+		if tt.wantErr == "" {
+			g.Expect(err).ShouldNot(gomega.HaveOccurred(), tt.name)
+			continue
+		}
+		g.Expect(err).Should(gomega.HaveOccurred(), tt.name)
+		g.Expect(err.Error()).Should(gomega.ContainSubstring(tt.wantErr), tt.name)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"sort"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// BlockingWave returns the lowest wave of the namespace with addons that have not completed their install yet, and
+// the names of those addons. The addon may install once no lower wave is returned, addons in its own wave do not block.
+func BlockingWave(cache VersionCacheClient, a *addonmgrv1alpha1.Addon) (int, []string) {
+	var wave = a.Spec.Wave
+	var names []string
+
+	for _, vmap := range cache.GetAllVersions() {
+		for _, v := range vmap {
+			if v.Namespace != a.GetNamespace() || v.UID == a.GetUID() || v.Wave >= a.Spec.Wave || v.PkgPhase.Completed() {
+				continue
+			}
+			if v.Wave < wave {
+				wave = v.Wave
+				names = nil
+			}
+			if v.Wave == wave {
+				names = append(names, v.Name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return wave, names
+}
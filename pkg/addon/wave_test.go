@@ -0,0 +1,62 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addon
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestBlockingWave(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := &cached{
+		addons: map[string]map[string]Version{
+			"core/crds":     {"1.0.0": Version{Name: "crds", Namespace: "system", UID: "1", Wave: -1, PkgPhase: addonmgrv1alpha1.Succeeded}},
+			"core/cni":      {"1.0.0": Version{Name: "cni", Namespace: "system", UID: "2", Wave: 0, PkgPhase: addonmgrv1alpha1.Pending}},
+			"core/dns":      {"1.0.0": Version{Name: "dns", Namespace: "system", UID: "3", Wave: 0, PkgPhase: addonmgrv1alpha1.Pending}},
+			"core/proxy":    {"1.0.0": Version{Name: "proxy", Namespace: "system", UID: "4", Wave: 1, PkgPhase: addonmgrv1alpha1.Pending}},
+			"core/failed":   {"1.0.0": Version{Name: "failed", Namespace: "system", UID: "5", Wave: 1, PkgPhase: addonmgrv1alpha1.Failed}},
+			"team/frontend": {"1.0.0": Version{Name: "frontend", Namespace: "team", UID: "6", Wave: 0, PkgPhase: addonmgrv1alpha1.Pending}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		wave      int
+		wantWave  int
+		wantNames []string
+	}{
+		{name: "lowest-wave", namespace: "system", wave: -1, wantWave: -1},
+		{name: "same-wave", namespace: "system", wave: 0, wantWave: 0},
+		{name: "lower-wave-pending", namespace: "system", wave: 1, wantWave: 0, wantNames: []string{"cni", "dns"}},
+		{name: "lowest-blocking-wave", namespace: "system", wave: 2, wantWave: 0, wantNames: []string{"cni", "dns"}},
+		{name: "other-namespace", namespace: "other", wave: 2, wantWave: 2},
+	}
+	for _, tt := range tests {
+		a := &addonmgrv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon", Namespace: tt.namespace, UID: "0"},
+			Spec:       addonmgrv1alpha1.AddonSpec{Wave: tt.wave},
+		}
+		wave, names := BlockingWave(c, a)
+		g.Expect(wave).To(Equal(tt.wantWave), tt.name)
+		g.Expect(names).To(Equal(tt.wantNames), tt.name)
+	}
+}
@@ -118,6 +118,9 @@ func newRootCommand() *cobra.Command {
 		Version: version.ToString(),
 	}
 
+	// validate runs offline and does not need a cluster config
+	rootCmd.AddCommand(newValidateCommand())
+
 	cfg, err := config.GetConfig()
 	if err != nil {
 		fmt.Println(err)
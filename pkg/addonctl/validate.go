@@ -0,0 +1,105 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addonctl
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+)
+
+func newValidateCommand() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:          "validate",
+		Short:        "Validate an addon manifest without cluster access",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := cmd.InOrStdin()
+			if file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+
+			return validateAddons(in, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "-", "Addon manifest to validate, - reads from stdin")
+
+	return cmd
+}
+
+// validateAddons runs the offline checks for every addon document in r and reports each result
+func validateAddons(r io.Reader, out io.Writer) error {
+	var failed, count int
+
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		instance := &addonmgrv1alpha1.Addon{}
+		if err := decoder.Decode(instance); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return errors.Wrap(err, "failed to parse addon manifest")
+		}
+
+		if instance.Kind == "" && instance.Name == "" {
+			// Empty document
+			continue
+		}
+		count++
+
+		if instance.Kind != "Addon" || instance.APIVersion != addonmgrv1alpha1.GroupVersion.String() {
+			return fmt.Errorf("%s %q is not an addon, expected kind Addon and apiVersion %s", instance.Kind, instance.Name, addonmgrv1alpha1.GroupVersion)
+		}
+
+		fmt.Fprintf(out, "Addon %s/%s\n", instance.Namespace, instance.Name)
+		for _, result := range addon.ValidateOffline(instance) {
+			switch {
+			case result.Skipped:
+				fmt.Fprintf(out, "  SKIP  %s (requires cluster access)\n", result.Name)
+			case result.Err != nil:
+				failed++
+				fmt.Fprintf(out, "  FAIL  %s: %v\n", result.Name, result.Err)
+			default:
+				fmt.Fprintf(out, "  PASS  %s\n", result.Name)
+			}
+		}
+	}
+
+	if count == 0 {
+		return errors.New("no addon found in manifest")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addonctl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const validAddon = `apiVersion: addonmgr.keikoproj.io/v1alpha1
+kind: Addon
+metadata:
+  name: event-router
+  namespace: addon-manager-system
+spec:
+  pkgName: event-router
+  pkgVersion: v0.2
+  pkgType: composite
+  pkgDescription: "Event router"
+  pkgDeps:
+    core/A: "*"
+  params:
+    namespace: event-router-ns
+  selector:
+    matchLabels:
+      app: event-router
+  secrets:
+  - name: event-router-secret
+  lifecycle:
+    install:
+      template: |
+        apiVersion: argoproj.io/v1alpha1
+        kind: Workflow
+        spec:
+          entrypoint: entry
+          templates:
+          - name: entry
+            container:
+              image: bitnami/kubectl:1.21
+              args: ["version"]
+`
+
+func TestValidateAddons(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var out bytes.Buffer
+	g.Expect(validateAddons(strings.NewReader(validAddon), &out)).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("PASS  workflows"))
+	g.Expect(out.String()).To(ContainSubstring("SKIP  dependencies-installed (requires cluster access)"))
+	g.Expect(out.String()).ToNot(ContainSubstring("FAIL"))
+}
+
+func TestValidateAddons_Fail(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	invalid := strings.NewReplacer(
+		"pkgType: composite", "pkgType: unknown",
+		"namespace: event-router-ns", "namespace: Event_Router",
+		"- name: event-router-secret", "- name: Bad_Secret",
+	).Replace(validAddon)
+
+	var out bytes.Buffer
+	err := validateAddons(strings.NewReader(invalid), &out)
+	g.Expect(err).To(MatchError("3 check(s) failed"))
+	g.Expect(out.String()).To(ContainSubstring(`FAIL  package: unsupported package type "unknown"`))
+	g.Expect(out.String()).To(ContainSubstring("FAIL  namespace"))
+	g.Expect(out.String()).To(ContainSubstring("FAIL  secrets"))
+
+	err = validateAddons(strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n"), &out)
+	g.Expect(err).To(HaveOccurred())
+
+	err = validateAddons(strings.NewReader(""), &out)
+	g.Expect(err).To(MatchError("no addon found in manifest"))
+}
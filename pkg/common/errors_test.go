@@ -0,0 +1,57 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsRetryable(t *testing.T) {
+	gr := schema.GroupResource{Group: "addonmgr.keikoproj.io", Resource: "addons"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "conflict", err: apierrors.NewConflict(gr, "my-addon", errors.New("object was modified")), want: true},
+		{name: "server-timeout", err: apierrors.NewServerTimeout(gr, "list", 1), want: true},
+		{name: "timeout", err: apierrors.NewTimeoutError("request timed out", 1), want: true},
+		{name: "too-many-requests", err: apierrors.NewTooManyRequests("throttled", 1), want: true},
+		{name: "service-unavailable", err: apierrors.NewServiceUnavailable("unavailable"), want: true},
+		{name: "wrapped-conflict", err: fmt.Errorf("update failed. %w", apierrors.NewConflict(gr, "my-addon", errors.New("object was modified"))), want: true},
+		{name: "deadline", err: context.DeadlineExceeded, want: true},
+		{name: "connection-refused", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, want: true},
+		{name: "connection-reset", err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}, want: true},
+		{name: "not-found", err: apierrors.NewNotFound(gr, "my-addon"), want: false},
+		{name: "forbidden", err: apierrors.NewForbidden(gr, "my-addon", errors.New("denied")), want: false},
+		{name: "invalid", err: apierrors.NewBadRequest("invalid spec"), want: false},
+		{name: "other", err: errors.New("addon my-addon needs secret \"db\" that was not found in namespace default"), want: false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: common.IsRetryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
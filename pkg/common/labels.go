@@ -0,0 +1,70 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ManagedByLabel is the label key set to the addon-manager group on all addon resources
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// NameLabel is the label key set to the addon name on all addon resources
+	NameLabel = "app.kubernetes.io/name"
+	// ForceReinstallAnnotation re-runs the prereqs and install workflows of an addon when set, it is removed afterwards
+	ForceReinstallAnnotation = "addonmgr.keikoproj.io/force-reinstall"
+	// ReconcileTokenAnnotation triggers a reconcile which refreshes the addon status without running workflows when changed
+	ReconcileTokenAnnotation = "addonmgr.keikoproj.io/reconcile-token"
+	// RetryDeleteAnnotation re-runs the delete workflow of an addon which failed to delete, it is removed afterwards
+	RetryDeleteAnnotation = "addonmgr.keikoproj.io/retry-delete"
+	// AllowDuplicateAnnotation lets an addon install a package version which another addon installs when set to true
+	AllowDuplicateAnnotation = "addonmgr.keikoproj.io/allow-duplicate"
+	// ForceDeleteAnnotation deletes an addon while installed addons still depend on it
+	ForceDeleteAnnotation = "addonmgr.keikoproj.io/force-delete"
+)
+
+// IsReservedLabel returns true for label keys that are always set by addon-manager
+func IsReservedLabel(key string) bool {
+	return key == ManagedByLabel || key == NameLabel
+}
+
+// SetCommonMetadata merges the common labels and annotations of an addon onto the object. Common labels replace
+// labels of the object with the same key, except for reserved labels which are never overwritten.
+func SetCommonMetadata(obj metav1.Object, labels, annotations map[string]string) {
+	if len(labels) > 0 {
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = make(map[string]string, len(labels))
+		}
+		for key, value := range labels {
+			if IsReservedLabel(key) {
+				continue
+			}
+			objLabels[key] = value
+		}
+		obj.SetLabels(objLabels)
+	}
+
+	if len(annotations) > 0 {
+		objAnnotations := obj.GetAnnotations()
+		if objAnnotations == nil {
+			objAnnotations = make(map[string]string, len(annotations))
+		}
+		for key, value := range annotations {
+			objAnnotations[key] = value
+		}
+		obj.SetAnnotations(objAnnotations)
+	}
+}
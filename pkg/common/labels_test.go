@@ -0,0 +1,45 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSetCommonMetadata(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetLabels(map[string]string{NameLabel: "fluentd", "team": "logging", "tier": "backend"})
+
+	SetCommonMetadata(obj, map[string]string{NameLabel: "other", ManagedByLabel: "helm", "team": "platform"}, map[string]string{"cost-center": "1234"})
+
+	// Common labels win over labels of the object, reserved labels are never set
+	want := map[string]string{NameLabel: "fluentd", "team": "platform", "tier": "backend"}
+	if got := obj.GetLabels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("common.SetCommonMetadata labels = %v, want %v", got, want)
+	}
+	if got := obj.GetAnnotations(); !reflect.DeepEqual(got, map[string]string{"cost-center": "1234"}) {
+		t.Errorf("common.SetCommonMetadata annotations = %v, want %v", got, map[string]string{"cost-center": "1234"})
+	}
+
+	// Nothing is set without common labels and annotations
+	obj = &unstructured.Unstructured{}
+	SetCommonMetadata(obj, nil, nil)
+	if obj.GetLabels() != nil || obj.GetAnnotations() != nil {
+		t.Errorf("common.SetCommonMetadata set metadata %v %v, want none", obj.GetLabels(), obj.GetAnnotations())
+	}
+}
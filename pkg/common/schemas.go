@@ -46,6 +46,33 @@ func SecretGVR() schema.GroupVersionResource {
 	}
 }
 
+// NamespaceGVR returns the schema representation of the namespace resource
+func NamespaceGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "namespaces",
+	}
+}
+
+// ServiceAccountGVR returns the schema representation of the service account resource
+func ServiceAccountGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "serviceaccounts",
+	}
+}
+
+// CustomResourceDefinitionGVR returns the schema representation of the custom resource definition resource
+func CustomResourceDefinitionGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+	}
+}
+
 // WorkflowGVR returns the schema representation of the workflow resource
 func WorkflowGVR() schema.GroupVersionResource {
 	return schema.GroupVersionResource{
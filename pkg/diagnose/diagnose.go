@@ -0,0 +1,210 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diagnose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+const inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Check verifies a dependency of the controller, Hint is printed with the error when it fails
+type Check struct {
+	Name string
+	Hint string
+	Run  func(ctx context.Context) error
+}
+
+// Result is the outcome of a check, Err is nil if it passed
+type Result struct {
+	Name string
+	Err  error
+	Hint string
+}
+
+// Run runs the checks in order, a failed check does not stop the following ones
+func Run(ctx context.Context, checks []Check) []Result {
+	var results = make([]Result, 0, len(checks))
+	for _, c := range checks {
+		results = append(results, Result{Name: c.Name, Err: c.Run(ctx), Hint: c.Hint})
+	}
+	return results
+}
+
+// Print writes a pass or fail line per result with the remediation hint of failed checks, it returns true if all
+// checks passed
+func Print(w io.Writer, results []Result) bool {
+	var passed = true
+	for _, r := range results {
+		if r.Err == nil {
+			fmt.Fprintf(w, "PASS  %s\n", r.Name)
+			continue
+		}
+		passed = false
+		fmt.Fprintf(w, "FAIL  %s: %v\n", r.Name, r.Err)
+		if r.Hint != "" {
+			fmt.Fprintf(w, "      %s\n", r.Hint)
+		}
+	}
+	return passed
+}
+
+// APIServer checks that the API server is reachable
+func APIServer(d discovery.DiscoveryInterface) Check {
+	return Check{
+		Name: "API server is reachable",
+		Hint: "Check the kubeconfig or in-cluster configuration and the network policies of the controller pod.",
+		Run: func(context.Context) error {
+			_, err := d.ServerVersion()
+			return err
+		},
+	}
+}
+
+// Permissions checks that addons and workflows can be watched in the namespaces, or cluster wide when none are given
+func Permissions(client kubernetes.Interface, namespaces []string) Check {
+	return Check{
+		Name: "addons and workflows can be watched",
+		Hint: "Grant the controller service account list and watch on addons and workflows, see config/rbac.",
+		Run: func(ctx context.Context) error {
+			return VerifyAccess(ctx, client, namespaces)
+		},
+	}
+}
+
+// VerifyAccess checks that addons and workflows can be watched in the namespaces, or cluster wide when none are given
+func VerifyAccess(ctx context.Context, client kubernetes.Interface, namespaces []string) error {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	resources := []authorizationv1.ResourceAttributes{
+		{Group: common.AddonGVR().Group, Resource: common.AddonGVR().Resource},
+		{Group: common.WorkflowGVR().Group, Resource: common.WorkflowGVR().Resource},
+	}
+
+	for _, ns := range namespaces {
+		for _, res := range resources {
+			for _, verb := range []string{"list", "watch"} {
+				attrs := res
+				attrs.Namespace = ns
+				attrs.Verb = verb
+
+				review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+					Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+				}, metav1.CreateOptions{})
+				if err != nil {
+					return err
+				}
+
+				if !review.Status.Allowed {
+					scope := "all namespaces"
+					if ns != metav1.NamespaceAll {
+						scope = fmt.Sprintf("namespace %s", ns)
+					}
+					return fmt.Errorf("cannot %s %s.%s in %s", verb, res.Resource, res.Group, scope)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// WorkflowCRD checks that the Argo Workflow CRD is served
+func WorkflowCRD(d discovery.DiscoveryInterface) Check {
+	gvr := common.WorkflowGVR()
+	return Check{
+		Name: fmt.Sprintf("%s.%s/%s are served", gvr.Resource, gvr.Group, gvr.Version),
+		Hint: "Install Argo Workflows, addons are kept Pending until it is installed.",
+		Run: func(context.Context) error {
+			list, err := d.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+			if err != nil {
+				return err
+			}
+			for _, r := range list.APIResources {
+				if r.Name == gvr.Resource {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s is not served by %s", gvr.Resource, gvr.GroupVersion())
+		},
+	}
+}
+
+// ServiceAccount checks that the service account workflows run with exists
+func ServiceAccount(client kubernetes.Interface, namespace, name string) Check {
+	return Check{
+		Name: fmt.Sprintf("workflow service account %s/%s exists", namespace, name),
+		Hint: "Create the service account or set --diagnose-workflow-service-account to the one workflows run with.",
+		Run: func(ctx context.Context) error {
+			_, err := client.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+			return err
+		},
+	}
+}
+
+// LeaderElection checks that the leader election lock has a leader which renewed it within the lease duration. The
+// lock is read from the in-cluster namespace when namespace is empty, like the manager does.
+func LeaderElection(client kubernetes.Interface, namespace, id string, leaseDuration time.Duration) Check {
+	return Check{
+		Name: fmt.Sprintf("leader election lock %s has a leader", id),
+		Hint: "Check the logs of the controller replicas, a leader renews the lock unless it lost access to the API server.",
+		Run: func(ctx context.Context) error {
+			if namespace == "" {
+				data, err := ioutil.ReadFile(inClusterNamespacePath)
+				if err != nil {
+					return fmt.Errorf("leader-election-namespace is not set and the controller is not running in-cluster. %v", err)
+				}
+				namespace = strings.TrimSpace(string(data))
+			}
+
+			cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, id, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			var record resourcelock.LeaderElectionRecord
+			raw, ok := cm.GetAnnotations()[resourcelock.LeaderElectionRecordAnnotationKey]
+			if !ok {
+				return fmt.Errorf("lock %s/%s has no leader election record", namespace, id)
+			}
+			if err := json.Unmarshal([]byte(raw), &record); err != nil {
+				return fmt.Errorf("lock %s/%s has an invalid leader election record. %v", namespace, id, err)
+			}
+			if record.HolderIdentity == "" {
+				return fmt.Errorf("lock %s/%s has no leader", namespace, id)
+			}
+			if expired := record.RenewTime.Add(leaseDuration); time.Now().After(expired) {
+				return fmt.Errorf("lease of leader %s expired at %s", record.HolderIdentity, expired.Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+}
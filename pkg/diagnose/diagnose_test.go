@@ -0,0 +1,104 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diagnose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+func TestPrint(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var out bytes.Buffer
+	g.Expect(Print(&out, []Result{{Name: "a"}})).To(BeTrue())
+	g.Expect(Print(&out, Run(context.TODO(), []Check{
+		{Name: "b", Hint: "fix b", Run: func(context.Context) error { return errors.New("b failed") }},
+		{Name: "c", Run: func(context.Context) error { return nil }},
+	}))).To(BeFalse())
+	g.Expect(out.String()).To(Equal("PASS  a\nFAIL  b: b failed\n      fix b\nPASS  c\n"))
+}
+
+func TestPermissions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Namespace == "addons"
+		return true, review, nil
+	})
+
+	g.Expect(Permissions(client, []string{"addons"}).Run(context.TODO())).To(Succeed())
+	g.Expect(Permissions(client, nil).Run(context.TODO())).To(MatchError("cannot list addons.addonmgr.keikoproj.io in all namespaces"))
+}
+
+func TestWorkflowCRD(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := fake.NewSimpleClientset()
+	check := WorkflowCRD(client.Discovery())
+	g.Expect(check.Run(context.TODO())).NotTo(Succeed())
+
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "argoproj.io/v1alpha1", APIResources: []metav1.APIResource{{Name: "workflows"}}},
+	}
+	g.Expect(check.Run(context.TODO())).To(Succeed())
+}
+
+func TestServiceAccount(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := fake.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "addon-manager-system", Name: "installer"}})
+	g.Expect(ServiceAccount(client, "addon-manager-system", "installer").Run(context.TODO())).To(Succeed())
+	g.Expect(ServiceAccount(client, "default", "installer").Run(context.TODO())).NotTo(Succeed())
+}
+
+func TestLeaderElection(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	lock := func(renewed time.Time) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "addon-manager-system",
+			Name:      "addonmgr-lock",
+			Annotations: map[string]string{
+				resourcelock.LeaderElectionRecordAnnotationKey: fmt.Sprintf(`{"holderIdentity":"manager-1","renewTime":%q}`, renewed.UTC().Format(time.RFC3339)),
+			},
+		}}
+	}
+
+	client := fake.NewSimpleClientset(lock(time.Now()))
+	g.Expect(LeaderElection(client, "addon-manager-system", "addonmgr-lock", time.Minute).Run(context.TODO())).To(Succeed())
+
+	client = fake.NewSimpleClientset(lock(time.Now().Add(-time.Hour)))
+	g.Expect(LeaderElection(client, "addon-manager-system", "addonmgr-lock", time.Minute).Run(context.TODO())).To(
+		MatchError(ContainSubstring("lease of leader manager-1 expired")))
+
+	g.Expect(LeaderElection(client, "default", "addonmgr-lock", time.Minute).Run(context.TODO())).NotTo(Succeed())
+}
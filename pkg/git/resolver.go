@@ -0,0 +1,226 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	uploadPackService = "git-upload-pack"
+	advertisementType = "application/x-git-upload-pack-advertisement"
+	headRef           = "HEAD"
+	peeledSuffix      = "^{}"
+	maxRefsBytes      = 16 << 20
+)
+
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// Auth is the basic auth of a repository, e.g. a username and an access token
+type Auth struct {
+	Username string
+	Password string
+}
+
+// ResolveError is returned when a ref could not be resolved, callers should retry
+type ResolveError struct {
+	Repo string
+	Ref  string
+	Err  error
+}
+
+func (e *ResolveError) Error() string {
+	return fmt.Sprintf("failed to resolve ref %q of %s. %v", e.Ref, e.Repo, e.Err)
+}
+
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// IsResolveError returns true if err was caused by a failed ref resolution
+func IsResolveError(err error) bool {
+	var resolveErr *ResolveError
+	return errors.As(err, &resolveErr)
+}
+
+// IsCommit returns true if the ref is a full commit id, which resolves to itself
+func IsCommit(ref string) bool {
+	return commitPattern.MatchString(ref)
+}
+
+// ValidateRepo returns an error unless the repo is an http(s) URL, only the smart HTTP protocol is supported
+func ValidateRepo(repo string) error {
+	u, err := url.Parse(repo)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("repo %q is not an http(s) URL", repo)
+	}
+	if u.User != nil {
+		return fmt.Errorf("repo %q must not contain credentials, use a secret", repo)
+	}
+	return nil
+}
+
+// Resolver resolves refs of remote repositories to commits
+type Resolver interface {
+	Resolve(ctx context.Context, repo, ref string, auth *Auth) (string, error)
+}
+
+type resolver struct {
+	client *http.Client
+}
+
+// NewResolver returns a Resolver reading the refs advertised by the smart HTTP protocol
+func NewResolver(client *http.Client) Resolver {
+	return &resolver{client: client}
+}
+
+// Resolve returns the commit of the ref, which is a branch, a tag or a full ref name. An empty ref resolves the
+// default branch and a commit id resolves to itself without contacting the repository.
+func (r *resolver) Resolve(ctx context.Context, repo, ref string, auth *Auth) (string, error) {
+	if IsCommit(ref) {
+		return ref, nil
+	}
+	if err := ValidateRepo(repo); err != nil {
+		return "", err
+	}
+
+	refs, err := r.refs(ctx, repo, auth)
+	if err != nil {
+		return "", &ResolveError{Repo: repo, Ref: ref, Err: err}
+	}
+
+	commit, ok := lookup(refs, ref)
+	if !ok {
+		return "", &ResolveError{Repo: repo, Ref: ref, Err: fmt.Errorf("ref not found")}
+	}
+	return commit, nil
+}
+
+// lookup finds the commit of the ref, annotated tags resolve to the commit they point to
+func lookup(refs map[string]string, ref string) (string, bool) {
+	if ref == "" {
+		ref = headRef
+	}
+
+	candidates := []string{ref}
+	if !strings.HasPrefix(ref, "refs/") && ref != headRef {
+		candidates = []string{"refs/heads/" + ref, "refs/tags/" + ref}
+	}
+
+	for _, name := range candidates {
+		if commit, ok := refs[name+peeledSuffix]; ok {
+			return commit, true
+		}
+		if commit, ok := refs[name]; ok {
+			return commit, true
+		}
+	}
+	return "", false
+}
+
+// refs returns the commits of the refs advertised by the repository, keyed by ref name
+func (r *resolver) refs(ctx context.Context, repo string, auth *Auth) (map[string]string, error) {
+	u := fmt.Sprintf("%s/info/refs?service=%s", strings.TrimSuffix(repo, "/"), uploadPackService)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != advertisementType {
+		return nil, fmt.Errorf("unexpected content type %q, the repository does not support the smart HTTP protocol", ct)
+	}
+
+	return parseAdvertisement(io.LimitReader(resp.Body, maxRefsBytes))
+}
+
+// parseAdvertisement parses the pkt-lines of a ref advertisement, the service announcement and capabilities are skipped
+func parseAdvertisement(rd io.Reader) (map[string]string, error) {
+	var refs = make(map[string]string)
+	br := bufio.NewReader(rd)
+	for {
+		line, err := readPktLine(br)
+		if err == io.EOF {
+			return refs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == "" || strings.HasPrefix(line, "# service=") {
+			continue
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if nul := strings.IndexByte(line, 0); nul >= 0 {
+			line = line[:nul]
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || !IsCommit(fields[0]) {
+			return nil, fmt.Errorf("invalid ref advertisement line %q", line)
+		}
+		refs[fields[1]] = fields[0]
+	}
+}
+
+// readPktLine returns the payload of the next pkt-line, flush packets are returned as empty lines
+func readPktLine(br *bufio.Reader) (string, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(br, size[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return "", fmt.Errorf("truncated pkt-line")
+		}
+		return "", err
+	}
+
+	n, err := strconv.ParseUint(string(size[:]), 16, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid pkt-line length %q", string(size[:]))
+	}
+	if n == 0 {
+		return "", nil
+	}
+	if n < 4 {
+		return "", fmt.Errorf("invalid pkt-line length %d", n)
+	}
+
+	payload := make([]byte, n-4)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return "", fmt.Errorf("truncated pkt-line")
+	}
+	return string(payload), nil
+}
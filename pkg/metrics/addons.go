@@ -0,0 +1,117 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// AddonPhase is the install phase of an addon with its common labels
+type AddonPhase struct {
+	Name      string
+	Namespace string
+	Phase     string
+	Labels    map[string]string
+}
+
+// AddonPhaseLister returns the install phase of each addon
+type AddonPhaseLister func() ([]AddonPhase, error)
+
+var (
+	addonPhaseLabels     = []string{"addon", "namespace", "phase"}
+	invalidLabelNameChar = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// LabelName returns the Prometheus label name of a label key, characters which are not allowed in label names are
+// replaced by underscores, e.g. example.com/team is example_com_team
+func LabelName(key string) string {
+	return invalidLabelNameChar.ReplaceAllString(key, "_")
+}
+
+// ValidateLabelKeys returns an error if a key is not a valid label key or its Prometheus label name is not unique
+func ValidateLabelKeys(keys []string) error {
+	var names = map[string]string{}
+	for _, l := range addonPhaseLabels {
+		names[l] = l
+	}
+	for _, key := range keys {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("metrics label key %q is invalid. %s", key, strings.Join(errs, ", "))
+		}
+		name := LabelName(key)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("metrics label key %q has the same label name %q as %q", key, name, other)
+		}
+		names[name] = key
+	}
+	return nil
+}
+
+// addonPhaseCollector reports the install phase of each addon at scrape time with the values of the allowed label
+// keys, so that the number of series is bounded by the number of addons
+type addonPhaseCollector struct {
+	desc      *prometheus.Desc
+	labelKeys []string
+	list      AddonPhaseLister
+}
+
+// NewAddonPhaseCollector returns a collector of the addon_lifecycle_phase metric listed by list, labeled with the
+// values of labelKeys of each addon. Label keys of addons which are not in labelKeys are not reported.
+func NewAddonPhaseCollector(labelKeys []string, list AddonPhaseLister) (prometheus.Collector, error) {
+	if err := ValidateLabelKeys(labelKeys); err != nil {
+		return nil, err
+	}
+
+	var labels = append([]string{}, addonPhaseLabels...)
+	for _, key := range labelKeys {
+		labels = append(labels, LabelName(key))
+	}
+
+	return &addonPhaseCollector{
+		desc: prometheus.NewDesc(
+			"addon_lifecycle_phase",
+			"Install phase of each addon, the value is always 1.",
+			labels, nil,
+		),
+		labelKeys: labelKeys,
+		list:      list,
+	}, nil
+}
+
+// Describe implements prometheus.Collector
+func (c *addonPhaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *addonPhaseCollector) Collect(ch chan<- prometheus.Metric) {
+	addons, err := c.list()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for _, a := range addons {
+		var values = []string{a.Name, a.Namespace, a.Phase}
+		for _, key := range c.labelKeys {
+			values = append(values, a.Labels[key])
+		}
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1, values...)
+	}
+}
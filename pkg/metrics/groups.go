@@ -0,0 +1,64 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Addon group statuses
+const (
+	GroupInstalled = "installed"
+	GroupFailed    = "failed"
+	GroupPending   = "pending"
+)
+
+// AddonGroupCounter returns the number of addons of each group by status
+type AddonGroupCounter func() (map[string]map[string]int, error)
+
+var addonGroupDesc = prometheus.NewDesc(
+	"addon_group_addons",
+	"Number of addons in each addon group by status, installed, failed or pending.",
+	[]string{"group", "status"}, nil,
+)
+
+// addonGroupCollector counts the addons of each group at scrape time, so that groups without addons are dropped
+type addonGroupCollector struct {
+	count AddonGroupCounter
+}
+
+// NewAddonGroupCollector returns a collector of the addon_group_addons metric counted by count
+func NewAddonGroupCollector(count AddonGroupCounter) prometheus.Collector {
+	return &addonGroupCollector{count: count}
+}
+
+// Describe implements prometheus.Collector
+func (c *addonGroupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- addonGroupDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *addonGroupCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.count()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(addonGroupDesc, err)
+		return
+	}
+	for group, statuses := range counts {
+		for status, n := range statuses {
+			ch <- prometheus.MustNewConstMetric(addonGroupDesc, prometheus.GaugeValue, float64(n), group, status)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Reconcile phases
+const (
+	PhaseValidation = "validation"
+	PhaseWorkflow   = "workflow"
+	PhaseObserve    = "observe"
+)
+
+// ReconcilePhaseSeconds is the duration of each reconcile phase, registered with the controller-runtime metrics registry
+var ReconcilePhaseSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "addon_reconcile_phase_seconds",
+	Help:    "Duration of addon reconcile phases in seconds.",
+	Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+}, []string{"phase"})
+
+// Version cache lookup results
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// VersionCacheLookups counts the lookups of the addon version cache by result
+var VersionCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "addon_version_cache_lookups_total",
+	Help: "Number of addon version cache lookups by result, hit or miss.",
+}, []string{"result"})
+
+// VersionCacheSize is the number of addon versions in the version cache
+var VersionCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "addon_version_cache_size",
+	Help: "Number of addon versions in the version cache.",
+})
+
+// StatusWrites counts the addon status updates written to the API server
+var StatusWrites = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "addon_status_writes_total",
+	Help: "Number of addon status updates written to the API server.",
+})
+
+// StatusWritesCoalesced counts the addon status updates which were not written, they are included in a later write
+var StatusWritesCoalesced = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "addon_status_writes_coalesced_total",
+	Help: "Number of addon status updates coalesced into a later write.",
+})
+
+// StatusStreamFailures counts the addon status changes which could not be sent to the status webhook
+var StatusStreamFailures = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "addon_status_stream_failures_total",
+	Help: "Number of addon status changes dropped or failed to be sent to the status webhook.",
+})
+
+func init() {
+	metrics.Registry.MustRegister(ReconcilePhaseSeconds, VersionCacheLookups, VersionCacheSize, StatusWrites, StatusWritesCoalesced,
+		StatusStreamFailures)
+}
+
+// ObserveCacheLookup counts a version cache lookup as a hit when the version was found
+func ObserveCacheLookup(found bool) {
+	if found {
+		VersionCacheLookups.WithLabelValues(CacheHit).Inc()
+		return
+	}
+	VersionCacheLookups.WithLabelValues(CacheMiss).Inc()
+}
+
+// PhaseTimings collects the durations of the phases of a single reconcile
+type PhaseTimings struct {
+	phases    []string
+	durations map[string]time.Duration
+}
+
+// NewPhaseTimings returns empty PhaseTimings
+func NewPhaseTimings() *PhaseTimings {
+	return &PhaseTimings{durations: map[string]time.Duration{}}
+}
+
+// Observe records the time elapsed since start for the phase
+func (t *PhaseTimings) Observe(phase string, start time.Time) {
+	d := time.Since(start)
+	ReconcilePhaseSeconds.WithLabelValues(phase).Observe(d.Seconds())
+
+	if _, ok := t.durations[phase]; !ok {
+		t.phases = append(t.phases, phase)
+	}
+	t.durations[phase] += d
+}
+
+// Get returns the duration recorded for the phase
+func (t *PhaseTimings) Get(phase string) time.Duration {
+	return t.durations[phase]
+}
+
+// Empty returns true if no phase was observed
+func (t *PhaseTimings) Empty() bool {
+	return len(t.phases) == 0
+}
+
+// String returns the phases in the order they were observed, e.g. validation=1ms workflow=2s
+func (t *PhaseTimings) String() string {
+	var parts = make([]string, 0, len(t.phases))
+	for _, phase := range t.phases {
+		parts = append(parts, fmt.Sprintf("%s=%s", phase, t.durations[phase].Round(time.Millisecond)))
+	}
+	return strings.Join(parts, " ")
+}
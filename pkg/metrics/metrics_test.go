@@ -0,0 +1,92 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPhaseTimings(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	timings := NewPhaseTimings()
+	g.Expect(timings.Empty()).To(BeTrue())
+
+	start := time.Now().Add(-time.Second)
+	timings.Observe(PhaseValidation, start)
+	timings.Observe(PhaseObserve, time.Now())
+
+	g.Expect(timings.Empty()).To(BeFalse())
+	g.Expect(timings.Get(PhaseValidation)).To(BeNumerically(">=", time.Second))
+	g.Expect(timings.Get(PhaseWorkflow)).To(BeZero())
+	g.Expect(strings.HasPrefix(timings.String(), "validation=1s observe=")).To(BeTrue())
+	// One histogram series per observed phase
+	g.Expect(testutil.CollectAndCount(ReconcilePhaseSeconds)).To(Equal(2))
+}
+
+func TestAddonGroupCollector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	counts := map[string]map[string]int{
+		"monitoring": {GroupInstalled: 2, GroupFailed: 1, GroupPending: 0},
+	}
+	collector := NewAddonGroupCollector(func() (map[string]map[string]int, error) { return counts, nil })
+
+	g.Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP addon_group_addons Number of addons in each addon group by status, installed, failed or pending.
+# TYPE addon_group_addons gauge
+addon_group_addons{group="monitoring",status="failed"} 1
+addon_group_addons{group="monitoring",status="installed"} 2
+addon_group_addons{group="monitoring",status="pending"} 0
+`))).To(Succeed())
+
+	// Groups without addons are dropped
+	counts = map[string]map[string]int{}
+	g.Expect(testutil.CollectAndCount(collector)).To(Equal(0))
+}
+
+func TestAddonPhaseCollector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addons := []AddonPhase{
+		{Name: "fluentd", Namespace: "logging", Phase: "Succeeded", Labels: map[string]string{"example.com/team": "platform", "build": "1a2b"}},
+		{Name: "prometheus", Namespace: "monitoring", Phase: "Failed"},
+	}
+	collector, err := NewAddonPhaseCollector([]string{"example.com/team"}, func() ([]AddonPhase, error) { return addons, nil })
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Only allowed label keys are reported
+	g.Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP addon_lifecycle_phase Install phase of each addon, the value is always 1.
+# TYPE addon_lifecycle_phase gauge
+addon_lifecycle_phase{addon="fluentd",example_com_team="platform",namespace="logging",phase="Succeeded"} 1
+addon_lifecycle_phase{addon="prometheus",example_com_team="",namespace="monitoring",phase="Failed"} 1
+`))).To(Succeed())
+}
+
+func TestValidateLabelKeys(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(ValidateLabelKeys([]string{"team", "example.com/owner"})).To(Succeed())
+	g.Expect(ValidateLabelKeys([]string{"example.com/"})).To(MatchError(ContainSubstring(`metrics label key "example.com/" is invalid`)))
+	g.Expect(ValidateLabelKeys([]string{"example.com/team", "example.com_team"})).To(MatchError(
+		`metrics label key "example.com_team" has the same label name "example_com_team" as "example.com/team"`))
+	g.Expect(ValidateLabelKeys([]string{"phase"})).To(MatchError(ContainSubstring(`has the same label name "phase"`)))
+}
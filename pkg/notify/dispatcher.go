@@ -0,0 +1,107 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+const (
+	queueSize     = 100
+	notifyTimeout = 10 * time.Second
+)
+
+// DefaultPhases are the phases notified on when none are configured
+var DefaultPhases = []string{string(addonmgrv1alpha1.Failed), string(addonmgrv1alpha1.DeleteFailed)}
+
+// Dispatcher sends notifications in the background so that a slow endpoint never blocks reconciles.
+// Notifications are best-effort, they are dropped when the queue is full and not retried when sending fails.
+type Dispatcher struct {
+	notifier Notifier
+	phases   []string
+	queue    chan Transition
+	log      logr.Logger
+}
+
+// NewDispatcher returns a dispatcher sending transitions into one of the phases to the notifier
+func NewDispatcher(notifier Notifier, phases []string, log logr.Logger) *Dispatcher {
+	return &Dispatcher{
+		notifier: notifier,
+		phases:   phases,
+		queue:    make(chan Transition, queueSize),
+		log:      log,
+	}
+}
+
+// Send queues the transition if it changes the phase into a notified phase, a nil dispatcher discards it
+func (d *Dispatcher) Send(t Transition) {
+	if d == nil || t.From == t.To || !common.ContainsString(d.phases, string(t.To)) {
+		return
+	}
+
+	select {
+	case d.queue <- t:
+	default:
+		d.log.Info("Notification queue is full, dropping notification.", "addon", t.Namespace+"/"+t.Name, "phase", t.To)
+	}
+}
+
+// Start sends queued notifications until the stop channel is closed
+func (d *Dispatcher) Start(stop <-chan struct{}) error {
+	for {
+		select {
+		case <-stop:
+			return nil
+		case t := <-d.queue:
+			d.notify(context.Background(), t)
+		}
+	}
+}
+
+// Flush sends the queued notifications until the queue is empty or the context is done. It is called on shutdown
+// once Start returned, so that transitions persisted by the last reconciles are still notified.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%d queued notifications were not sent. %v", len(d.queue), err)
+		}
+
+		select {
+		case t := <-d.queue:
+			d.notify(ctx, t)
+		default:
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) notify(ctx context.Context, t Transition) {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	if err := d.notifier.Notify(ctx, t); err != nil {
+		d.log.Error(err, "Failed to send notification.", "addon", t.Namespace+"/"+t.Name, "phase", t.To)
+	}
+}
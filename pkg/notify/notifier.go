@@ -0,0 +1,112 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+const (
+	// WebhookType posts the transition as JSON to a generic webhook
+	WebhookType = "webhook"
+	// SlackType posts the transition as a message to a Slack incoming webhook
+	SlackType = "slack"
+)
+
+// Transition is a change of the install phase of an addon
+type Transition struct {
+	Name      string                                    `json:"name"`
+	Namespace string                                    `json:"namespace"`
+	From      addonmgrv1alpha1.ApplicationAssemblyPhase `json:"from"`
+	To        addonmgrv1alpha1.ApplicationAssemblyPhase `json:"to"`
+	Reason    string                                    `json:"reason,omitempty"`
+}
+
+func (t Transition) String() string {
+	s := fmt.Sprintf("Addon %s/%s changed from %q to %q.", t.Namespace, t.Name, t.From, t.To)
+	if t.Reason != "" {
+		s = fmt.Sprintf("%s %s", s, t.Reason)
+	}
+	return s
+}
+
+// Notifier sends a notification about an addon transition
+type Notifier interface {
+	Notify(ctx context.Context, t Transition) error
+}
+
+// New returns the notifier of the given type posting to url
+func New(notifierType, url string, client *http.Client) (Notifier, error) {
+	switch notifierType {
+	case WebhookType:
+		return &webhookNotifier{url: url, client: client}, nil
+	case SlackType:
+		return &slackNotifier{url: url, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q, expected %s or %s", notifierType, WebhookType, SlackType)
+	}
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, t Transition) error {
+	return post(ctx, n.client, n.url, t)
+}
+
+type slackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (n *slackNotifier) Notify(ctx context.Context, t Transition) error {
+	return post(ctx, n.client, n.url, map[string]string{"text": t.String()})
+}
+
+func post(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification to %s failed with status %s", url, resp.Status)
+	}
+
+	return nil
+}
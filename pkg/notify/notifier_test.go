@@ -0,0 +1,270 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+var failed = Transition{Name: "my-addon", Namespace: "default", From: v1alpha1.Pending, To: v1alpha1.Failed, Reason: "install failed"}
+
+func newTestEndpoint(g *GomegaWithT, status int, received chan<- map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		g.Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+		received <- body
+		w.WriteHeader(status)
+	}))
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	received := make(chan map[string]interface{}, 1)
+	srv := newTestEndpoint(g, http.StatusOK, received)
+	defer srv.Close()
+
+	n, err := New(WebhookType, srv.URL, srv.Client())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(n.Notify(context.TODO(), failed)).To(Succeed())
+
+	body := <-received
+	g.Expect(body["name"]).To(Equal("my-addon"))
+	g.Expect(body["from"]).To(Equal("Pending"))
+	g.Expect(body["to"]).To(Equal("Failed"))
+}
+
+func TestSlackNotifier(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	received := make(chan map[string]interface{}, 1)
+	srv := newTestEndpoint(g, http.StatusInternalServerError, received)
+	defer srv.Close()
+
+	n, err := New(SlackType, srv.URL, srv.Client())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(n.Notify(context.TODO(), failed)).NotTo(Succeed())
+
+	body := <-received
+	g.Expect(body["text"]).To(Equal(`Addon default/my-addon changed from "Pending" to "Failed". install failed`))
+}
+
+func TestNew_UnknownType(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := New("email", "http://example.com", http.DefaultClient)
+	g.Expect(err).To(HaveOccurred())
+}
+
+type recordingNotifier struct {
+	sent chan Transition
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, t Transition) error {
+	n.sent <- t
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	n := &recordingNotifier{sent: make(chan Transition, 10)}
+	d := NewDispatcher(n, DefaultPhases, zap.New(zap.UseDevMode(true)))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() { _ = d.Start(stop) }()
+
+	// Unchanged and non-notified phases are filtered
+	d.Send(Transition{Name: "a", From: v1alpha1.Failed, To: v1alpha1.Failed})
+	d.Send(Transition{Name: "b", From: v1alpha1.Pending, To: v1alpha1.Succeeded})
+	d.Send(Transition{Name: "c", From: v1alpha1.Deleting, To: v1alpha1.DeleteFailed})
+
+	g.Eventually(n.sent, time.Second).Should(Receive(Equal(Transition{Name: "c", From: v1alpha1.Deleting, To: v1alpha1.DeleteFailed})))
+	g.Consistently(n.sent, 100*time.Millisecond).ShouldNot(Receive())
+
+	// A nil dispatcher discards notifications
+	var disabled *Dispatcher
+	disabled.Send(failed)
+}
+
+func TestDispatcher_Flush(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	n := &recordingNotifier{sent: make(chan Transition, 10)}
+	d := NewDispatcher(n, DefaultPhases, zap.New(zap.UseDevMode(true)))
+
+	// Notifications queued after Start returned are sent on flush
+	d.Send(failed)
+	d.Send(Transition{Name: "c", From: v1alpha1.Deleting, To: v1alpha1.DeleteFailed})
+	g.Expect(d.Flush(context.TODO())).To(Succeed())
+	g.Expect(n.sent).To(HaveLen(2))
+
+	// Nothing is sent once the context is done
+	d.Send(failed)
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	g.Expect(d.Flush(ctx)).To(MatchError(ContainSubstring("1 queued notifications were not sent")))
+
+	var disabled *Dispatcher
+	g.Expect(disabled.Flush(context.TODO())).To(Succeed())
+}
+
+func TestStatusDiff(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	prev := map[string]interface{}{
+		"lifecycle": map[string]interface{}{"installed": "Pending", "prereqs": "Succeeded"},
+		"reason":    "waiting",
+		"checksum":  "1a2b",
+	}
+	cur := map[string]interface{}{
+		"lifecycle": map[string]interface{}{"installed": "Succeeded", "prereqs": "Succeeded"},
+		"checksum":  "1a2b",
+		"resources": []interface{}{"Deployment/a"},
+	}
+
+	g.Expect(statusDiff(prev, cur)).To(Equal(map[string]interface{}{
+		"lifecycle": map[string]interface{}{"installed": "Succeeded"},
+		"reason":    nil,
+		"resources": []interface{}{"Deployment/a"},
+	}))
+	g.Expect(statusDiff(cur, cur)).To(BeEmpty())
+}
+
+func TestStream(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// The endpoint fails the first request, which is retried
+	received := make(chan map[string]interface{}, 10)
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body map[string]interface{}
+		g.Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+		received <- body
+	}))
+	defer srv.Close()
+
+	s := NewStream(srv.URL, srv.Client(), 100, zap.New(zap.UseDevMode(true)))
+	s.backoff = wait.Backoff{Steps: 3, Duration: time.Millisecond}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() { _ = s.Start(stop) }()
+
+	a := &v1alpha1.Addon{}
+	a.Name, a.Namespace = "my-addon", "default"
+	a.Status.Lifecycle.Installed = v1alpha1.Pending
+	s.Send(a)
+
+	var body map[string]interface{}
+	g.Eventually(received, 5*time.Second).Should(Receive(&body))
+	g.Expect(body["name"]).To(Equal("my-addon"))
+	g.Expect(body["diff"]).To(HaveKeyWithValue("lifecycle", map[string]interface{}{"installed": "Pending"}))
+
+	// Unchanged status is not sent, changes only include the changed fields
+	s.Send(a)
+	a.Status.Lifecycle.Installed = v1alpha1.Succeeded
+	s.Send(a)
+	g.Eventually(received, 5*time.Second).Should(Receive(&body))
+	g.Expect(body["diff"]).To(Equal(map[string]interface{}{"lifecycle": map[string]interface{}{"installed": "Succeeded"}}))
+	g.Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+
+	// A nil stream discards status changes
+	var disabled *Stream
+	disabled.Send(a)
+	disabled.Forget(types.NamespacedName{Namespace: "default", Name: "my-addon"})
+}
+
+func TestStream_DroppedChange(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := NewStream("http://localhost", http.DefaultClient, 100, zap.New(zap.UseDevMode(true)))
+	s.queue = make(chan StatusChange, 1)
+
+	a := &v1alpha1.Addon{}
+	a.Name, a.Namespace = "my-addon", "default"
+	a.Status.Lifecycle.Installed = v1alpha1.Pending
+	s.Send(a)
+
+	// The queue is full, the change is dropped
+	a.Status.Lifecycle.Installed = v1alpha1.Succeeded
+	a.Status.Reason = "installed"
+	s.Send(a)
+	g.Expect(s.queue).To(HaveLen(1))
+	c := <-s.queue
+	g.Expect(c.Diff).To(HaveKeyWithValue("lifecycle", map[string]interface{}{"installed": "Pending"}))
+
+	// The next change still includes the fields of the dropped change
+	a.Status.Resources = []v1alpha1.ObjectStatus{{Kind: "Deployment", Name: "my-addon"}}
+	s.Send(a)
+	g.Expect(s.queue).To(HaveLen(1))
+	c = <-s.queue
+	g.Expect(c.Diff).To(HaveKeyWithValue("lifecycle", map[string]interface{}{"installed": "Succeeded"}))
+	g.Expect(c.Diff).To(HaveKeyWithValue("reason", "installed"))
+	g.Expect(c.Diff).To(HaveKey("resources"))
+}
+
+func TestStream_Flush(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	received := make(chan map[string]interface{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		g.Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+		received <- body
+	}))
+	defer srv.Close()
+
+	s := NewStream(srv.URL, srv.Client(), 100, zap.New(zap.UseDevMode(true)))
+
+	// Status changes queued after Start returned are sent on flush
+	a := &v1alpha1.Addon{}
+	a.Name, a.Namespace = "my-addon", "default"
+	a.Status.Lifecycle.Installed = v1alpha1.Pending
+	s.Send(a)
+	b := a.DeepCopy()
+	b.Name = "other-addon"
+	s.Send(b)
+	g.Expect(s.Flush(context.TODO())).To(Succeed())
+	g.Expect(received).To(HaveLen(2))
+
+	// Nothing is sent once the context is done
+	a.Status.Lifecycle.Installed = v1alpha1.Succeeded
+	s.Send(a)
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	g.Expect(s.Flush(ctx)).To(MatchError(ContainSubstring("1 queued status changes were not sent")))
+
+	var disabled *Stream
+	g.Expect(disabled.Flush(context.TODO())).To(Succeed())
+}
@@ -0,0 +1,221 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/metrics"
+)
+
+// DefaultStreamRate is the number of status changes posted per second when none is set
+const DefaultStreamRate = 10
+
+// streamBackoff retries a status change 4 times, waiting 1s, 2s, 4s and 8s
+var streamBackoff = wait.Backoff{Steps: 5, Duration: time.Second, Factor: 2.0}
+
+// StatusChange is a change of the status of an addon. Diff holds the status fields which changed since the last
+// change of the addon was streamed with their new value, removed fields are null.
+type StatusChange struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Diff      map[string]interface{} `json:"diff"`
+}
+
+// Stream posts every status change of addons to a webhook in the background, so that neither a slow nor a failing
+// endpoint blocks reconciles. Changes are rate-limited and retried with backoff, they are dropped when the queue is
+// full or the retries are exhausted.
+type Stream struct {
+	url     string
+	client  *http.Client
+	limiter *rate.Limiter
+	backoff wait.Backoff
+	queue   chan StatusChange
+	last    sync.Map
+	log     logr.Logger
+}
+
+// NewStream returns a stream posting at most limit status changes per second to url
+func NewStream(url string, client *http.Client, limit float64, log logr.Logger) *Stream {
+	return &Stream{
+		url:     url,
+		client:  client,
+		limiter: rate.NewLimiter(rate.Limit(limit), 1),
+		backoff: streamBackoff,
+		queue:   make(chan StatusChange, queueSize),
+		log:     log,
+	}
+}
+
+// Send queues the status fields of the addon which changed since it was last sent, a nil stream discards it
+func (s *Stream) Send(addon *addonmgrv1alpha1.Addon) {
+	if s == nil {
+		return
+	}
+
+	name := types.NamespacedName{Namespace: addon.Namespace, Name: addon.Name}
+	status, err := toMap(addon.Status)
+	if err != nil {
+		metrics.StatusStreamFailures.Inc()
+		s.log.Error(err, "Failed to encode status change.", "addon", name.String())
+		return
+	}
+
+	var prev map[string]interface{}
+	if v, ok := s.last.Load(name); ok {
+		prev = v.(map[string]interface{})
+	}
+	diff := statusDiff(prev, status)
+	if len(diff) == 0 {
+		return
+	}
+
+	// Dropped changes are not remembered, the next change is diffed against the status the receiver was sent last
+	select {
+	case s.queue <- StatusChange{Name: addon.Name, Namespace: addon.Namespace, Diff: diff}:
+		s.last.Store(name, status)
+	default:
+		metrics.StatusStreamFailures.Inc()
+		s.log.Info("Status change queue is full, dropping status change.", "addon", name.String())
+	}
+}
+
+// Forget removes the last sent status of the addon, the next change of an addon with the same name is sent in full
+func (s *Stream) Forget(name types.NamespacedName) {
+	if s == nil {
+		return
+	}
+	s.last.Delete(name)
+}
+
+// Start posts queued status changes until the stop channel is closed
+func (s *Stream) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case c := <-s.queue:
+			if err := s.limiter.Wait(ctx); err != nil {
+				return nil
+			}
+			s.post(ctx, c)
+		}
+	}
+}
+
+// Flush sends the status changes which are still queued once Start returned, each is sent once without retries. It
+// gives up when the context is done.
+func (s *Stream) Flush(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%d queued status changes were not sent. %v", len(s.queue), err)
+		}
+
+		select {
+		case c := <-s.queue:
+			if err := s.limiter.Wait(ctx); err != nil {
+				return fmt.Errorf("%d queued status changes were not sent. %v", len(s.queue)+1, err)
+			}
+			s.send(ctx, c)
+		default:
+			return nil
+		}
+	}
+}
+
+func (s *Stream) send(ctx context.Context, c StatusChange) {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	if err := post(ctx, s.client, s.url, c); err != nil {
+		metrics.StatusStreamFailures.Inc()
+		s.log.Error(err, "Failed to send status change.", "addon", c.Namespace+"/"+c.Name)
+	}
+}
+
+func (s *Stream) post(ctx context.Context, c StatusChange) {
+	err := retry.OnError(s.backoff, func(error) bool { return ctx.Err() == nil }, func() error {
+		ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		defer cancel()
+		return post(ctx, s.client, s.url, c)
+	})
+	if err != nil {
+		metrics.StatusStreamFailures.Inc()
+		s.log.Error(err, "Failed to send status change.", "addon", c.Namespace+"/"+c.Name)
+	}
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// statusDiff returns the fields of cur which differ from prev, descending into objects so that only the changed
+// nested fields are included. Fields missing from cur are null.
+func statusDiff(prev, cur map[string]interface{}) map[string]interface{} {
+	var diff = map[string]interface{}{}
+	for k, v := range cur {
+		p, ok := prev[k]
+		if !ok {
+			diff[k] = v
+			continue
+		}
+		pm, pok := p.(map[string]interface{})
+		vm, vok := v.(map[string]interface{})
+		if pok && vok {
+			if d := statusDiff(pm, vm); len(d) > 0 {
+				diff[k] = d
+			}
+			continue
+		}
+		if !reflect.DeepEqual(p, v) {
+			diff[k] = v
+		}
+	}
+	for k := range prev {
+		if _, ok := cur[k]; !ok {
+			diff[k] = nil
+		}
+	}
+	return diff
+}
@@ -0,0 +1,373 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// Scheme is the prefix used by workflow templates hosted in an OCI registry
+	Scheme = "oci://"
+
+	defaultTag       = "latest"
+	digestAlgorithm  = "sha256:"
+	digestHeader     = "Docker-Content-Digest"
+	maxManifestBytes = 4 << 20
+	maxTemplateBytes = 16 << 20
+)
+
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Reference is a parsed oci:// template reference
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// PullError is returned when a template could not be pulled from the registry, callers should retry
+type PullError struct {
+	Ref string
+	Err error
+}
+
+func (e *PullError) Error() string {
+	return fmt.Sprintf("failed to pull template %s. %v", e.Ref, e.Err)
+}
+
+func (e *PullError) Unwrap() error {
+	return e.Err
+}
+
+// IsPullError returns true if err was caused by a failed template pull
+func IsPullError(err error) bool {
+	var pullErr *PullError
+	return errors.As(err, &pullErr)
+}
+
+// IsReference returns true if the template points to an OCI artifact instead of an inline workflow
+func IsReference(template string) bool {
+	return strings.HasPrefix(strings.TrimSpace(template), Scheme)
+}
+
+// ParseReference parses oci://registry/repository[:tag][@sha256:digest]
+func ParseReference(ref string) (*Reference, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(ref), Scheme)
+
+	slash := strings.Index(s, "/")
+	if slash <= 0 || slash == len(s)-1 {
+		return nil, fmt.Errorf("invalid oci reference %q, expected %sregistry/repository:tag", ref, Scheme)
+	}
+
+	r := &Reference{Registry: s[:slash]}
+	repo := s[slash+1:]
+
+	if at := strings.Index(repo, "@"); at >= 0 {
+		r.Digest = repo[at+1:]
+		repo = repo[:at]
+		if !strings.HasPrefix(r.Digest, digestAlgorithm) || len(r.Digest) != len(digestAlgorithm)+sha256.Size*2 {
+			return nil, fmt.Errorf("invalid oci reference %q, only sha256 digests are supported", ref)
+		}
+	}
+
+	if colon := strings.LastIndex(repo, ":"); colon > strings.LastIndex(repo, "/") {
+		r.Tag = repo[colon+1:]
+		repo = repo[:colon]
+	}
+
+	if repo == "" {
+		return nil, fmt.Errorf("invalid oci reference %q, missing repository", ref)
+	}
+	r.Repository = repo
+
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = defaultTag
+	}
+
+	return r, nil
+}
+
+// Fetcher pulls workflow templates from an OCI registry
+type Fetcher interface {
+	Fetch(ctx context.Context, ref string) (string, error)
+}
+
+type manifest struct {
+	Layers []descriptor `json:"layers"`
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type fetcher struct {
+	client *http.Client
+	sync.RWMutex
+	// templates keyed by manifest digest
+	templates map[string]string
+}
+
+// NewFetcher returns a Fetcher which caches pulled templates by manifest digest
+func NewFetcher(client *http.Client) Fetcher {
+	return &fetcher{
+		client:    client,
+		templates: map[string]string{},
+	}
+}
+
+// Fetch resolves the reference to a manifest digest and returns the template stored in its first layer.
+// Templates are verified against their digests and only pulled once per manifest digest.
+func (f *fetcher) Fetch(ctx context.Context, ref string) (string, error) {
+	r, err := ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	digest := r.Digest
+	if digest == "" {
+		digest, err = f.resolve(ctx, r)
+		if err != nil {
+			return "", &PullError{Ref: ref, Err: err}
+		}
+	}
+
+	if tmpl, ok := f.cached(digest); ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := f.pull(ctx, r, digest)
+	if err != nil {
+		return "", &PullError{Ref: ref, Err: err}
+	}
+
+	f.Lock()
+	f.templates[digest] = tmpl
+	f.Unlock()
+
+	return tmpl, nil
+}
+
+func (f *fetcher) cached(digest string) (string, bool) {
+	f.RLock()
+	defer f.RUnlock()
+
+	tmpl, ok := f.templates[digest]
+	return tmpl, ok
+}
+
+// resolve returns the manifest digest for a tag
+func (f *fetcher) resolve(ctx context.Context, r *Reference) (string, error) {
+	resp, err := f.do(ctx, http.MethodHead, r.url("manifests", r.Tag), manifestMediaTypes)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	digest := resp.Header.Get(digestHeader)
+	if digest == "" {
+		// Registry did not return a digest, fall back to hashing the manifest body
+		body, err := f.get(ctx, r.url("manifests", r.Tag), manifestMediaTypes, maxManifestBytes)
+		if err != nil {
+			return "", err
+		}
+		return digestOf(body), nil
+	}
+
+	return digest, nil
+}
+
+// pull fetches and verifies the manifest and template layer for the digest
+func (f *fetcher) pull(ctx context.Context, r *Reference, digest string) (string, error) {
+	body, err := f.get(ctx, r.url("manifests", digest), manifestMediaTypes, maxManifestBytes)
+	if err != nil {
+		return "", err
+	}
+
+	if err := verify(body, digest); err != nil {
+		return "", fmt.Errorf("manifest %v", err)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return "", fmt.Errorf("invalid manifest. %v", err)
+	}
+
+	if len(m.Layers) == 0 {
+		return "", fmt.Errorf("manifest %s has no layers", digest)
+	}
+
+	layer := m.Layers[0]
+	blob, err := f.get(ctx, r.url("blobs", layer.Digest), nil, maxTemplateBytes)
+	if err != nil {
+		return "", err
+	}
+
+	if err := verify(blob, layer.Digest); err != nil {
+		return "", fmt.Errorf("template layer %v", err)
+	}
+
+	return string(blob), nil
+}
+
+func (f *fetcher) get(ctx context.Context, u string, accept []string, limit int64) ([]byte, error) {
+	resp, err := f.do(ctx, http.MethodGet, u, accept)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s exceeds %d bytes", u, limit)
+	}
+
+	return body, nil
+}
+
+// do sends the request, retrying once with an anonymous bearer token when the registry asks for one
+func (f *fetcher) do(ctx context.Context, method, u string, accept []string) (*http.Response, error) {
+	resp, err := f.send(ctx, method, u, accept, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		token, err := f.token(ctx, challenge)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = f.send(ctx, method, u, accept, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s returned %s", method, u, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (f *fetcher) send(ctx context.Context, method, u string, accept []string, token string) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return f.client.Do(req)
+}
+
+// token requests an anonymous token from the realm in a Bearer challenge
+func (f *fetcher) token(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("registry requires unsupported authentication %q", challenge)
+	}
+
+	params := map[string]string{}
+	for _, p := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm in challenge %q", challenge)
+	}
+
+	q := realm.Query()
+	for _, k := range []string{"service", "scope"} {
+		if v, ok := params[k]; ok {
+			q.Set(k, v)
+		}
+	}
+	realm.RawQuery = q.Encode()
+
+	resp, err := f.send(ctx, http.MethodGet, realm.String(), nil, "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request to %s returned %s", realm.Host, resp.Status)
+	}
+
+	var t struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestBytes)).Decode(&t); err != nil {
+		return "", fmt.Errorf("invalid token response. %v", err)
+	}
+
+	if t.Token != "" {
+		return t.Token, nil
+	}
+	return t.AccessToken, nil
+}
+
+func (r *Reference) url(kind, ref string) string {
+	return fmt.Sprintf("https://%s/v2/%s/%s/%s", r.Registry, r.Repository, kind, ref)
+}
+
+func digestOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return digestAlgorithm + hex.EncodeToString(sum[:])
+}
+
+func verify(b []byte, digest string) error {
+	if actual := digestOf(b); actual != digest {
+		return fmt.Errorf("digest mismatch, expected %s got %s", digest, actual)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const testTemplate = `apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+spec:
+  entrypoint: entry
+`
+
+type testRegistry struct {
+	*httptest.Server
+	manifestDigest string
+	blobPulls      int32
+	corruptBlob    bool
+}
+
+func newTestRegistry() *testRegistry {
+	blob := []byte(testTemplate)
+	m, _ := json.Marshal(manifest{Layers: []descriptor{{
+		MediaType: "application/vnd.argoproj.workflow.v1+yaml",
+		Digest:    digestOf(blob),
+		Size:      int64(len(blob)),
+	}}})
+
+	reg := &testRegistry{manifestDigest: digestOf(m)}
+	reg.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/templates/addon/manifests/v1", "/v2/templates/addon/manifests/" + reg.manifestDigest:
+			w.Header().Set(digestHeader, reg.manifestDigest)
+			_, _ = w.Write(m)
+		case "/v2/templates/addon/blobs/" + digestOf(blob):
+			atomic.AddInt32(&reg.blobPulls, 1)
+			if reg.corruptBlob {
+				_, _ = w.Write([]byte("tampered"))
+				return
+			}
+			_, _ = w.Write(blob)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return reg
+}
+
+func (reg *testRegistry) ref(path string) string {
+	return Scheme + strings.TrimPrefix(reg.URL, "https://") + path
+}
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		ref     string
+		want    *Reference
+		wantErr bool
+	}{
+		{ref: "oci://registry.io/templates/addon:v1", want: &Reference{Registry: "registry.io", Repository: "templates/addon", Tag: "v1"}},
+		{ref: "oci://localhost:5000/addon", want: &Reference{Registry: "localhost:5000", Repository: "addon", Tag: "latest"}},
+		{ref: "oci://registry.io/addon@" + digest, want: &Reference{Registry: "registry.io", Repository: "addon", Digest: digest}},
+		{ref: "oci://registry.io/addon:v1@" + digest, want: &Reference{Registry: "registry.io", Repository: "addon", Tag: "v1", Digest: digest}},
+		{ref: "oci://registry.io/addon@md5:abc", wantErr: true},
+		{ref: "oci://registry.io", wantErr: true},
+		{ref: "oci://registry.io/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := ParseReference(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && *got != *tt.want {
+				t.Errorf("ParseReference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	g := NewGomegaWithT(t)
+	reg := newTestRegistry()
+	defer reg.Close()
+
+	f := NewFetcher(reg.Client())
+
+	tmpl, err := f.Fetch(context.TODO(), reg.ref("/templates/addon:v1"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tmpl).To(Equal(testTemplate))
+
+	// Same digest is served from cache, pinned or by tag
+	tmpl, err = f.Fetch(context.TODO(), reg.ref("/templates/addon@"+reg.manifestDigest))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tmpl).To(Equal(testTemplate))
+	g.Expect(atomic.LoadInt32(&reg.blobPulls)).To(Equal(int32(1)))
+}
+
+func TestFetcher_Fetch_PullError(t *testing.T) {
+	g := NewGomegaWithT(t)
+	reg := newTestRegistry()
+	defer reg.Close()
+
+	f := NewFetcher(reg.Client())
+
+	_, err := f.Fetch(context.TODO(), reg.ref("/templates/missing:v1"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(IsPullError(err)).To(BeTrue())
+
+	reg.corruptBlob = true
+	_, err = f.Fetch(context.TODO(), reg.ref("/templates/addon:v1"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(IsPullError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("digest mismatch"))
+
+	_, err = f.Fetch(context.TODO(), "oci://invalid")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(IsPullError(err)).To(BeFalse())
+}
+
+func TestFetcher_Fetch_BearerToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+	reg := newTestRegistry()
+	defer reg.Close()
+
+	auth := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("scope") != "repository:templates/addon:pull" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"secret"}`))
+	}))
+	defer auth.Close()
+
+	next := reg.Config.Handler
+	reg.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+auth.URL+`/token",service="registry",scope="repository:templates/addon:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	f := NewFetcher(reg.Client())
+
+	tmpl, err := f.Fetch(context.TODO(), reg.ref("/templates/addon:v1"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tmpl).To(Equal(testTemplate))
+}
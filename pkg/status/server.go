@@ -0,0 +1,265 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+)
+
+const (
+	addonsPath = "/addons"
+	graphPath  = "/graph"
+	groupsPath = "/groups"
+)
+
+// AddonState is the read-only view of an addon served by the status server
+type AddonState struct {
+	Name       string                                    `json:"name"`
+	Namespace  string                                    `json:"namespace"`
+	PkgName    string                                    `json:"pkgName"`
+	PkgVersion string                                    `json:"pkgVersion"`
+	Installed  addonmgrv1alpha1.ApplicationAssemblyPhase `json:"installed"`
+	Lifecycle  addonmgrv1alpha1.AddonStatusLifecycle     `json:"lifecycle"`
+	Resources  []addonmgrv1alpha1.ObjectStatus           `json:"resources"`
+	Checksum   string                                    `json:"checksum"`
+	Reason     string                                    `json:"reason,omitempty"`
+	Group      string                                    `json:"group,omitempty"`
+
+	LastAppliedChecksum string `json:"lastAppliedChecksum,omitempty"`
+}
+
+// Server is a read-only HTTP server exposing addon state from the controller cache
+type Server struct {
+	addr   string
+	reader client.Reader
+	log    logr.Logger
+
+	// VersionCache is the addon version cache of the controller the dependency graph is served from, nil disables it
+	VersionCache addon.VersionCacheClient
+}
+
+// NewServer returns a status server listening on addr, reads are served by the given (cache backed) reader
+func NewServer(addr string, reader client.Reader, log logr.Logger) *Server {
+	return &Server{
+		addr:   addr,
+		reader: reader,
+		log:    log,
+	}
+}
+
+// Handler returns the http handler for the status endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(addonsPath, s.listAddons)
+	mux.HandleFunc(addonsPath+"/", s.getAddon)
+	mux.HandleFunc(graphPath, s.getGraph)
+	mux.HandleFunc(groupsPath, s.listGroups)
+	mux.HandleFunc(groupsPath+"/", s.getGroup)
+	return mux
+}
+
+// Start runs the server until the stop channel is closed
+func (s *Server) Start(stop <-chan struct{}) error {
+	srv := &http.Server{Addr: s.addr, Handler: s.Handler()}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.log.Info("Starting status server", "addr", s.addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	}
+}
+
+// NeedLeaderElection allows every replica to serve reads from its own cache
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) listAddons(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var opts = []client.ListOption{client.InNamespace(r.URL.Query().Get("namespace"))}
+	// Addons with an install status are listed from the field index of the cache
+	if installed := r.URL.Query().Get("installed"); installed != "" {
+		opts = append(opts, client.MatchingFields{addon.InstallStatusField: installed})
+	}
+
+	var list = &addonmgrv1alpha1.AddonList{}
+	if err := s.reader.List(r.Context(), list, opts...); err != nil {
+		s.log.Error(err, "Failed to list addons.")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	states := make([]AddonState, 0, len(list.Items))
+	for i := range list.Items {
+		states = append(states, NewAddonState(&list.Items[i]))
+	}
+
+	s.writeJSON(w, states)
+}
+
+func (s *Server) getAddon(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, addonsPath), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, "expected path /addons/{namespace}/{name}", http.StatusBadRequest)
+		return
+	}
+
+	var instance = &addonmgrv1alpha1.Addon{}
+	if err := s.reader.Get(r.Context(), types.NamespacedName{Namespace: parts[0], Name: parts[1]}, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.log.Error(err, "Failed to get addon.")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, NewAddonState(instance))
+}
+
+// getGraph serves the dependency graph of the cached addon versions as JSON, or as DOT with ?format=dot
+func (s *Server) getGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.VersionCache == nil {
+		http.Error(w, "dependency graph is not available", http.StatusNotFound)
+		return
+	}
+
+	graph := addon.DependencyGraph(s.VersionCache)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		s.writeJSON(w, graph)
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		if _, err := w.Write([]byte(graph.DOT())); err != nil {
+			s.log.Error(err, "Failed to write response.")
+		}
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q, expected json or dot", format), http.StatusBadRequest)
+	}
+}
+
+// listGroups serves the aggregate install status of all addon groups, optionally of a single namespace
+func (s *Server) listGroups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	summaries, ok := s.groupSummaries(w, r)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, summaries)
+}
+
+// getGroup serves the aggregate install status of the addon group /groups/{group}
+func (s *Server) getGroup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	group := strings.Trim(strings.TrimPrefix(r.URL.Path, groupsPath), "/")
+	if group == "" || strings.Contains(group, "/") {
+		http.Error(w, "expected path /groups/{group}", http.StatusBadRequest)
+		return
+	}
+
+	summaries, ok := s.groupSummaries(w, r)
+	if !ok {
+		return
+	}
+	for _, summary := range summaries {
+		if summary.Group == group {
+			s.writeJSON(w, summary)
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("addon group %q not found", group), http.StatusNotFound)
+}
+
+// groupSummaries summarizes the groups of the cached addons, it writes the error response if they cannot be listed
+func (s *Server) groupSummaries(w http.ResponseWriter, r *http.Request) ([]addon.GroupSummary, bool) {
+	var list = &addonmgrv1alpha1.AddonList{}
+	if err := s.reader.List(r.Context(), list, client.InNamespace(r.URL.Query().Get("namespace"))); err != nil {
+		s.log.Error(err, "Failed to list addons.")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return addon.SummarizeGroups(list.Items), true
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.log.Error(err, "Failed to write response.")
+	}
+}
+
+// NewAddonState converts an addon into its served state
+func NewAddonState(a *addonmgrv1alpha1.Addon) AddonState {
+	return AddonState{
+		Name:       a.GetName(),
+		Namespace:  a.GetNamespace(),
+		PkgName:    a.Spec.PkgName,
+		PkgVersion: a.Spec.PkgVersion,
+		Installed:  a.GetInstallStatus(),
+		Lifecycle:  a.Status.Lifecycle,
+		Resources:  a.Status.Resources,
+		Checksum:   a.Status.Checksum,
+		Reason:     a.Status.Reason,
+		Group:      a.Spec.Group,
+
+		LastAppliedChecksum: a.Status.LastAppliedChecksum,
+	}
+}
@@ -0,0 +1,211 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/addon"
+)
+
+func newTestServer(objs ...runtime.Object) *Server {
+	sch := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(sch)
+	return NewServer(":0", runtimefake.NewFakeClientWithScheme(sch, objs...), zap.New(zap.UseDevMode(true)))
+}
+
+func testAddon(name, namespace string) *v1alpha1.Addon {
+	return &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{
+				PkgName:    "test/" + name,
+				PkgVersion: "1.0.0",
+				PkgType:    v1alpha1.CompositePkg,
+			},
+		},
+		Status: v1alpha1.AddonStatus{
+			Checksum:            "abc123",
+			LastAppliedChecksum: "abc123",
+			Lifecycle: v1alpha1.AddonStatusLifecycle{
+				Prereqs:   v1alpha1.Succeeded,
+				Installed: v1alpha1.Succeeded,
+			},
+			Resources: []v1alpha1.ObjectStatus{{Kind: "Deployment", Group: "apps", Name: name}},
+		},
+	}
+}
+
+func TestServer_ListAddons(t *testing.T) {
+	g := NewGomegaWithT(t)
+	s := newTestServer(testAddon("addon-1", "default"), testAddon("addon-2", "other"))
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/addons", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var states []AddonState
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), &states)).To(Succeed())
+	g.Expect(states).To(HaveLen(2))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/addons?namespace=other", nil))
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), &states)).To(Succeed())
+	g.Expect(states).To(HaveLen(1))
+	g.Expect(states[0].Name).To(Equal("addon-2"))
+}
+
+// selectorReader records the field selector of the last list
+type selectorReader struct {
+	client.Reader
+	selector string
+}
+
+func (r *selectorReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	if sel := (&client.ListOptions{}).ApplyOptions(opts).FieldSelector; sel != nil {
+		r.selector = sel.String()
+	}
+	return r.Reader.List(ctx, list, opts...)
+}
+
+func TestServer_ListAddons_Installed(t *testing.T) {
+	g := NewGomegaWithT(t)
+	reader := &selectorReader{Reader: newTestServer(testAddon("addon-1", "default")).reader}
+	s := NewServer(":0", reader, zap.New(zap.UseDevMode(true)))
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/addons?installed=Failed", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	g.Expect(reader.selector).To(Equal(addon.InstallStatusField + "=Failed"))
+}
+
+func TestServer_GetAddon(t *testing.T) {
+	g := NewGomegaWithT(t)
+	s := newTestServer(testAddon("addon-1", "default"))
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/addons/default/addon-1", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var state AddonState
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), &state)).To(Succeed())
+	g.Expect(state.Checksum).To(Equal("abc123"))
+	g.Expect(state.LastAppliedChecksum).To(Equal("abc123"))
+	g.Expect(state.Installed).To(Equal(v1alpha1.Succeeded))
+	g.Expect(state.Lifecycle.Prereqs).To(Equal(v1alpha1.Succeeded))
+	g.Expect(state.Resources).To(HaveLen(1))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/addons/default/missing", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusNotFound))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/addons/default", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/addons/default/addon-1", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+}
+
+func TestServer_GetGraph(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := newTestServer()
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graph", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusNotFound))
+
+	s.VersionCache = addon.NewAddonVersionCacheClient()
+	s.VersionCache.AddVersion(addon.Version{
+		Name:        "addon-1",
+		Namespace:   "default",
+		PackageSpec: testAddon("addon-1", "default").Spec.PackageSpec,
+		PkgPhase:    v1alpha1.Succeeded,
+	})
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graph", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var graph addon.Graph
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), &graph)).To(Succeed())
+	g.Expect(graph.Nodes).To(HaveLen(1))
+	g.Expect(graph.Nodes[0].Installed).To(Equal(v1alpha1.Succeeded))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graph?format=dot", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	g.Expect(rec.Body.String()).To(ContainSubstring(`"test/addon-1:1.0.0"`))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graph?format=svg", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusBadRequest))
+}
+
+func TestServer_Groups(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	monitoring := testAddon("addon-1", "default")
+	monitoring.Spec.Group = "monitoring"
+	failed := testAddon("addon-2", "other")
+	failed.Spec.Group = "monitoring"
+	failed.Status.Lifecycle.Installed = v1alpha1.Failed
+	s := newTestServer(monitoring, failed, testAddon("addon-3", "default"))
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/groups", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var summaries []addon.GroupSummary
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), &summaries)).To(Succeed())
+	g.Expect(summaries).To(HaveLen(1))
+	g.Expect(summaries[0].Addons).To(Equal([]string{"default/addon-1", "other/addon-2"}))
+	g.Expect(summaries[0].Installed).To(Equal(1))
+	g.Expect(summaries[0].Failed).To(Equal(1))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/groups/monitoring?namespace=default", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var summary addon.GroupSummary
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), &summary)).To(Succeed())
+	g.Expect(summary.Addons).To(Equal([]string{"default/addon-1"}))
+	g.Expect(summary.Phases).To(Equal(map[v1alpha1.ApplicationAssemblyPhase]int{v1alpha1.Succeeded: 1}))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/groups/missing", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusNotFound))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/groups/monitoring/addon-1", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/groups", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+}
@@ -0,0 +1,61 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// TemplatePhases returns the phase of each workflow template run by the nodes of the workflow. A template is Failed
+// when any of its nodes failed, Pending while any of them runs and Succeeded once all of them completed.
+func TemplatePhases(workflow *unstructured.Unstructured) map[string]addonmgrv1alpha1.ApplicationAssemblyPhase {
+	nodes, _, _ := unstructured.NestedMap(workflow.Object, "status", "nodes")
+
+	var phases = make(map[string]addonmgrv1alpha1.ApplicationAssemblyPhase)
+	for _, n := range nodes {
+		node, ok := n.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		template, _, _ := unstructured.NestedString(node, "templateName")
+		if template == "" {
+			continue
+		}
+
+		var phase addonmgrv1alpha1.ApplicationAssemblyPhase
+		switch p, _, _ := unstructured.NestedString(node, "phase"); p {
+		case "Failed", "Error":
+			phase = addonmgrv1alpha1.Failed
+		case "Succeeded", "Skipped", "Omitted":
+			phase = addonmgrv1alpha1.Succeeded
+		default:
+			phase = addonmgrv1alpha1.Pending
+		}
+
+		switch phases[template] {
+		case addonmgrv1alpha1.Failed:
+		case addonmgrv1alpha1.Pending:
+			if phase == addonmgrv1alpha1.Failed {
+				phases[template] = phase
+			}
+		default:
+			phases[template] = phase
+		}
+	}
+
+	return phases
+}
@@ -0,0 +1,47 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestTemplatePhases(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	wf := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"nodes": map[string]interface{}{
+				"wf":   map[string]interface{}{"templateName": "main", "phase": "Running"},
+				"wf-1": map[string]interface{}{"templateName": "prereqs", "phase": "Succeeded"},
+				"wf-2": map[string]interface{}{"templateName": "prereqs", "phase": "Skipped"},
+				"wf-3": map[string]interface{}{"templateName": "install", "phase": "Running"},
+				"wf-4": map[string]interface{}{"templateName": "install", "phase": "Error"},
+				"wf-5": map[string]interface{}{"templateName": "install", "phase": "Pending"},
+				"wf-6": map[string]interface{}{"phase": "Failed"},
+			},
+		},
+	}}
+	g.Expect(TemplatePhases(wf)).To(Equal(map[string]addonmgrv1alpha1.ApplicationAssemblyPhase{
+		"main":    addonmgrv1alpha1.Pending,
+		"prereqs": addonmgrv1alpha1.Succeeded,
+		"install": addonmgrv1alpha1.Failed,
+	}))
+}
@@ -0,0 +1,108 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MaxFailureMessageLength is the length workflow failure messages are truncated to in the addon status
+const MaxFailureMessageLength = 256
+
+// deadlineMessages are messages Argo reports for workflows and steps that ran longer than their active deadline
+var deadlineMessages = []string{
+	"Max duration limit exceeded",
+	"Step exceeded its deadline",
+	"longer than the specified deadline",
+	"DeadlineExceeded",
+}
+
+// deadlineExceeded returns true when the message reports an exceeded active deadline
+func deadlineExceeded(message string) bool {
+	for _, m := range deadlineMessages {
+		if strings.Contains(message, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkflowFailure returns the step of the workflow that failed first with its template and message, or the message of
+// the workflow when no step failed or the workflow exceeded its active deadline. It is empty when the workflow reports
+// no failure.
+func WorkflowFailure(workflow *unstructured.Unstructured) string {
+	nodes, _, _ := unstructured.NestedMap(workflow.Object, "status", "nodes")
+
+	var failed []map[string]interface{}
+	for _, n := range nodes {
+		node, ok := n.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		phase, _, _ := unstructured.NestedString(node, "phase")
+		children, _, _ := unstructured.NestedStringSlice(node, "children")
+		// Steps and DAGs fail with their children, the leaf nodes carry the cause
+		if (phase == "Failed" || phase == "Error") && len(children) == 0 {
+			failed = append(failed, node)
+		}
+	}
+
+	sort.Slice(failed, func(i, j int) bool {
+		fi, _, _ := unstructured.NestedString(failed[i], "finishedAt")
+		fj, _, _ := unstructured.NestedString(failed[j], "finishedAt")
+		if fi != fj {
+			return fi < fj
+		}
+		ni, _, _ := unstructured.NestedString(failed[i], "name")
+		nj, _, _ := unstructured.NestedString(failed[j], "name")
+		return ni < nj
+	})
+
+	message, _, _ := unstructured.NestedString(workflow.Object, "status", "message")
+
+	// Steps killed at the deadline fail with Argo internals, report the deadline that was exceeded instead
+	if deadline, found, _ := unstructured.NestedInt64(workflow.Object, "spec", "activeDeadlineSeconds"); found && deadlineExceeded(message) {
+		message = fmt.Sprintf("workflow exceeded its active deadline of %ds", deadline)
+		failed = nil
+	}
+	if len(failed) > 0 {
+		node := failed[0]
+		name, _, _ := unstructured.NestedString(node, "displayName")
+		if name == "" {
+			name, _, _ = unstructured.NestedString(node, "name")
+		}
+		template, _, _ := unstructured.NestedString(node, "templateName")
+		nodeMessage, _, _ := unstructured.NestedString(node, "message")
+		if nodeMessage != "" {
+			message = nodeMessage
+		}
+		message = fmt.Sprintf("step %s (template %s): %s", name, template, message)
+	}
+
+	return truncateMessage(strings.TrimSpace(message), MaxFailureMessageLength)
+}
+
+// truncateMessage shortens the message to max characters, marking it as truncated
+func truncateMessage(message string, max int) string {
+	runes := []rune(message)
+	if len(runes) <= max {
+		return message
+	}
+	return string(runes[:max-3]) + "..."
+}
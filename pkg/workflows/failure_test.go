@@ -0,0 +1,98 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestWorkflowFailure(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	wf := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"phase":   "Failed",
+			"message": "child 'my-addon-install-wf-2' failed",
+			"nodes": map[string]interface{}{
+				"my-addon-install-wf": map[string]interface{}{
+					"name":         "my-addon-install-wf",
+					"displayName":  "my-addon-install-wf",
+					"templateName": "entry",
+					"type":         "Steps",
+					"phase":        "Failed",
+					"children":     []interface{}{"my-addon-install-wf-1", "my-addon-install-wf-2"},
+				},
+				"my-addon-install-wf-1": map[string]interface{}{
+					"name":         "my-addon-install-wf[0].apply",
+					"displayName":  "apply",
+					"templateName": "apply-manifests",
+					"type":         "Pod",
+					"phase":        "Failed",
+					"message":      "Error (exit code 1): deployments.apps \"fluentd\" is forbidden",
+					"finishedAt":   "2021-06-05T00:01:00Z",
+				},
+				"my-addon-install-wf-2": map[string]interface{}{
+					"name":         "my-addon-install-wf[1].verify",
+					"displayName":  "verify",
+					"templateName": "verify",
+					"type":         "Pod",
+					"phase":        "Error",
+					"message":      "pod deleted",
+					"finishedAt":   "2021-06-05T00:02:00Z",
+				},
+			},
+		},
+	}}
+	g.Expect(WorkflowFailure(wf)).To(Equal(`step apply (template apply-manifests): Error (exit code 1): deployments.apps "fluentd" is forbidden`))
+
+	// The workflow message is used when no step failed
+	_ = unstructured.SetNestedMap(wf.Object, map[string]interface{}{}, "status", "nodes")
+	g.Expect(WorkflowFailure(wf)).To(Equal("child 'my-addon-install-wf-2' failed"))
+
+	// Long messages are truncated
+	_ = unstructured.SetNestedField(wf.Object, strings.Repeat("x", 1000), "status", "message")
+	failure := WorkflowFailure(wf)
+	g.Expect(failure).To(HaveLen(MaxFailureMessageLength))
+	g.Expect(failure).To(HaveSuffix("..."))
+
+	g.Expect(WorkflowFailure(&unstructured.Unstructured{Object: map[string]interface{}{}})).To(BeEmpty())
+}
+
+func TestWorkflowFailure_DeadlineExceeded(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	wf := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"activeDeadlineSeconds": int64(600)},
+		"status": map[string]interface{}{
+			"phase":   "Failed",
+			"message": "Max duration limit exceeded",
+			"nodes": map[string]interface{}{
+				"my-addon-install-wf-1": map[string]interface{}{
+					"name":         "my-addon-install-wf[0].apply",
+					"displayName":  "apply",
+					"templateName": "apply-manifests",
+					"type":         "Pod",
+					"phase":        "Failed",
+					"message":      "Step exceeded its deadline",
+				},
+			},
+		},
+	}}
+	g.Expect(WorkflowFailure(wf)).To(Equal("workflow exceeded its active deadline of 600s"))
+}
@@ -0,0 +1,121 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+// DefaultGitImage is the image cloning and applying git sources when none is set, it provides git and kubectl
+const DefaultGitImage = "bitnami/kubectl:1.21"
+
+// GitUsernameKey and GitPasswordKey are the keys of the git source secret
+const (
+	GitUsernameKey = corev1.BasicAuthUsernameKey
+	GitPasswordKey = corev1.BasicAuthPasswordKey
+)
+
+// gitScript checks out the resolved commit and renders the kustomization or the manifests at the source path. The
+// rendered resources are applied with a kustomization adding the addon labels and override patches.
+const gitScript = `set -eu
+if [ -n "${GIT_USERNAME:-}" ]; then
+  git config --global credential.helper '!f() { echo "username=${GIT_USERNAME}"; echo "password=${GIT_PASSWORD}"; }; f'
+fi
+git clone --quiet --no-checkout "${GIT_REPO}" /tmp/src
+cd /tmp/src
+git checkout --quiet "${GIT_COMMIT}"
+cd "/tmp/src/${GIT_PATH}"
+if [ -f kustomization.yaml ] || [ -f kustomization.yml ] || [ -f Kustomization ]; then
+  kubectl kustomize . > /tmp/addon/resources.yaml
+else
+  find . -type f \( -name '*.yaml' -o -name '*.yml' \) | sort | while read -r f; do
+    echo '---'
+    cat "${f}"
+    echo
+  done > /tmp/addon/resources.yaml
+fi
+kubectl apply -k /tmp/addon
+`
+
+// GitTemplate returns an install workflow which checks out the resolved commit of the git source of the addon and
+// applies the resources at its path with the addon labels, so that they are observed like resources of template
+// workflows.
+func GitTemplate(addon *addonmgrv1alpha1.Addon) (string, error) {
+	src := addon.Spec.Source.Git
+
+	kustomization, err := addonKustomization(addon, "resources.yaml")
+	if err != nil {
+		return "", err
+	}
+
+	image := src.Image
+	if image == "" {
+		image = DefaultGitImage
+	}
+
+	env := []interface{}{
+		map[string]interface{}{"name": "GIT_REPO", "value": src.Repo},
+		map[string]interface{}{"name": "GIT_COMMIT", "value": addon.Status.ResolvedCommit},
+		map[string]interface{}{"name": "GIT_PATH", "value": src.Path},
+	}
+	if src.SecretName != "" {
+		env = append(env, secretEnv("GIT_USERNAME", src.SecretName, GitUsernameKey), secretEnv("GIT_PASSWORD", src.SecretName, GitPasswordKey))
+	}
+
+	wf, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Workflow",
+		"spec": map[string]interface{}{
+			"entrypoint": "git",
+			"templates": []interface{}{
+				map[string]interface{}{
+					"name": "git",
+					"inputs": map[string]interface{}{
+						"artifacts": []interface{}{
+							map[string]interface{}{
+								"name": "kustomization",
+								"path": "/tmp/addon/kustomization.yaml",
+								"raw":  map[string]interface{}{"data": string(kustomization)},
+							},
+						},
+					},
+					"script": map[string]interface{}{
+						"image":   image,
+						"command": []string{"sh"},
+						"env":     env,
+						"source":  gitScript,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(wf), nil
+}
+
+func secretEnv(name, secret, key string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"valueFrom": map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{"name": secret, "key": key},
+		},
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestGitTemplate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "event-router", Namespace: "default"},
+		Spec: v1alpha1.AddonSpec{
+			Source: v1alpha1.AddonSource{
+				Git: v1alpha1.GitSource{Repo: "https://github.com/org/addons.git", Ref: "main", Path: "event-router", SecretName: "git-creds"},
+			},
+		},
+		Status: v1alpha1.AddonStatus{ResolvedCommit: "0123456789abcdef0123456789abcdef01234567"},
+	}
+
+	template, err := GitTemplate(addon)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var wf map[string]interface{}
+	g.Expect(yaml.Unmarshal([]byte(template), &wf)).To(Succeed())
+
+	templates, _, _ := unstructured.NestedSlice(wf, "spec", "templates")
+	g.Expect(templates).To(HaveLen(1))
+	script := templates[0].(map[string]interface{})["script"].(map[string]interface{})
+	g.Expect(script["image"]).To(Equal(DefaultGitImage))
+
+	env := map[string]interface{}{}
+	for _, e := range script["env"].([]interface{}) {
+		e := e.(map[string]interface{})
+		env[e["name"].(string)] = e
+	}
+	g.Expect(env["GIT_REPO"]).To(HaveKeyWithValue("value", "https://github.com/org/addons.git"))
+	g.Expect(env["GIT_COMMIT"]).To(HaveKeyWithValue("value", "0123456789abcdef0123456789abcdef01234567"))
+	g.Expect(env["GIT_PATH"]).To(HaveKeyWithValue("value", "event-router"))
+	key, _, _ := unstructured.NestedString(env["GIT_PASSWORD"].(map[string]interface{}), "valueFrom", "secretKeyRef", "key")
+	g.Expect(key).To(Equal(GitPasswordKey))
+
+	artifacts := templates[0].(map[string]interface{})["inputs"].(map[string]interface{})["artifacts"].([]interface{})
+	data, _, _ := unstructured.NestedString(artifacts[0].(map[string]interface{}), "raw", "data")
+	g.Expect(data).To(ContainSubstring("- resources.yaml"))
+	g.Expect(data).To(ContainSubstring("app.kubernetes.io/name: event-router"))
+
+	// Public repositories are cloned without credentials
+	addon.Spec.Source.Git.SecretName = ""
+	template, err = GitTemplate(addon)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(template).NotTo(ContainSubstring("secretKeyRef"))
+}
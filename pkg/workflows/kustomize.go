@@ -0,0 +1,171 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+	"github.com/keikoproj/addon-manager/pkg/common"
+)
+
+// DefaultKustomizeImage is the image building and applying kustomize sources when none is set
+const DefaultKustomizeImage = "bitnami/kubectl:1.21"
+
+// KustomizeTarget returns the kustomization target of the source with the ref appended to the path
+func KustomizeTarget(src addonmgrv1alpha1.KustomizeSource) string {
+	if src.Ref == "" {
+		return src.Path
+	}
+	sep := "?"
+	if strings.Contains(src.Path, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%sref=%s", src.Path, sep, src.Ref)
+}
+
+// KustomizeTemplate returns an install workflow which builds the kustomize source of the addon and applies the
+// rendered resources with the addon labels, so that they are observed like resources of template workflows.
+func KustomizeTemplate(addon *addonmgrv1alpha1.Addon) (string, error) {
+	src := addon.Spec.Source.Kustomize
+
+	kustomization, err := addonKustomization(addon, KustomizeTarget(src))
+	if err != nil {
+		return "", err
+	}
+
+	image := src.Image
+	if image == "" {
+		image = DefaultKustomizeImage
+	}
+
+	wf, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Workflow",
+		"spec": map[string]interface{}{
+			"entrypoint": "kustomize",
+			"templates": []interface{}{
+				map[string]interface{}{
+					"name": "kustomize",
+					"inputs": map[string]interface{}{
+						"artifacts": []interface{}{
+							map[string]interface{}{
+								"name": "kustomization",
+								"path": "/tmp/addon/kustomization.yaml",
+								"raw":  map[string]interface{}{"data": string(kustomization)},
+							},
+						},
+					},
+					"container": map[string]interface{}{
+						"image":   image,
+						"command": []string{"kubectl"},
+						"args":    []string{"apply", "-k", "/tmp/addon"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(wf), nil
+}
+
+// addonKustomization returns a kustomization of the resources with the addon labels, common labels and annotations
+// and override patches
+func addonKustomization(addon *addonmgrv1alpha1.Addon, resources ...string) ([]byte, error) {
+	var pairs = map[string]string{}
+	for key, value := range addon.Spec.CommonLabels {
+		pairs[key] = value
+	}
+	pairs[common.ManagedByLabel] = common.AddonGVR().Group
+	pairs[common.NameLabel] = addon.GetName()
+
+	var spec = map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+		"labels": []interface{}{
+			map[string]interface{}{
+				"pairs": pairs,
+			},
+		},
+	}
+
+	if len(addon.Spec.CommonAnnotations) > 0 {
+		spec["commonAnnotations"] = addon.Spec.CommonAnnotations
+	}
+
+	if len(addon.Spec.Overrides.Patches) > 0 {
+		patches, err := kustomizePatches(addon.Spec.Overrides.Patches)
+		if err != nil {
+			return nil, err
+		}
+		spec["patches"] = patches
+	}
+
+	return yaml.Marshal(spec)
+}
+
+// kustomizePatches returns the override patches as kustomization patches of their targets. Strategic merge patches
+// are completed with the target kind and name, which kustomize requires to parse them.
+func kustomizePatches(overrides []addonmgrv1alpha1.OverridePatch) ([]interface{}, error) {
+	var patches = make([]interface{}, 0, len(overrides))
+	for _, o := range overrides {
+		target := map[string]interface{}{
+			"version": o.Target.Version,
+			"kind":    o.Target.Kind,
+			"name":    o.Target.Name,
+		}
+		if o.Target.Group != "" {
+			target["group"] = o.Target.Group
+		}
+
+		patch := o.Patch
+		if o.Type != addonmgrv1alpha1.JSONPatch {
+			var obj map[string]interface{}
+			if err := yaml.Unmarshal([]byte(o.Patch), &obj); err != nil {
+				return nil, fmt.Errorf("override %s patch is invalid. %v", o.Target, err)
+			}
+			if obj == nil {
+				obj = make(map[string]interface{})
+			}
+			metadata, _ := obj["metadata"].(map[string]interface{})
+			if metadata == nil {
+				metadata = make(map[string]interface{})
+			}
+			metadata["name"] = o.Target.Name
+			obj["metadata"] = metadata
+			obj["kind"] = o.Target.Kind
+			obj["apiVersion"] = o.Target.Version
+			if o.Target.Group != "" {
+				obj["apiVersion"] = fmt.Sprintf("%s/%s", o.Target.Group, o.Target.Version)
+			}
+
+			data, err := yaml.Marshal(obj)
+			if err != nil {
+				return nil, err
+			}
+			patch = string(data)
+		}
+
+		patches = append(patches, map[string]interface{}{"patch": patch, "target": target})
+	}
+	return patches, nil
+}
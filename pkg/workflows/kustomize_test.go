@@ -0,0 +1,154 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func TestKustomizeTarget(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(KustomizeTarget(v1alpha1.KustomizeSource{Path: "github.com/org/repo//prod"})).To(Equal("github.com/org/repo//prod"))
+	g.Expect(KustomizeTarget(v1alpha1.KustomizeSource{Path: "github.com/org/repo//prod", Ref: "v1.2.0"})).To(Equal("github.com/org/repo//prod?ref=v1.2.0"))
+	g.Expect(KustomizeTarget(v1alpha1.KustomizeSource{Path: "github.com/org/repo//prod?timeout=60", Ref: "v1.2.0"})).To(Equal("github.com/org/repo//prod?timeout=60&ref=v1.2.0"))
+}
+
+func TestKustomizeTemplate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "event-router", Namespace: "default"},
+		Spec: v1alpha1.AddonSpec{
+			Source: v1alpha1.AddonSource{
+				Kustomize: v1alpha1.KustomizeSource{Path: "github.com/org/repo//prod", Ref: "v1.2.0"},
+			},
+		},
+	}
+
+	template, err := KustomizeTemplate(addon)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var wf map[string]interface{}
+	g.Expect(yaml.Unmarshal([]byte(template), &wf)).To(Succeed())
+	g.Expect(wf["kind"]).To(Equal("Workflow"))
+
+	templates, _, _ := unstructured.NestedSlice(wf, "spec", "templates")
+	g.Expect(templates).To(HaveLen(1))
+	container := templates[0].(map[string]interface{})["container"].(map[string]interface{})
+	g.Expect(container["image"]).To(Equal(DefaultKustomizeImage))
+
+	artifacts := templates[0].(map[string]interface{})["inputs"].(map[string]interface{})["artifacts"].([]interface{})
+	data, _, _ := unstructured.NestedString(artifacts[0].(map[string]interface{}), "raw", "data")
+
+	var kustomization map[string]interface{}
+	g.Expect(yaml.Unmarshal([]byte(data), &kustomization)).To(Succeed())
+	g.Expect(kustomization["resources"]).To(Equal([]interface{}{"github.com/org/repo//prod?ref=v1.2.0"}))
+	g.Expect(data).To(ContainSubstring("app.kubernetes.io/name: event-router"))
+	g.Expect(data).To(ContainSubstring("app.kubernetes.io/managed-by: addonmgr.keikoproj.io"))
+	g.Expect(kustomization).NotTo(HaveKey("patches"))
+}
+
+func TestKustomizeTemplate_Overrides(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "event-router", Namespace: "default"},
+		Spec: v1alpha1.AddonSpec{
+			Source: v1alpha1.AddonSource{
+				Kustomize: v1alpha1.KustomizeSource{Path: "github.com/org/repo//prod"},
+			},
+			Overrides: v1alpha1.AddonOverridesSpec{
+				Patches: []v1alpha1.OverridePatch{
+					{
+						Target: v1alpha1.OverrideTarget{Group: "apps", Version: "v1", Kind: "Deployment", Name: "event-router"},
+						Patch:  "spec:\n  replicas: 2\n",
+					},
+					{
+						Target: v1alpha1.OverrideTarget{Version: "v1", Kind: "Service", Name: "event-router"},
+						Type:   v1alpha1.JSONPatch,
+						Patch:  `[{"op": "replace", "path": "/spec/type", "value": "NodePort"}]`,
+					},
+				},
+			},
+		},
+	}
+
+	template, err := KustomizeTemplate(addon)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var wf map[string]interface{}
+	g.Expect(yaml.Unmarshal([]byte(template), &wf)).To(Succeed())
+	templates, _, _ := unstructured.NestedSlice(wf, "spec", "templates")
+	artifacts := templates[0].(map[string]interface{})["inputs"].(map[string]interface{})["artifacts"].([]interface{})
+	data, _, _ := unstructured.NestedString(artifacts[0].(map[string]interface{}), "raw", "data")
+
+	var kustomization map[string]interface{}
+	g.Expect(yaml.Unmarshal([]byte(data), &kustomization)).To(Succeed())
+	patches := kustomization["patches"].([]interface{})
+	g.Expect(patches).To(HaveLen(2))
+
+	strategic := patches[0].(map[string]interface{})
+	g.Expect(strategic["target"]).To(Equal(map[string]interface{}{"group": "apps", "version": "v1", "kind": "Deployment", "name": "event-router"}))
+	var patch map[string]interface{}
+	g.Expect(yaml.Unmarshal([]byte(strategic["patch"].(string)), &patch)).To(Succeed())
+	g.Expect(patch).To(Equal(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "event-router"},
+		"spec":       map[string]interface{}{"replicas": 2},
+	}))
+
+	json := patches[1].(map[string]interface{})
+	g.Expect(json["patch"]).To(Equal(`[{"op": "replace", "path": "/spec/type", "value": "NodePort"}]`))
+}
+
+func TestKustomizeTemplate_CommonMetadata(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "event-router", Namespace: "default"},
+		Spec: v1alpha1.AddonSpec{
+			CommonLabels:      map[string]string{"team": "platform", "app.kubernetes.io/managed-by": "helm"},
+			CommonAnnotations: map[string]string{"cost-center": "1234"},
+			Source: v1alpha1.AddonSource{
+				Kustomize: v1alpha1.KustomizeSource{Path: "github.com/org/repo//prod"},
+			},
+		},
+	}
+
+	data, err := addonKustomization(addon, KustomizeTarget(addon.Spec.Source.Kustomize))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var kustomization map[string]interface{}
+	g.Expect(yaml.Unmarshal(data, &kustomization)).To(Succeed())
+	g.Expect(kustomization["labels"]).To(Equal([]interface{}{
+		map[string]interface{}{
+			"pairs": map[string]interface{}{
+				"team":                         "platform",
+				"app.kubernetes.io/managed-by": "addonmgr.keikoproj.io",
+				"app.kubernetes.io/name":       "event-router",
+			},
+		},
+	}))
+	g.Expect(kustomization["commonAnnotations"]).To(Equal(map[string]interface{}{"cost-center": "1234"}))
+}
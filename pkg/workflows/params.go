@@ -0,0 +1,260 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	addonmgrv1alpha1 "github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+var (
+	// addonParamPattern matches {{addon.params.<key>}}, only simple key lookups are supported
+	addonParamPattern = regexp.MustCompile(`\{\{\s*addon\.params\.([A-Za-z0-9_.-]+)\s*\}\}`)
+	// addonFeaturePattern matches {{addon.features.<key>}}
+	addonFeaturePattern = regexp.MustCompile(`\{\{\s*addon\.features\.([A-Za-z0-9_.-]+)\s*\}\}`)
+	// addonPlaceholderPattern matches anything that looks like an addon placeholder
+	addonPlaceholderPattern = regexp.MustCompile(`\{\{\s*addon\.[^}]*\}\}`)
+	// clusterParamPattern matches {{cluster.<key>}}
+	clusterParamPattern = regexp.MustCompile(`\{\{\s*cluster\.([A-Za-z0-9_.-]+)\s*\}\}`)
+)
+
+// ClusterValues describe the cluster the controller runs in, templates reference them with {{cluster.name}} and
+// {{cluster.environment}}
+type ClusterValues struct {
+	Name        string
+	Environment string
+}
+
+// Params returns the cluster values which are set by their placeholder key
+func (c ClusterValues) Params() map[string]string {
+	values := map[string]string{}
+	if c.Name != "" {
+		values["name"] = c.Name
+	}
+	if c.Environment != "" {
+		values["environment"] = c.Environment
+	}
+	return values
+}
+
+// AddonParamValues returns the values that templates can reference with {{addon.params.<key>}},
+// these are the namespace, cluster context, additional configs and data params of the addon.
+func AddonParamValues(a *addonmgrv1alpha1.Addon) map[string]string {
+	params := a.Spec.Params
+	values := map[string]string{
+		"namespace":     params.Namespace,
+		"clusterName":   params.Context.ClusterName,
+		"clusterRegion": params.Context.ClusterRegion,
+	}
+
+	for k, v := range params.Context.AdditionalConfigs {
+		values[k] = string(v)
+	}
+
+	for k, v := range params.Data {
+		values[k] = string(v)
+	}
+
+	return values
+}
+
+// AddonFeatureValues returns the values that templates can reference with {{addon.features.<key>}}, "true" or "false"
+// for the features set in the params of the addon and the defaults of the features declared in its feature schema.
+func AddonFeatureValues(a *addonmgrv1alpha1.Addon) map[string]string {
+	values := map[string]string{}
+	for _, f := range a.Spec.FeatureSchema {
+		values[f.Name] = strconv.FormatBool(f.Default)
+	}
+	for k, v := range a.Spec.Params.Features {
+		values[k] = strconv.FormatBool(v)
+	}
+	return values
+}
+
+// UnresolvedAddonParams returns the placeholders in the template that cannot be substituted, feature placeholders are
+// checked with UnresolvedAddonFeatures
+func UnresolvedAddonParams(template string, values map[string]string) []string {
+	var unresolved = map[string]bool{}
+
+	for _, placeholder := range addonPlaceholderPattern.FindAllString(template, -1) {
+		if addonFeaturePattern.FindString(placeholder) == placeholder {
+			continue
+		}
+		m := addonParamPattern.FindStringSubmatch(placeholder)
+		if m == nil || m[0] != placeholder {
+			unresolved[placeholder] = true
+			continue
+		}
+		if _, ok := values[m[1]]; !ok {
+			unresolved[placeholder] = true
+		}
+	}
+
+	var result = make([]string, 0, len(unresolved))
+	for placeholder := range unresolved {
+		result = append(result, placeholder)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// RenderAddonParams substitutes {{addon.params.<key>}} placeholders in every string value of the parsed workflow.
+// Values are only ever inserted into existing strings so they cannot change the structure of the workflow.
+func RenderAddonParams(obj map[string]interface{}, values map[string]string) error {
+	var unresolved []string
+	renderValue(obj, values, &unresolved)
+
+	if len(unresolved) > 0 {
+		sort.Strings(unresolved)
+		return fmt.Errorf("unresolved addon params %s", strings.Join(unresolved, ", "))
+	}
+
+	return nil
+}
+
+func renderValue(v interface{}, values map[string]string, unresolved *[]string) interface{} {
+	switch t := v.(type) {
+	case string:
+		*unresolved = append(*unresolved, UnresolvedAddonParams(t, values)...)
+		return addonParamPattern.ReplaceAllStringFunc(t, func(placeholder string) string {
+			key := addonParamPattern.FindStringSubmatch(placeholder)[1]
+			if value, ok := values[key]; ok {
+				return value
+			}
+			return placeholder
+		})
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = renderValue(e, values, unresolved)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = renderValue(e, values, unresolved)
+		}
+	}
+
+	return v
+}
+
+// UnresolvedAddonFeatures returns the {{addon.features.<key>}} placeholders in the template of features which are
+// neither set nor declared
+func UnresolvedAddonFeatures(template string, values map[string]string) []string {
+	var unresolved = map[string]bool{}
+	for _, m := range addonFeaturePattern.FindAllStringSubmatch(template, -1) {
+		if _, ok := values[m[1]]; !ok {
+			unresolved[m[0]] = true
+		}
+	}
+
+	var result = make([]string, 0, len(unresolved))
+	for placeholder := range unresolved {
+		result = append(result, placeholder)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// RenderAddonFeatures substitutes {{addon.features.<key>}} placeholders in every string value of the parsed workflow
+// with "true" or "false", so that steps can be included conditionally with a when expression such as
+// "{{addon.features.metrics}} == true".
+func RenderAddonFeatures(obj map[string]interface{}, values map[string]string) error {
+	var unresolved []string
+	renderFeatureValue(obj, values, &unresolved)
+
+	if len(unresolved) > 0 {
+		sort.Strings(unresolved)
+		return fmt.Errorf("unresolved addon features %s", strings.Join(unresolved, ", "))
+	}
+
+	return nil
+}
+
+func renderFeatureValue(v interface{}, values map[string]string, unresolved *[]string) interface{} {
+	switch t := v.(type) {
+	case string:
+		*unresolved = append(*unresolved, UnresolvedAddonFeatures(t, values)...)
+		return addonFeaturePattern.ReplaceAllStringFunc(t, func(placeholder string) string {
+			key := addonFeaturePattern.FindStringSubmatch(placeholder)[1]
+			if value, ok := values[key]; ok {
+				return value
+			}
+			return placeholder
+		})
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = renderFeatureValue(e, values, unresolved)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = renderFeatureValue(e, values, unresolved)
+		}
+	}
+
+	return v
+}
+
+// UnresolvedClusterParams returns the {{cluster.<key>}} placeholders in the template that cannot be substituted
+func UnresolvedClusterParams(template string, values map[string]string) []string {
+	var unresolved = map[string]bool{}
+	for _, m := range clusterParamPattern.FindAllStringSubmatch(template, -1) {
+		if _, ok := values[m[1]]; !ok {
+			unresolved[m[0]] = true
+		}
+	}
+
+	var result = make([]string, 0, len(unresolved))
+	for placeholder := range unresolved {
+		result = append(result, placeholder)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// RenderClusterParams substitutes {{cluster.<key>}} placeholders in every string value of the parsed workflow,
+// placeholders of values which are not set are left as they are.
+func RenderClusterParams(obj map[string]interface{}, values map[string]string) {
+	renderClusterValue(obj, values)
+}
+
+func renderClusterValue(v interface{}, values map[string]string) interface{} {
+	switch t := v.(type) {
+	case string:
+		return clusterParamPattern.ReplaceAllStringFunc(t, func(placeholder string) string {
+			key := clusterParamPattern.FindStringSubmatch(placeholder)[1]
+			if value, ok := values[key]; ok {
+				return value
+			}
+			return placeholder
+		})
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = renderClusterValue(e, values)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = renderClusterValue(e, values)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,157 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+func paramsAddon() *v1alpha1.Addon {
+	return &v1alpha1.Addon{
+		Spec: v1alpha1.AddonSpec{
+			Params: v1alpha1.AddonParams{
+				Namespace: "addon-ns",
+				Context: v1alpha1.ClusterContext{
+					ClusterName: "cluster-1",
+				},
+				Data: map[string]v1alpha1.FlexString{
+					"replicas": "3",
+					"evil":     "x\nkind: Secret",
+				},
+			},
+		},
+	}
+}
+
+func TestRenderAddonParams(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"namespace": "{{addon.params.namespace}}",
+		},
+		"spec": map[string]interface{}{
+			"templates": []interface{}{
+				map[string]interface{}{
+					"args": []interface{}{"--cluster={{ addon.params.clusterName }}", "--replicas={{addon.params.replicas}}"},
+				},
+			},
+			"message": "{{addon.params.evil}}",
+			"argo":    "{{workflow.parameters.namespace}}",
+		},
+	}
+
+	g.Expect(RenderAddonParams(obj, AddonParamValues(paramsAddon()))).To(Succeed())
+	g.Expect(obj["metadata"].(map[string]interface{})["namespace"]).To(Equal("addon-ns"))
+
+	spec := obj["spec"].(map[string]interface{})
+	args := spec["templates"].([]interface{})[0].(map[string]interface{})["args"]
+	g.Expect(args).To(Equal([]interface{}{"--cluster=cluster-1", "--replicas=3"}))
+	// Values are inserted as plain strings and do not add to the workflow
+	g.Expect(spec["message"]).To(Equal("x\nkind: Secret"))
+	g.Expect(spec).ToNot(HaveKey("kind"))
+	// Argo parameters are left untouched
+	g.Expect(spec["argo"]).To(Equal("{{workflow.parameters.namespace}}"))
+}
+
+func TestRenderAddonParams_Unresolved(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"a": "{{addon.params.missing}}",
+			"b": "{{addon.params.replicas | exec}}",
+		},
+	}
+
+	err := RenderAddonParams(obj, AddonParamValues(paramsAddon()))
+	g.Expect(err).To(MatchError("unresolved addon params {{addon.params.missing}}, {{addon.params.replicas | exec}}"))
+}
+
+func TestUnresolvedAddonParams(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	values := AddonParamValues(paramsAddon())
+	g.Expect(UnresolvedAddonParams("namespace: {{addon.params.namespace}}", values)).To(BeEmpty())
+	g.Expect(UnresolvedAddonParams("a: {{addon.params.foo}}\nb: {{addon.params.foo}}\nc: {{addon.spec}}", values)).
+		To(Equal([]string{"{{addon.params.foo}}", "{{addon.spec}}"}))
+}
+
+func TestRenderAddonFeatures(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	a := paramsAddon()
+	a.Spec.FeatureSchema = []v1alpha1.AddonFeature{{Name: "metrics"}, {Name: "ingress", Default: true}}
+	a.Spec.Params.Features = map[string]bool{"metrics": true, "ingress": false}
+	values := AddonFeatureValues(a)
+	g.Expect(values).To(Equal(map[string]string{"metrics": "true", "ingress": "false"}))
+
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"templates": []interface{}{
+				map[string]interface{}{
+					"steps": []interface{}{
+						[]interface{}{
+							map[string]interface{}{"name": "monitor", "when": "{{addon.features.metrics}} == true"},
+							map[string]interface{}{"name": "expose", "when": "{{ addon.features.ingress }} == true"},
+						},
+					},
+				},
+			},
+		},
+	}
+	g.Expect(RenderAddonFeatures(obj, values)).To(Succeed())
+	steps := obj["spec"].(map[string]interface{})["templates"].([]interface{})[0].(map[string]interface{})["steps"].([]interface{})[0].([]interface{})
+	g.Expect(steps[0].(map[string]interface{})["when"]).To(Equal("true == true"))
+	g.Expect(steps[1].(map[string]interface{})["when"]).To(Equal("false == true"))
+
+	// Features which are neither set nor declared cannot be rendered
+	obj = map[string]interface{}{"spec": map[string]interface{}{"a": "{{addon.features.tracing}}"}}
+	g.Expect(RenderAddonFeatures(obj, values)).To(MatchError("unresolved addon features {{addon.features.tracing}}"))
+
+	// Feature placeholders are not addon params
+	g.Expect(UnresolvedAddonParams("{{addon.features.tracing}}", AddonParamValues(a))).To(BeEmpty())
+	g.Expect(UnresolvedAddonFeatures("{{addon.features.tracing}} {{addon.features.metrics}}", values)).To(Equal([]string{"{{addon.features.tracing}}"}))
+}
+
+func TestRenderClusterParams(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	values := ClusterValues{Name: "prod-usw2"}.Params()
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"templates": []interface{}{
+				map[string]interface{}{
+					"container": map[string]interface{}{
+						"args": []interface{}{"--cluster={{cluster.name}}", "--env={{ cluster.environment }}"},
+					},
+				},
+			},
+		},
+	}
+
+	RenderClusterParams(obj, values)
+	args := obj["spec"].(map[string]interface{})["templates"].([]interface{})[0].(map[string]interface{})["container"].(map[string]interface{})["args"]
+	// Unset values are left as they are
+	g.Expect(args).To(Equal([]interface{}{"--cluster=prod-usw2", "--env={{ cluster.environment }}"}))
+
+	g.Expect(UnresolvedClusterParams("{{cluster.name}} {{ cluster.environment }} {{cluster.region}}", values)).
+		To(Equal([]string{"{{ cluster.environment }}", "{{cluster.region}}"}))
+	g.Expect(UnresolvedClusterParams("{{cluster.name}} {{cluster.environment}}", ClusterValues{Name: "a", Environment: "prod"}.Params())).To(BeEmpty())
+}
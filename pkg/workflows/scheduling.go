@@ -0,0 +1,161 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// injectPodTemplate merges the scheduling of the addon pod template into the workflow spec, which applies it to all
+// pods of the workflow. Scheduling of the workflow template is kept, templates of single steps still override it.
+func (w *workflowLifecycle) injectPodTemplate(wf *unstructured.Unstructured) error {
+	pt := w.addon.Spec.Lifecycle.PodTemplate
+	if pt == nil {
+		return nil
+	}
+
+	var spec struct {
+		NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+		Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+		Affinity     *corev1.Affinity    `json:"affinity,omitempty"`
+	}
+	var content = make(map[string]interface{})
+	for _, field := range []string{"nodeSelector", "tolerations", "affinity"} {
+		if v, ok, _ := unstructured.NestedFieldNoCopy(wf.Object, "spec", field); ok {
+			content[field] = v
+		}
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &spec); err != nil {
+		return fmt.Errorf("invalid workflow scheduling. %v", err)
+	}
+
+	spec.NodeSelector = mergeNodeSelector(spec.NodeSelector, pt.NodeSelector)
+	spec.Tolerations = mergeTolerations(spec.Tolerations, pt.Tolerations)
+	spec.Affinity = mergeAffinity(spec.Affinity, pt.Affinity)
+
+	merged, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&spec)
+	if err != nil {
+		return err
+	}
+	for _, field := range []string{"nodeSelector", "tolerations", "affinity"} {
+		if v, ok := merged[field]; ok {
+			if err := unstructured.SetNestedField(wf.Object, v, "spec", field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergeNodeSelector adds the labels to the node selector, labels take precedence over equal keys
+func mergeNodeSelector(selector, labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return selector
+	}
+
+	var merged = make(map[string]string, len(selector)+len(labels))
+	for k, v := range selector {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
+
+// mergeTolerations adds the tolerations which are not tolerated yet
+func mergeTolerations(tolerations, added []corev1.Toleration) []corev1.Toleration {
+	var merged = append([]corev1.Toleration{}, tolerations...)
+	for _, t := range added {
+		var found bool
+		for _, existing := range merged {
+			if reflect.DeepEqual(existing, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// mergeAffinity adds the affinity terms of added to affinity. Required node selector terms are ORed by Kubernetes,
+// so that both required node affinities hold every term of one is combined with every term of the other.
+func mergeAffinity(affinity, added *corev1.Affinity) *corev1.Affinity {
+	if added == nil {
+		return affinity
+	}
+	if affinity == nil {
+		return added.DeepCopy()
+	}
+
+	merged := affinity.DeepCopy()
+	if added.NodeAffinity != nil {
+		if merged.NodeAffinity == nil {
+			merged.NodeAffinity = &corev1.NodeAffinity{}
+		}
+		merged.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = mergeNodeSelectorTerms(
+			merged.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution, added.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+		merged.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			merged.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, added.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+	}
+	if added.PodAffinity != nil {
+		if merged.PodAffinity == nil {
+			merged.PodAffinity = &corev1.PodAffinity{}
+		}
+		merged.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+			merged.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution, added.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		merged.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			merged.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution, added.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+	}
+	if added.PodAntiAffinity != nil {
+		if merged.PodAntiAffinity == nil {
+			merged.PodAntiAffinity = &corev1.PodAntiAffinity{}
+		}
+		merged.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+			merged.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, added.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		merged.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			merged.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, added.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+	}
+	return merged
+}
+
+// mergeNodeSelectorTerms returns a node selector matching nodes which match both selectors
+func mergeNodeSelectorTerms(selector, added *corev1.NodeSelector) *corev1.NodeSelector {
+	if added == nil || len(added.NodeSelectorTerms) == 0 {
+		return selector
+	}
+	if selector == nil || len(selector.NodeSelectorTerms) == 0 {
+		return added.DeepCopy()
+	}
+
+	var merged = &corev1.NodeSelector{}
+	for _, term := range selector.NodeSelectorTerms {
+		for _, addedTerm := range added.NodeSelectorTerms {
+			var t = term.DeepCopy()
+			t.MatchExpressions = append(t.MatchExpressions, addedTerm.MatchExpressions...)
+			t.MatchFields = append(t.MatchFields, addedTerm.MatchFields...)
+			merged.NodeSelectorTerms = append(merged.NodeSelectorTerms, *t)
+		}
+	}
+	return merged
+}
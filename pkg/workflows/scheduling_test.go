@@ -0,0 +1,137 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/keikoproj/addon-manager/api/v1alpha1"
+)
+
+var wfSchedulingTemplate = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+spec:
+  entrypoint: entry
+  nodeSelector:
+    kubernetes.io/os: linux
+    pool: general
+  tolerations:
+  - key: dedicated
+    operator: Equal
+    value: workflows
+    effect: NoExecute
+    tolerationSeconds: 300
+  affinity:
+    nodeAffinity:
+      requiredDuringSchedulingIgnoredDuringExecution:
+        nodeSelectorTerms:
+        - matchExpressions:
+          - key: kubernetes.io/arch
+            operator: In
+            values: [amd64]
+  templates:
+  - name: entry
+    container:
+      image: busybox
+`
+
+func TestWorkflowLifecycle_Install_PodTemplate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-wf-scheduling", Namespace: "default"},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{PkgName: "my-addon-scheduling", PkgVersion: "1.0.0", PkgType: v1alpha1.CompositePkg},
+			Lifecycle: v1alpha1.LifecycleWorkflowSpec{
+				Install: v1alpha1.WorkflowType{Template: wfSchedulingTemplate},
+				PodTemplate: &v1alpha1.PodTemplate{
+					NodeSelector: map[string]string{"pool": "addons"},
+					Tolerations: []corev1.Toleration{
+						{Key: "pool", Operator: corev1.TolerationOpEqual, Value: "addons", Effect: corev1.TaintEffectNoSchedule},
+					},
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{
+									{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+									{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+	wfName := addon.GetFormattedWorkflowName(v1alpha1.Install)
+	wt, _ := addon.GetWorkflowType(v1alpha1.Install)
+
+	phase, err := wfl.Install(context.Background(), wt, wfName, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	var wfv1 = &unstructured.Unstructured{}
+	wfv1.SetGroupVersionKind(schema.GroupVersionKind{Kind: "Workflow", Group: "argoproj.io", Version: "v1alpha1"})
+	g.Eventually(func() error {
+		return fclient.Get(context.TODO(), types.NamespacedName{Name: wfName, Namespace: "default"}, wfv1)
+	}, timeout).Should(Succeed())
+
+	var spec struct {
+		NodeSelector map[string]string   `json:"nodeSelector"`
+		Tolerations  []corev1.Toleration `json:"tolerations"`
+		Affinity     *corev1.Affinity    `json:"affinity"`
+	}
+	content, _, _ := unstructured.NestedMap(wfv1.Object, "spec")
+	g.Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(content, &spec)).To(Succeed())
+
+	// Scheduling of the template is kept, the addon node selector takes precedence over equal keys
+	g.Expect(spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux", "pool": "addons"}))
+	g.Expect(spec.Tolerations).To(HaveLen(2))
+	g.Expect(*spec.Tolerations[0].TolerationSeconds).To(Equal(int64(300)))
+	g.Expect(spec.Tolerations[1].Key).To(Equal("pool"))
+
+	// Every required term of the template is combined with every required term of the addon
+	terms := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	g.Expect(terms).To(HaveLen(2))
+	for _, term := range terms {
+		g.Expect(term.MatchExpressions).To(HaveLen(2))
+		g.Expect(term.MatchExpressions[0].Key).To(Equal("kubernetes.io/arch"))
+		g.Expect(term.MatchExpressions[1].Key).To(Equal("zone"))
+	}
+}
+
+func TestMergeTolerations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	existing := []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+	merged := mergeTolerations(existing, []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpExists},
+		{Key: "pool", Operator: corev1.TolerationOpExists},
+	})
+	g.Expect(merged).To(HaveLen(2))
+	g.Expect(existing).To(HaveLen(1))
+}
@@ -0,0 +1,186 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"fmt"
+	"strings"
+)
+
+// templateTypes are the fields of an Argo template which define what it runs, exactly one must be set
+var templateTypes = []string{"container", "containerSet", "dag", "data", "http", "plugin", "resource", "script", "steps", "suspend"}
+
+// ValidateSpec checks the workflow spec the way Argo would before running it, so that malformed templates are
+// rejected without submitting them. Only the structure is checked, parameters are resolved when the workflow runs.
+func ValidateSpec(spec interface{}) error {
+	s, ok := spec.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("spec is not an object")
+	}
+
+	if err := validateParameters(s["arguments"], "spec.arguments"); err != nil {
+		return err
+	}
+
+	// Templates are defined elsewhere for workflows referencing a workflow template
+	if _, ok := s["workflowTemplateRef"]; ok {
+		return nil
+	}
+
+	list, ok := s["templates"].([]interface{})
+	if !ok || len(list) == 0 {
+		return fmt.Errorf("spec.templates must be a non-empty list")
+	}
+
+	var templates = make(map[string]map[string]interface{}, len(list))
+	for i, t := range list {
+		tmpl, ok := t.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("spec.templates[%d] is not an object", i)
+		}
+		name, _ := tmpl["name"].(string)
+		if name == "" {
+			return fmt.Errorf("spec.templates[%d].name is required", i)
+		}
+		if _, dup := templates[name]; dup {
+			return fmt.Errorf("spec.templates[%d].name %q is not unique", i, name)
+		}
+		templates[name] = tmpl
+	}
+
+	entrypoint, _ := s["entrypoint"].(string)
+	if entrypoint == "" {
+		return fmt.Errorf("spec.entrypoint is required")
+	}
+	if _, ok := templates[entrypoint]; !ok {
+		return fmt.Errorf("spec.entrypoint template %q is not defined", entrypoint)
+	}
+
+	for i, t := range list {
+		if err := validateTemplate(t.(map[string]interface{}), templates, fmt.Sprintf("spec.templates[%d]", i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateTemplate(tmpl map[string]interface{}, templates map[string]map[string]interface{}, path string) error {
+	var types []string
+	for _, t := range templateTypes {
+		if _, ok := tmpl[t]; ok {
+			types = append(types, t)
+		}
+	}
+	switch len(types) {
+	case 0:
+		return fmt.Errorf("%s template type is unspecified, one of %s is required", path, strings.Join(templateTypes, ", "))
+	case 1:
+	default:
+		return fmt.Errorf("%s has multiple template types %s", path, strings.Join(types, ", "))
+	}
+
+	if inputs, ok := tmpl["inputs"].(map[string]interface{}); ok {
+		if err := validateParameters(inputs, path+".inputs"); err != nil {
+			return err
+		}
+	}
+
+	switch types[0] {
+	case "steps":
+		groups, ok := tmpl["steps"].([]interface{})
+		if !ok {
+			return fmt.Errorf("%s.steps must be a list of step lists", path)
+		}
+		for i, g := range groups {
+			steps, ok := g.([]interface{})
+			if !ok {
+				return fmt.Errorf("%s.steps[%d] must be a list of steps", path, i)
+			}
+			for j, step := range steps {
+				if err := validateStep(step, templates, fmt.Sprintf("%s.steps[%d][%d]", path, i, j)); err != nil {
+					return err
+				}
+			}
+		}
+	case "dag":
+		dag, ok := tmpl["dag"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s.dag is not an object", path)
+		}
+		tasks, ok := dag["tasks"].([]interface{})
+		if !ok || len(tasks) == 0 {
+			return fmt.Errorf("%s.dag.tasks must be a non-empty list", path)
+		}
+		for i, task := range tasks {
+			if err := validateStep(task, templates, fmt.Sprintf("%s.dag.tasks[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateStep checks that a step or dag task is named and runs a template that is defined
+func validateStep(step interface{}, templates map[string]map[string]interface{}, path string) error {
+	s, ok := step.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s is not an object", path)
+	}
+	if name, _ := s["name"].(string); name == "" {
+		return fmt.Errorf("%s.name is required", path)
+	}
+	if _, ok := s["templateRef"]; ok {
+		return nil
+	}
+	template, _ := s["template"].(string)
+	if template == "" {
+		return fmt.Errorf("%s.template or templateRef is required", path)
+	}
+	if _, ok := templates[template]; !ok {
+		return fmt.Errorf("%s.template %q is not defined", path, template)
+	}
+	return nil
+}
+
+// validateParameters checks that the parameters of arguments or inputs are named objects
+func validateParameters(obj interface{}, path string) error {
+	if obj == nil {
+		return nil
+	}
+	o, ok := obj.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s is not an object", path)
+	}
+	params, ok := o["parameters"]
+	if !ok {
+		return nil
+	}
+	list, ok := params.([]interface{})
+	if !ok {
+		return fmt.Errorf("%s.parameters must be a list", path)
+	}
+	for i, p := range list {
+		param, ok := p.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s.parameters[%d] is not an object", path, i)
+		}
+		if name, _ := param["name"].(string); name == "" {
+			return fmt.Errorf("%s.parameters[%d].name is required", path, i)
+		}
+	}
+	return nil
+}
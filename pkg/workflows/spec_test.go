@@ -0,0 +1,148 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflows
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateSpec(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{name: "steps", spec: `
+entrypoint: entry
+arguments:
+  parameters:
+  - name: region
+templates:
+- name: entry
+  steps:
+  - - name: apply
+      template: apply
+    - name: shared
+      templateRef:
+        name: shared
+        template: run
+- name: apply
+  inputs:
+    parameters:
+    - name: manifest
+  resource:
+    action: apply
+    manifest: "{{inputs.parameters.manifest}}"
+`},
+		{name: "dag", spec: `
+entrypoint: entry
+templates:
+- name: entry
+  dag:
+    tasks:
+    - name: a
+      template: echo
+- name: echo
+  container:
+    image: alpine
+`},
+		{name: "workflow-template-ref", spec: `
+workflowTemplateRef:
+  name: shared
+`},
+		{name: "not-object", spec: `- entrypoint`, wantErr: "spec is not an object"},
+		{name: "missing-templates", spec: `entrypoint: entry`, wantErr: "spec.templates must be a non-empty list"},
+		{name: "missing-entrypoint", spec: `
+templates:
+- name: entry
+  container:
+    image: alpine
+`, wantErr: "spec.entrypoint is required"},
+		{name: "undefined-entrypoint", spec: `
+entrypoint: main
+templates:
+- name: entry
+  container:
+    image: alpine
+`, wantErr: `spec.entrypoint template "main" is not defined`},
+		{name: "duplicate-template", spec: `
+entrypoint: entry
+templates:
+- name: entry
+  container:
+    image: alpine
+- name: entry
+  container:
+    image: alpine
+`, wantErr: `spec.templates[1].name "entry" is not unique`},
+		{name: "no-template-type", spec: `
+entrypoint: entry
+templates:
+- name: entry
+`, wantErr: "spec.templates[0] template type is unspecified"},
+		{name: "multiple-template-types", spec: `
+entrypoint: entry
+templates:
+- name: entry
+  container:
+    image: alpine
+  script:
+    image: alpine
+`, wantErr: "spec.templates[0] has multiple template types container, script"},
+		{name: "undefined-step-template", spec: `
+entrypoint: entry
+templates:
+- name: entry
+  steps:
+  - - name: apply
+      template: apply
+`, wantErr: `spec.templates[0].steps[0][0].template "apply" is not defined`},
+		{name: "steps-not-nested", spec: `
+entrypoint: entry
+templates:
+- name: entry
+  steps:
+  - name: apply
+    template: entry
+`, wantErr: "spec.templates[0].steps[0] must be a list of steps"},
+		{name: "unnamed-parameter", spec: `
+entrypoint: entry
+arguments:
+  parameters:
+  - value: foo
+templates:
+- name: entry
+  container:
+    image: alpine
+`, wantErr: "spec.arguments.parameters[0].name is required"},
+	}
+	for _, tt := range tests {
+		var spec interface{}
+		g.Expect(yaml.Unmarshal([]byte(tt.spec), &spec)).To(Succeed(), tt.name)
+
+		err := ValidateSpec(spec)
+		if tt.wantErr == "" {
+			g.Expect(err).NotTo(HaveOccurred(), tt.name)
+			continue
+		}
+		g.Expect(err).To(HaveOccurred(), tt.name)
+		g.Expect(err.Error()).To(HavePrefix(tt.wantErr), tt.name)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -45,10 +46,18 @@ const (
 	WfDefaultActiveDeadlineSeconds = 300
 )
 
+// DefaultWorkflowTTL is how long completed workflows are kept before Argo deletes them, long enough to inspect recent
+// failures
+const DefaultWorkflowTTL = 72 * time.Hour
+
 // AddonLifecycle represents the following workflows
 type AddonLifecycle interface {
-	Install(context.Context, *addonmgrv1alpha1.WorkflowType, string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error)
+	Install(context.Context, *addonmgrv1alpha1.WorkflowType, string, map[string]string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error)
 	Delete(context.Context, string) error
+	Outputs(context.Context, string) (map[string]string, error)
+	Failure(context.Context, string) (string, error)
+	TemplatePhases(context.Context, string) (map[string]addonmgrv1alpha1.ApplicationAssemblyPhase, error)
+	Prune(context.Context, addonmgrv1alpha1.LifecycleStep, int) error
 }
 
 type workflowLifecycle struct {
@@ -57,27 +66,41 @@ type workflowLifecycle struct {
 	addon     *addonmgrv1alpha1.Addon
 	recorder  record.EventRecorder
 	scheme    *runtime.Scheme
+	cluster   ClusterValues
+	ttl       time.Duration
 }
 
-// NewWorkflowLifecycle returns a AddonLifecycle object
-func NewWorkflowLifecycle(client client.Client, dynClient dynamic.Interface, addon *addonmgrv1alpha1.Addon, recorder record.EventRecorder, scheme *runtime.Scheme) AddonLifecycle {
+// NewWorkflowLifecycle returns a AddonLifecycle object, workflows are rendered with the values of the cluster and
+// deleted by Argo once the ttl passed after their completion. Workflows are kept when the ttl is 0.
+func NewWorkflowLifecycle(client client.Client, dynClient dynamic.Interface, addon *addonmgrv1alpha1.Addon, recorder record.EventRecorder, scheme *runtime.Scheme, cluster ClusterValues, ttl time.Duration) AddonLifecycle {
 	return &workflowLifecycle{
 		Client:    client,
 		dynClient: dynClient,
 		addon:     addon,
 		recorder:  recorder,
 		scheme:    scheme,
+		cluster:   cluster,
+		ttl:       ttl,
 	}
 }
 
-func (w *workflowLifecycle) Install(ctx context.Context, wt *addonmgrv1alpha1.WorkflowType, name string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+// Install submits the workflow, params are passed as workflow parameters in addition to the addon params
+func (w *workflowLifecycle) Install(ctx context.Context, wt *addonmgrv1alpha1.WorkflowType, name string, params map[string]string) (addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
 	wp := &unstructured.Unstructured{}
 	err := w.parse(wt, wp, name)
 	if err != nil {
 		return addonmgrv1alpha1.Failed, fmt.Errorf("invalid workflow. %v", err)
 	}
 
-	if !w.configureGlobalWFParameters(w.addon, wp) {
+	if err := RenderAddonFeatures(wp.Object, AddonFeatureValues(w.addon)); err != nil {
+		return addonmgrv1alpha1.Failed, fmt.Errorf("invalid workflow. %v", err)
+	}
+	if err := RenderAddonParams(wp.Object, AddonParamValues(w.addon)); err != nil {
+		return addonmgrv1alpha1.Failed, fmt.Errorf("invalid workflow. %v", err)
+	}
+	RenderClusterParams(wp.Object, w.cluster.Params())
+
+	if !w.configureGlobalWFParameters(w.addon, wp, params) {
 		return addonmgrv1alpha1.Failed, errors.New("invalid workflow parameter")
 	}
 
@@ -90,17 +113,30 @@ func (w *workflowLifecycle) Install(ctx context.Context, wt *addonmgrv1alpha1.Wo
 		return addonmgrv1alpha1.Failed, err
 	}
 
-	if err := w.injectActiveDeadlineSeconds(wp); err != nil {
+	if err := w.injectActiveDeadlineSeconds(wp, wt); err != nil {
+		return addonmgrv1alpha1.Failed, err
+	}
+
+	if err := w.injectServiceAccount(wp); err != nil {
 		return addonmgrv1alpha1.Failed, err
 	}
 
+	if err := w.injectImagePullSecrets(wp); err != nil {
+		return addonmgrv1alpha1.Failed, err
+	}
+
+	if err := w.injectPodTemplate(wp); err != nil {
+		return addonmgrv1alpha1.Failed, err
+	}
+
+	common.SetCommonMetadata(wp, w.addon.Spec.CommonLabels, w.addon.Spec.CommonAnnotations)
 	w.injectInstanceId(wp)
 
 	return w.submit(ctx, wp)
 }
 
-// Appends addon.spec.params to workflow.spec.arguments.parameters
-func (w *workflowLifecycle) configureGlobalWFParameters(addon *addonmgrv1alpha1.Addon, wf *unstructured.Unstructured) bool {
+// Appends addon.spec.params and params to workflow.spec.arguments.parameters
+func (w *workflowLifecycle) configureGlobalWFParameters(addon *addonmgrv1alpha1.Addon, wf *unstructured.Unstructured, params map[string]string) bool {
 	// get workflow argument parameters
 	spec, _ := wf.UnstructuredContent()["spec"].(map[string]interface{})
 	if spec["arguments"] == nil {
@@ -175,6 +211,16 @@ func (w *workflowLifecycle) configureGlobalWFParameters(addon *addonmgrv1alpha1.
 		wfParams = append(wfParams, addParam)
 	}
 
+	// Copy additional params, e.g. prereqs outputs, in a stable order
+	var names = make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		wfParams = append(wfParams, map[string]interface{}{"name": name, "value": params[name]})
+	}
+
 	err := unstructured.SetNestedSlice(wf.UnstructuredContent(), wfParams, "spec", "arguments", "parameters")
 	if err != nil {
 		return false
@@ -184,13 +230,134 @@ func (w *workflowLifecycle) configureGlobalWFParameters(addon *addonmgrv1alpha1.
 }
 
 func (w *workflowLifecycle) Delete(ctx context.Context, name string) error {
-	err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(w.addon.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(w.addon.GetWorkflowNamespace()).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// Outputs returns the global output parameters of the named workflow
+func (w *workflowLifecycle) Outputs(ctx context.Context, name string) (map[string]string, error) {
+	workflow, err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(w.addon.GetWorkflowNamespace()).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not find workflow %s/%s. %v", w.addon.GetWorkflowNamespace(), name, err)
+	}
+
+	parameters, _, err := unstructured.NestedSlice(workflow.Object, "status", "outputs", "parameters")
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs = make(map[string]string, len(parameters))
+	for _, p := range parameters {
+		param, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(param, "name")
+		value, found, _ := unstructured.NestedString(param, "value")
+		if name != "" && found {
+			outputs[name] = value
+		}
+	}
+
+	return outputs, nil
+}
+
+// Failure returns the failed step and message of the named workflow
+func (w *workflowLifecycle) Failure(ctx context.Context, name string) (string, error) {
+	workflow, err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(w.addon.GetWorkflowNamespace()).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("could not find workflow %s/%s. %v", w.addon.GetWorkflowNamespace(), name, err)
+	}
+
+	return WorkflowFailure(workflow), nil
+}
+
+// TemplatePhases returns the phases of the workflow templates run by the named workflow
+func (w *workflowLifecycle) TemplatePhases(ctx context.Context, name string) (map[string]addonmgrv1alpha1.ApplicationAssemblyPhase, error) {
+	workflow, err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(w.addon.GetWorkflowNamespace()).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not find workflow %s/%s. %v", w.addon.GetWorkflowNamespace(), name, err)
+	}
+
+	return TemplatePhases(workflow), nil
+}
+
+// Prune deletes the completed workflows of a lifecycle step run for previous specs of the addon, keeping the limit
+// most recently finished ones. The workflow of the current spec is never deleted, a negative limit keeps all workflows.
+func (w *workflowLifecycle) Prune(ctx context.Context, lifecycleStep addonmgrv1alpha1.LifecycleStep, limit int) error {
+	if limit < 0 {
+		return nil
+	}
+
+	wt, err := w.addon.GetWorkflowType(lifecycleStep)
+	if err != nil {
+		return err
+	}
+	prefix := w.addon.GetName()
+	if wt.NamePrefix != "" {
+		prefix = fmt.Sprintf("%s-%s", prefix, wt.NamePrefix)
+	}
+	prefix = fmt.Sprintf("%s-%s-", prefix, lifecycleStep)
+	current := w.addon.GetFormattedWorkflowName(lifecycleStep)
+
+	workflows, err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(w.addon.GetWorkflowNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list workflows. %v", err)
+	}
+
+	var history []unstructured.Unstructured
+	for _, workflow := range workflows.Items {
+		name := workflow.GetName()
+		if name == current || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, "-wf") || !w.ownsWorkflow(&workflow) {
+			continue
+		}
+		phase, _, _ := unstructured.NestedString(workflow.Object, "status", "phase")
+		if phase != "Succeeded" && phase != "Failed" && phase != "Error" {
+			continue
+		}
+		history = append(history, workflow)
+	}
+
+	if len(history) <= limit {
+		return nil
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return finishedAt(&history[i]).After(finishedAt(&history[j]))
+	})
+
+	for _, workflow := range history[limit:] {
+		if err := w.Delete(ctx, workflow.GetName()); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete workflow %s. %v", workflow.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// ownsWorkflow returns true if the workflow is owned by the addon, or labeled for it outside of the addon namespace
+func (w *workflowLifecycle) ownsWorkflow(workflow *unstructured.Unstructured) bool {
+	if ref := metav1.GetControllerOf(workflow); ref != nil {
+		return ref.UID == w.addon.GetUID()
+	}
+	return workflow.GetLabels()[common.NameLabel] == w.addon.GetName()
+}
+
+// finishedAt returns when the workflow finished, or when it was created if it has no finish time
+func finishedAt(workflow *unstructured.Unstructured) time.Time {
+	value, _, _ := unstructured.NestedString(workflow.Object, "status", "finishedAt")
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	return workflow.GetCreationTimestamp().Time
+}
+
+// findWorkflowByName returns the workflow, nil when it does not exist. Workflows of deleting addons are read live, the
+// cache can still hold a delete workflow which was deleted, e.g. while the controller was down, which would then never
+// be submitted again.
 func (w *workflowLifecycle) findWorkflowByName(ctx context.Context, name types.NamespacedName) (*unstructured.Unstructured, error) {
 	found := &unstructured.Unstructured{}
 	found.SetGroupVersionKind(schema.GroupVersionKind{
@@ -198,11 +365,17 @@ func (w *workflowLifecycle) findWorkflowByName(ctx context.Context, name types.N
 		Group:   "argoproj.io",
 		Version: "v1alpha1",
 	})
-	err := w.Get(ctx, name, found)
+
+	var err error
+	if w.addon.GetDeletionTimestamp() != nil {
+		found, err = w.dynClient.Resource(common.WorkflowGVR()).Namespace(name.Namespace).Get(ctx, name.Name, metav1.GetOptions{})
+	} else {
+		err = w.Get(ctx, name, found)
+	}
 	if err != nil && apierrors.IsNotFound(err) {
 		return nil, nil
 	} else if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not find workflow %s. %w", name, err)
 	}
 
 	return found, nil
@@ -245,12 +418,21 @@ func (w *workflowLifecycle) submit(ctx context.Context, wp *unstructured.Unstruc
 		})
 		wfv1.SetNamespace(wp.GetNamespace())
 		wfv1.SetName(wp.GetName())
-		// Set the owner references for workflow
-		if err := controllerutil.SetControllerReference(w.addon, wfv1, w.scheme); err != nil {
-			return addonmgrv1alpha1.Failed, err
+		if wfv1.GetNamespace() == w.addon.GetNamespace() {
+			// Set the owner references for workflow
+			if err := controllerutil.SetControllerReference(w.addon, wfv1, w.scheme); err != nil {
+				return addonmgrv1alpha1.Failed, err
+			}
+		} else {
+			// Owner references cannot cross namespaces, label the workflow so it can be mapped back to the addon
+			w.addDefaultLabelsToResource(wfv1)
 		}
 
 		err = w.Create(ctx, wfv1)
+		if apierrors.IsAlreadyExists(err) {
+			// Submitted by an earlier reconcile which has not been observed yet
+			return addonmgrv1alpha1.Pending, nil
+		}
 		if err != nil {
 			return addonmgrv1alpha1.Failed, err
 		}
@@ -260,14 +442,9 @@ func (w *workflowLifecycle) submit(ctx context.Context, wp *unstructured.Unstruc
 		return addonmgrv1alpha1.Pending, nil
 	}
 
-	workflow, err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(wfv1.GetNamespace()).Get(ctx, wfv1.GetName(), metav1.GetOptions{})
-	if err != nil {
-		return addonmgrv1alpha1.Failed, fmt.Errorf("could not find workflow %s/%s. %v", wfv1.GetNamespace(), wfv1.GetName(), err)
-	}
-
 	// validate workflow status
 	var phase = addonmgrv1alpha1.Pending
-	status, ok := workflow.UnstructuredContent()["status"].(map[string]interface{})
+	status, ok := wfv1.UnstructuredContent()["status"].(map[string]interface{})
 	if ok && status["phase"] == "Succeeded" {
 		phase = addonmgrv1alpha1.Succeeded
 	} else if ok && status["phase"] == "Failed" {
@@ -302,7 +479,7 @@ func (w *workflowLifecycle) parse(wt *addonmgrv1alpha1.WorkflowType, wf *unstruc
 		Version: "v1alpha1",
 	})
 
-	wf.SetNamespace(w.addon.GetNamespace())
+	wf.SetNamespace(w.addon.GetWorkflowNamespace())
 	wf.SetName(name)
 
 	if _, foundSpec, err := unstructured.NestedFieldNoCopy(wf.Object, "spec"); err != nil || !foundSpec {
@@ -423,6 +600,9 @@ func (w *workflowLifecycle) processArtifact(obj string, resource *unstructured.U
 
 	resource.SetUnstructuredContent(data)
 
+	// Add the common labels and annotations of the addon, default labels take precedence
+	common.SetCommonMetadata(resource, w.addon.Spec.CommonLabels, w.addon.Spec.CommonAnnotations)
+
 	// Add the default labels to the resource
 	w.addDefaultLabelsToResource(resource)
 
@@ -472,7 +652,7 @@ func (w *workflowLifecycle) deleteCollisionWorkflows(ctx context.Context) (bool,
 	var mostRecentWorkflow unstructured.Unstructured
 	var deleted = false
 
-	workflows, err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(w.addon.GetNamespace()).List(ctx, metav1.ListOptions{})
+	workflows, err := w.dynClient.Resource(common.WorkflowGVR()).Namespace(w.addon.GetWorkflowNamespace()).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return false, fmt.Errorf("failed to list workflows. %v", err)
 	}
@@ -499,11 +679,13 @@ func (w *workflowLifecycle) deleteCollisionWorkflows(ctx context.Context) (bool,
 		return false, nil
 	}
 
-	// If the most recently run workflow doesn't have the current checksum, delete the old checksum workflows
-	if !strings.Contains(mostRecentWorkflow.GetName(), w.addon.Status.Checksum) {
+	// If the most recently run workflow doesn't have the current config hash, delete the workflows of the current spec
+	// which ran before, so that a spec that is changed back is installed again
+	hash := w.addon.CalculateConfigHash()
+	if !strings.Contains(mostRecentWorkflow.GetName(), hash) {
 		for _, workflow := range workflows.Items {
 			phase := workflow.UnstructuredContent()["status"].(map[string]interface{})["phase"].(string)
-			if strings.Contains(workflow.GetName(), w.addon.Status.Checksum) && phase != "Pending" {
+			if strings.Contains(workflow.GetName(), hash) && phase != "Pending" {
 				_ = w.Delete(ctx, workflow.GetName())
 				deleted = true
 			}
@@ -521,16 +703,18 @@ func (w *workflowLifecycle) injectTTLs(wf *unstructured.Unstructured) error {
 	//secondsAfterSuccess: 5     # Time to live after workflow is successful
 	//secondsAfterFailure: 5     # Time to live after workflow fails
 
-	// Default ttl is to cleanup workflows after 3 days
-	var ttl, _ = time.ParseDuration("72h")
+	if w.ttl <= 0 {
+		return nil
+	}
+
 	val, found, err := unstructured.NestedInt64(wf.UnstructuredContent(), "spec", "ttlStrategy", "secondsAfterCompletion")
 	if err != nil {
 		return err
 	}
 
-	// Make sure workflows by default get cleaned up after 3 days
+	// The ttl of the template is kept, other workflows are cleaned up once the ttl passed after their completion
 	if !found || val == 0 {
-		err = unstructured.SetNestedField(wf.Object, int64(ttl.Seconds()), "spec", "ttlStrategy", "secondsAfterCompletion")
+		err = unstructured.SetNestedField(wf.Object, int64(w.ttl.Seconds()), "spec", "ttlStrategy", "secondsAfterCompletion")
 		if err != nil {
 			return err
 		}
@@ -547,11 +731,61 @@ func (w *workflowLifecycle) injectInstanceId(wp *unstructured.Unstructured) {
 	}
 
 	labels[WfInstanceIdLabelKey] = WfInstanceId
+	if w.addon.Spec.Lifecycle.WorkflowInstanceID != "" {
+		// Pin the workflow to the workflow-controller shard of the addon
+		labels[WfInstanceIdLabelKey] = w.addon.Spec.Lifecycle.WorkflowInstanceID
+	}
 
 	wp.SetLabels(labels)
 }
 
-func (w *workflowLifecycle) injectActiveDeadlineSeconds(wf *unstructured.Unstructured) error {
+func (w *workflowLifecycle) injectServiceAccount(wf *unstructured.Unstructured) error {
+	// Addon service account takes precedence over the one in the template
+	if w.addon.Spec.Lifecycle.ServiceAccount == "" {
+		return nil
+	}
+
+	return unstructured.SetNestedField(wf.Object, w.addon.Spec.Lifecycle.ServiceAccount, "spec", "serviceAccountName")
+}
+
+// injectImagePullSecrets adds the image pull secrets of the addon to the workflow spec, which applies them to all pods
+// of the workflow. Pull secrets of the workflow template are kept.
+func (w *workflowLifecycle) injectImagePullSecrets(wf *unstructured.Unstructured) error {
+	names := w.addon.Spec.Lifecycle.ImagePullSecrets
+	if len(names) == 0 {
+		return nil
+	}
+
+	secrets, _, err := unstructured.NestedSlice(wf.Object, "spec", "imagePullSecrets")
+	if err != nil {
+		return fmt.Errorf("invalid workflow image pull secrets. %v", err)
+	}
+
+	var found = make(map[string]bool, len(secrets)+len(names))
+	for _, s := range secrets {
+		if ref, ok := s.(map[string]interface{}); ok {
+			if name, ok := ref["name"].(string); ok {
+				found[name] = true
+			}
+		}
+	}
+	for _, name := range names {
+		if !found[name] {
+			secrets = append(secrets, map[string]interface{}{"name": name})
+			found[name] = true
+		}
+	}
+
+	return unstructured.SetNestedSlice(wf.Object, secrets, "spec", "imagePullSecrets")
+}
+
+// injectActiveDeadlineSeconds sets the deadline of the workflow type, or the default deadline when neither the workflow
+// type nor the template set one
+func (w *workflowLifecycle) injectActiveDeadlineSeconds(wf *unstructured.Unstructured, wt *addonmgrv1alpha1.WorkflowType) error {
+	if wt.ActiveDeadlineSeconds > 0 {
+		return unstructured.SetNestedField(wf.Object, wt.ActiveDeadlineSeconds, "spec", "activeDeadlineSeconds")
+	}
+
 	val, found, err := unstructured.NestedInt64(wf.UnstructuredContent(), "spec", "activeDeadlineSeconds")
 	if err != nil {
 		return err
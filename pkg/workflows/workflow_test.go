@@ -16,6 +16,7 @@ package workflows
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -23,12 +24,14 @@ import (
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v3"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	dynfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -320,7 +323,7 @@ func TestNewWorkflowLifecycle(t *testing.T) {
 
 	a := &v1alpha1.Addon{}
 
-	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch)
+	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
 
 	var expected AddonLifecycle = &workflowLifecycle{}
 	g.Expect(wfl).To(BeAssignableToTypeOf(expected))
@@ -376,13 +379,13 @@ func TestWorkflowLifecycle_Install_Resources(t *testing.T) {
 		},
 	}
 
-	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch)
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
 	for _, lifecycle := range []v1alpha1.LifecycleStep{v1alpha1.Prereqs, v1alpha1.Install} {
 
 		wfName := addon.GetFormattedWorkflowName(lifecycle)
 		wt, _ := addon.GetWorkflowType(lifecycle)
 
-		phase, err := wfl.Install(context.Background(), wt, wfName)
+		phase, err := wfl.Install(context.Background(), wt, wfName, nil)
 
 		g.Expect(err).To(Not(HaveOccurred()))
 		g.Expect(phase).To(Equal(v1alpha1.Pending))
@@ -519,13 +522,13 @@ func TestWorkflowLifecycle_Install_Artifacts(t *testing.T) {
 		},
 	}
 
-	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch)
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
 	for _, lifecycle := range []v1alpha1.LifecycleStep{v1alpha1.Prereqs, v1alpha1.Install} {
 
 		wfName := addon.GetFormattedWorkflowName(lifecycle)
 		wt, _ := addon.GetWorkflowType(lifecycle)
 
-		phase, err := wfl.Install(context.Background(), wt, wfName)
+		phase, err := wfl.Install(context.Background(), wt, wfName, nil)
 
 		g.Expect(err).To(Not(HaveOccurred()))
 		g.Expect(phase).To(Equal(v1alpha1.Pending))
@@ -599,7 +602,521 @@ func TestWorkflowLifecycle_Install_Artifacts(t *testing.T) {
 
 }
 
+func TestWorkflowLifecycle_Install_WorkflowNamespace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addon-wf-ns",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{
+				PkgName:    "my-addon-ns",
+				PkgVersion: "1.0.0",
+				PkgType:    v1alpha1.CompositePkg,
+			},
+			Lifecycle: v1alpha1.LifecycleWorkflowSpec{
+				Install: v1alpha1.WorkflowType{
+					Template: wfSpecTemplate,
+				},
+			},
+			WorkflowNamespace: "workflows",
+		},
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+
+	wfName := addon.GetFormattedWorkflowName(v1alpha1.Install)
+	wt, _ := addon.GetWorkflowType(v1alpha1.Install)
+
+	phase, err := wfl.Install(context.Background(), wt, wfName, nil)
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	var wfv1 = &unstructured.Unstructured{}
+	wfv1.SetGroupVersionKind(schema.GroupVersionKind{
+		Kind:    "Workflow",
+		Group:   "argoproj.io",
+		Version: "v1alpha1",
+	})
+	var wfv1Key = types.NamespacedName{Name: wfName, Namespace: "workflows"}
+	g.Eventually(func() error { return fclient.Get(context.TODO(), wfv1Key, wfv1) }, timeout).
+		Should(Succeed())
+
+	// Verify workflow is labeled instead of owned across namespaces
+	g.Expect(wfv1.GetOwnerReferences()).To(BeEmpty())
+	g.Expect(wfv1.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/name", addon.GetName()))
+	g.Expect(wfv1.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "addonmgr.keikoproj.io"))
+}
+
 // Test that an empty workflow type will fail
+func TestWorkflowLifecycle_Install_ServiceAccount(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addon-wf-sa",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{
+				PkgName:    "my-addon-sa",
+				PkgVersion: "1.0.0",
+				PkgType:    v1alpha1.CompositePkg,
+			},
+			Lifecycle: v1alpha1.LifecycleWorkflowSpec{
+				Install: v1alpha1.WorkflowType{
+					Template: wfSpecTemplate,
+				},
+				ServiceAccount: "addon-installer",
+			},
+		},
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+
+	wfName := addon.GetFormattedWorkflowName(v1alpha1.Install)
+	wt, _ := addon.GetWorkflowType(v1alpha1.Install)
+
+	phase, err := wfl.Install(context.Background(), wt, wfName, nil)
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	var wfv1 = &unstructured.Unstructured{}
+	wfv1.SetGroupVersionKind(schema.GroupVersionKind{
+		Kind:    "Workflow",
+		Group:   "argoproj.io",
+		Version: "v1alpha1",
+	})
+	var wfv1Key = types.NamespacedName{Name: wfName, Namespace: "default"}
+	g.Eventually(func() error { return fclient.Get(context.TODO(), wfv1Key, wfv1) }, timeout).
+		Should(Succeed())
+
+	// Addon service account replaces the template service account
+	sa, _, _ := unstructured.NestedString(wfv1.Object, "spec", "serviceAccountName")
+	g.Expect(sa).To(Equal("addon-installer"))
+}
+
+func TestWorkflowLifecycle_Install_ImagePullSecrets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addon-wf-pull-secrets",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{
+				PkgName:    "my-addon-pull-secrets",
+				PkgVersion: "1.0.0",
+				PkgType:    v1alpha1.CompositePkg,
+			},
+			Lifecycle: v1alpha1.LifecycleWorkflowSpec{
+				Install: v1alpha1.WorkflowType{
+					Template: wfSpecTemplate,
+				},
+				ImagePullSecrets: []string{"registry", "mirror", "registry"},
+			},
+		},
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+
+	wfName := addon.GetFormattedWorkflowName(v1alpha1.Install)
+	wt, _ := addon.GetWorkflowType(v1alpha1.Install)
+
+	phase, err := wfl.Install(context.Background(), wt, wfName, nil)
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	var wfv1 = &unstructured.Unstructured{}
+	wfv1.SetGroupVersionKind(schema.GroupVersionKind{
+		Kind:    "Workflow",
+		Group:   "argoproj.io",
+		Version: "v1alpha1",
+	})
+	var wfv1Key = types.NamespacedName{Name: wfName, Namespace: "default"}
+	g.Eventually(func() error { return fclient.Get(context.TODO(), wfv1Key, wfv1) }, timeout).
+		Should(Succeed())
+
+	// All pods of the workflow pull images with the addon pull secrets
+	secrets, _, _ := unstructured.NestedSlice(wfv1.Object, "spec", "imagePullSecrets")
+	g.Expect(secrets).To(Equal([]interface{}{
+		map[string]interface{}{"name": "registry"},
+		map[string]interface{}{"name": "mirror"},
+	}))
+}
+
+func TestInjectImagePullSecrets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{}
+	w := &workflowLifecycle{addon: addon}
+
+	wf := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"imagePullSecrets": []interface{}{map[string]interface{}{"name": "template"}},
+		},
+	}}
+
+	// Without pull secrets the workflow is unchanged
+	g.Expect(w.injectImagePullSecrets(wf)).To(Succeed())
+	g.Expect(wf.Object["spec"]).To(Equal(map[string]interface{}{
+		"imagePullSecrets": []interface{}{map[string]interface{}{"name": "template"}},
+	}))
+
+	// Pull secrets of the template are kept
+	addon.Spec.Lifecycle.ImagePullSecrets = []string{"template", "registry"}
+	g.Expect(w.injectImagePullSecrets(wf)).To(Succeed())
+	secrets, _, _ := unstructured.NestedSlice(wf.Object, "spec", "imagePullSecrets")
+	g.Expect(secrets).To(Equal([]interface{}{
+		map[string]interface{}{"name": "template"},
+		map[string]interface{}{"name": "registry"},
+	}))
+}
+
+func TestInjectTTLs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	w := &workflowLifecycle{ttl: time.Hour}
+	wf := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+	g.Expect(w.injectTTLs(wf)).To(Succeed())
+	ttl, _, _ := unstructured.NestedInt64(wf.Object, "spec", "ttlStrategy", "secondsAfterCompletion")
+	g.Expect(ttl).To(Equal(int64(3600)))
+
+	// The ttl of the template is kept
+	wf = &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"ttlStrategy": map[string]interface{}{"secondsAfterCompletion": int64(60)}},
+	}}
+	g.Expect(w.injectTTLs(wf)).To(Succeed())
+	ttl, _, _ = unstructured.NestedInt64(wf.Object, "spec", "ttlStrategy", "secondsAfterCompletion")
+	g.Expect(ttl).To(Equal(int64(60)))
+
+	// Workflows are kept without a ttl
+	w.ttl = 0
+	wf = &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+	g.Expect(w.injectTTLs(wf)).To(Succeed())
+	g.Expect(wf.Object["spec"]).To(BeEmpty())
+}
+
+func TestInjectActiveDeadlineSeconds(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	w := &workflowLifecycle{}
+	wf := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"activeDeadlineSeconds": int64(600)},
+	}}
+	g.Expect(w.injectActiveDeadlineSeconds(wf, &v1alpha1.WorkflowType{})).To(Succeed())
+	deadline, _, _ := unstructured.NestedInt64(wf.Object, "spec", "activeDeadlineSeconds")
+	g.Expect(deadline).To(Equal(int64(600)))
+
+	// The deadline of the workflow type replaces the one of the template
+	g.Expect(w.injectActiveDeadlineSeconds(wf, &v1alpha1.WorkflowType{ActiveDeadlineSeconds: 1800})).To(Succeed())
+	deadline, _, _ = unstructured.NestedInt64(wf.Object, "spec", "activeDeadlineSeconds")
+	g.Expect(deadline).To(Equal(int64(1800)))
+}
+
+func TestWorkflowLifecycle_Install_WorkflowInstanceID(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addon-wf-instanceid",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{
+				PkgName:    "my-addon-instanceid",
+				PkgVersion: "1.0.0",
+				PkgType:    v1alpha1.CompositePkg,
+			},
+			Lifecycle: v1alpha1.LifecycleWorkflowSpec{
+				Install: v1alpha1.WorkflowType{
+					Template: wfSpecTemplate,
+				},
+				WorkflowInstanceID: "shard-b",
+			},
+		},
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+
+	wfName := addon.GetFormattedWorkflowName(v1alpha1.Install)
+	wt, _ := addon.GetWorkflowType(v1alpha1.Install)
+
+	phase, err := wfl.Install(context.Background(), wt, wfName, nil)
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	var wfv1 = &unstructured.Unstructured{}
+	wfv1.SetGroupVersionKind(schema.GroupVersionKind{
+		Kind:    "Workflow",
+		Group:   "argoproj.io",
+		Version: "v1alpha1",
+	})
+	var wfv1Key = types.NamespacedName{Name: wfName, Namespace: "default"}
+	g.Eventually(func() error { return fclient.Get(context.TODO(), wfv1Key, wfv1) }, timeout).
+		Should(Succeed())
+
+	// The workflow is only picked up by the workflow-controller of the instance id
+	g.Expect(wfv1.GetLabels()).To(HaveKeyWithValue("workflows.argoproj.io/controller-instanceid", "shard-b"))
+}
+
+func TestWorkflowLifecycle_Install_CommonMetadata(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addon-wf-common",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{
+				PkgName:    "my-addon-common",
+				PkgVersion: "1.0.0",
+				PkgType:    v1alpha1.CompositePkg,
+			},
+			CommonLabels: map[string]string{
+				"team":                   "platform",
+				"app.kubernetes.io/name": "other",
+				"workflows.argoproj.io/controller-instanceid": "other",
+			},
+			CommonAnnotations: map[string]string{"cost-center": "1234"},
+			Lifecycle: v1alpha1.LifecycleWorkflowSpec{
+				Install: v1alpha1.WorkflowType{
+					Template: wfSpecTemplate,
+				},
+			},
+		},
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+
+	wfName := addon.GetFormattedWorkflowName(v1alpha1.Install)
+	wt, _ := addon.GetWorkflowType(v1alpha1.Install)
+
+	phase, err := wfl.Install(context.Background(), wt, wfName, nil)
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	var wfv1 = &unstructured.Unstructured{}
+	wfv1.SetGroupVersionKind(schema.GroupVersionKind{
+		Kind:    "Workflow",
+		Group:   "argoproj.io",
+		Version: "v1alpha1",
+	})
+	var wfv1Key = types.NamespacedName{Name: wfName, Namespace: "default"}
+	g.Eventually(func() error { return fclient.Get(context.TODO(), wfv1Key, wfv1) }, timeout).
+		Should(Succeed())
+
+	// Common labels are added to the workflow without replacing the labels set by addon-manager
+	g.Expect(wfv1.GetLabels()).To(HaveKeyWithValue("team", "platform"))
+	g.Expect(wfv1.GetLabels()).To(HaveKeyWithValue("workflows.argoproj.io/controller-instanceid", "addon-manager-workflow-controller"))
+	g.Expect(wfv1.GetAnnotations()).To(HaveKeyWithValue("cost-center", "1234"))
+
+	templates, _, _ := unstructured.NestedSlice(wfv1.UnstructuredContent(), "spec", "templates")
+	manifest, found, _ := unstructured.NestedString(templates[1].(map[string]interface{}), "resource", "manifest")
+	g.Expect(found).To(BeTrue())
+
+	for _, obj := range strings.Split(manifest, "---\n") {
+		var data map[string]interface{}
+		g.Expect(yaml.Unmarshal([]byte(obj), &data)).To(Succeed())
+
+		u := &unstructured.Unstructured{}
+		u.SetUnstructuredContent(data)
+		g.Expect(u.GetLabels()).To(HaveKeyWithValue("team", "platform"))
+		g.Expect(u.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/name", addon.GetName()))
+		g.Expect(u.GetAnnotations()).To(HaveKeyWithValue("cost-center", "1234"))
+	}
+}
+
+func TestWorkflowLifecycle_Install_Params(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addon-wf-params",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{
+				PkgName:    "my-addon-params",
+				PkgVersion: "1.0.0",
+				PkgType:    v1alpha1.CompositePkg,
+			},
+			Lifecycle: v1alpha1.LifecycleWorkflowSpec{
+				Install: v1alpha1.WorkflowType{
+					Template: wfSpecTemplate,
+				},
+			},
+		},
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+
+	wfName := addon.GetFormattedWorkflowName(v1alpha1.Install)
+	wt, _ := addon.GetWorkflowType(v1alpha1.Install)
+
+	phase, err := wfl.Install(context.Background(), wt, wfName, map[string]string{"password": "s3cret"})
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	var wfv1 = &unstructured.Unstructured{}
+	wfv1.SetGroupVersionKind(schema.GroupVersionKind{
+		Kind:    "Workflow",
+		Group:   "argoproj.io",
+		Version: "v1alpha1",
+	})
+	var wfv1Key = types.NamespacedName{Name: wfName, Namespace: "default"}
+	g.Eventually(func() error { return fclient.Get(context.TODO(), wfv1Key, wfv1) }, timeout).
+		Should(Succeed())
+
+	params, _, _ := unstructured.NestedSlice(wfv1.Object, "spec", "arguments", "parameters")
+	g.Expect(params).To(ContainElement(map[string]interface{}{"name": "password", "value": "s3cret"}))
+}
+
+func TestWorkflowLifecycle_Outputs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addon-wf-outputs",
+			Namespace: "default",
+		},
+	}
+
+	wf := &unstructured.Unstructured{}
+	wf.SetGroupVersionKind(schema.GroupVersionKind{
+		Kind:    "Workflow",
+		Group:   "argoproj.io",
+		Version: "v1alpha1",
+	})
+	wf.SetNamespace("default")
+	wf.SetName("addon-wf-outputs-prereqs-wf")
+	_ = unstructured.SetNestedSlice(wf.Object, []interface{}{
+		map[string]interface{}{"name": "password", "value": "s3cret"},
+		map[string]interface{}{"name": "unset"},
+	}, "status", "outputs", "parameters")
+
+	_, err := dynClient.Resource(common.WorkflowGVR()).Namespace("default").Create(ctx, wf, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+
+	outputs, err := wfl.Outputs(ctx, "addon-wf-outputs-prereqs-wf")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(outputs).To(Equal(map[string]string{"password": "s3cret"}))
+
+	_, err = wfl.Outputs(ctx, "addon-wf-missing")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestWorkflowLifecycle_Prune(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addon-wf-prune",
+			Namespace: "prune",
+		},
+	}
+	current := addon.GetFormattedWorkflowName(v1alpha1.Install)
+
+	workflows := []struct {
+		name       string
+		phase      string
+		finishedAt string
+		owner      string
+	}{
+		{name: current, phase: "Succeeded", finishedAt: "2021-06-05T00:00:00Z", owner: addon.Name},
+		{name: "addon-wf-prune-install-a1-wf", phase: "Succeeded", finishedAt: "2021-06-04T00:00:00Z", owner: addon.Name},
+		{name: "addon-wf-prune-install-a2-wf", phase: "Failed", finishedAt: "2021-06-03T00:00:00Z", owner: addon.Name},
+		{name: "addon-wf-prune-install-a3-wf", phase: "Succeeded", finishedAt: "2021-06-02T00:00:00Z", owner: addon.Name},
+		{name: "addon-wf-prune-install-a4-wf", phase: "Running", owner: addon.Name},
+		{name: "addon-wf-prune-prereqs-a1-wf", phase: "Succeeded", finishedAt: "2021-06-01T00:00:00Z", owner: addon.Name},
+		{name: "addon-wf-prune-install-b1-wf", phase: "Succeeded", finishedAt: "2021-06-01T00:00:00Z", owner: "other"},
+	}
+	for _, w := range workflows {
+		wf := &unstructured.Unstructured{}
+		wf.SetGroupVersionKind(schema.GroupVersionKind{
+			Kind:    "Workflow",
+			Group:   "argoproj.io",
+			Version: "v1alpha1",
+		})
+		wf.SetNamespace("prune")
+		wf.SetName(w.name)
+		wf.SetLabels(map[string]string{common.NameLabel: w.owner})
+		_ = unstructured.SetNestedField(wf.Object, w.phase, "status", "phase")
+		_ = unstructured.SetNestedField(wf.Object, w.finishedAt, "status", "finishedAt")
+
+		_, err := dynClient.Resource(common.WorkflowGVR()).Namespace("prune").Create(ctx, wf, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+	g.Expect(wfl.Prune(ctx, v1alpha1.Install, 1)).To(Succeed())
+
+	list, err := dynClient.Resource(common.WorkflowGVR()).Namespace("prune").List(ctx, metav1.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	var names []string
+	for _, wf := range list.Items {
+		names = append(names, wf.GetName())
+	}
+	g.Expect(names).To(ConsistOf(
+		current,
+		"addon-wf-prune-install-a1-wf",
+		"addon-wf-prune-install-a4-wf",
+		"addon-wf-prune-prereqs-a1-wf",
+		"addon-wf-prune-install-b1-wf",
+	))
+}
+
+func TestWorkflowLifecycle_Install_Revert(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newAddon := func(version string) *v1alpha1.Addon {
+		return &v1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon-wf-revert", Namespace: "revert"},
+			Spec: v1alpha1.AddonSpec{
+				PackageSpec: v1alpha1.PackageSpec{PkgName: "my-addon", PkgVersion: version, PkgType: v1alpha1.HelmPkg},
+				Params:      v1alpha1.AddonParams{Namespace: "my-addon-ns"},
+				Lifecycle:   v1alpha1.LifecycleWorkflowSpec{Install: v1alpha1.WorkflowType{Template: wfSpecTemplate}},
+			},
+		}
+	}
+
+	// The spec went from A to B, the workflows of both are kept by the history
+	a, b := newAddon("1.0.0"), newAddon("2.0.0")
+	for i, addon := range []*v1alpha1.Addon{a, b} {
+		wf := &unstructured.Unstructured{}
+		wf.SetGroupVersionKind(schema.GroupVersionKind{
+			Kind:    "Workflow",
+			Group:   "argoproj.io",
+			Version: "v1alpha1",
+		})
+		wf.SetNamespace("revert")
+		wf.SetName(addon.GetFormattedWorkflowName(v1alpha1.Install))
+		_ = unstructured.SetNestedField(wf.Object, "Succeeded", "status", "phase")
+		_ = unstructured.SetNestedField(wf.Object, fmt.Sprintf("2021-06-0%dT00:00:00Z", i+1), "status", "startedAt")
+
+		g.Expect(fclient.Create(ctx, wf.DeepCopy())).To(Succeed())
+		_, err := dynClient.Resource(common.WorkflowGVR()).Namespace("revert").Create(ctx, wf, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	// Reverting to A installs it again instead of reporting the workflow which ran before B
+	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
+	phase, err := wfl.Install(ctx, &a.Spec.Lifecycle.Install, a.GetFormattedWorkflowName(v1alpha1.Install), nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	_, err = dynClient.Resource(common.WorkflowGVR()).Namespace("revert").Get(ctx, a.GetFormattedWorkflowName(v1alpha1.Install), metav1.GetOptions{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	_, err = dynClient.Resource(common.WorkflowGVR()).Namespace("revert").Get(ctx, b.GetFormattedWorkflowName(v1alpha1.Install), metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
 func TestWorkflowLifecycle_Install_InvalidWorkflowType(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -624,12 +1141,12 @@ func TestWorkflowLifecycle_Install_InvalidWorkflowType(t *testing.T) {
 		},
 	}
 
-	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch)
+	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
 
 	// Empty workflow type should fail
 	wt := &v1alpha1.WorkflowType{}
 
-	phase, err := wfl.Install(context.Background(), wt, "addon-wf-test")
+	phase, err := wfl.Install(context.Background(), wt, "addon-wf-test", nil)
 
 	g.Expect(err).To(HaveOccurred())
 	g.Expect(phase).To(Equal(v1alpha1.Failed))
@@ -660,14 +1177,14 @@ func TestWorkflowLifecycle_Install_InvalidWorkflowTemplate(t *testing.T) {
 		},
 	}
 
-	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch)
+	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
 
 	// Workflow missing "spec" should fail
 	wt := &v1alpha1.WorkflowType{
 		Template: wfInvalidTemplate,
 	}
 
-	phase, err := wfl.Install(context.Background(), wt, "addon-wf-test")
+	phase, err := wfl.Install(context.Background(), wt, "addon-wf-test", nil)
 
 	g.Expect(err).To(HaveOccurred())
 	g.Expect(phase).To(Equal(v1alpha1.Failed))
@@ -697,11 +1214,29 @@ func TestWorkflowLifecycle_Delete_NotExists(t *testing.T) {
 		},
 	}
 
-	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch)
+	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
 
 	g.Expect(wfl.Delete(ctx, "addon-wf-test")).To(HaveOccurred())
 }
 
+func TestWorkflowLifecycle_FindWorkflowByName_Retryable(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := metav1.Now()
+	a := &v1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", DeletionTimestamp: &now}}
+
+	// Delete workflows are read live, API errors of the lookup keep their type so that they are retried
+	throttled := dynfake.NewSimpleDynamicClient(sch)
+	throttled.PrependReactor("get", "workflows", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewTooManyRequests("throttled", 1)
+	})
+	wfl := &workflowLifecycle{Client: fclient, dynClient: throttled, addon: a}
+
+	_, err := wfl.findWorkflowByName(ctx, types.NamespacedName{Name: "foo-delete-1a2b3c4d-wf", Namespace: "default"})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(common.IsRetryable(err)).To(BeTrue())
+}
+
 func TestNewWorkflowLifecycle_Delete(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -726,7 +1261,7 @@ func TestNewWorkflowLifecycle_Delete(t *testing.T) {
 		},
 	}
 
-	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch)
+	wfl := NewWorkflowLifecycle(fclient, dynClient, a, rcdr, sch, ClusterValues{}, DefaultWorkflowTTL)
 
 	wf := &unstructured.Unstructured{}
 	wf.SetGroupVersionKind(schema.GroupVersionKind{
@@ -744,3 +1279,43 @@ func TestNewWorkflowLifecycle_Delete(t *testing.T) {
 	// Now try to delete
 	g.Expect(wfl.Delete(ctx, "addon-wf-test")).To(Not(HaveOccurred()))
 }
+
+func TestWorkflowLifecycle_Install_ClusterParams(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	addon := &v1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-wf-cluster", Namespace: "default"},
+		Spec: v1alpha1.AddonSpec{
+			PackageSpec: v1alpha1.PackageSpec{PkgName: "my-addon-cluster", PkgVersion: "1.0.0", PkgType: v1alpha1.CompositePkg},
+			Lifecycle: v1alpha1.LifecycleWorkflowSpec{
+				Install: v1alpha1.WorkflowType{Template: `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+spec:
+  entrypoint: entry
+  templates:
+  - name: entry
+    container:
+      image: busybox
+      args: ["{{cluster.name}}", "{{cluster.environment}}"]
+`},
+			},
+		},
+	}
+
+	wfl := NewWorkflowLifecycle(fclient, dynClient, addon, rcdr, sch, ClusterValues{Name: "prod-usw2", Environment: "prod"}, DefaultWorkflowTTL)
+	wfName := addon.GetFormattedWorkflowName(v1alpha1.Install)
+	wt, _ := addon.GetWorkflowType(v1alpha1.Install)
+
+	phase, err := wfl.Install(context.Background(), wt, wfName, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(v1alpha1.Pending))
+
+	var wfv1 = &unstructured.Unstructured{}
+	wfv1.SetGroupVersionKind(schema.GroupVersionKind{Kind: "Workflow", Group: "argoproj.io", Version: "v1alpha1"})
+	g.Expect(fclient.Get(context.TODO(), types.NamespacedName{Name: wfName, Namespace: "default"}, wfv1)).To(Succeed())
+
+	templates, _, _ := unstructured.NestedSlice(wfv1.Object, "spec", "templates")
+	args, _, _ := unstructured.NestedStringSlice(templates[0].(map[string]interface{}), "container", "args")
+	g.Expect(args).To(Equal([]string{"prod-usw2", "prod"}))
+}
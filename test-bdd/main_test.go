@@ -29,6 +29,7 @@ import (
 	apiextcs "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -124,15 +125,12 @@ var _ = Describe("Addon Mgr should install CRD and Addon correctly", func() {
 			Expect(found).To(BeTrue())
 			dataParams, found, _ := unstructured.NestedMap(addonObject.UnstructuredContent(), "spec", "params", "data")
 			Expect(found).To(BeFalse())
-			addonChecksum, found, _ := unstructured.NestedString(addonObject.UnstructuredContent(), "status", "checksum")
+			_, found, _ = unstructured.NestedString(addonObject.UnstructuredContent(), "status", "checksum")
 			Expect(found).To(BeTrue())
 
-			workflowTemplatePrefixName, found, _ := unstructured.NestedString(addonObject.UnstructuredContent(), "spec", "lifecycle", workflowLifecycleStep, "namePrefix")
-			prefix := addonName
-			if found {
-				prefix = fmt.Sprintf("%s-%s", prefix, workflowTemplatePrefixName)
-			}
-			workflowIdentifierName := fmt.Sprintf("%s-%s-%s-wf", prefix, workflowLifecycleStep, addonChecksum)
+			var addon = &addonmgrv1alpha1.Addon{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(addonObject.UnstructuredContent(), addon)).To(Succeed())
+			workflowIdentifierName := addon.GetFormattedWorkflowName(addonmgrv1alpha1.LifecycleStep(workflowLifecycleStep))
 
 			workflow, err := dynClient.Resource(workflowGroupSchema).Namespace(addonNamespace).Get(ctx, workflowIdentifierName, metav1.GetOptions{})
 			Expect(err).NotTo(HaveOccurred())